@@ -0,0 +1,186 @@
+// Package client 是 paguu API 的 Go 客户端
+//
+// 请求和响应结构体以及每个接口的方法由 cmd/openapi 根据 OpenAPI 文档生成 (zz_generated.go)，
+// 修改 handler 或路由后运行 go generate ./client 重新生成
+//
+//	c := client.New("http://localhost:8080", client.WithAPIKey(key), client.WithWorkspace("backend"))
+//	page, err := c.ListArticles(ctx, &client.ListArticlesParams{Tags: []string{"Go"}})
+package client
+
+//go:generate go run ../cmd/openapi -client zz_generated.go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client 调用 paguu API，可以被多个 goroutine 同时使用
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	credential string
+	workspace  string
+}
+
+// Option 是 Client 的配置项
+type Option func(*Client)
+
+// WithAPIKey 设置凭证，API key 和 POST /auth/token 签发的 JWT 都可以
+func WithAPIKey(credential string) Option {
+	return func(c *Client) {
+		c.credential = credential
+	}
+}
+
+// WithWorkspace 设置请求的工作区 (标识)，不设置时为 default
+func WithWorkspace(slug string) Option {
+	return func(c *Client) {
+		c.workspace = slug
+	}
+}
+
+// WithHTTPClient 设置发送请求的 http.Client，默认超时 60 秒
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// New 创建客户端，baseURL 为服务地址 (不含 /api/v1)
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// InWorkspace 返回使用另一个工作区的客户端，与原客户端共享凭证和 http.Client
+func (c *Client) InWorkspace(slug string) *Client {
+	copied := *c
+	copied.workspace = slug
+	return &copied
+}
+
+// Error 是接口返回的错误，应按 Code 而不是 Message 判断错误类型
+type Error struct {
+	StatusCode int
+	Code       string // 稳定的错误码，如 validation_failed、article_not_found、rate_limited
+	Message    string
+	RequestID  string        // 请求 ID，排查问题时提供给服务端
+	Details    []FieldError  // 字段校验失败 (validation_failed) 时每个字段的错误
+	RetryAfter time.Duration // 限流或超出每日配额 (429) 时建议的等待时间
+}
+
+// FieldError 是一个字段的校验错误
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("paguu: %d %s", e.StatusCode, e.Message)
+	if e.Code != "" {
+		msg = fmt.Sprintf("paguu: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.RetryAfter)
+	}
+	return msg
+}
+
+// Page 是带分页的列表响应
+type Page[T any] struct {
+	Data       []T        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// envelope 是只需要 data 字段的响应
+type envelope[T any] struct {
+	Data T `json:"data"`
+}
+
+// do 发送请求，2xx 时将响应解码到 out，否则返回 *Error
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.credential != "" {
+		req.Header.Set("Authorization", "Bearer "+c.credential)
+	}
+	if c.workspace != "" {
+		req.Header.Set("X-Workspace", c.workspace)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// decodeError 解析错误响应 {"error": "...", "code": "...", "request_id": "...", "details": [...], "retry_after": 秒}
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{StatusCode: resp.StatusCode, Message: resp.Status, RequestID: resp.Header.Get("X-Request-ID")}
+	var body struct {
+		Error      string       `json:"error"`
+		Code       string       `json:"code"`
+		RequestID  string       `json:"request_id"`
+		Details    []FieldError `json:"details"`
+		RetryAfter int          `json:"retry_after"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		apiErr.Message = body.Error
+		apiErr.Code = body.Code
+		apiErr.Details = body.Details
+		if body.RequestID != "" {
+			apiErr.RequestID = body.RequestID
+		}
+	}
+	retryAfter := body.RetryAfter
+	if retryAfter == 0 {
+		retryAfter, _ = strconv.Atoi(resp.Header.Get("Retry-After"))
+	}
+	apiErr.RetryAfter = time.Duration(retryAfter) * time.Second
+	return apiErr
+}
@@ -0,0 +1,1519 @@
+// Code generated by cmd/openapi from the OpenAPI document (paguu API v1). DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type APIKeyResponse struct {
+	ID         int64    `json:"id,omitempty"`
+	UserID     int64    `json:"user_id,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	Prefix     string   `json:"prefix,omitempty"`
+	Scopes     []string `json:"scopes,omitempty"`
+	ExpiresAt  *string  `json:"expires_at,omitempty"`
+	LastUsedAt *string  `json:"last_used_at,omitempty"`
+	RevokedAt  *string  `json:"revoked_at,omitempty"`
+	CreatedAt  string   `json:"created_at,omitempty"`
+}
+
+type AddTagAliasRequest struct {
+	Tag   string `json:"tag"`
+	Alias string `json:"alias"`
+}
+
+type AnswerAttemptResponse struct {
+	ID              int64    `json:"id,omitempty"`
+	ArticleID       int64    `json:"article_id,omitempty"`
+	User            string   `json:"user,omitempty"`
+	Answer          string   `json:"answer,omitempty"`
+	Score           int64    `json:"score,omitempty"`
+	CoveredConcepts []string `json:"covered_concepts,omitempty"`
+	MissedConcepts  []string `json:"missed_concepts,omitempty"`
+	FollowUps       []string `json:"follow_ups,omitempty"`
+	Feedback        *string  `json:"feedback,omitempty"`
+	Model           string   `json:"model,omitempty"`
+	CreatedAt       string   `json:"created_at,omitempty"`
+}
+
+type ArticleResponse struct {
+	ID               int64      `json:"id,omitempty"`
+	OriginalQuestion string     `json:"original_question,omitempty"`
+	DetailedQuestion *string    `json:"detailed_question,omitempty"`
+	ConciseAnswer    *string    `json:"concise_answer,omitempty"`
+	Tags             []string   `json:"tags,omitempty"`
+	CreatedAt        string     `json:"created_at,omitempty"`
+	UpdatedAt        string     `json:"updated_at,omitempty"`
+	Similarity       *float64   `json:"similarity,omitempty"`
+	RerankScore      *float64   `json:"rerank_score,omitempty"`
+	VectorRank       *int64     `json:"vector_rank,omitempty"`
+	Duplicates       []ExtEntry `json:"duplicates,omitempty"`
+	OccurrenceCount  *int64     `json:"occurrence_count,omitempty"`
+}
+
+type AskRequest struct {
+	Question      string  `json:"question"`
+	Limit         int64   `json:"limit,omitempty"`
+	MinSimilarity float64 `json:"min_similarity,omitempty"`
+	Stream        bool    `json:"stream,omitempty"`
+	Enqueue       bool    `json:"enqueue,omitempty"`
+	Source        string  `json:"source,omitempty"`
+}
+
+type AuditLogResponse struct {
+	ID        int64  `json:"id,omitempty"`
+	User      string `json:"user,omitempty"`
+	KeyID     *int64 `json:"key_id,omitempty"`
+	Method    string `json:"method,omitempty"`
+	Route     string `json:"route,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Status    int64  `json:"status,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+type AuthTokenResponse struct {
+	Token     string `json:"token,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+type CacheStats struct {
+	MemoryHits     int64 `json:"memory_hits,omitempty"`
+	StoreHits      int64 `json:"store_hits,omitempty"`
+	Misses         int64 `json:"misses,omitempty"`
+	MemorySize     int64 `json:"memory_size,omitempty"`
+	MemoryCapacity int64 `json:"memory_capacity,omitempty"`
+}
+
+type ClusterResponse struct {
+	ID           int64    `json:"id,omitempty"`
+	RunID        int64    `json:"run_id,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	Summary      *string  `json:"summary,omitempty"`
+	TopTags      []string `json:"top_tags,omitempty"`
+	Size         int64    `json:"size,omitempty"`
+	ArticleCount int64    `json:"article_count,omitempty"`
+	Recent       int64    `json:"recent,omitempty"`
+	Previous     int64    `json:"previous,omitempty"`
+	Growth       int64    `json:"growth,omitempty"`
+	CreatedAt    string   `json:"created_at,omitempty"`
+}
+
+type ClusterRunResponse struct {
+	ID           int64  `json:"id,omitempty"`
+	K            int64  `json:"k,omitempty"`
+	ArticleCount int64  `json:"article_count,omitempty"`
+	NamingModel  string `json:"naming_model,omitempty"`
+	CreatedAt    string `json:"created_at,omitempty"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name,omitempty"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int64    `json:"expires_in_days,omitempty"`
+}
+
+type CreateAPIKeyResponse struct {
+	Data    APIKeyResponse `json:"data,omitempty"`
+	Key     string         `json:"key,omitempty"`
+	Message string         `json:"message,omitempty"`
+}
+
+type CreateInterviewRequest struct {
+	User   string   `json:"user,omitempty"`
+	Tags   []string `json:"tags"`
+	Level  string   `json:"level"`
+	Length int64    `json:"length,omitempty"`
+	Days   int64    `json:"days,omitempty"`
+}
+
+type CreateInterviewResponse struct {
+	Data    InterviewResponse `json:"data"`
+	Message string            `json:"message,omitempty"`
+}
+
+type CreateTaskRequest struct {
+	RawQuestions string         `json:"raw_questions"`
+	Source       string         `json:"source,omitempty"`
+	Metadata     map[string]any `json:"metadata,omitempty"`
+	NoCache      bool           `json:"no_cache,omitempty"`
+}
+
+type CreateTaskResponse struct {
+	Data    TaskResponse `json:"data"`
+	Message string       `json:"message,omitempty"`
+	TaskID  string       `json:"task_id,omitempty"`
+}
+
+type CreateUserRequest struct {
+	Name string `json:"name"`
+}
+
+type CreateWorkspaceRequest struct {
+	Slug               string  `json:"slug"`
+	Name               string  `json:"name,omitempty"`
+	EnrichModel        *string `json:"enrich_model,omitempty"`
+	EnrichTemplatePath *string `json:"enrich_template_path,omitempty"`
+}
+
+type DueCardResponse struct {
+	Card    ReviewCardResponse `json:"card,omitempty"`
+	Article ArticleResponse    `json:"article,omitempty"`
+}
+
+type DuplicateResolutionResponse struct {
+	ID          int64  `json:"id,omitempty"`
+	Status      string `json:"status,omitempty"`
+	ArticleID   int64  `json:"article_id,omitempty"`
+	CandidateID int64  `json:"candidate_id,omitempty"`
+}
+
+type DuplicateReviewResponse struct {
+	ID         int64            `json:"id,omitempty"`
+	Status     string           `json:"status,omitempty"`
+	Similarity float64          `json:"similarity,omitempty"`
+	CreatedAt  string           `json:"created_at,omitempty"`
+	ResolvedAt *string          `json:"resolved_at,omitempty"`
+	Article    *ArticleResponse `json:"article,omitempty"`
+	Candidate  *ArticleResponse `json:"candidate,omitempty"`
+}
+
+type EnrichmentCacheStats struct {
+	Model           string `json:"model,omitempty"`
+	TemplateVersion string `json:"template_version,omitempty"`
+	Entries         int64  `json:"entries,omitempty"`
+	Hits            int64  `json:"hits,omitempty"`
+}
+
+type ExtEntry struct {
+	OriginalQuestion string   `json:"original_question,omitempty"`
+	DetailedQuestion string   `json:"detailed_question,omitempty"`
+	ConciseAnswer    string   `json:"concise_answer,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+	SourceArticleID  *int64   `json:"source_article_id,omitempty"`
+	OccurrenceIDs    []int64  `json:"occurrence_ids,omitempty"`
+}
+
+type FindSimilarArticlesResponse struct {
+	Data     []ArticleResponse `json:"data"`
+	SourceID int64             `json:"source_id,omitempty"`
+	Limit    int64             `json:"limit,omitempty"`
+}
+
+type GetArticleResponse struct {
+	Data           ArticleResponse `json:"data"`
+	RedirectedFrom int64           `json:"redirected_from,omitempty"`
+}
+
+type GetClusterResponse struct {
+	Data ClusterResponse `json:"data"`
+	Days int64           `json:"days,omitempty"`
+}
+
+type GetEmbeddingCacheStatsResponse struct {
+	Data          CacheStats `json:"data"`
+	StoredEntries int64      `json:"stored_entries,omitempty"`
+}
+
+type GetTagCooccurrenceResponse struct {
+	Data   TagCooccurrenceResponse `json:"data"`
+	Cached bool                    `json:"cached,omitempty"`
+}
+
+type GetTagGraphResponse struct {
+	Data   TagGraph `json:"data"`
+	Cached bool     `json:"cached,omitempty"`
+}
+
+type GetTagStatsResponse struct {
+	Data   []TagStat `json:"data"`
+	Cached bool      `json:"cached,omitempty"`
+}
+
+type GetUsageResponse struct {
+	Data  []UsageSummary     `json:"data"`
+	Total UsageTotalResponse `json:"total,omitempty"`
+	From  string             `json:"from,omitempty"`
+	To    string             `json:"to,omitempty"`
+}
+
+type GradeAnswerRequest struct {
+	Answer string `json:"answer"`
+	User   string `json:"user,omitempty"`
+}
+
+type GradeAnswerResponse struct {
+	Data           AnswerAttemptResponse `json:"data"`
+	Article        ArticleResponse       `json:"article,omitempty"`
+	RedirectedFrom int64                 `json:"redirected_from,omitempty"`
+}
+
+type HotArticleResponse struct {
+	ID               int64      `json:"id,omitempty"`
+	OriginalQuestion string     `json:"original_question,omitempty"`
+	DetailedQuestion *string    `json:"detailed_question,omitempty"`
+	ConciseAnswer    *string    `json:"concise_answer,omitempty"`
+	Tags             []string   `json:"tags,omitempty"`
+	CreatedAt        string     `json:"created_at,omitempty"`
+	UpdatedAt        string     `json:"updated_at,omitempty"`
+	Similarity       *float64   `json:"similarity,omitempty"`
+	RerankScore      *float64   `json:"rerank_score,omitempty"`
+	VectorRank       *int64     `json:"vector_rank,omitempty"`
+	Duplicates       []ExtEntry `json:"duplicates,omitempty"`
+	OccurrenceCount  *int64     `json:"occurrence_count,omitempty"`
+	LastSeen         string     `json:"last_seen,omitempty"`
+}
+
+type HotQuestionsResponse struct {
+	Data []HotTagResponse `json:"data"`
+	Days int64            `json:"days,omitempty"`
+}
+
+type HotTagResponse struct {
+	Tag             string               `json:"tag,omitempty"`
+	OccurrenceCount int64                `json:"occurrence_count,omitempty"`
+	Articles        []HotArticleResponse `json:"articles,omitempty"`
+}
+
+type InterviewItemResponse struct {
+	Position   int64            `json:"position,omitempty"`
+	Tag        string           `json:"tag,omitempty"`
+	Depth      int64            `json:"depth,omitempty"`
+	Article    *ArticleResponse `json:"article,omitempty"`
+	Answer     *string          `json:"answer,omitempty"`
+	AttemptID  *int64           `json:"attempt_id,omitempty"`
+	AnsweredAt *string          `json:"answered_at,omitempty"`
+}
+
+type InterviewResponse struct {
+	ID          int64                   `json:"id,omitempty"`
+	User        string                  `json:"user,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Level       string                  `json:"level,omitempty"`
+	Length      int64                   `json:"length,omitempty"`
+	Answered    int64                   `json:"answered,omitempty"`
+	Status      string                  `json:"status,omitempty"`
+	ReplayOf    *int64                  `json:"replay_of,omitempty"`
+	CreatedAt   string                  `json:"created_at,omitempty"`
+	CompletedAt *string                 `json:"completed_at,omitempty"`
+	Items       []InterviewItemResponse `json:"items,omitempty"`
+}
+
+type InvalidateEnrichCacheResponse struct {
+	Deleted int64 `json:"deleted,omitempty"`
+}
+
+type ListArticleOccurrencesResponse struct {
+	Data           []OccurrenceResponse `json:"data"`
+	Pagination     Pagination           `json:"pagination"`
+	Summary        OccurrenceSummary    `json:"summary,omitempty"`
+	RedirectedFrom int64                `json:"redirected_from,omitempty"`
+}
+
+type ListClustersResponse struct {
+	Data []ClusterResponse  `json:"data"`
+	Run  ClusterRunResponse `json:"run,omitempty"`
+	Days int64              `json:"days,omitempty"`
+	Sort string             `json:"sort,omitempty"`
+}
+
+type ListDueCardsResponse struct {
+	Data     []DueCardResponse `json:"data"`
+	TotalDue int64             `json:"total_due,omitempty"`
+}
+
+type MergeArticlesRequest struct {
+	SourceIDs []int64 `json:"source_ids"`
+}
+
+type MergeArticlesResponse struct {
+	Data      ArticleResponse `json:"data"`
+	MergedIDs []int64         `json:"merged_ids,omitempty"`
+}
+
+type MergeTagsRequest struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+type OccurrenceResponse struct {
+	ID         int64    `json:"id,omitempty"`
+	Kind       string   `json:"kind,omitempty"`
+	Question   string   `json:"question,omitempty"`
+	RawLine    *string  `json:"raw_line,omitempty"`
+	TaskID     *string  `json:"task_id,omitempty"`
+	Source     string   `json:"source,omitempty"`
+	Metadata   any      `json:"metadata,omitempty"`
+	Similarity *float64 `json:"similarity,omitempty"`
+	SeenAt     string   `json:"seen_at,omitempty"`
+}
+
+type OccurrenceSummary struct {
+	Count     int64         `json:"count,omitempty"`
+	FirstSeen *time.Time    `json:"first_seen,omitempty"`
+	LastSeen  *time.Time    `json:"last_seen,omitempty"`
+	Sources   []SourceCount `json:"sources,omitempty"`
+}
+
+type Pagination struct {
+	Page           int64  `json:"page,omitempty"`
+	PageSize       int64  `json:"page_size,omitempty"`
+	Total          int64  `json:"total,omitempty"`
+	TotalPage      int64  `json:"total_page,omitempty"`
+	TotalEstimated bool   `json:"total_estimated,omitempty"`
+	NextCursor     string `json:"next_cursor,omitempty"`
+}
+
+type QuotaResponse struct {
+	Tasks          int64  `json:"tasks,omitempty"`
+	TasksLimit     int64  `json:"tasks_limit,omitempty"`
+	Questions      int64  `json:"questions,omitempty"`
+	QuestionsLimit int64  `json:"questions_limit,omitempty"`
+	ResetsAt       string `json:"resets_at,omitempty"`
+}
+
+type RecordReviewRequest struct {
+	User   string `json:"user,omitempty"`
+	Rating string `json:"rating"`
+}
+
+type RecordReviewResponse struct {
+	Data           ReviewCardResponse `json:"data"`
+	RedirectedFrom int64              `json:"redirected_from,omitempty"`
+}
+
+type RenameTagRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type ReplayInterviewRequest struct {
+	User string `json:"user,omitempty"`
+}
+
+type ReviewCardResponse struct {
+	ArticleID      int64   `json:"article_id,omitempty"`
+	Ease           float64 `json:"ease,omitempty"`
+	IntervalDays   int64   `json:"interval_days,omitempty"`
+	Repetitions    int64   `json:"repetitions,omitempty"`
+	Lapses         int64   `json:"lapses,omitempty"`
+	DueAt          string  `json:"due_at,omitempty"`
+	LastReviewedAt *string `json:"last_reviewed_at,omitempty"`
+}
+
+type ReviewStats struct {
+	Total    int64 `json:"total,omitempty"`
+	Due      int64 `json:"due,omitempty"`
+	New      int64 `json:"new,omitempty"`
+	Reviewed int64 `json:"reviewed,omitempty"`
+}
+
+type SetTagParentRequest struct {
+	Tag    string  `json:"tag"`
+	Parent *string `json:"parent,omitempty"`
+}
+
+type SetWorkspaceMemberRequest struct {
+	Role string `json:"role"`
+}
+
+type SourceCount struct {
+	Source string `json:"source,omitempty"`
+	Count  int64  `json:"count,omitempty"`
+}
+
+type SubmitInterviewAnswerRequest struct {
+	Position int64  `json:"position"`
+	Answer   string `json:"answer"`
+	Grade    bool   `json:"grade,omitempty"`
+}
+
+type SubmitInterviewAnswerResponse struct {
+	Data    InterviewItemResponse  `json:"data"`
+	Status  string                 `json:"status,omitempty"`
+	Attempt *AnswerAttemptResponse `json:"attempt,omitempty"`
+}
+
+type SubscribeTagsRequest struct {
+	User   string   `json:"user,omitempty"`
+	Tags   []string `json:"tags"`
+	Enroll *bool    `json:"enroll,omitempty"`
+}
+
+type SubscribeTagsResponse struct {
+	Data     []string `json:"data"`
+	Enrolled int64    `json:"enrolled,omitempty"`
+}
+
+type TagCooccurrenceResponse struct {
+	Tags   []string  `json:"tags,omitempty"`
+	Matrix [][]int64 `json:"matrix,omitempty"`
+	Pairs  []TagPair `json:"pairs,omitempty"`
+}
+
+type TagGraph struct {
+	Nodes []TagGraphNode `json:"nodes,omitempty"`
+	Edges []TagGraphEdge `json:"edges,omitempty"`
+}
+
+type TagGraphEdge struct {
+	Source string `json:"source,omitempty"`
+	Target string `json:"target,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Weight int64  `json:"weight,omitempty"`
+}
+
+type TagGraphNode struct {
+	Name            string    `json:"name,omitempty"`
+	ArticleCount    int64     `json:"article_count,omitempty"`
+	OccurrenceCount int64     `json:"occurrence_count,omitempty"`
+	NewestArticleAt time.Time `json:"newest_article_at,omitempty"`
+	Parent          *string   `json:"parent,omitempty"`
+}
+
+type TagInfo struct {
+	Name    string   `json:"name,omitempty"`
+	Parent  *string  `json:"parent,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+type TagPair struct {
+	A     string `json:"a,omitempty"`
+	B     string `json:"b,omitempty"`
+	Count int64  `json:"count,omitempty"`
+}
+
+type TagStat struct {
+	Name            string    `json:"name,omitempty"`
+	ArticleCount    int64     `json:"article_count,omitempty"`
+	OccurrenceCount int64     `json:"occurrence_count,omitempty"`
+	NewestArticleAt time.Time `json:"newest_article_at,omitempty"`
+}
+
+type TaskResponse struct {
+	TaskID    string `json:"task_id,omitempty"`
+	Source    string `json:"source,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+type UnmergeArticleRequest struct {
+	Index *int64 `json:"index"`
+}
+
+type UnmergeArticleResponse struct {
+	Data     ArticleResponse `json:"data"`
+	SourceID int64           `json:"source_id,omitempty"`
+}
+
+type UnsubscribeTagsResponse struct {
+	Removed int64 `json:"removed,omitempty"`
+}
+
+type UpdateWorkspaceRequest struct {
+	Name               *string `json:"name,omitempty"`
+	EnrichModel        *string `json:"enrich_model,omitempty"`
+	EnrichTemplatePath *string `json:"enrich_template_path,omitempty"`
+}
+
+type UpdatedArticlesResponse struct {
+	UpdatedArticles int64 `json:"updated_articles,omitempty"`
+}
+
+type UsageSummary struct {
+	Day              string  `json:"day,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	Source           string  `json:"source,omitempty"`
+	Calls            int64   `json:"calls,omitempty"`
+	Errors           int64   `json:"errors,omitempty"`
+	PromptTokens     int64   `json:"prompt_tokens,omitempty"`
+	CompletionTokens int64   `json:"completion_tokens,omitempty"`
+	TotalTokens      int64   `json:"total_tokens,omitempty"`
+	Cost             float64 `json:"cost,omitempty"`
+	AvgLatencyMs     float64 `json:"avg_latency_ms,omitempty"`
+}
+
+type UsageTotalResponse struct {
+	Calls  int64   `json:"calls,omitempty"`
+	Tokens int64   `json:"tokens,omitempty"`
+	Cost   float64 `json:"cost,omitempty"`
+}
+
+type UserResponse struct {
+	ID         int64   `json:"id,omitempty"`
+	Name       string  `json:"name,omitempty"`
+	CreatedAt  string  `json:"created_at,omitempty"`
+	DisabledAt *string `json:"disabled_at,omitempty"`
+}
+
+type VectorSearchRequest struct {
+	Query          string `json:"query"`
+	Limit          int64  `json:"limit"`
+	Rerank         bool   `json:"rerank,omitempty"`
+	Candidates     int64  `json:"candidates,omitempty"`
+	Days           int64  `json:"days,omitempty"`
+	Source         string `json:"source,omitempty"`
+	Company        string `json:"company,omitempty"`
+	MinOccurrences int64  `json:"min_occurrences,omitempty"`
+	Sort           string `json:"sort,omitempty"`
+}
+
+type VectorSearchResponse struct {
+	Data     []ArticleResponse `json:"data"`
+	Query    string            `json:"query,omitempty"`
+	Reranked bool              `json:"reranked,omitempty"`
+}
+
+type WorkspaceMemberResponse struct {
+	UserID    int64  `json:"user_id,omitempty"`
+	User      string `json:"user,omitempty"`
+	Role      string `json:"role,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+type WorkspaceResponse struct {
+	ID                 int64   `json:"id,omitempty"`
+	Slug               string  `json:"slug,omitempty"`
+	Name               string  `json:"name,omitempty"`
+	EnrichModel        *string `json:"enrich_model,omitempty"`
+	EnrichTemplatePath *string `json:"enrich_template_path,omitempty"`
+	Role               string  `json:"role,omitempty"`
+	CreatedAt          string  `json:"created_at,omitempty"`
+}
+
+// ListArticlesParams 是 ListArticles 的查询参数
+type ListArticlesParams struct {
+	Page           int64
+	PageSize       int64
+	Cursor         string
+	Tags           []string
+	Sort           string
+	Order          string
+	Total          string
+	Days           int64
+	Source         string
+	Company        string
+	MinOccurrences int64
+}
+
+func (p *ListArticlesParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Page != 0 {
+		v.Set("page", fmt.Sprint(p.Page))
+	}
+	if p.PageSize != 0 {
+		v.Set("page_size", fmt.Sprint(p.PageSize))
+	}
+	if p.Cursor != "" {
+		v.Set("cursor", p.Cursor)
+	}
+	for _, item := range p.Tags {
+		v.Add("tags[]", item)
+	}
+	if p.Sort != "" {
+		v.Set("sort", p.Sort)
+	}
+	if p.Order != "" {
+		v.Set("order", p.Order)
+	}
+	if p.Total != "" {
+		v.Set("total", p.Total)
+	}
+	if p.Days != 0 {
+		v.Set("days", fmt.Sprint(p.Days))
+	}
+	if p.Source != "" {
+		v.Set("source", p.Source)
+	}
+	if p.Company != "" {
+		v.Set("company", p.Company)
+	}
+	if p.MinOccurrences != 0 {
+		v.Set("min_occurrences", fmt.Sprint(p.MinOccurrences))
+	}
+	return v
+}
+
+// ListArticles 文章列表 (GET /api/v1/articles，需要 read 权限，成功时返回 200)
+// 可以用 page 按页码分页，或者把 pagination.next_cursor 作为 cursor 按游标分页 (排序方式需与签发游标时一致)；total=estimate 时 total 为估计值，游标分页默认使用估计值
+func (c *Client) ListArticles(ctx context.Context, params *ListArticlesParams) (Page[ArticleResponse], error) {
+	var out Page[ArticleResponse]
+	err := c.do(ctx, "GET", "/api/v1/articles", params.values(), nil, &out)
+	return out, err
+}
+
+// HotQuestionsParams 是 HotQuestions 的查询参数
+type HotQuestionsParams struct {
+	Tags     []string
+	TagLimit int64
+	Limit    int64
+	Days     int64
+	Source   string
+	Company  string
+}
+
+func (p *HotQuestionsParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	for _, item := range p.Tags {
+		v.Add("tags[]", item)
+	}
+	if p.TagLimit != 0 {
+		v.Set("tag_limit", fmt.Sprint(p.TagLimit))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", fmt.Sprint(p.Limit))
+	}
+	if p.Days != 0 {
+		v.Set("days", fmt.Sprint(p.Days))
+	}
+	if p.Source != "" {
+		v.Set("source", p.Source)
+	}
+	if p.Company != "" {
+		v.Set("company", p.Company)
+	}
+	return v
+}
+
+// HotQuestions 按标签分组的高频题 (GET /api/v1/articles/hot，需要 read 权限，成功时返回 200)
+func (c *Client) HotQuestions(ctx context.Context, params *HotQuestionsParams) (HotQuestionsResponse, error) {
+	var out HotQuestionsResponse
+	err := c.do(ctx, "GET", "/api/v1/articles/hot", params.values(), nil, &out)
+	return out, err
+}
+
+// VectorSearch 向量搜索 (POST /api/v1/articles/search，需要 read 权限，成功时返回 200)
+func (c *Client) VectorSearch(ctx context.Context, body VectorSearchRequest) (VectorSearchResponse, error) {
+	var out VectorSearchResponse
+	err := c.do(ctx, "POST", "/api/v1/articles/search", nil, body, &out)
+	return out, err
+}
+
+// GetArticle 文章详情 (GET /api/v1/articles/{id}，需要 read 权限，成功时返回 200)
+func (c *Client) GetArticle(ctx context.Context, id int64) (GetArticleResponse, error) {
+	var out GetArticleResponse
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/articles/%d", id), nil, nil, &out)
+	return out, err
+}
+
+// GradeAnswer 作答评分 (POST /api/v1/articles/{id}/grade，需要 write 权限，成功时返回 201)
+func (c *Client) GradeAnswer(ctx context.Context, id int64, body GradeAnswerRequest) (GradeAnswerResponse, error) {
+	var out GradeAnswerResponse
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/articles/%d/grade", id), nil, body, &out)
+	return out, err
+}
+
+// MergeArticles 合并文章 (POST /api/v1/articles/{id}/merge，需要 write 权限，成功时返回 200)
+func (c *Client) MergeArticles(ctx context.Context, id int64, body MergeArticlesRequest) (MergeArticlesResponse, error) {
+	var out MergeArticlesResponse
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/articles/%d/merge", id), nil, body, &out)
+	return out, err
+}
+
+// ListArticleOccurrencesParams 是 ListArticleOccurrences 的查询参数
+type ListArticleOccurrencesParams struct {
+	Page     int64
+	PageSize int64
+}
+
+func (p *ListArticleOccurrencesParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Page != 0 {
+		v.Set("page", fmt.Sprint(p.Page))
+	}
+	if p.PageSize != 0 {
+		v.Set("page_size", fmt.Sprint(p.PageSize))
+	}
+	return v
+}
+
+// ListArticleOccurrences 文章的出现记录 (GET /api/v1/articles/{id}/occurrences，需要 read 权限，成功时返回 200)
+func (c *Client) ListArticleOccurrences(ctx context.Context, id int64, params *ListArticleOccurrencesParams) (ListArticleOccurrencesResponse, error) {
+	var out ListArticleOccurrencesResponse
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/articles/%d/occurrences", id), params.values(), nil, &out)
+	return out, err
+}
+
+// FindSimilarArticlesParams 是 FindSimilarArticles 的查询参数
+type FindSimilarArticlesParams struct {
+	Limit int64
+}
+
+func (p *FindSimilarArticlesParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Limit != 0 {
+		v.Set("limit", fmt.Sprint(p.Limit))
+	}
+	return v
+}
+
+// FindSimilarArticles 相似文章 (GET /api/v1/articles/{id}/similar，需要 read 权限，成功时返回 200)
+func (c *Client) FindSimilarArticles(ctx context.Context, id int64, params *FindSimilarArticlesParams) (FindSimilarArticlesResponse, error) {
+	var out FindSimilarArticlesResponse
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/articles/%d/similar", id), params.values(), nil, &out)
+	return out, err
+}
+
+// UnmergeArticle 拆分重复项为新文章 (POST /api/v1/articles/{id}/unmerge，需要 write 权限，成功时返回 201)
+func (c *Client) UnmergeArticle(ctx context.Context, id int64, body UnmergeArticleRequest) (UnmergeArticleResponse, error) {
+	var out UnmergeArticleResponse
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/articles/%d/unmerge", id), nil, body, &out)
+	return out, err
+}
+
+// Ask 检索增强问答 (POST /api/v1/ask，需要 read 权限，成功时返回 200)
+// stream=true 时以 text/event-stream 返回；没有足够相关的文章时返回 answered=false 和最接近的文章，enqueue=true 时同时创建丰富化任务
+func (c *Client) Ask(ctx context.Context, body AskRequest) (map[string]any, error) {
+	var out map[string]any
+	err := c.do(ctx, "POST", "/api/v1/ask", nil, body, &out)
+	return out, err
+}
+
+// ListAnswerAttemptsParams 是 ListAnswerAttempts 的查询参数
+type ListAnswerAttemptsParams struct {
+	Page      int64
+	PageSize  int64
+	ArticleID int64
+	User      string
+	MaxScore  *int64
+}
+
+func (p *ListAnswerAttemptsParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Page != 0 {
+		v.Set("page", fmt.Sprint(p.Page))
+	}
+	if p.PageSize != 0 {
+		v.Set("page_size", fmt.Sprint(p.PageSize))
+	}
+	if p.ArticleID != 0 {
+		v.Set("article_id", fmt.Sprint(p.ArticleID))
+	}
+	if p.User != "" {
+		v.Set("user", p.User)
+	}
+	if p.MaxScore != nil {
+		v.Set("max_score", fmt.Sprint(*p.MaxScore))
+	}
+	return v
+}
+
+// ListAnswerAttempts 作答记录 (GET /api/v1/attempts，需要 read 权限，成功时返回 200)
+func (c *Client) ListAnswerAttempts(ctx context.Context, params *ListAnswerAttemptsParams) (Page[AnswerAttemptResponse], error) {
+	var out Page[AnswerAttemptResponse]
+	err := c.do(ctx, "GET", "/api/v1/attempts", params.values(), nil, &out)
+	return out, err
+}
+
+// GetAnswerAttempt 作答记录详情 (GET /api/v1/attempts/{id}，需要 read 权限，成功时返回 200)
+func (c *Client) GetAnswerAttempt(ctx context.Context, id int64) (AnswerAttemptResponse, error) {
+	var out envelope[AnswerAttemptResponse]
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/attempts/%d", id), nil, nil, &out)
+	return out.Data, err
+}
+
+// ListAuditLogsParams 是 ListAuditLogs 的查询参数
+type ListAuditLogsParams struct {
+	Page     int64
+	PageSize int64
+	User     string
+	Route    string
+	Days     int64
+}
+
+func (p *ListAuditLogsParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Page != 0 {
+		v.Set("page", fmt.Sprint(p.Page))
+	}
+	if p.PageSize != 0 {
+		v.Set("page_size", fmt.Sprint(p.PageSize))
+	}
+	if p.User != "" {
+		v.Set("user", p.User)
+	}
+	if p.Route != "" {
+		v.Set("route", p.Route)
+	}
+	if p.Days != 0 {
+		v.Set("days", fmt.Sprint(p.Days))
+	}
+	return v
+}
+
+// ListAuditLogs 审计日志 (GET /api/v1/audit，需要 admin 权限，成功时返回 200)
+func (c *Client) ListAuditLogs(ctx context.Context, params *ListAuditLogsParams) (Page[AuditLogResponse], error) {
+	var out Page[AuditLogResponse]
+	err := c.do(ctx, "GET", "/api/v1/audit", params.values(), nil, &out)
+	return out, err
+}
+
+// IssueAuthToken 用 API key 换取 JWT (POST /api/v1/auth/token，需要 read 权限，成功时返回 201)
+func (c *Client) IssueAuthToken(ctx context.Context) (AuthTokenResponse, error) {
+	var out envelope[AuthTokenResponse]
+	err := c.do(ctx, "POST", "/api/v1/auth/token", nil, nil, &out)
+	return out.Data, err
+}
+
+// ListClustersParams 是 ListClusters 的查询参数
+type ListClustersParams struct {
+	Days int64
+	Sort string
+}
+
+func (p *ListClustersParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Days != 0 {
+		v.Set("days", fmt.Sprint(p.Days))
+	}
+	if p.Sort != "" {
+		v.Set("sort", p.Sort)
+	}
+	return v
+}
+
+// ListClusters 主题簇列表 (GET /api/v1/clusters，需要 read 权限，成功时返回 200)
+func (c *Client) ListClusters(ctx context.Context, params *ListClustersParams) (ListClustersResponse, error) {
+	var out ListClustersResponse
+	err := c.do(ctx, "GET", "/api/v1/clusters", params.values(), nil, &out)
+	return out, err
+}
+
+// GetClusterParams 是 GetCluster 的查询参数
+type GetClusterParams struct {
+	Days int64
+	Sort string
+}
+
+func (p *GetClusterParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Days != 0 {
+		v.Set("days", fmt.Sprint(p.Days))
+	}
+	if p.Sort != "" {
+		v.Set("sort", p.Sort)
+	}
+	return v
+}
+
+// GetCluster 主题簇详情 (GET /api/v1/clusters/{id}，需要 read 权限，成功时返回 200)
+func (c *Client) GetCluster(ctx context.Context, id int64, params *GetClusterParams) (GetClusterResponse, error) {
+	var out GetClusterResponse
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/clusters/%d", id), params.values(), nil, &out)
+	return out, err
+}
+
+// ListClusterArticlesParams 是 ListClusterArticles 的查询参数
+type ListClusterArticlesParams struct {
+	Page     int64
+	PageSize int64
+}
+
+func (p *ListClusterArticlesParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Page != 0 {
+		v.Set("page", fmt.Sprint(p.Page))
+	}
+	if p.PageSize != 0 {
+		v.Set("page_size", fmt.Sprint(p.PageSize))
+	}
+	return v
+}
+
+// ListClusterArticles 主题簇的文章 (GET /api/v1/clusters/{id}/articles，需要 read 权限，成功时返回 200)
+func (c *Client) ListClusterArticles(ctx context.Context, id int64, params *ListClusterArticlesParams) (Page[ArticleResponse], error) {
+	var out Page[ArticleResponse]
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/clusters/%d/articles", id), params.values(), nil, &out)
+	return out, err
+}
+
+// ListDuplicateReviewsParams 是 ListDuplicateReviews 的查询参数
+type ListDuplicateReviewsParams struct {
+	Status   string
+	Page     int64
+	PageSize int64
+}
+
+func (p *ListDuplicateReviewsParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Status != "" {
+		v.Set("status", p.Status)
+	}
+	if p.Page != 0 {
+		v.Set("page", fmt.Sprint(p.Page))
+	}
+	if p.PageSize != 0 {
+		v.Set("page_size", fmt.Sprint(p.PageSize))
+	}
+	return v
+}
+
+// ListDuplicateReviews 疑似重复列表 (GET /api/v1/duplicates，需要 read 权限，成功时返回 200)
+func (c *Client) ListDuplicateReviews(ctx context.Context, params *ListDuplicateReviewsParams) (Page[DuplicateReviewResponse], error) {
+	var out Page[DuplicateReviewResponse]
+	err := c.do(ctx, "GET", "/api/v1/duplicates", params.values(), nil, &out)
+	return out, err
+}
+
+// ConfirmDuplicateReview 确认重复并合并 (POST /api/v1/duplicates/{id}/confirm，需要 write 权限，成功时返回 200)
+func (c *Client) ConfirmDuplicateReview(ctx context.Context, id int64) (DuplicateResolutionResponse, error) {
+	var out envelope[DuplicateResolutionResponse]
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/duplicates/%d/confirm", id), nil, nil, &out)
+	return out.Data, err
+}
+
+// DismissDuplicateReview 驳回疑似重复 (POST /api/v1/duplicates/{id}/dismiss，需要 write 权限，成功时返回 200)
+func (c *Client) DismissDuplicateReview(ctx context.Context, id int64) (DuplicateResolutionResponse, error) {
+	var out envelope[DuplicateResolutionResponse]
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/duplicates/%d/dismiss", id), nil, nil, &out)
+	return out.Data, err
+}
+
+// GetEmbeddingCacheStats 向量缓存统计 (GET /api/v1/embedding-cache，需要 admin 权限，成功时返回 200)
+func (c *Client) GetEmbeddingCacheStats(ctx context.Context) (GetEmbeddingCacheStatsResponse, error) {
+	var out GetEmbeddingCacheStatsResponse
+	err := c.do(ctx, "GET", "/api/v1/embedding-cache", nil, nil, &out)
+	return out, err
+}
+
+// GetEnrichCacheStats 丰富化缓存统计 (GET /api/v1/enrich-cache，需要 read 权限，成功时返回 200)
+func (c *Client) GetEnrichCacheStats(ctx context.Context) ([]EnrichmentCacheStats, error) {
+	var out envelope[[]EnrichmentCacheStats]
+	err := c.do(ctx, "GET", "/api/v1/enrich-cache", nil, nil, &out)
+	return out.Data, err
+}
+
+// InvalidateEnrichCacheParams 是 InvalidateEnrichCache 的查询参数
+type InvalidateEnrichCacheParams struct {
+	Question        string
+	Model           string
+	TemplateVersion string
+	All             bool
+}
+
+func (p *InvalidateEnrichCacheParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Question != "" {
+		v.Set("question", p.Question)
+	}
+	if p.Model != "" {
+		v.Set("model", p.Model)
+	}
+	if p.TemplateVersion != "" {
+		v.Set("template_version", p.TemplateVersion)
+	}
+	if p.All != false {
+		v.Set("all", fmt.Sprint(p.All))
+	}
+	return v
+}
+
+// InvalidateEnrichCache 清除丰富化缓存 (DELETE /api/v1/enrich-cache，需要 admin 权限，成功时返回 200)
+func (c *Client) InvalidateEnrichCache(ctx context.Context, params *InvalidateEnrichCacheParams) (InvalidateEnrichCacheResponse, error) {
+	var out InvalidateEnrichCacheResponse
+	err := c.do(ctx, "DELETE", "/api/v1/enrich-cache", params.values(), nil, &out)
+	return out, err
+}
+
+// ListInterviewsParams 是 ListInterviews 的查询参数
+type ListInterviewsParams struct {
+	Page     int64
+	PageSize int64
+	User     string
+}
+
+func (p *ListInterviewsParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Page != 0 {
+		v.Set("page", fmt.Sprint(p.Page))
+	}
+	if p.PageSize != 0 {
+		v.Set("page_size", fmt.Sprint(p.PageSize))
+	}
+	if p.User != "" {
+		v.Set("user", p.User)
+	}
+	return v
+}
+
+// ListInterviews 模拟面试列表 (GET /api/v1/interviews，需要 read 权限，成功时返回 200)
+func (c *Client) ListInterviews(ctx context.Context, params *ListInterviewsParams) (Page[InterviewResponse], error) {
+	var out Page[InterviewResponse]
+	err := c.do(ctx, "GET", "/api/v1/interviews", params.values(), nil, &out)
+	return out, err
+}
+
+// CreateInterview 开始模拟面试 (POST /api/v1/interviews，需要 write 权限，成功时返回 201)
+func (c *Client) CreateInterview(ctx context.Context, body CreateInterviewRequest) (CreateInterviewResponse, error) {
+	var out CreateInterviewResponse
+	err := c.do(ctx, "POST", "/api/v1/interviews", nil, body, &out)
+	return out, err
+}
+
+// GetInterviewParams 是 GetInterview 的查询参数
+type GetInterviewParams struct {
+	Reveal bool
+}
+
+func (p *GetInterviewParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Reveal != false {
+		v.Set("reveal", fmt.Sprint(p.Reveal))
+	}
+	return v
+}
+
+// GetInterview 模拟面试详情 (GET /api/v1/interviews/{id}，需要 read 权限，成功时返回 200)
+func (c *Client) GetInterview(ctx context.Context, id int64, params *GetInterviewParams) (InterviewResponse, error) {
+	var out envelope[InterviewResponse]
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/interviews/%d", id), params.values(), nil, &out)
+	return out.Data, err
+}
+
+// SubmitInterviewAnswer 提交作答 (POST /api/v1/interviews/{id}/answers，需要 write 权限，成功时返回 200)
+func (c *Client) SubmitInterviewAnswer(ctx context.Context, id int64, body SubmitInterviewAnswerRequest) (SubmitInterviewAnswerResponse, error) {
+	var out SubmitInterviewAnswerResponse
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/interviews/%d/answers", id), nil, body, &out)
+	return out, err
+}
+
+// ReplayInterview 重新开始同一场面试 (POST /api/v1/interviews/{id}/replay，需要 write 权限，成功时返回 201)
+func (c *Client) ReplayInterview(ctx context.Context, id int64, body ReplayInterviewRequest) (InterviewResponse, error) {
+	var out envelope[InterviewResponse]
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/interviews/%d/replay", id), nil, body, &out)
+	return out.Data, err
+}
+
+// RevokeAPIKey 吊销 API key (本人或 admin) (DELETE /api/v1/keys/{id}，需要 write 权限，成功时返回 200)
+func (c *Client) RevokeAPIKey(ctx context.Context, id int64) (APIKeyResponse, error) {
+	var out envelope[APIKeyResponse]
+	err := c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/keys/%d", id), nil, nil, &out)
+	return out.Data, err
+}
+
+// GetMe 当前调用者 (GET /api/v1/me，需要 read 权限，成功时返回 200)
+// 未启用鉴权时只返回 authenticated=false
+func (c *Client) GetMe(ctx context.Context) (map[string]any, error) {
+	var out map[string]any
+	err := c.do(ctx, "GET", "/api/v1/me", nil, nil, &out)
+	return out, err
+}
+
+// GetMyQuota 当天的配额用量 (GET /api/v1/me/quota，需要 read 权限，成功时返回 200)
+// 未配置配额或调用者为 admin 时 data 只包含 enabled=false
+func (c *Client) GetMyQuota(ctx context.Context) (QuotaResponse, error) {
+	var out envelope[QuotaResponse]
+	err := c.do(ctx, "GET", "/api/v1/me/quota", nil, nil, &out)
+	return out.Data, err
+}
+
+// GetOpenAPISpec OpenAPI 文档 (GET /api/v1/openapi.json，成功时返回 200)
+func (c *Client) GetOpenAPISpec(ctx context.Context) (map[string]any, error) {
+	var out map[string]any
+	err := c.do(ctx, "GET", "/api/v1/openapi.json", nil, nil, &out)
+	return out, err
+}
+
+// ListDueCardsParams 是 ListDueCards 的查询参数
+type ListDueCardsParams struct {
+	User  string
+	Tags  []string
+	Limit int64
+}
+
+func (p *ListDueCardsParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.User != "" {
+		v.Set("user", p.User)
+	}
+	for _, item := range p.Tags {
+		v.Add("tags[]", item)
+	}
+	if p.Limit != 0 {
+		v.Set("limit", fmt.Sprint(p.Limit))
+	}
+	return v
+}
+
+// ListDueCards 到期的复习卡片 (GET /api/v1/review/due，需要 read 权限，成功时返回 200)
+func (c *Client) ListDueCards(ctx context.Context, params *ListDueCardsParams) (ListDueCardsResponse, error) {
+	var out ListDueCardsResponse
+	err := c.do(ctx, "GET", "/api/v1/review/due", params.values(), nil, &out)
+	return out, err
+}
+
+// GetReviewStatsParams 是 GetReviewStats 的查询参数
+type GetReviewStatsParams struct {
+	User string
+}
+
+func (p *GetReviewStatsParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.User != "" {
+		v.Set("user", p.User)
+	}
+	return v
+}
+
+// GetReviewStats 复习统计 (GET /api/v1/review/stats，需要 read 权限，成功时返回 200)
+func (c *Client) GetReviewStats(ctx context.Context, params *GetReviewStatsParams) (ReviewStats, error) {
+	var out envelope[ReviewStats]
+	err := c.do(ctx, "GET", "/api/v1/review/stats", params.values(), nil, &out)
+	return out.Data, err
+}
+
+// ListTagSubscriptionsParams 是 ListTagSubscriptions 的查询参数
+type ListTagSubscriptionsParams struct {
+	User string
+}
+
+func (p *ListTagSubscriptionsParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.User != "" {
+		v.Set("user", p.User)
+	}
+	return v
+}
+
+// ListTagSubscriptions 订阅的标签 (GET /api/v1/review/subscriptions，需要 read 权限，成功时返回 200)
+func (c *Client) ListTagSubscriptions(ctx context.Context, params *ListTagSubscriptionsParams) ([]string, error) {
+	var out envelope[[]string]
+	err := c.do(ctx, "GET", "/api/v1/review/subscriptions", params.values(), nil, &out)
+	return out.Data, err
+}
+
+// SubscribeTags 订阅标签 (POST /api/v1/review/subscriptions，需要 write 权限，成功时返回 200)
+func (c *Client) SubscribeTags(ctx context.Context, body SubscribeTagsRequest) (SubscribeTagsResponse, error) {
+	var out SubscribeTagsResponse
+	err := c.do(ctx, "POST", "/api/v1/review/subscriptions", nil, body, &out)
+	return out, err
+}
+
+// UnsubscribeTagsParams 是 UnsubscribeTags 的查询参数
+type UnsubscribeTagsParams struct {
+	User string
+	Tags []string
+}
+
+func (p *UnsubscribeTagsParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.User != "" {
+		v.Set("user", p.User)
+	}
+	for _, item := range p.Tags {
+		v.Add("tags[]", item)
+	}
+	return v
+}
+
+// UnsubscribeTags 取消订阅标签 (DELETE /api/v1/review/subscriptions，需要 write 权限，成功时返回 200)
+func (c *Client) UnsubscribeTags(ctx context.Context, params *UnsubscribeTagsParams) (UnsubscribeTagsResponse, error) {
+	var out UnsubscribeTagsResponse
+	err := c.do(ctx, "DELETE", "/api/v1/review/subscriptions", params.values(), nil, &out)
+	return out, err
+}
+
+// RecordReview 记录复习自评 (POST /api/v1/review/{id}，需要 write 权限，成功时返回 200)
+func (c *Client) RecordReview(ctx context.Context, id int64, body RecordReviewRequest) (RecordReviewResponse, error) {
+	var out RecordReviewResponse
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/review/%d", id), nil, body, &out)
+	return out, err
+}
+
+// GetAllTags 全部标签 (GET /api/v1/tags，需要 read 权限，成功时返回 200)
+func (c *Client) GetAllTags(ctx context.Context) ([]string, error) {
+	var out envelope[[]string]
+	err := c.do(ctx, "GET", "/api/v1/tags", nil, nil, &out)
+	return out.Data, err
+}
+
+// AddTagAlias 添加标签别名 (POST /api/v1/tags/aliases，需要 admin 权限，成功时返回 200)
+func (c *Client) AddTagAlias(ctx context.Context, body AddTagAliasRequest) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "POST", "/api/v1/tags/aliases", nil, body, &out)
+	return out, err
+}
+
+// GetTagCooccurrenceParams 是 GetTagCooccurrence 的查询参数
+type GetTagCooccurrenceParams struct {
+	Tags     []string
+	Limit    int64
+	MinCount int64
+}
+
+func (p *GetTagCooccurrenceParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	for _, item := range p.Tags {
+		v.Add("tags[]", item)
+	}
+	if p.Limit != 0 {
+		v.Set("limit", fmt.Sprint(p.Limit))
+	}
+	if p.MinCount != 0 {
+		v.Set("min_count", fmt.Sprint(p.MinCount))
+	}
+	return v
+}
+
+// GetTagCooccurrence 标签共现矩阵 (GET /api/v1/tags/cooccurrence，需要 read 权限，成功时返回 200)
+func (c *Client) GetTagCooccurrence(ctx context.Context, params *GetTagCooccurrenceParams) (GetTagCooccurrenceResponse, error) {
+	var out GetTagCooccurrenceResponse
+	err := c.do(ctx, "GET", "/api/v1/tags/cooccurrence", params.values(), nil, &out)
+	return out, err
+}
+
+// GetTagGraphParams 是 GetTagGraph 的查询参数
+type GetTagGraphParams struct {
+	Limit     int64
+	MinWeight int64
+}
+
+func (p *GetTagGraphParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Limit != 0 {
+		v.Set("limit", fmt.Sprint(p.Limit))
+	}
+	if p.MinWeight != 0 {
+		v.Set("min_weight", fmt.Sprint(p.MinWeight))
+	}
+	return v
+}
+
+// GetTagGraph 标签图 (GET /api/v1/tags/graph，需要 read 权限，成功时返回 200)
+func (c *Client) GetTagGraph(ctx context.Context, params *GetTagGraphParams) (GetTagGraphResponse, error) {
+	var out GetTagGraphResponse
+	err := c.do(ctx, "GET", "/api/v1/tags/graph", params.values(), nil, &out)
+	return out, err
+}
+
+// MergeTags 合并标签 (POST /api/v1/tags/merge，需要 admin 权限，成功时返回 200)
+func (c *Client) MergeTags(ctx context.Context, body MergeTagsRequest) (UpdatedArticlesResponse, error) {
+	var out UpdatedArticlesResponse
+	err := c.do(ctx, "POST", "/api/v1/tags/merge", nil, body, &out)
+	return out, err
+}
+
+// NormalizeArticleTags 按标签体系规范化文章标签 (POST /api/v1/tags/normalize，需要 admin 权限，成功时返回 200)
+func (c *Client) NormalizeArticleTags(ctx context.Context) (UpdatedArticlesResponse, error) {
+	var out UpdatedArticlesResponse
+	err := c.do(ctx, "POST", "/api/v1/tags/normalize", nil, nil, &out)
+	return out, err
+}
+
+// SetTagParent 设置上级标签 (POST /api/v1/tags/parent，需要 admin 权限，成功时返回 200)
+func (c *Client) SetTagParent(ctx context.Context, body SetTagParentRequest) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "POST", "/api/v1/tags/parent", nil, body, &out)
+	return out, err
+}
+
+// RenameTag 重命名标签 (POST /api/v1/tags/rename，需要 admin 权限，成功时返回 200)
+func (c *Client) RenameTag(ctx context.Context, body RenameTagRequest) (UpdatedArticlesResponse, error) {
+	var out UpdatedArticlesResponse
+	err := c.do(ctx, "POST", "/api/v1/tags/rename", nil, body, &out)
+	return out, err
+}
+
+// GetTagStats 标签统计 (GET /api/v1/tags/stats，需要 read 权限，成功时返回 200)
+func (c *Client) GetTagStats(ctx context.Context) (GetTagStatsResponse, error) {
+	var out GetTagStatsResponse
+	err := c.do(ctx, "GET", "/api/v1/tags/stats", nil, nil, &out)
+	return out, err
+}
+
+// GetTagTaxonomy 标签体系 (GET /api/v1/tags/taxonomy，需要 read 权限，成功时返回 200)
+func (c *Client) GetTagTaxonomy(ctx context.Context) ([]TagInfo, error) {
+	var out envelope[[]TagInfo]
+	err := c.do(ctx, "GET", "/api/v1/tags/taxonomy", nil, nil, &out)
+	return out.Data, err
+}
+
+// CreateTask 提交丰富化任务 (POST /api/v1/tasks，需要 write 权限，成功时返回 201)
+func (c *Client) CreateTask(ctx context.Context, body CreateTaskRequest) (CreateTaskResponse, error) {
+	var out CreateTaskResponse
+	err := c.do(ctx, "POST", "/api/v1/tasks", nil, body, &out)
+	return out, err
+}
+
+// GetUsageParams 是 GetUsage 的查询参数
+type GetUsageParams struct {
+	From string
+	To   string
+}
+
+func (p *GetUsageParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.From != "" {
+		v.Set("from", p.From)
+	}
+	if p.To != "" {
+		v.Set("to", p.To)
+	}
+	return v
+}
+
+// GetUsage LLM 与 Embedding 用量 (GET /api/v1/usage，需要 admin 权限，成功时返回 200)
+func (c *Client) GetUsage(ctx context.Context, params *GetUsageParams) (GetUsageResponse, error) {
+	var out GetUsageResponse
+	err := c.do(ctx, "GET", "/api/v1/usage", params.values(), nil, &out)
+	return out, err
+}
+
+// ListUsers 用户列表 (GET /api/v1/users，需要 admin 权限，成功时返回 200)
+func (c *Client) ListUsers(ctx context.Context) ([]UserResponse, error) {
+	var out envelope[[]UserResponse]
+	err := c.do(ctx, "GET", "/api/v1/users", nil, nil, &out)
+	return out.Data, err
+}
+
+// CreateUser 创建用户 (POST /api/v1/users，需要 admin 权限，成功时返回 201)
+func (c *Client) CreateUser(ctx context.Context, body CreateUserRequest) (UserResponse, error) {
+	var out envelope[UserResponse]
+	err := c.do(ctx, "POST", "/api/v1/users", nil, body, &out)
+	return out.Data, err
+}
+
+// DisableUser 禁用用户 (POST /api/v1/users/{id}/disable，需要 admin 权限，成功时返回 200)
+func (c *Client) DisableUser(ctx context.Context, id int64) (UserResponse, error) {
+	var out envelope[UserResponse]
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/users/%d/disable", id), nil, nil, &out)
+	return out.Data, err
+}
+
+// EnableUser 启用用户 (POST /api/v1/users/{id}/enable，需要 admin 权限，成功时返回 200)
+func (c *Client) EnableUser(ctx context.Context, id int64) (UserResponse, error) {
+	var out envelope[UserResponse]
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/users/%d/enable", id), nil, nil, &out)
+	return out.Data, err
+}
+
+// ListAPIKeys 用户的 API key 列表 (GET /api/v1/users/{id}/keys，需要 admin 权限，成功时返回 200)
+func (c *Client) ListAPIKeys(ctx context.Context, id int64) ([]APIKeyResponse, error) {
+	var out envelope[[]APIKeyResponse]
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/users/%d/keys", id), nil, nil, &out)
+	return out.Data, err
+}
+
+// CreateAPIKey 创建 API key (POST /api/v1/users/{id}/keys，需要 admin 权限，成功时返回 201)
+// 明文 key 只在此响应中返回一次
+func (c *Client) CreateAPIKey(ctx context.Context, id int64, body CreateAPIKeyRequest) (CreateAPIKeyResponse, error) {
+	var out CreateAPIKeyResponse
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/users/%d/keys", id), nil, body, &out)
+	return out, err
+}
+
+// ListWorkspaces 可访问的工作区 (GET /api/v1/workspaces，需要 read 权限，成功时返回 200)
+func (c *Client) ListWorkspaces(ctx context.Context) ([]WorkspaceResponse, error) {
+	var out envelope[[]WorkspaceResponse]
+	err := c.do(ctx, "GET", "/api/v1/workspaces", nil, nil, &out)
+	return out.Data, err
+}
+
+// CreateWorkspace 创建工作区 (POST /api/v1/workspaces，需要 admin 权限，成功时返回 201)
+func (c *Client) CreateWorkspace(ctx context.Context, body CreateWorkspaceRequest) (WorkspaceResponse, error) {
+	var out envelope[WorkspaceResponse]
+	err := c.do(ctx, "POST", "/api/v1/workspaces", nil, body, &out)
+	return out.Data, err
+}
+
+// GetWorkspace 工作区详情 (GET /api/v1/workspaces/{slug}，需要 read 权限，成功时返回 200)
+func (c *Client) GetWorkspace(ctx context.Context, slug string) (WorkspaceResponse, error) {
+	var out envelope[WorkspaceResponse]
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/workspaces/%s", url.PathEscape(slug)), nil, nil, &out)
+	return out.Data, err
+}
+
+// UpdateWorkspace 修改工作区 (PUT /api/v1/workspaces/{slug}，需要 admin 权限，成功时返回 200)
+func (c *Client) UpdateWorkspace(ctx context.Context, slug string, body UpdateWorkspaceRequest) (WorkspaceResponse, error) {
+	var out envelope[WorkspaceResponse]
+	err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/workspaces/%s", url.PathEscape(slug)), nil, body, &out)
+	return out.Data, err
+}
+
+// ListWorkspaceMembers 工作区成员 (GET /api/v1/workspaces/{slug}/members，需要 read 权限，成功时返回 200)
+func (c *Client) ListWorkspaceMembers(ctx context.Context, slug string) ([]WorkspaceMemberResponse, error) {
+	var out envelope[[]WorkspaceMemberResponse]
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/workspaces/%s/members", url.PathEscape(slug)), nil, nil, &out)
+	return out.Data, err
+}
+
+// SetWorkspaceMember 添加成员或修改角色 (owner) (PUT /api/v1/workspaces/{slug}/members/{user}，需要 write 权限，成功时返回 200)
+func (c *Client) SetWorkspaceMember(ctx context.Context, slug string, user string, body SetWorkspaceMemberRequest) (WorkspaceMemberResponse, error) {
+	var out envelope[WorkspaceMemberResponse]
+	err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/workspaces/%s/members/%s", url.PathEscape(slug), url.PathEscape(user)), nil, body, &out)
+	return out.Data, err
+}
+
+// RemoveWorkspaceMember 移除成员 (owner) (DELETE /api/v1/workspaces/{slug}/members/{user}，需要 write 权限，成功时返回 200)
+func (c *Client) RemoveWorkspaceMember(ctx context.Context, slug string, user string) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/workspaces/%s/members/%s", url.PathEscape(slug), url.PathEscape(user)), nil, nil, &out)
+	return out, err
+}
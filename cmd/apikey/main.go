@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"paguu/configs"
+	"paguu/internal/auth"
+	"paguu/internal/storage/postgres"
+	"strings"
+	"time"
+
+	"github.com/lmittmann/tint"
+)
+
+func main() {
+	userName := flag.String("user", "", "用户名，不存在时自动创建")
+	scopes := flag.String("scopes", "read", "逗号分隔的权限范围: read,write,admin")
+	name := flag.String("name", "", "key 的用途备注")
+	expires := flag.Int("expires", 0, "有效天数，0 表示不过期")
+	workspace := flag.String("workspace", "", "同时将用户加入该工作区 (标识)")
+	role := flag.String("role", postgres.WorkspaceRoleEditor, "加入工作区时的角色: viewer,editor,owner")
+	flag.Parse()
+
+	handler := tint.NewHandler(os.Stderr, &tint.Options{
+		Level:      slog.LevelInfo,
+		TimeFormat: time.Kitchen,
+	})
+	slog.SetDefault(slog.New(handler))
+
+	if strings.TrimSpace(*userName) == "" || *expires < 0 || !postgres.ValidWorkspaceRole(*role) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	keyScopes, err := auth.NormalizeScopes(strings.Split(*scopes, ","))
+	if err != nil || len(keyScopes) == 0 {
+		slog.Error("权限范围无效", "scopes", *scopes, "error", err)
+		os.Exit(2)
+	}
+
+	config, err := configs.LoadConfig()
+	if err != nil {
+		slog.Error("加载配置失败", "error", err)
+		os.Exit(1)
+	}
+
+	repo, err := postgres.NewRepository(config.Database.DSN)
+	if err != nil {
+		slog.Error("数据库初始化失败", "error", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	user, err := repo.GetUserByName(ctx, strings.TrimSpace(*userName))
+	if errors.Is(err, postgres.ErrUserNotFound) {
+		user, err = repo.CreateUser(ctx, strings.TrimSpace(*userName))
+		if err == nil {
+			slog.Info("已创建用户", "user", user.Name, "id", user.ID)
+		}
+	}
+	if err != nil {
+		slog.Error("获取用户失败", "error", err)
+		os.Exit(1)
+	}
+
+	if *workspace != "" {
+		ws, err := repo.GetWorkspaceBySlug(ctx, strings.TrimSpace(*workspace))
+		if err != nil {
+			slog.Error("获取工作区失败", "error", err)
+			os.Exit(1)
+		}
+		if _, err := repo.SetWorkspaceMember(ctx, ws.ID, user.ID, *role); err != nil {
+			slog.Error("加入工作区失败", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("已加入工作区", "user", user.Name, "workspace", ws.Slug, "role", *role)
+	}
+
+	plain, prefix, hash, err := auth.GenerateKey()
+	if err != nil {
+		slog.Error("生成 API key 失败", "error", err)
+		os.Exit(1)
+	}
+	key := &postgres.APIKey{
+		UserID:  user.ID,
+		Name:    *name,
+		Prefix:  prefix,
+		KeyHash: hash,
+		Scopes:  keyScopes,
+	}
+	if *expires > 0 {
+		expiresAt := time.Now().AddDate(0, 0, *expires)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := repo.CreateAPIKey(ctx, key); err != nil {
+		slog.Error("保存 API key 失败", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("key %d for %s [%s]\n", key.ID, user.Name, strings.Join(keyScopes, ","))
+	fmt.Println(plain)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"paguu/configs"
+	"paguu/internal/cluster"
+	"paguu/internal/storage/postgres"
+	"paguu/internal/usage"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lmittmann/tint"
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime"
+)
+
+func main() {
+	k := flag.Int("k", 0, "簇数，0 表示按文章数自动选择 sqrt(n/2)")
+	maxIter := flag.Int("iter", 50, "k-means 最大迭代次数")
+	seed := flag.Int64("seed", 1, "随机种子，相同的数据和种子得到相同的结果")
+	samples := flag.Int("samples", 8, "命名时提供给 LLM 的代表性问题数")
+	topTags := flag.Int("top-tags", 5, "每个簇记录的常见标签数")
+	keep := flag.Int("keep", 5, "保留最近几次聚类运行的结果")
+	noName := flag.Bool("no-name", false, "不调用 LLM 命名，直接使用常见标签作为簇名")
+	workspace := flag.String("workspace", postgres.DefaultWorkspaceSlug, "聚类的工作区 (标识)")
+	flag.Parse()
+
+	handler := tint.NewHandler(os.Stderr, &tint.Options{
+		Level:      slog.LevelInfo,
+		TimeFormat: time.Kitchen,
+	})
+	slog.SetDefault(slog.New(handler))
+
+	if *k < 0 || *maxIter < 1 || *keep < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	config, err := configs.LoadConfig()
+	if err != nil {
+		slog.Error("加载配置失败", "error", err)
+		os.Exit(1)
+	}
+
+	repo, err := postgres.NewRepository(config.Database.DSN)
+	if err != nil {
+		slog.Error("数据库初始化失败", "error", err)
+		os.Exit(1)
+	}
+
+	ws, err := repo.GetWorkspaceBySlug(context.Background(), *workspace)
+	if err != nil {
+		slog.Error("获取工作区失败", "error", err)
+		os.Exit(1)
+	}
+	ctx := postgres.WithWorkspace(context.Background(), ws.ID)
+
+	var namer cluster.Namer
+	var tracker *usage.Tracker
+	if !*noName {
+		arkClient := arkruntime.NewClientWithApiKey(
+			config.Ark.ApiKey,
+			arkruntime.WithBaseUrl(config.Ark.BaseUrl),
+		)
+		llmNamer, err := cluster.NewLLMNamer(arkClient, config.Cluster.TemplatePath, config.Cluster.Model)
+		if err != nil {
+			slog.Error("创建簇命名器失败", "error", err)
+			os.Exit(1)
+		}
+		// 命名调用计入用量统计
+		tracker = usage.NewTracker(repo, config.Usage.Prices, usage.Budget{
+			DailyCost:   config.Usage.DailyBudget,
+			DailyTokens: config.Usage.DailyTokens,
+		})
+		llmNamer.SetUsageRecorder(tracker)
+		namer = llmNamer
+	}
+
+	result, err := cluster.Run(ctx, repo, namer, cluster.Options{
+		K:       *k,
+		MaxIter: *maxIter,
+		Seed:    *seed,
+		Samples: *samples,
+		TopTags: *topTags,
+		Keep:    *keep,
+	})
+	// 写入命名调用的用量事件
+	if tracker != nil {
+		tracker.Close()
+	}
+	if err != nil {
+		slog.Error("聚类失败", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("workspace %s, run %d: %d articles, k=%d, %d iterations, inertia %.4f\n",
+		ws.Slug, result.Run.ID, result.Run.ArticleCount, result.Run.K, result.Run.Iterations, result.Run.Inertia)
+	if result.Unnamed > 0 {
+		fmt.Printf("%d clusters named from tags\n", result.Unnamed)
+	}
+	if result.Pruned > 0 {
+		fmt.Printf("pruned %d old runs\n", result.Pruned)
+	}
+	fmt.Println()
+
+	clusters := result.Clusters
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].Size > clusters[j].Size
+	})
+	for _, c := range clusters {
+		fmt.Printf("%6d  %5d  %s  [%s]\n", c.ID, c.Size, c.Name, strings.Join(c.TopTags, ", "))
+	}
+}
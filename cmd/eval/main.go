@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"paguu/configs"
+	"paguu/internal/embedding"
+	"paguu/internal/eval"
+	"paguu/internal/rerank"
+	"paguu/internal/storage/postgres"
+	"strings"
+	"time"
+
+	"github.com/lmittmann/tint"
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime"
+	"google.golang.org/genai"
+)
+
+// evalConfig 描述一个被评测的检索配置
+// 格式: name=xxx,query_task_type=RETRIEVAL_QUERY,mode=vector|rerank
+type evalConfig struct {
+	Name          string
+	QueryTaskType embedding.TaskType
+	Mode          string
+}
+
+func parseEvalConfig(spec, defaultName string) (evalConfig, error) {
+	cfg := evalConfig{
+		Name:          defaultName,
+		QueryTaskType: embedding.TaskTypeQuery,
+		Mode:          "vector",
+	}
+	if strings.TrimSpace(spec) == "" {
+		return cfg, nil
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return cfg, fmt.Errorf("invalid config item %q, want key=value", part)
+		}
+		switch key {
+		case "name":
+			cfg.Name = value
+		case "query_task_type":
+			cfg.QueryTaskType = embedding.TaskType(value)
+		case "mode":
+			cfg.Mode = value
+		default:
+			return cfg, fmt.Errorf("unknown config key %q", key)
+		}
+	}
+	return cfg, nil
+}
+
+func main() {
+	goldenPath := flag.String("golden", "", "评测集 JSON 文件路径 (必需)")
+	k := flag.Int("k", 10, "每条查询取前 k 个结果计算指标")
+	specA := flag.String("a", "", "配置 A，例如 name=query,query_task_type=RETRIEVAL_QUERY")
+	specB := flag.String("b", "", "配置 B (可选)，指定后与配置 A 并排对比")
+	compareTaskTypes := flag.Bool("compare-task-types", false, "对比非对称 (RETRIEVAL_QUERY) 与对称 (RETRIEVAL_DOCUMENT) 查询嵌入，忽略 -a/-b")
+	outPath := flag.String("out", "", "将完整评测结果写入 JSON 文件 (可选)")
+	embedderKind := flag.String("embedder", "gemini", "查询嵌入来源: gemini (在线，写入向量缓存)、cache (只读向量缓存，离线复现) 或 fixture (确定性假向量，仅验证流程)")
+	workspace := flag.String("workspace", postgres.DefaultWorkspaceSlug, "评测的工作区 (标识)")
+	flag.Parse()
+
+	handler := tint.NewHandler(os.Stderr, &tint.Options{
+		Level:      slog.LevelWarn,
+		TimeFormat: time.Kitchen,
+	})
+	slog.SetDefault(slog.New(handler))
+
+	if *goldenPath == "" || *k < 1 || !validEmbedderKind(*embedderKind) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	set, err := eval.LoadGoldenSet(*goldenPath)
+	if err != nil {
+		slog.Error("加载评测集失败", "error", err)
+		os.Exit(1)
+	}
+
+	if *compareTaskTypes {
+		*specA = "name=query,query_task_type=" + string(embedding.TaskTypeQuery)
+		*specB = "name=document,query_task_type=" + string(embedding.TaskTypeDocument)
+	}
+	configsToRun := make([]evalConfig, 0, 2)
+	cfgA, err := parseEvalConfig(*specA, "a")
+	if err != nil {
+		slog.Error("解析配置 A 失败", "error", err)
+		os.Exit(2)
+	}
+	configsToRun = append(configsToRun, cfgA)
+	if *specB != "" {
+		cfgB, err := parseEvalConfig(*specB, "b")
+		if err != nil {
+			slog.Error("解析配置 B 失败", "error", err)
+			os.Exit(2)
+		}
+		configsToRun = append(configsToRun, cfgB)
+	}
+
+	config, err := configs.LoadConfig()
+	if err != nil {
+		slog.Error("加载配置失败", "error", err)
+		os.Exit(1)
+	}
+
+	repo, err := postgres.NewRepository(config.Database.DSN)
+	if err != nil {
+		slog.Error("数据库初始化失败", "error", err)
+		os.Exit(1)
+	}
+
+	ws, err := repo.GetWorkspaceBySlug(context.Background(), *workspace)
+	if err != nil {
+		slog.Error("获取工作区失败", "error", err)
+		os.Exit(1)
+	}
+	ctx := postgres.WithWorkspace(context.Background(), ws.ID)
+
+	// 只有在线模式才需要 Gemini 客户端，离线模式不要求 API key
+	var geminiClient *genai.Client
+	if *embedderKind == "gemini" {
+		geminiClient, err = genai.NewClient(ctx, &genai.ClientConfig{
+			APIKey:  config.Gemini.ApiKey,
+			Backend: genai.BackendGeminiAPI,
+		})
+		if err != nil {
+			slog.Error("创建 Gemini 客户端失败", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	reports := make([]*eval.Report, 0, len(configsToRun))
+	for _, cfg := range configsToRun {
+		searcher, err := newSearcher(cfg, config, *embedderKind, geminiClient, repo)
+		if err != nil {
+			slog.Error("创建检索器失败", "config", cfg.Name, "error", err)
+			os.Exit(1)
+		}
+		reports = append(reports, eval.Run(ctx, cfg.Name, set, searcher, *k))
+	}
+
+	fmt.Printf("golden set: %s (%d queries), workspace %s, embedder %s\n\n", set.Name, len(set.Queries), ws.Slug, *embedderKind)
+	if err := eval.WriteComparison(os.Stdout, reports...); err != nil {
+		slog.Error("输出评测结果失败", "error", err)
+		os.Exit(1)
+	}
+
+	if *outPath != "" {
+		data, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			slog.Error("序列化评测结果失败", "error", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(*outPath, data, 0o644); err != nil {
+			slog.Error("写入评测结果失败", "error", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func validEmbedderKind(kind string) bool {
+	switch kind {
+	case "gemini", "cache", "fixture":
+		return true
+	}
+	return false
+}
+
+// newQueryEmbedder 按 -embedder 创建查询嵌入，每个配置使用独立的实例以便设置不同的查询嵌入类型
+func newQueryEmbedder(kind string, cfg evalConfig, config configs.Config, client *genai.Client, repo *postgres.Repository) (eval.QueryEmbedder, error) {
+	switch kind {
+	case "cache":
+		return embedding.NewStoreEmbedder(repo, config.Gemini.EmbeddingModel, cfg.QueryTaskType)
+	case "fixture":
+		return embedding.FixtureEmbedder{}, nil
+	}
+	embedder, err := embedding.NewEmbedder(config.Gemini.EmbeddingModel, client)
+	if err != nil {
+		return nil, err
+	}
+	embedder.SetQueryTaskType(cfg.QueryTaskType)
+	// 复用持久化向量缓存，重复评测不重复计费
+	embedder.SetCache(repo, config.Gemini.CacheSize)
+	return embedder, nil
+}
+
+// newSearcher 按配置创建检索器
+func newSearcher(cfg evalConfig, config configs.Config, embedderKind string, client *genai.Client, repo *postgres.Repository) (eval.Searcher, error) {
+	embedder, err := newQueryEmbedder(embedderKind, cfg, config, client, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	vectorSearcher := eval.NewVectorSearcher(embedder, repo)
+
+	switch cfg.Mode {
+	case "vector":
+		return vectorSearcher, nil
+	case "rerank":
+		arkClient := arkruntime.NewClientWithApiKey(
+			config.Ark.ApiKey,
+			arkruntime.WithBaseUrl(config.Ark.BaseUrl),
+		)
+		reranker, err := rerank.New(rerank.Options{
+			Provider:     config.Rerank.Provider,
+			Model:        config.Rerank.Model,
+			TemplatePath: config.Rerank.TemplatePath,
+			Endpoint:     config.Rerank.Endpoint,
+			Timeout:      config.Rerank.Timeout,
+		}, arkClient, nil)
+		if err != nil {
+			return nil, err
+		}
+		if reranker == nil {
+			return nil, fmt.Errorf("mode rerank requires rerank.provider to be configured")
+		}
+		candidates := config.Rerank.Candidates
+		if candidates <= 0 {
+			candidates = 30
+		}
+		return eval.NewRerankSearcher(vectorSearcher, reranker, candidates), nil
+	default:
+		return nil, fmt.Errorf("unknown search mode %q", cfg.Mode)
+	}
+}
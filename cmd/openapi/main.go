@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"os"
+	"paguu/internal/api"
+	"paguu/internal/openapi"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmittmann/tint"
+)
+
+// 生成 OpenAPI 文档和 Go 客户端，不需要数据库和配置文件
+//
+//	go run ./cmd/openapi -o openapi.json        # 输出文档
+//	go run ./cmd/openapi -check                 # 检查路由是否都已登记，不一致时返回非 0
+//	go run ./cmd/openapi -client client/zz_generated.go
+func main() {
+	output := flag.String("o", "", "文档输出路径，为空时输出到标准输出 (指定 -client 或 -check 时不输出文档)")
+	check := flag.Bool("check", false, "检查 SetupRouter 的路由与文档是否一致")
+	clientPath := flag.String("client", "", "生成的 Go 客户端代码的输出路径")
+	pkg := flag.String("package", "client", "生成的 Go 客户端的包名")
+	flag.Parse()
+
+	handler := tint.NewHandler(os.Stderr, &tint.Options{
+		Level:      slog.LevelInfo,
+		TimeFormat: time.Kitchen,
+	})
+	slog.SetDefault(slog.New(handler))
+
+	if *check {
+		gin.SetMode(gin.ReleaseMode)
+		r := api.SetupRouter(api.NewHandler(nil, nil, nil), api.RouterOptions{})
+		missing, stale := api.UndocumentedRoutes(r)
+		if len(missing) > 0 || len(stale) > 0 {
+			slog.Error("路由与 OpenAPI 文档不一致，请更新 internal/api/openapi.go 中的 operations", "missing", missing, "stale", stale)
+			os.Exit(1)
+		}
+		slog.Info("路由与 OpenAPI 文档一致", "routes", len(r.Routes()))
+	}
+
+	doc := api.OpenAPIDocument()
+
+	if *clientPath != "" {
+		src, err := openapi.GenerateClient(doc, *pkg)
+		if err != nil {
+			slog.Error("生成客户端失败", "error", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(*clientPath, src, 0o644); err != nil {
+			slog.Error("写入客户端失败", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("已生成客户端", "path", *clientPath)
+	}
+
+	if *output == "" && (*check || *clientPath != "") {
+		return
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		slog.Error("序列化文档失败", "error", err)
+		os.Exit(1)
+	}
+	data = append(data, '\n')
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		slog.Error("写入文档失败", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("已生成 OpenAPI 文档", "path", *output, "paths", len(doc.Paths))
+}
@@ -2,14 +2,26 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
 	"paguu/configs"
 	"paguu/internal/api"
+	"paguu/internal/ask"
+	"paguu/internal/auth"
 	"paguu/internal/embedding"
 	"paguu/internal/enrich"
+	"paguu/internal/grade"
+	"paguu/internal/interview"
 	"paguu/internal/processor"
+	"paguu/internal/ratelimit"
+	"paguu/internal/rerank"
 	"paguu/internal/storage/postgres"
+	"paguu/internal/usage"
+	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/lmittmann/tint"
@@ -17,6 +29,9 @@ import (
 	"google.golang.org/genai"
 )
 
+// shutdownTimeout 是关闭时等待进行中请求完成的最长时间
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	// 设置日志
 	handler := tint.NewHandler(os.Stdout, &tint.Options{
@@ -24,7 +39,7 @@ func main() {
 		TimeFormat: time.Kitchen,
 		AddSource:  false,
 	})
-	logger := slog.New(handler)
+	logger := slog.New(api.RequestIDLogHandler(handler))
 	slog.SetDefault(logger)
 
 	// 加载配置
@@ -73,21 +88,146 @@ func main() {
 		slog.Error("QuestionsEnricher 初始化失败", "error", err)
 		panic(err)
 	}
+	questionEnricher.SetChunking(config.Ark.EnrichChunkSize, config.Ark.EnrichConcurrency)
+
+	// 用量记录与每日预算
+	usageTracker := usage.NewTracker(repo, config.Usage.Prices, usage.Budget{
+		DailyCost:   config.Usage.DailyBudget,
+		DailyTokens: config.Usage.DailyTokens,
+	})
+	defer usageTracker.Close()
+	questionEnricher.SetUsageRecorder(usageTracker)
+	if config.Ark.EnrichCache {
+		questionEnricher.SetCache(repo, config.Ark.EnrichCacheTTL)
+	}
+	questionEnricher.SetTagVocabulary(repo, config.Ark.EnrichTagVocabulary)
+	embedder.SetQueryTaskType(embedding.TaskType(config.Gemini.QueryTaskType))
+	embedder.SetUsageRecorder(usageTracker)
+	if config.Gemini.CacheStore {
+		embedder.SetCache(repo, config.Gemini.CacheSize)
+	} else {
+		embedder.SetCache(nil, config.Gemini.CacheSize)
+	}
 
 	// 创建 TaskProcessor
 	taskProcessor := processor.NewTaskProcessor(questionEnricher, repo, embedder)
+	taskProcessor.SetUsageTracker(usageTracker)
+	// 工作区可以覆盖丰富化模板和模型，空值沿用全局配置
+	taskProcessor.SetEnricherFactory(func(templatePath, model string) (*enrich.QuestionsEnricher, error) {
+		// 工作区的模板是模板目录下的文件名，取 Base 兼容之前保存的路径
+		if templatePath == "" {
+			templatePath = config.Ark.EnrichTemplatePath
+		} else {
+			templatePath = filepath.Join(config.Ark.EnrichTemplateDir, filepath.Base(templatePath))
+		}
+		if model == "" {
+			model = config.Ark.EnrichModel
+		}
+		enricher, err := enrich.NewQuestionsEnricher(arkClient, templatePath, model)
+		if err != nil {
+			return nil, err
+		}
+		enricher.SetChunking(config.Ark.EnrichChunkSize, config.Ark.EnrichConcurrency)
+		enricher.SetUsageRecorder(usageTracker)
+		if config.Ark.EnrichCache {
+			enricher.SetCache(repo, config.Ark.EnrichCacheTTL)
+		}
+		enricher.SetTagVocabulary(repo, config.Ark.EnrichTagVocabulary)
+		return enricher, nil
+	})
+	taskProcessor.SetDedupeThresholds(postgres.DedupeThresholds{
+		Merge:  config.Dedupe.MergeSimilarity,
+		Review: config.Dedupe.ReviewSimilarity,
+	})
 
 	// 创建 API Handler
 	apiHandler := api.NewHandler(repo, embedder, taskProcessor)
+	apiHandler.SetTagStatsCacheTTL(config.Tags.StatsCacheTTL)
+	apiHandler.SetEnrichTemplateDir(config.Ark.EnrichTemplateDir)
+
+	// 可选的搜索重排序
+	reranker, err := rerank.New(rerank.Options{
+		Provider:     config.Rerank.Provider,
+		Model:        config.Rerank.Model,
+		TemplatePath: config.Rerank.TemplatePath,
+		Endpoint:     config.Rerank.Endpoint,
+		Timeout:      config.Rerank.Timeout,
+	}, arkClient, usageTracker)
+	if err != nil {
+		slog.Error("reranker init error", "error", err)
+		panic(err)
+	}
+	apiHandler.SetReranker(reranker, config.Rerank.Candidates)
+
+	// 检索增强问答
+	answerer, err := ask.NewAnswerer(arkClient, config.Ask.TemplatePath, config.Ask.Model)
+	if err != nil {
+		slog.Error("answerer init error", "error", err)
+		panic(err)
+	}
+	answerer.SetUsageRecorder(usageTracker)
+	apiHandler.SetAnswerer(answerer, config.Ask.TopK, config.Ask.MinSimilarity)
+
+	// 练习作答评分
+	grader, err := grade.NewGrader(arkClient, config.Grade.TemplatePath, config.Grade.Model)
+	if err != nil {
+		slog.Error("grader init error", "error", err)
+		panic(err)
+	}
+	grader.SetUsageRecorder(usageTracker)
+	apiHandler.SetGrader(grader)
+
+	// 模拟面试组卷
+	depthRater, err := interview.NewLLMRater(arkClient, config.Interview.TemplatePath, config.Interview.Model)
+	if err != nil {
+		slog.Error("interview depth rater init error", "error", err)
+		panic(err)
+	}
+	depthRater.SetUsageRecorder(usageTracker)
+	interviewPlanner := interview.NewPlanner(repo, depthRater)
+	interviewPlanner.SetDuplicateSimilarity(config.Interview.DuplicateSimilarity)
+	apiHandler.SetInterviewPlanner(interviewPlanner)
+
+	// API 鉴权
+	if config.Auth.Enabled {
+		authenticator := auth.NewAuthenticator(repo)
+		authenticator.SetJWT(config.Auth.JWTSecret, config.Auth.JWTTTL)
+		apiHandler.SetAuthenticator(authenticator)
+	} else {
+		slog.Warn("API 鉴权未启用，所有接口都不需要凭证")
+	}
+
+	// 限流和每日配额
+	if config.RateLimit.Enabled {
+		apiHandler.SetLimiter(ratelimit.NewLimiter(repo, map[string]ratelimit.Limit{
+			ratelimit.BucketAPI:  config.RateLimit.API,
+			ratelimit.BucketPaid: config.RateLimit.Paid,
+		}, config.RateLimit.Quota))
+	}
 
 	// 设置路由
-	router := api.SetupRouter(apiHandler)
+	router := api.SetupRouter(apiHandler, api.RouterOptions{CORSOrigins: config.Server.CORSOrigins})
 
 	// 启动服务器
-	port := ":8080"
-	slog.Info("API 服务器启动", "port", port)
-	if err := router.Run(port); err != nil {
-		slog.Error("服务器启动失败", "error", err)
-		panic(err)
+	server := &http.Server{Addr: ":8080", Handler: router}
+	go func() {
+		slog.Info("API 服务器启动", "port", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("服务器启动失败", "error", err)
+			panic(err)
+		}
+	}()
+
+	// 等待中断信号
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	sig := <-sigChan
+	slog.Info("收到信号，准备关闭", "signal", sig)
+
+	// 等待进行中的请求完成后再写入剩余的用量事件 (defer usageTracker.Close)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("API 服务器关闭超时", "error", err)
 	}
 }
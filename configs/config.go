@@ -3,26 +3,91 @@ package configs
 import (
 	"fmt"
 	"log"
+	"paguu/internal/ratelimit"
+	"paguu/internal/usage"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
 	Ark struct {
-		ApiKey             string `mapstructure:"api_key"`
-		BaseUrl            string `mapstructure:"base_url"`
-		EnrichModel        string `mapstructure:"enrich_model"`
-		EnrichTemplatePath string `mapstructure:"enrich_template_path"`
-		EmbeddingModel     string `mapstructure:"embedding_model"` // 已废弃，改用 Gemini
+		ApiKey              string        `mapstructure:"api_key"`
+		BaseUrl             string        `mapstructure:"base_url"`
+		EnrichModel         string        `mapstructure:"enrich_model"`
+		EnrichTemplatePath  string        `mapstructure:"enrich_template_path"`
+		EnrichTemplateDir   string        `mapstructure:"enrich_template_dir"`   // 工作区可选的丰富化模板所在目录，工作区只能指定其中的文件名；为空时不允许工作区覆盖模板
+		EnrichChunkSize     int           `mapstructure:"enrich_chunk_size"`     // 单次请求的最大问题行数，0 表示默认值
+		EnrichConcurrency   int           `mapstructure:"enrich_concurrency"`    // 分块并发请求上限，0 表示默认值
+		EnrichCache         bool          `mapstructure:"enrich_cache"`          // 是否启用丰富化结果缓存
+		EnrichCacheTTL      time.Duration `mapstructure:"enrich_cache_ttl"`      // 缓存有效期，0 表示不过期
+		EnrichTagVocabulary int           `mapstructure:"enrich_tag_vocabulary"` // 写入 prompt 的已有规范标签数，0 表示不提供
+		EmbeddingModel      string        `mapstructure:"embedding_model"`       // 已废弃，改用 Gemini
 	} `mapstructure:"ark"`
 	Gemini struct {
 		ApiKey         string `mapstructure:"api_key"`
 		EmbeddingModel string `mapstructure:"embedding_model"`
+		QueryTaskType  string `mapstructure:"query_task_type"` // 搜索查询的嵌入任务类型，默认 RETRIEVAL_QUERY
+		CacheSize      int    `mapstructure:"cache_size"`      // 进程内 LRU 向量缓存条数，0 表示不启用
+		CacheStore     bool   `mapstructure:"cache_store"`     // 是否启用 Postgres 持久化向量缓存
 	} `mapstructure:"gemini"`
+	Dedupe struct {
+		MergeSimilarity  float64 `mapstructure:"merge_similarity"`  // 相似度高于该值自动合并
+		ReviewSimilarity float64 `mapstructure:"review_similarity"` // 相似度介于该值与 merge_similarity 之间时插入并标记待审核，0 表示不启用
+	} `mapstructure:"dedupe"`
+	Rerank struct {
+		Provider     string        `mapstructure:"provider"`      // llm/http，空表示不启用重排序
+		Model        string        `mapstructure:"model"`         // llm 重排序使用的 Ark 模型，默认同 ark.enrich_model
+		TemplatePath string        `mapstructure:"template_path"` // llm 重排序的 prompt 模板
+		Endpoint     string        `mapstructure:"endpoint"`      // http 重排序服务地址
+		Timeout      time.Duration `mapstructure:"timeout"`       // http 请求超时
+		Candidates   int           `mapstructure:"candidates"`    // 默认重排序的候选数
+	} `mapstructure:"rerank"`
+	Ask struct {
+		Model         string  `mapstructure:"model"`          // 问答使用的 Ark 模型，默认同 ark.enrich_model
+		TemplatePath  string  `mapstructure:"template_path"`  // 问答的 prompt 模板
+		TopK          int     `mapstructure:"top_k"`          // 默认检索的文章数
+		MinSimilarity float64 `mapstructure:"min_similarity"` // 低于该相似度的文章不作为回答依据
+	} `mapstructure:"ask"`
+	Grade struct {
+		Model        string `mapstructure:"model"`         // 作答评分使用的 Ark 模型，默认同 ark.enrich_model
+		TemplatePath string `mapstructure:"template_path"` // 作答评分的 prompt 模板
+	} `mapstructure:"grade"`
+	Interview struct {
+		Model               string  `mapstructure:"model"`                // 题目深度评估使用的 Ark 模型，默认同 ark.enrich_model
+		TemplatePath        string  `mapstructure:"template_path"`        // 题目深度评估的 prompt 模板
+		DuplicateSimilarity float64 `mapstructure:"duplicate_similarity"` // 同一场面试中两道题的相似度上限，0 表示默认 0.9
+	} `mapstructure:"interview"`
+	Cluster struct {
+		Model        string `mapstructure:"model"`         // 簇命名使用的 Ark 模型，默认同 ark.enrich_model
+		TemplatePath string `mapstructure:"template_path"` // 簇命名的 prompt 模板
+	} `mapstructure:"cluster"`
+	Tags struct {
+		StatsCacheTTL time.Duration `mapstructure:"stats_cache_ttl"` // 标签统计/共现/图接口的缓存时间，0 表示默认 5 分钟，负数表示不缓存
+	} `mapstructure:"tags"`
+	Usage struct {
+		Prices      map[string]usage.Price `mapstructure:"prices"`       // 模型名 (不区分大小写) -> 每百万 token 价格
+		DailyBudget float64                `mapstructure:"daily_budget"` // 每日费用上限，0 表示不限制
+		DailyTokens int64                  `mapstructure:"daily_tokens"` // 每日 token 上限，0 表示不限制
+	} `mapstructure:"usage"`
 	Database struct {
 		DSN string `mapstructure:"dsn"`
 	} `mapstructure:"database"`
+	Server struct {
+		CORSOrigins []string `mapstructure:"cors_origins"` // 允许跨域访问的来源，"*" 表示任意来源，为空时不允许跨域
+	} `mapstructure:"server"`
+	RateLimit struct {
+		Enabled bool            `mapstructure:"enabled"` // 是否启用限流和每日配额
+		API     ratelimit.Limit `mapstructure:"api"`     // 所有 API 请求，按 API key (未启用鉴权时按 IP)
+		Paid    ratelimit.Limit `mapstructure:"paid"`    // 提交任务、搜索、问答、评分、组卷等调用付费 API 的请求，另外扣减
+		Quota   ratelimit.Quota `mapstructure:"quota"`   // 每个用户每天提交的任务数和问题行数，admin 不受限制
+	} `mapstructure:"rate_limit"`
+	Auth struct {
+		Enabled   bool          `mapstructure:"enabled"`    // 是否要求 API key / JWT，关闭时所有接口都不需要凭证
+		JWTSecret string        `mapstructure:"jwt_secret"` // JWT 签名密钥，为空时不支持 JWT
+		JWTTTL    time.Duration `mapstructure:"jwt_ttl"`    // JWT 有效期，0 表示默认 1 小时
+	} `mapstructure:"auth"`
 }
 
 // loadConfig 函数负责使用 viper 加载配置
@@ -46,5 +111,25 @@ func LoadConfig() (config Config, err error) {
 		return Config{}, fmt.Errorf("反序列化配置出错: %w", err)
 	}
 
+	// 未配置合并阈值时使用默认值，避免阈值为 0 导致所有问题都被合并
+	if config.Dedupe.MergeSimilarity == 0 {
+		config.Dedupe.MergeSimilarity = 0.95
+	}
+	if config.Rerank.Model == "" {
+		config.Rerank.Model = config.Ark.EnrichModel
+	}
+	if config.Ask.Model == "" {
+		config.Ask.Model = config.Ark.EnrichModel
+	}
+	if config.Grade.Model == "" {
+		config.Grade.Model = config.Ark.EnrichModel
+	}
+	if config.Interview.Model == "" {
+		config.Interview.Model = config.Ark.EnrichModel
+	}
+	if config.Cluster.Model == "" {
+		config.Cluster.Model = config.Ark.EnrichModel
+	}
+
 	return config, nil
 }
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"paguu/internal/auth"
+	"paguu/internal/storage/postgres"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gin.Context 中保存调用者、当前工作区及调用者在其中角色的键
+const (
+	principalKey     = "principal"
+	workspaceKey     = "workspace"
+	workspaceRoleKey = "workspace_role"
+	mutatingKey      = "mutating" // 只读路由上实际执行了写操作 (如 /ask 的 enqueue)
+)
+
+// readOnlyRoutes 是只需要 read 权限的 POST 路由 (查询条件放在请求体中)
+var readOnlyRoutes = map[string]bool{
+	"POST /api/v1/articles/search": true,
+	"POST /api/v1/ask":             true, // enqueue=true 时在 handler 中另外要求 write 并标记为写操作
+	"POST /api/v1/auth/token":      true,
+}
+
+// routeScope 返回访问当前路由所需的权限范围
+func routeScope(c *gin.Context) string {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead:
+		return auth.ScopeRead
+	}
+	if readOnlyRoutes[c.Request.Method+" "+c.FullPath()] {
+		return auth.ScopeRead
+	}
+	return auth.ScopeWrite
+}
+
+// principalFrom 返回通过鉴权的调用者，未启用鉴权时为 nil
+func principalFrom(c *gin.Context) *auth.Principal {
+	if v, ok := c.Get(principalKey); ok {
+		return v.(*auth.Principal)
+	}
+	return nil
+}
+
+// credentialFrom 从 Authorization: Bearer 或 X-API-Key 头中读取凭证
+func credentialFrom(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, credential, ok := strings.Cut(header, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(credential)
+		}
+	}
+	return strings.TrimSpace(c.GetHeader("X-API-Key"))
+}
+
+// authenticate 校验凭证并按请求方法检查 read/write 权限，authenticator 为 nil 时不鉴权
+func authenticate(authenticator *auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authenticator == nil {
+			c.Next()
+			return
+		}
+
+		principal, err := authenticator.Authenticate(c.Request.Context(), credentialFrom(c))
+		if err != nil {
+			if errors.Is(err, auth.ErrUnauthorized) {
+				c.Header("WWW-Authenticate", `Bearer realm="paguu"`)
+				renderError(c, err)
+				return
+			}
+			slog.ErrorContext(c.Request.Context(), "Authenticate error", "error", err)
+			renderError(c, errInternal("failed to authenticate"))
+			return
+		}
+		c.Set(principalKey, principal)
+
+		if scope := routeScope(c); !principal.Has(scope) {
+			renderError(c, newAPIError(http.StatusForbidden, CodeInsufficientScope, "insufficient scope: requires %s", scope))
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireScope 要求调用者拥有指定权限范围，未启用鉴权时不检查
+func requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p := principalFrom(c); p != nil && !p.Has(scope) {
+			renderError(c, newAPIError(http.StatusForbidden, CodeInsufficientScope, "insufficient scope: requires %s", scope))
+			return
+		}
+		c.Next()
+	}
+}
+
+// workspaceSlugFrom 从 X-Workspace 头或 workspace 查询参数中读取工作区标识，都未指定时为 default
+func workspaceSlugFrom(c *gin.Context) string {
+	if slug := strings.TrimSpace(c.GetHeader("X-Workspace")); slug != "" {
+		return slug
+	}
+	if slug := strings.TrimSpace(c.Query("workspace")); slug != "" {
+		return slug
+	}
+	return postgres.DefaultWorkspaceSlug
+}
+
+// workspaceScope 解析请求选择的工作区并按调用者的成员角色检查 read/write 权限，
+// 之后 Repository 的查询和写入都限定在该工作区内
+func workspaceScope(repo *postgres.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		workspace, err := repo.GetWorkspaceBySlug(ctx, workspaceSlugFrom(c))
+		if err != nil {
+			if errors.Is(err, postgres.ErrWorkspaceNotFound) {
+				renderError(c, err)
+				return
+			}
+			slog.ErrorContext(c.Request.Context(), "GetWorkspaceBySlug error", "error", err)
+			renderError(c, errInternal("failed to resolve workspace"))
+			return
+		}
+
+		role, ok := workspaceRole(c, repo, workspace.ID)
+		if !ok {
+			c.Abort()
+			return
+		}
+		if scope := routeScope(c); !auth.RoleAllows(role, scope) {
+			renderError(c, newAPIError(http.StatusForbidden, CodeInsufficientScope, "workspace role %s does not allow %s", role, scope))
+			return
+		}
+
+		c.Set(workspaceKey, workspace)
+		c.Set(workspaceRoleKey, role)
+		c.Request = c.Request.WithContext(postgres.WithWorkspace(ctx, workspace.ID))
+		c.Next()
+	}
+}
+
+// workspaceRole 返回调用者在工作区中的角色，未启用鉴权或调用者有 admin 权限时视为 owner
+// 调用者不是成员时已写入 403 响应并返回 false
+func workspaceRole(c *gin.Context, repo *postgres.Repository, workspaceID uint) (string, bool) {
+	p := principalFrom(c)
+	if p == nil || p.Has(auth.ScopeAdmin) {
+		return postgres.WorkspaceRoleOwner, true
+	}
+	role, err := repo.GetWorkspaceRole(c.Request.Context(), workspaceID, p.UserID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrMemberNotFound) {
+			renderError(c, errForbidden("not a member of this workspace"))
+			return "", false
+		}
+		slog.ErrorContext(c.Request.Context(), "GetWorkspaceRole error", "error", err, "workspace_id", workspaceID)
+		renderError(c, errInternal("failed to resolve workspace role"))
+		return "", false
+	}
+	return role, true
+}
+
+// allows 判断调用者能否执行需要 scope 的操作：同时检查 API key 权限范围和当前工作区中的角色
+func allows(c *gin.Context, scope string) bool {
+	if p := principalFrom(c); p != nil && !p.Has(scope) {
+		return false
+	}
+	if role := c.GetString(workspaceRoleKey); role != "" && !auth.RoleAllows(role, scope) {
+		return false
+	}
+	return true
+}
+
+// markMutating 标记只读路由上的请求执行了写操作，使其同样写入审计日志
+func markMutating(c *gin.Context) {
+	c.Set(mutatingKey, true)
+}
+
+// shouldAudit 判断请求是否需要记录审计日志：成功的写请求，或由 markMutating 标记的请求
+func shouldAudit(c *gin.Context) bool {
+	if c.Writer.Status() >= http.StatusBadRequest {
+		return false
+	}
+	return routeScope(c) != auth.ScopeRead || c.GetBool(mutatingKey)
+}
+
+// audit 在写请求成功后记录审计日志，用于追溯任务和修改的发起人
+func audit(repo *postgres.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if repo == nil || !shouldAudit(c) {
+			return
+		}
+		entry := &postgres.AuditLog{
+			Method: c.Request.Method,
+			Route:  c.FullPath(),
+			Path:   c.Request.URL.Path,
+			Status: c.Writer.Status(),
+		}
+		if p := principalFrom(c); p != nil {
+			entry.UserName = p.UserName
+			entry.KeyID = &p.KeyID
+		}
+		// 请求已结束，使用不会被取消的 context 写入
+		if err := repo.CreateAuditLog(context.WithoutCancel(c.Request.Context()), entry); err != nil {
+			slog.WarnContext(c.Request.Context(), "记录审计日志失败", "error", err, "route", entry.Route)
+		}
+	}
+}
+
+// cors 只允许配置中的来源跨域访问，列表中包含 "*" 时允许任意来源
+func cors(origins []string) gin.HandlerFunc {
+	allowAll := slices.Contains(origins, "*")
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin != "" && (allowAll || slices.Contains(origins, origin)) {
+			if allowAll {
+				c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+				c.Writer.Header().Add("Vary", "Origin")
+			}
+			c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Workspace, X-Request-ID, Accept-Language")
+			c.Writer.Header().Set("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After, X-Request-ID")
+		}
+
+		// 处理 OPTIONS 预检请求
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// resolveUser 确定请求所代表的用户
+// 未启用鉴权时直接使用请求参数；启用后默认为调用者本人，只有 admin 可以指定其他用户
+// 返回 false 时已写入 403 响应
+func resolveUser(c *gin.Context, requested string) (string, bool) {
+	requested = strings.TrimSpace(requested)
+	p := principalFrom(c)
+	if p == nil {
+		return requested, true
+	}
+	if requested == "" || requested == p.UserName {
+		return p.UserName, true
+	}
+	if p.Has(auth.ScopeAdmin) {
+		return requested, true
+	}
+	renderError(c, errForbidden("cannot act on behalf of another user"))
+	return "", false
+}
+
+// resolveUserFilter 与 resolveUser 相同，但 admin 未指定用户时返回空 (不按用户筛选)
+func resolveUserFilter(c *gin.Context, requested string) (string, bool) {
+	if p := principalFrom(c); p != nil && p.Has(auth.ScopeAdmin) {
+		return strings.TrimSpace(requested), true
+	}
+	return resolveUser(c, requested)
+}
+
+// requireUser 与 resolveUser 相同，但用户不能为空 (未启用鉴权且请求未指定用户时返回 400)
+func requireUser(c *gin.Context, requested string) (string, bool) {
+	user, ok := resolveUser(c, requested)
+	if !ok {
+		return "", false
+	}
+	if user == "" {
+		renderError(c, errBadRequest("user is required"))
+		return "", false
+	}
+	return user, true
+}
+
+// ownsResource 判断调用者能否访问属于 owner 的数据：未启用鉴权、admin 或本人
+func ownsResource(c *gin.Context, owner string) bool {
+	p := principalFrom(c)
+	return p == nil || p.Has(auth.ScopeAdmin) || p.UserName == owner
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"paguu/internal/auth"
+	"paguu/internal/storage/postgres"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeKeyStore 为每个 API key 明文返回预设的权限范围
+type fakeKeyStore struct {
+	scopes map[string][]string // key 为 API key 明文
+}
+
+func (s *fakeKeyStore) FindActiveAPIKey(_ context.Context, keyHash string) (*postgres.ActiveAPIKey, error) {
+	for key, scopes := range s.scopes {
+		if auth.HashKey(key) == keyHash {
+			return &postgres.ActiveAPIKey{
+				Key:  postgres.APIKey{ID: 1, UserID: 1, Scopes: scopes},
+				User: postgres.User{ID: 1, Name: "alice"},
+			}, nil
+		}
+	}
+	return nil, postgres.ErrAPIKeyNotFound
+}
+
+func (s *fakeKeyStore) GetActiveAPIKey(context.Context, uint) (*postgres.ActiveAPIKey, error) {
+	return nil, postgres.ErrAPIKeyNotFound
+}
+
+func (s *fakeKeyStore) TouchAPIKey(context.Context, uint) error {
+	return nil
+}
+
+func TestRouteScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		method, route, path string
+		want                string
+	}{
+		{http.MethodGet, "/api/v1/articles", "/api/v1/articles", auth.ScopeRead},
+		{http.MethodHead, "/api/v1/articles", "/api/v1/articles", auth.ScopeRead},
+		{http.MethodPost, "/api/v1/articles/search", "/api/v1/articles/search", auth.ScopeRead},
+		{http.MethodPost, "/api/v1/ask", "/api/v1/ask", auth.ScopeRead},
+		{http.MethodPost, "/api/v1/auth/token", "/api/v1/auth/token", auth.ScopeRead},
+		{http.MethodPost, "/api/v1/tasks", "/api/v1/tasks", auth.ScopeWrite},
+		{http.MethodPost, "/api/v1/articles/:id/merge", "/api/v1/articles/1/merge", auth.ScopeWrite},
+		{http.MethodDelete, "/api/v1/keys/:id", "/api/v1/keys/8", auth.ScopeWrite},
+		{http.MethodPut, "/api/v1/workspaces/:slug", "/api/v1/workspaces/backend", auth.ScopeWrite},
+	}
+	for _, tt := range tests {
+		var got string
+		r := gin.New()
+		r.Handle(tt.method, tt.route, func(c *gin.Context) { got = routeScope(c) })
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+		if got != tt.want {
+			t.Errorf("routeScope(%s %s) = %q, want %q", tt.method, tt.route, got, tt.want)
+		}
+	}
+}
+
+func TestAuthenticateScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &fakeKeyStore{scopes: map[string][]string{
+		"pg_read":  {auth.ScopeRead},
+		"pg_write": {auth.ScopeWrite},
+		"pg_admin": {auth.ScopeAdmin},
+	}}
+	authenticator := auth.NewAuthenticator(store)
+
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	r := gin.New()
+	r.Use(requestID(), authenticate(authenticator))
+	r.GET("/api/v1/articles", ok)
+	r.POST("/api/v1/articles/search", ok)
+	r.POST("/api/v1/tasks", ok)
+	r.POST("/api/v1/tags/aliases", requireScope(auth.ScopeAdmin), ok)
+
+	tests := []struct {
+		name         string
+		method, path string
+		key          string
+		want         int
+	}{
+		{"missing credential", http.MethodGet, "/api/v1/articles", "", http.StatusUnauthorized},
+		{"unknown key", http.MethodGet, "/api/v1/articles", "pg_unknown", http.StatusUnauthorized},
+		{"invalid jwt", http.MethodGet, "/api/v1/articles", "a.b.c", http.StatusUnauthorized},
+		{"read key reads", http.MethodGet, "/api/v1/articles", "pg_read", http.StatusNoContent},
+		{"read key searches", http.MethodPost, "/api/v1/articles/search", "pg_read", http.StatusNoContent},
+		{"read key cannot write", http.MethodPost, "/api/v1/tasks", "pg_read", http.StatusForbidden},
+		{"write key writes", http.MethodPost, "/api/v1/tasks", "pg_write", http.StatusNoContent},
+		{"write key cannot edit aliases", http.MethodPost, "/api/v1/tags/aliases", "pg_write", http.StatusForbidden},
+		{"admin edits aliases", http.MethodPost, "/api/v1/tags/aliases", "pg_admin", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.key)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestOwnsResource(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		owner     string
+		want      bool
+	}{
+		{"auth disabled", nil, "bob", true},
+		{"owner", &auth.Principal{UserName: "alice", Scopes: []string{auth.ScopeRead}}, "alice", true},
+		{"other user", &auth.Principal{UserName: "alice", Scopes: []string{auth.ScopeWrite}}, "bob", false},
+		{"admin", &auth.Principal{UserName: "root", Scopes: []string{auth.ScopeAdmin}}, "bob", true},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		if tt.principal != nil {
+			c.Set(principalKey, tt.principal)
+		}
+		if got := ownsResource(c, tt.owner); got != tt.want {
+			t.Errorf("%s: ownsResource = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestShouldAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name     string
+		method   string
+		route    string
+		status   int
+		mutating bool
+		want     bool
+	}{
+		{"successful write", http.MethodPost, "/api/v1/tasks", http.StatusCreated, false, true},
+		{"failed write", http.MethodPost, "/api/v1/tasks", http.StatusBadRequest, false, false},
+		{"read", http.MethodGet, "/api/v1/articles", http.StatusOK, false, false},
+		{"ask without enqueue", http.MethodPost, "/api/v1/ask", http.StatusOK, false, false},
+		{"ask with enqueue", http.MethodPost, "/api/v1/ask", http.StatusOK, true, true},
+	}
+	for _, tt := range tests {
+		var got bool
+		r := gin.New()
+		r.Handle(tt.method, tt.route, func(c *gin.Context) {
+			if tt.mutating {
+				markMutating(c)
+			}
+			c.Status(tt.status)
+			got = shouldAudit(c)
+		})
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.route, nil))
+		if got != tt.want {
+			t.Errorf("%s: shouldAudit = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
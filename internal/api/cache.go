@@ -0,0 +1,59 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache 是一个按 key 缓存计算结果的简单 TTL 缓存，用于开销较大的统计接口
+type ttlCache struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	items map[string]ttlEntry
+}
+
+type ttlEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+func newTTLCache(ttl time.Duration) *ttlCache {
+	return &ttlCache{
+		ttl:   ttl,
+		items: make(map[string]ttlEntry),
+	}
+}
+
+// get 返回未过期的缓存值；ttl 不大于 0 时缓存不生效
+func (c *ttlCache) get(key string) (any, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.items, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *ttlCache) set(key string, value any) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = ttlEntry{value: value, expiresAt: time.Now().Add(c.ttl)}
+}
+
+// clear 清空缓存，在数据被修改后调用
+func (c *ttlCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[string]ttlEntry)
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"paguu/internal/storage/postgres"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// ClusterRequest 簇查询的公共参数
+type ClusterRequest struct {
+	Days int    `form:"days" binding:"omitempty,min=1,max=3650"`    // 统计增长的窗口天数，默认 30
+	Sort string `form:"sort" binding:"omitempty,oneof=size growth"` // 默认 size
+}
+
+// ClusterResponse 簇响应结构
+type ClusterResponse struct {
+	ID           uint           `json:"id"`
+	RunID        uint           `json:"run_id"`
+	Name         string         `json:"name"`
+	Summary      *string        `json:"summary,omitempty"`
+	TopTags      pq.StringArray `json:"top_tags"`
+	Size         int            `json:"size"`          // 聚类时的文章数
+	ArticleCount int64          `json:"article_count"` // 当前仍存在的文章数
+	Recent       int64          `json:"recent"`        // 最近 days 天内的出现次数
+	Previous     int64          `json:"previous"`      // 之前 days 天内的出现次数
+	Growth       int64          `json:"growth"`        // recent - previous
+	CreatedAt    string         `json:"created_at"`
+}
+
+// toClusterResponse 将簇摘要转换为响应结构
+func toClusterResponse(s postgres.ClusterSummary) ClusterResponse {
+	return ClusterResponse{
+		ID:           s.ID,
+		RunID:        s.RunID,
+		Name:         s.Name,
+		Summary:      s.Summary,
+		TopTags:      s.TopTags,
+		Size:         s.Size,
+		ArticleCount: s.ArticleCount,
+		Recent:       s.Recent,
+		Previous:     s.Previous,
+		Growth:       s.Growth(),
+		CreatedAt:    s.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// ListClusters 返回最近一次聚类运行的主题簇，可按规模或增长排序
+func (h *Handler) ListClusters(c *gin.Context) {
+	var req ClusterRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if req.Days == 0 {
+		req.Days = 30
+	}
+	if req.Sort == "" {
+		req.Sort = postgres.ClusterSortSize
+	}
+
+	run, err := h.repo.LatestClusterRun(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, postgres.ErrClusterNotFound) {
+			renderError(c, errNotFound("no cluster run yet, run cmd/cluster first"))
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "LatestClusterRun error", "error", err)
+		renderError(c, errInternal("failed to list clusters"))
+		return
+	}
+
+	window := time.Duration(req.Days) * 24 * time.Hour
+	summaries, err := h.repo.ListClusters(c.Request.Context(), run.ID, window, req.Sort)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListClusters error", "error", err, "run_id", run.ID)
+		renderError(c, errInternal("failed to list clusters"))
+		return
+	}
+
+	responses := make([]ClusterResponse, len(summaries))
+	for i, s := range summaries {
+		responses[i] = toClusterResponse(s)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": responses,
+		"run": gin.H{
+			"id":            run.ID,
+			"k":             run.K,
+			"article_count": run.ArticleCount,
+			"naming_model":  run.NamingModel,
+			"created_at":    run.CreatedAt.Format("2006-01-02 15:04:05"),
+		},
+		"days": req.Days,
+		"sort": req.Sort,
+	})
+}
+
+// GetCluster 获取单个簇及其增长情况
+func (h *Handler) GetCluster(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid cluster id"))
+		return
+	}
+
+	var req ClusterRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if req.Days == 0 {
+		req.Days = 30
+	}
+
+	window := time.Duration(req.Days) * 24 * time.Hour
+	summary, err := h.repo.GetClusterSummary(c.Request.Context(), uint(id), window)
+	if err != nil {
+		if errors.Is(err, postgres.ErrClusterNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "GetClusterSummary error", "error", err, "id", id)
+		renderError(c, errInternal("failed to get cluster"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": toClusterResponse(*summary),
+		"days": req.Days,
+	})
+}
+
+// ListClusterArticlesRequest 簇成员列表请求参数
+type ListClusterArticlesRequest struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// ListClusterArticles 按与簇中心的相似度降序列出簇中的文章
+func (h *Handler) ListClusterArticles(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid cluster id"))
+		return
+	}
+
+	var req ListClusterArticlesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	members, total, err := h.repo.ListClusterArticles(c.Request.Context(), uint(id), req.PageSize, offset)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListClusterArticles error", "error", err, "id", id)
+		renderError(c, errInternal("failed to list cluster articles"))
+		return
+	}
+	if total == 0 {
+		// 区分空簇和不存在的簇
+		if _, err := h.repo.GetClusterSummary(c.Request.Context(), uint(id), 0); errors.Is(err, postgres.ErrClusterNotFound) {
+			renderError(c, postgres.ErrClusterNotFound)
+			return
+		}
+	}
+
+	responses := make([]ArticleResponse, len(members))
+	for i, m := range members {
+		similarity := m.Similarity
+		responses[i] = toArticleResponse(m.Article)
+		responses[i].Similarity = &similarity
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       responses,
+		"pagination": newPagination(req.Page, req.PageSize, total),
+	})
+}
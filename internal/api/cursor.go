@@ -0,0 +1,21 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// encodeCursor 将分页位置编码为不透明的游标 (base64url 编码的 JSON)，调用方不应解析或构造游标
+func encodeCursor(position any) string {
+	data, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor 解析 encodeCursor 生成的游标
+func decodeCursor(cursor string, position any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, position)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"testing"
+
+	"paguu/internal/storage/postgres"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []postgres.ArticleCursor{
+		{Sort: "id", Order: "desc", ID: 42},
+		{Sort: "created_at", Order: "asc", Value: "2025-10-01T09:00:00.123456Z", ID: 7},
+		{Sort: "occurrences", Order: "desc", Value: "0", ID: 1},
+		{},
+	}
+	for _, want := range tests {
+		cursor := encodeCursor(want)
+		var got postgres.ArticleCursor
+		if err := decodeCursor(cursor, &got); err != nil {
+			t.Errorf("decodeCursor(encodeCursor(%+v)): %v", want, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("round trip = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"padded base64", encodeCursor(postgres.ArticleCursor{ID: 1}) + "="},
+		{"not json", "bm90IGpzb24"},                 // "not json"
+		{"wrong type", "eyJpZCI6Im9uZSJ9"},          // {"id":"one"}
+		{"negative id", "eyJzIjoiaWQiLCJpZCI6LTF9"}, // {"s":"id","id":-1}
+		{"truncated", encodeCursor(postgres.ArticleCursor{Sort: "id", ID: 1})[:5]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got postgres.ArticleCursor
+			if err := decodeCursor(tt.cursor, &got); err == nil {
+				t.Errorf("decodeCursor(%q) = %+v, want error", tt.cursor, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"paguu/internal/auth"
+	"paguu/internal/storage/postgres"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// 错误响应中的 code 字段，调用方应按 code 而不是 error 文本判断错误类型
+// 仓储层的领域错误使用 postgres.Error 的错误码 (如 article_not_found、tag_conflict)
+const (
+	CodeInvalidRequest    = "invalid_request"    // 400 参数格式错误
+	CodeValidationFailed  = "validation_failed"  // 400 字段校验失败，details 列出每个字段
+	CodeUnauthorized      = "unauthorized"       // 401 凭证缺失、无效或过期
+	CodeForbidden         = "forbidden"          // 403 无权访问该资源
+	CodeInsufficientScope = "insufficient_scope" // 403 API key 权限范围或工作区角色不足
+	CodeNotFound          = "not_found"          // 404
+	CodeConflict          = "conflict"           // 409
+	CodeUnprocessable     = "unprocessable"      // 422 参数合法但无法满足
+	CodeRateLimited       = "rate_limited"       // 429 超出限流
+	CodeQuotaExceeded     = "quota_exceeded"     // 429 超出每日配额
+	CodeInternal          = "internal_error"     // 500
+	CodeNotConfigured     = "not_configured"     // 503 可选功能未配置
+)
+
+// apiError 是返回给调用者的错误，由 renderError 统一写成错误响应
+// Message 是英文消息 (fmt 格式，参数为 Args)，同时是翻译目录的键
+type apiError struct {
+	Status  int
+	Code    string
+	Message string
+	Args    []any
+	Detail  string       // 附加在消息之后、不翻译的说明 (如领域错误的具体原因)
+	Fields  []FieldError // 字段校验失败的列表
+	Extra   gin.H        // 与 error 并列返回的其他字段 (如 retry_after)
+}
+
+func (e *apiError) Error() string {
+	return e.localize(langEN)
+}
+
+// with 附加与 error 并列返回的字段
+func (e *apiError) with(extra gin.H) *apiError {
+	e.Extra = extra
+	return e
+}
+
+// withDetail 附加不翻译的具体原因，只用于本项目生成的、不包含内部信息的错误文本
+func (e *apiError) withDetail(detail string) *apiError {
+	e.Detail = detail
+	return e
+}
+
+// localize 按语言返回消息，没有翻译时使用英文
+func (e *apiError) localize(lang string) string {
+	if len(e.Fields) > 0 {
+		messages := make([]string, len(e.Fields))
+		for i, f := range e.Fields {
+			messages[i] = translate(lang, f.message, f.args...)
+		}
+		return strings.Join(messages, "; ")
+	}
+	message := translate(lang, e.Message, e.Args...)
+	if e.Detail != "" {
+		message += ": " + e.Detail
+	}
+	return message
+}
+
+func newAPIError(status int, code, message string, args ...any) *apiError {
+	return &apiError{Status: status, Code: code, Message: message, Args: args}
+}
+
+func errBadRequest(message string, args ...any) *apiError {
+	return newAPIError(http.StatusBadRequest, CodeInvalidRequest, message, args...)
+}
+
+func errUnauthorized(message string, args ...any) *apiError {
+	return newAPIError(http.StatusUnauthorized, CodeUnauthorized, message, args...)
+}
+
+func errForbidden(message string, args ...any) *apiError {
+	return newAPIError(http.StatusForbidden, CodeForbidden, message, args...)
+}
+
+func errNotFound(message string, args ...any) *apiError {
+	return newAPIError(http.StatusNotFound, CodeNotFound, message, args...)
+}
+
+func errUnprocessable(message string, args ...any) *apiError {
+	return newAPIError(http.StatusUnprocessableEntity, CodeUnprocessable, message, args...)
+}
+
+func errInternal(message string, args ...any) *apiError {
+	return newAPIError(http.StatusInternalServerError, CodeInternal, message, args...)
+}
+
+func errNotConfigured(message string, args ...any) *apiError {
+	return newAPIError(http.StatusServiceUnavailable, CodeNotConfigured, message, args...)
+}
+
+// toAPIError 将错误映射为响应：领域错误按类别映射为 404/409/400，鉴权错误映射为 401/400/503
+// 其他错误一律视为内部错误，不把原始错误文本 (可能包含数据库信息) 返回给调用者
+func toAPIError(err error) (*apiError, bool) {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+
+	var domainErr *postgres.Error
+	if errors.As(err, &domainErr) {
+		e := &apiError{Code: domainErr.Code, Message: domainErr.Message}
+		switch domainErr.Kind {
+		case postgres.ErrNotFound:
+			e.Status = http.StatusNotFound
+		case postgres.ErrConflict:
+			e.Status = http.StatusConflict
+		default:
+			e.Status = http.StatusBadRequest
+		}
+		// fmt.Errorf("%w: 具体原因", ErrX) 附加的说明
+		if detail, ok := strings.CutPrefix(err.Error(), domainErr.Message+": "); ok {
+			e.Detail = detail
+		}
+		return e, true
+	}
+
+	switch {
+	case errors.Is(err, auth.ErrUnauthorized):
+		return errUnauthorized(auth.ErrUnauthorized.Error()), true
+	case errors.Is(err, auth.ErrTokenRenewal):
+		return errBadRequest(auth.ErrTokenRenewal.Error()), true
+	case errors.Is(err, auth.ErrJWTDisabled):
+		return errNotConfigured(auth.ErrJWTDisabled.Error()), true
+	}
+	return errInternal("internal server error"), false
+}
+
+// isDomainError 判断 err 是否可以直接返回给调用者 (而不是记录日志后返回 500)
+func isDomainError(err error) bool {
+	_, ok := toAPIError(err)
+	return ok
+}
+
+// renderError 是所有错误响应的出口，写入统一的错误结构并中止后续 handler：
+//
+//	{"error": "本地化的消息", "code": "article_not_found", "request_id": "...", "details": [...]}
+//
+// err 通常是 errXxx 构造的 apiError 或仓储层的领域错误；未知错误记录日志后返回 500
+func renderError(c *gin.Context, err error) {
+	e, ok := toAPIError(err)
+	if !ok {
+		slog.ErrorContext(c.Request.Context(), "unhandled error", "error", err, "route", c.FullPath())
+	}
+	c.AbortWithStatusJSON(e.Status, errorBody(c, e))
+}
+
+// errorBody 返回错误响应体，也用于 SSE 的 error 事件
+func errorBody(c *gin.Context, e *apiError) gin.H {
+	body := gin.H{
+		"error":      e.localize(requestLanguage(c)),
+		"code":       e.Code,
+		"request_id": requestIDFrom(c),
+	}
+	if len(e.Fields) > 0 {
+		body["details"] = e.Fields
+	}
+	for k, v := range e.Extra {
+		body[k] = v
+	}
+	return body
+}
+
+// FieldError 是一个字段的校验错误，field 为 JSON 字段名或查询参数名，rule 为未通过的 binding 规则
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+
+	message string
+	args    []any
+}
+
+// bindError 将 ShouldBindJSON/ShouldBindQuery 的错误转换为 400
+// 字段校验失败时逐个列出字段，JSON 格式错误时不返回解析器的原始信息
+func bindError(err error) *apiError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		e := newAPIError(http.StatusBadRequest, CodeValidationFailed, "invalid request")
+		for _, fe := range validationErrs {
+			e.Fields = append(e.Fields, fieldError(fe))
+		}
+		return e
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return errBadRequest("request body is required")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return errBadRequest("request body is not valid JSON")
+	case errors.As(err, &typeErr):
+		return errBadRequest("field %s has the wrong type", typeErr.Field)
+	}
+	return errBadRequest("invalid request parameters")
+}
+
+// fieldError 按规则生成字段的说明：字符串的 min/max 是长度，数组是元素个数，数字是取值范围
+func fieldError(fe validator.FieldError) FieldError {
+	f := FieldError{Field: fe.Field(), Rule: fe.Tag(), Param: fe.Param()}
+	sized := fe.Kind() == reflect.String || fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map
+	switch {
+	case f.Rule == "required":
+		f.message, f.args = "%s is required", []any{f.Field}
+	case f.Rule == "min" && sized:
+		f.message, f.args = "%s must contain at least %s items", []any{f.Field, f.Param}
+		if fe.Kind() == reflect.String {
+			f.message = "%s must be at least %s characters"
+		}
+	case f.Rule == "max" && sized:
+		f.message, f.args = "%s must contain at most %s items", []any{f.Field, f.Param}
+		if fe.Kind() == reflect.String {
+			f.message = "%s must be at most %s characters"
+		}
+	case f.Rule == "min":
+		f.message, f.args = "%s must be at least %s", []any{f.Field, f.Param}
+	case f.Rule == "max":
+		f.message, f.args = "%s must be at most %s", []any{f.Field, f.Param}
+	case f.Rule == "gt":
+		f.message, f.args = "%s must be greater than %s", []any{f.Field, f.Param}
+	case f.Rule == "oneof":
+		f.message, f.args = "%s must be one of: %s", []any{f.Field, strings.ReplaceAll(f.Param, " ", ", ")}
+	default:
+		f.message, f.args = "%s is invalid", []any{f.Field}
+	}
+	return f
+}
+
+var registerFieldNamesOnce sync.Once
+
+// registerFieldNames 让校验错误使用 json/form 标签中的字段名，而不是 Go 结构体字段名
+func registerFieldNames() {
+	registerFieldNamesOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, key := range []string{"json", "form"} {
+				name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+				if name != "" && name != "-" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	})
+}
+
+// 错误消息的语言
+const (
+	langEN = "en"
+	langZH = "zh"
+)
+
+// requestLanguage 按 Accept-Language 选择错误消息的语言，默认英文
+func requestLanguage(c *gin.Context) string {
+	best, bestQ := langEN, 0.0
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if _, err := fmt.Sscanf(v, "%g", &q); err != nil {
+				continue
+			}
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if (primary == langZH || primary == langEN) && q > bestQ {
+			best, bestQ = primary, q
+		}
+	}
+	return best
+}
+
+// translate 返回消息在 lang 中的翻译并填入参数，没有翻译时使用英文原文
+func translate(lang, message string, args ...any) string {
+	if lang == langZH {
+		if translated, ok := zhMessages[message]; ok {
+			message = translated
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
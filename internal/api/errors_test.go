@@ -0,0 +1,45 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"paguu/internal/auth"
+	"paguu/internal/storage/postgres"
+	"testing"
+)
+
+func TestToAPIError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantText   string
+		wantKnown  bool
+	}{
+		{"api error passes through", errUnprocessable("rerank is not configured"), http.StatusUnprocessableEntity, CodeUnprocessable, "rerank is not configured", true},
+		{"wrapped api error", fmt.Errorf("handler: %w", errBadRequest("invalid id")), http.StatusBadRequest, CodeInvalidRequest, "invalid id", true},
+		{"not found", postgres.ErrArticleNotFound, http.StatusNotFound, "article_not_found", "article not found", true},
+		{"conflict", postgres.ErrUserExists, http.StatusConflict, "user_exists", "user already exists", true},
+		{"invalid with detail", fmt.Errorf("%w: source and target are the same", postgres.ErrInvalidMerge), http.StatusBadRequest, "invalid_merge", "invalid merge request: source and target are the same", true},
+		{"unauthorized", auth.ErrUnauthorized, http.StatusUnauthorized, CodeUnauthorized, auth.ErrUnauthorized.Error(), true},
+		{"token renewal", auth.ErrTokenRenewal, http.StatusBadRequest, CodeInvalidRequest, auth.ErrTokenRenewal.Error(), true},
+		{"jwt disabled", auth.ErrJWTDisabled, http.StatusServiceUnavailable, CodeNotConfigured, auth.ErrJWTDisabled.Error(), true},
+		{"internal error hides text", errors.New("pq: relation \"articles\" does not exist"), http.StatusInternalServerError, CodeInternal, "internal server error", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, known := toAPIError(tt.err)
+			if known != tt.wantKnown {
+				t.Errorf("known = %v, want %v", known, tt.wantKnown)
+			}
+			if got.Status != tt.wantStatus || got.Code != tt.wantCode {
+				t.Errorf("status, code = %d, %q, want %d, %q", got.Status, got.Code, tt.wantStatus, tt.wantCode)
+			}
+			if text := got.Error(); text != tt.wantText {
+				t.Errorf("message = %q, want %q", text, tt.wantText)
+			}
+		})
+	}
+}
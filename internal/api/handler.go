@@ -2,60 +2,250 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"paguu/internal/ask"
+	"paguu/internal/auth"
 	"paguu/internal/embedding"
+	"paguu/internal/enrich"
+	"paguu/internal/grade"
+	"paguu/internal/interview"
 	"paguu/internal/processor"
+	"paguu/internal/ratelimit"
+	"paguu/internal/rerank"
 	"paguu/internal/storage/postgres"
+	"paguu/internal/usage"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
+	"gorm.io/datatypes"
 )
 
 type Handler struct {
 	repo          *postgres.Repository
 	embedder      *embedding.Embedder
 	taskProcessor *processor.TaskProcessor
+
+	// 可选的搜索重排序
+	reranker         rerank.Reranker
+	rerankCandidates int
+
+	// 标签统计结果缓存，标签管理操作后清空
+	tagStatsCache *ttlCache
+
+	// 可选的检索增强问答
+	answerer         *ask.Answerer
+	askTopK          int
+	askMinSimilarity float64
+
+	// 可选的练习作答评分
+	grader *grade.Grader
+
+	// 模拟面试组卷，默认按答案长度估算题目深度
+	interviewPlanner *interview.Planner
+
+	// 可选的鉴权，为 nil 时所有接口都不需要凭证
+	authenticator *auth.Authenticator
+
+	// 可选的限流和每日配额，为 nil 时不限制
+	limiter *ratelimit.Limiter
+
+	// 工作区可选的丰富化模板所在目录，为空时工作区不能覆盖模板
+	enrichTemplateDir string
 }
 
+// defaultTagStatsCacheTTL 是标签统计结果的默认缓存时间
+const defaultTagStatsCacheTTL = 5 * time.Minute
+
 func NewHandler(repo *postgres.Repository, embedder *embedding.Embedder, taskProcessor *processor.TaskProcessor) *Handler {
 	return &Handler{
-		repo:          repo,
-		embedder:      embedder,
-		taskProcessor: taskProcessor,
+		repo:             repo,
+		embedder:         embedder,
+		taskProcessor:    taskProcessor,
+		tagStatsCache:    newTTLCache(defaultTagStatsCacheTTL),
+		interviewPlanner: interview.NewPlanner(repo, nil),
+	}
+}
+
+// SetTagStatsCacheTTL 设置标签统计结果的缓存时间，0 表示使用默认值，负数表示不缓存
+func (h *Handler) SetTagStatsCacheTTL(ttl time.Duration) {
+	if ttl == 0 {
+		ttl = defaultTagStatsCacheTTL
+	}
+	h.tagStatsCache = newTTLCache(ttl)
+}
+
+// defaultRerankCandidates 是重排序默认取的向量检索候选数
+const defaultRerankCandidates = 30
+
+// SetReranker 设置搜索重排序器及默认候选数，reranker 为 nil 时不支持重排序
+func (h *Handler) SetReranker(reranker rerank.Reranker, candidates int) {
+	if candidates <= 0 {
+		candidates = defaultRerankCandidates
+	}
+	h.reranker = reranker
+	h.rerankCandidates = candidates
+}
+
+// 问答的默认参数
+const (
+	defaultAskTopK          = 5
+	defaultAskMinSimilarity = 0.75
+)
+
+// SetAnswerer 设置问答使用的 LLM、默认检索文章数和最低相似度，answerer 为 nil 时不支持问答
+func (h *Handler) SetAnswerer(answerer *ask.Answerer, topK int, minSimilarity float64) {
+	if topK <= 0 {
+		topK = defaultAskTopK
+	}
+	if minSimilarity <= 0 {
+		minSimilarity = defaultAskMinSimilarity
+	}
+	h.answerer = answerer
+	h.askTopK = topK
+	h.askMinSimilarity = minSimilarity
+}
+
+// SetGrader 设置练习作答的评分器，为 nil 时不支持评分
+func (h *Handler) SetGrader(grader *grade.Grader) {
+	h.grader = grader
+}
+
+// SetAuthenticator 设置 API 鉴权，为 nil 时不鉴权
+func (h *Handler) SetAuthenticator(authenticator *auth.Authenticator) {
+	h.authenticator = authenticator
+}
+
+// SetInterviewPlanner 设置模拟面试组卷器，为 nil 时保留默认组卷器
+func (h *Handler) SetInterviewPlanner(planner *interview.Planner) {
+	if planner != nil {
+		h.interviewPlanner = planner
+	}
+}
+
+// SetLimiter 设置限流和每日任务配额，需要在 SetupRouter 之前调用
+func (h *Handler) SetLimiter(limiter *ratelimit.Limiter) {
+	h.limiter = limiter
+}
+
+// SetEnrichTemplateDir 设置工作区可选的丰富化模板目录，工作区的模板只能是该目录下的文件名
+func (h *Handler) SetEnrichTemplateDir(dir string) {
+	h.enrichTemplateDir = dir
+}
+
+// Pagination 分页信息，列表接口的响应都带有该字段
+type Pagination struct {
+	Page           int    `json:"page"` // 游标分页时为 0
+	PageSize       int    `json:"page_size"`
+	Total          int64  `json:"total"`
+	TotalPage      int64  `json:"total_page"`
+	TotalEstimated bool   `json:"total_estimated,omitempty"` // total 为查询规划器的估计值
+	NextCursor     string `json:"next_cursor,omitempty"`     // 下一页的游标，没有更多数据时为空 (目前只有文章列表返回)
+}
+
+func newPagination(page, pageSize int, total int64) Pagination {
+	return Pagination{
+		Page:      page,
+		PageSize:  pageSize,
+		Total:     total,
+		TotalPage: (total + int64(pageSize) - 1) / int64(pageSize),
 	}
 }
 
 // ArticleResponse 文章响应结构
 type ArticleResponse struct {
-	ID               uint           `json:"id"`
-	OriginalQuestion string         `json:"original_question"`
-	DetailedQuestion *string        `json:"detailed_question,omitempty"`
-	ConciseAnswer    *string        `json:"concise_answer,omitempty"`
-	Tags             pq.StringArray `json:"tags"`
-	CreatedAt        string         `json:"created_at"`
-	Similarity       *float64       `json:"similarity,omitempty"`
+	ID               uint                `json:"id"`
+	OriginalQuestion string              `json:"original_question"`
+	DetailedQuestion *string             `json:"detailed_question,omitempty"`
+	ConciseAnswer    *string             `json:"concise_answer,omitempty"`
+	Tags             pq.StringArray      `json:"tags"`
+	CreatedAt        string              `json:"created_at"`
+	UpdatedAt        string              `json:"updated_at"`
+	Similarity       *float64            `json:"similarity,omitempty"`
+	RerankScore      *float64            `json:"rerank_score,omitempty"` // 重排序分数，仅重排序搜索返回
+	VectorRank       *int                `json:"vector_rank,omitempty"`  // 重排序前在向量检索中的名次 (从 1 开始)
+	Duplicates       []postgres.ExtEntry `json:"duplicates,omitempty"`   // 已合并的重复项，仅详情接口返回
+	OccurrenceCount  *int64              `json:"occurrence_count,omitempty"`
+}
+
+// toArticleResponse 将文章模型转换为响应结构
+func toArticleResponse(article postgres.Article) ArticleResponse {
+	return ArticleResponse{
+		ID:               article.ID,
+		OriginalQuestion: article.OriginalQuestion,
+		DetailedQuestion: article.DetailedQuestion,
+		ConciseAnswer:    article.ConciseAnswer,
+		Tags:             article.Tags,
+		CreatedAt:        article.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:        article.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// OccurrenceQuery 是按出现记录统计次数的公共参数
+type OccurrenceQuery struct {
+	Days    int    `form:"days" json:"days" binding:"omitempty,min=1,max=3650"` // 只统计最近 N 天的出现
+	Source  string `form:"source" json:"source"`                                // 只统计该来源的任务
+	Company string `form:"company" json:"company"`                              // 只统计 metadata.company 为该值的任务
+}
+
+// filter 转换为仓储层的筛选条件
+func (q OccurrenceQuery) filter() postgres.OccurrenceFilter {
+	f := postgres.OccurrenceFilter{Source: q.Source, Company: q.Company}
+	if q.Days > 0 {
+		since := time.Now().AddDate(0, 0, -q.Days)
+		f.Since = &since
+	}
+	return f
 }
 
 // ListArticlesRequest 列表请求参数
+// 可以用 page 按页码分页，也可以用上一页返回的 next_cursor 按游标分页；游标分页不受新写入的文章影响，翻页越深越能体现优势
 type ListArticlesRequest struct {
 	Page     int      `form:"page" binding:"omitempty,min=1"`
 	PageSize int      `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Cursor   string   `form:"cursor"`
 	Tags     []string `form:"tags[]"`
+	Sort     string   `form:"sort" binding:"omitempty,oneof=latest id created_at updated_at occurrences"`
+	Order    string   `form:"order" binding:"omitempty,oneof=desc asc"`
+	Total    string   `form:"total" binding:"omitempty,oneof=exact estimate"` // 默认按页码分页时为 exact，按游标分页时为 estimate
+
+	OccurrenceQuery
+	MinOccurrences int64 `form:"min_occurrences" binding:"omitempty,min=0"`
 }
 
+// totalEstimate 表示列表的总数使用估计值
+const totalEstimate = "estimate"
+
+// searchSortOccurrences 表示搜索结果按出现次数排序，默认按相关度
+const searchSortOccurrences = "occurrences"
+
+// searchFilterOverfetch 是按出现次数筛选时向量检索多取的倍数，弥补筛选掉的结果
+const searchFilterOverfetch = 4
+
 // VectorSearchRequest 向量搜索请求参数
 type VectorSearchRequest struct {
-	Query string `json:"query" binding:"required"`
-	Limit int    `json:"limit" binding:"required,min=1,max=100"`
+	Query      string `json:"query" binding:"required"`
+	Limit      int    `json:"limit" binding:"required,min=1,max=100"`
+	Rerank     bool   `json:"rerank"`                                       // 是否对候选重排序
+	Candidates int    `json:"candidates" binding:"omitempty,min=1,max=200"` // 重排序的候选数，默认取配置值
+
+	// 按出现次数筛选和排序
+	OccurrenceQuery
+	MinOccurrences int64  `json:"min_occurrences" binding:"omitempty,min=0"`
+	Sort           string `json:"sort" binding:"omitempty,oneof=relevance occurrences"` // 默认 relevance
 }
 
 // ListArticles 获取文章列表
 func (h *Handler) ListArticles(c *gin.Context) {
 	var req ListArticlesRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		renderError(c, bindError(err))
 		return
 	}
 
@@ -66,35 +256,72 @@ func (h *Handler) ListArticles(c *gin.Context) {
 		req.PageSize = 20
 	}
 
+	var after *postgres.ArticleCursor
+	if req.Cursor != "" {
+		if req.Page > 1 {
+			renderError(c, errBadRequest("cursor cannot be combined with page"))
+			return
+		}
+		after = &postgres.ArticleCursor{}
+		if err := decodeCursor(req.Cursor, after); err != nil {
+			renderError(c, fmt.Errorf("%w: malformed cursor", postgres.ErrInvalidCursor))
+			return
+		}
+	}
+	estimate := req.Total == totalEstimate || req.Total == "" && after != nil
+
 	offset := (req.Page - 1) * req.PageSize
 
-	articles, total, err := h.repo.ListArticles(c.Request.Context(), req.Tags, req.PageSize, offset)
+	// 查询参数中的标签别名 (如 golang) 映射为规范名
+	tags, err := h.repo.CanonicalizeTags(c.Request.Context(), req.Tags)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "CanonicalizeTags error", "error", err)
+		renderError(c, errInternal("failed to list articles"))
+		return
+	}
+
+	// 多取一篇判断是否还有下一页
+	articles, total, err := h.repo.ListArticles(c.Request.Context(), postgres.ListArticlesOptions{
+		Tags:           tags,
+		Sort:           req.Sort,
+		Order:          req.Order,
+		Occurrence:     req.filter(),
+		MinOccurrences: req.MinOccurrences,
+		After:          after,
+		EstimateTotal:  estimate,
+		Limit:          req.PageSize + 1,
+		Offset:         offset,
+	})
 	if err != nil {
-		slog.Error("ListArticles error", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list articles"})
+		if errors.Is(err, postgres.ErrInvalidCursor) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "ListArticles error", "error", err)
+		renderError(c, errInternal("failed to list articles"))
 		return
 	}
 
+	pagination := newPagination(req.Page, req.PageSize, total)
+	pagination.TotalEstimated = estimate
+	if after != nil {
+		pagination.Page = 0
+	}
+	if len(articles) > req.PageSize {
+		articles = articles[:req.PageSize]
+		pagination.NextCursor = encodeCursor(postgres.CursorAfter(articles[len(articles)-1], req.Sort, req.Order))
+	}
+
 	responses := make([]ArticleResponse, len(articles))
 	for i, article := range articles {
-		responses[i] = ArticleResponse{
-			ID:               article.ID,
-			OriginalQuestion: article.OriginalQuestion,
-			DetailedQuestion: article.DetailedQuestion,
-			ConciseAnswer:    article.ConciseAnswer,
-			Tags:             article.Tags,
-			CreatedAt:        article.CreatedAt.Format("2006-01-02 15:04:05"),
-		}
+		count := article.OccurrenceCount
+		responses[i] = toArticleResponse(article.Article)
+		responses[i].OccurrenceCount = &count
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": responses,
-		"pagination": gin.H{
-			"page":       req.Page,
-			"page_size":  req.PageSize,
-			"total":      total,
-			"total_page": (total + int64(req.PageSize) - 1) / int64(req.PageSize),
-		},
+		"data":       responses,
+		"pagination": pagination,
 	})
 }
 
@@ -102,41 +329,121 @@ func (h *Handler) ListArticles(c *gin.Context) {
 func (h *Handler) VectorSearch(c *gin.Context) {
 	var req VectorSearchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		renderError(c, bindError(err))
+		return
+	}
+
+	// 在调用付费的 Embedding API 之前拒绝无法完成的请求
+	if req.Rerank && h.reranker == nil {
+		renderError(c, errBadRequest("rerank is not configured"))
+		return
+	}
+
+	vector, err := h.embedder.EmbedQuery(usage.WithTask(c.Request.Context(), "", "search"), req.Query)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Embedding error", "error", err)
+		renderError(c, errInternal("failed to generate embedding"))
 		return
 	}
 
-	vector, err := h.embedder.Embed(context.Background(), req.Query)
+	// 重排序时先取更多候选
+	fetch := req.Limit
+	if req.Rerank {
+		fetch = req.Candidates
+		if fetch == 0 {
+			fetch = h.rerankCandidates
+		}
+		fetch = max(fetch, req.Limit)
+	}
+	occurrenceFilter := req.filter()
+	filterByOccurrence := req.MinOccurrences > 0 || !occurrenceFilter.IsZero()
+	if filterByOccurrence {
+		fetch = min(max(fetch, req.Limit*searchFilterOverfetch), 200)
+	}
+
+	articles, similarities, err := h.repo.VectorSearchArticles(c.Request.Context(), vector, fetch)
 	if err != nil {
-		slog.Error("Embedding error", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate embedding"})
+		slog.ErrorContext(c.Request.Context(), "VectorSearchArticles error", "error", err)
+		renderError(c, errInternal("failed to search articles"))
 		return
 	}
 
-	articles, similarities, err := h.repo.VectorSearchArticles(c.Request.Context(), vector, req.Limit)
+	ids := make([]uint, len(articles))
+	for i, article := range articles {
+		ids[i] = article.ID
+	}
+	counts, err := h.repo.CountOccurrences(c.Request.Context(), ids, occurrenceFilter)
 	if err != nil {
-		slog.Error("VectorSearchArticles error", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search articles"})
+		slog.ErrorContext(c.Request.Context(), "CountOccurrences error", "error", err)
+		renderError(c, errInternal("failed to search articles"))
 		return
 	}
 
-	responses := make([]ArticleResponse, len(articles))
+	// 按出现次数筛选；设置了来源/时间窗口等条件时至少需要出现一次
+	minOccurrences := req.MinOccurrences
+	if !occurrenceFilter.IsZero() {
+		minOccurrences = max(minOccurrences, 1)
+	}
+	responses := make([]ArticleResponse, 0, len(articles))
+	kept := articles[:0]
 	for i, article := range articles {
+		count := counts[article.ID]
+		if count < minOccurrences {
+			continue
+		}
 		similarity := similarities[i]
-		responses[i] = ArticleResponse{
-			ID:               article.ID,
-			OriginalQuestion: article.OriginalQuestion,
-			DetailedQuestion: article.DetailedQuestion,
-			ConciseAnswer:    article.ConciseAnswer,
-			Tags:             article.Tags,
-			CreatedAt:        article.CreatedAt.Format("2006-01-02 15:04:05"),
-			Similarity:       &similarity,
+		response := toArticleResponse(article)
+		response.Similarity = &similarity
+		response.OccurrenceCount = &count
+		responses = append(responses, response)
+		kept = append(kept, article)
+	}
+	articles = kept
+
+	reranked := false
+	if req.Rerank {
+		docs := make([]rerank.Document, len(articles))
+		for i, article := range articles {
+			docs[i] = rerank.Document{
+				ID:   article.ID,
+				Text: rerank.DocumentText(article.OriginalQuestion, article.DetailedQuestion, article.ConciseAnswer),
+			}
+		}
+
+		ctx := usage.WithTask(c.Request.Context(), "", "search")
+		results, err := rerank.Order(ctx, h.reranker, req.Query, docs)
+		if err != nil {
+			// 重排序失败时退回向量检索顺序
+			slog.ErrorContext(c.Request.Context(), "Rerank error", "error", err)
+		} else {
+			ordered := make([]ArticleResponse, len(results))
+			for i, r := range results {
+				score := r.Score
+				rank := r.Index + 1
+				ordered[i] = responses[r.Index]
+				ordered[i].RerankScore = &score
+				ordered[i].VectorRank = &rank
+			}
+			responses = ordered
+			reranked = true
 		}
 	}
 
+	if req.Sort == searchSortOccurrences {
+		// 稳定排序：次数相同时保持相关度顺序
+		sort.SliceStable(responses, func(i, j int) bool {
+			return *responses[i].OccurrenceCount > *responses[j].OccurrenceCount
+		})
+	}
+
+	if len(responses) > req.Limit {
+		responses = responses[:req.Limit]
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"data":  responses,
-		"query": req.Query,
+		"data":     responses,
+		"query":    req.Query,
+		"reranked": reranked,
 	})
 }
 
@@ -145,35 +452,48 @@ func (h *Handler) GetArticle(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid article id"})
+		renderError(c, errBadRequest("invalid article id"))
 		return
 	}
 
-	article, err := h.repo.GetArticleByID(c.Request.Context(), uint(id))
+	// 已被合并的文章通过重定向解析到合并目标
+	resolvedID, err := h.repo.ResolveArticleID(c.Request.Context(), uint(id))
 	if err != nil {
-		slog.Error("GetArticleByID error", "error", err, "id", id)
-		c.JSON(http.StatusNotFound, gin.H{"error": "article not found"})
+		slog.ErrorContext(c.Request.Context(), "ResolveArticleID error", "error", err, "id", id)
+		renderError(c, errInternal("failed to get article"))
 		return
 	}
 
-	response := ArticleResponse{
-		ID:               article.ID,
-		OriginalQuestion: article.OriginalQuestion,
-		DetailedQuestion: article.DetailedQuestion,
-		ConciseAnswer:    article.ConciseAnswer,
-		Tags:             article.Tags,
-		CreatedAt:        article.CreatedAt.Format("2006-01-02 15:04:05"),
+	article, err := h.repo.GetArticleByID(c.Request.Context(), resolvedID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrArticleNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "GetArticleByID error", "error", err, "id", resolvedID)
+		renderError(c, errInternal("failed to get article"))
+		return
+	}
+
+	response := toArticleResponse(*article)
+	response.Duplicates, err = postgres.ParseArticleExt(article.Ext)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "ParseArticleExt error", "error", err, "id", article.ID)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": response})
+	body := gin.H{"data": response}
+	if resolvedID != uint(id) {
+		body["redirected_from"] = uint(id)
+	}
+	c.JSON(http.StatusOK, body)
 }
 
 // GetAllTags 获取所有 tag 列表
 func (h *Handler) GetAllTags(c *gin.Context) {
 	tags, err := h.repo.GetAllTags(c.Request.Context())
 	if err != nil {
-		slog.Error("GetAllTags error", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tags"})
+		slog.ErrorContext(c.Request.Context(), "GetAllTags error", "error", err)
+		renderError(c, errInternal("failed to get tags"))
 		return
 	}
 
@@ -187,13 +507,21 @@ type CreateTaskRequest struct {
 	RawQuestions string                 `json:"raw_questions" binding:"required"`
 	Source       string                 `json:"source"`
 	Metadata     map[string]interface{} `json:"metadata"`
+	NoCache      bool                   `json:"no_cache"`
+}
+
+// TaskResponse 创建任务响应
+type TaskResponse struct {
+	TaskID    string `json:"task_id"`
+	Source    string `json:"source"`
+	CreatedAt string `json:"created_at"`
 }
 
 // CreateTask 创建新的处理任务
 func (h *Handler) CreateTask(c *gin.Context) {
 	var req CreateTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		renderError(c, bindError(err))
 		return
 	}
 
@@ -202,23 +530,32 @@ func (h *Handler) CreateTask(c *gin.Context) {
 		RawQuestions: req.RawQuestions,
 		Source:       req.Source,
 		Metadata:     req.Metadata,
+		NoCache:      req.NoCache,
+	}
+	if p := principalFrom(c); p != nil {
+		task.CreatedBy = p.UserName
 	}
 	task.FillMetadata()
 
+	questions := len(enrich.SplitQuestionLines(req.RawQuestions))
+	if !h.consumeQuota(c, questions) {
+		return
+	}
 	err := h.taskProcessor.NewTask(c.Request.Context(), "enrich_questions", task)
 	if err != nil {
-		slog.Error("CreateTask error", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create task"})
+		h.releaseQuota(c, questions)
+		slog.ErrorContext(c.Request.Context(), "CreateTask error", "error", err)
+		renderError(c, errInternal("failed to create task"))
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "task created successfully",
 		"task_id": task.TaskID,
-		"data": gin.H{
-			"task_id":    task.TaskID,
-			"source":     task.Source,
-			"created_at": task.CreatedAt.Format("2006-01-02 15:04:05"),
+		"data": TaskResponse{
+			TaskID:    task.TaskID,
+			Source:    task.Source,
+			CreatedAt: task.CreatedAt.Format("2006-01-02 15:04:05"),
 		},
 	})
 }
@@ -228,34 +565,39 @@ func (h *Handler) FindSimilarArticles(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid article id"})
+		renderError(c, errBadRequest("invalid article id"))
 		return
 	}
 
 	limitStr := c.DefaultQuery("limit", "10")
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit < 1 || limit > 100 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+		renderError(c, errBadRequest("limit must be between 1 and 100"))
+		return
+	}
+
+	resolvedID, err := h.repo.ResolveArticleID(c.Request.Context(), uint(id))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ResolveArticleID error", "error", err, "id", id)
+		renderError(c, errInternal("failed to find similar articles"))
 		return
 	}
+	id = uint64(resolvedID)
 
 	articles, err := h.repo.FindSimilarBySourceID(c.Request.Context(), uint(id), limit)
 	if err != nil {
-		slog.Error("FindSimilarBySourceID error", "error", err, "id", id)
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		if errors.Is(err, postgres.ErrArticleNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "FindSimilarBySourceID error", "error", err, "id", id)
+		renderError(c, errInternal("failed to find similar articles"))
 		return
 	}
 
 	responses := make([]ArticleResponse, len(articles))
 	for i, article := range articles {
-		responses[i] = ArticleResponse{
-			ID:               article.ID,
-			OriginalQuestion: article.OriginalQuestion,
-			DetailedQuestion: article.DetailedQuestion,
-			ConciseAnswer:    article.ConciseAnswer,
-			Tags:             article.Tags,
-			CreatedAt:        article.CreatedAt.Format("2006-01-02 15:04:05"),
-		}
+		responses[i] = toArticleResponse(article)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -264,3 +606,927 @@ func (h *Handler) FindSimilarArticles(c *gin.Context) {
 		"limit":     limit,
 	})
 }
+
+// GetUsage 按 天/模型/来源 汇总 LLM 与 Embedding 用量
+// 查询参数 from/to 为 YYYY-MM-DD (均包含)，默认最近 7 天
+func (h *Handler) GetUsage(c *gin.Context) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	from := today.AddDate(0, 0, -6)
+	to := today
+	if s := c.Query("from"); s != "" {
+		t, err := time.ParseInLocation("2006-01-02", s, now.Location())
+		if err != nil {
+			renderError(c, errBadRequest("from must be YYYY-MM-DD"))
+			return
+		}
+		from = t
+	}
+	if s := c.Query("to"); s != "" {
+		t, err := time.ParseInLocation("2006-01-02", s, now.Location())
+		if err != nil {
+			renderError(c, errBadRequest("to must be YYYY-MM-DD"))
+			return
+		}
+		to = t
+	}
+	if to.Before(from) {
+		renderError(c, errBadRequest("to must not be before from"))
+		return
+	}
+
+	summaries, err := h.repo.AggregateUsage(c.Request.Context(), from, to.AddDate(0, 0, 1))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "AggregateUsage error", "error", err)
+		renderError(c, errInternal("failed to get usage"))
+		return
+	}
+
+	var totalCost float64
+	var totalTokens, totalCalls int64
+	for _, s := range summaries {
+		totalCost += s.Cost
+		totalTokens += s.TotalTokens
+		totalCalls += s.Calls
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": summaries,
+		"total": gin.H{
+			"calls":  totalCalls,
+			"tokens": totalTokens,
+			"cost":   totalCost,
+		},
+		"from": from.Format("2006-01-02"),
+		"to":   to.Format("2006-01-02"),
+	})
+}
+
+// GetEnrichCacheStats 获取丰富化缓存统计
+func (h *Handler) GetEnrichCacheStats(c *gin.Context) {
+	stats, err := h.repo.GetEnrichmentCacheStats(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "GetEnrichmentCacheStats error", "error", err)
+		renderError(c, errInternal("failed to get enrichment cache stats"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
+
+// InvalidateEnrichCache 按 问题/模型/模板版本 失效丰富化缓存，不带参数时需显式传 all=true 清空全部
+func (h *Handler) InvalidateEnrichCache(c *gin.Context) {
+	filter := postgres.EnrichmentCacheFilter{
+		Question:        c.Query("question"),
+		Model:           c.Query("model"),
+		TemplateVersion: c.Query("template_version"),
+	}
+	if filter == (postgres.EnrichmentCacheFilter{}) && c.Query("all") != "true" {
+		renderError(c, errBadRequest("specify question, model or template_version, or all=true to clear the whole cache"))
+		return
+	}
+
+	deleted, err := h.repo.InvalidateEnrichmentCache(c.Request.Context(), filter)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "InvalidateEnrichmentCache error", "error", err)
+		renderError(c, errInternal("failed to invalidate enrichment cache"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted})
+}
+
+// GetEmbeddingCacheStats 获取向量缓存的命中统计
+func (h *Handler) GetEmbeddingCacheStats(c *gin.Context) {
+	stored, err := h.repo.CountCachedEmbeddings(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "CountCachedEmbeddings error", "error", err)
+		renderError(c, errInternal("failed to get embedding cache stats"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":           h.embedder.CacheStats(),
+		"stored_entries": stored,
+	})
+}
+
+// DuplicateReviewResponse 疑似重复审核记录响应结构
+type DuplicateReviewResponse struct {
+	ID         uint             `json:"id"`
+	Status     string           `json:"status"`
+	Similarity float64          `json:"similarity"`
+	CreatedAt  string           `json:"created_at"`
+	ResolvedAt *string          `json:"resolved_at,omitempty"`
+	Article    *ArticleResponse `json:"article"`   // 新插入的疑似重复文章，合并后为空
+	Candidate  *ArticleResponse `json:"candidate"` // 最近邻 (合并目标)
+}
+
+// ListDuplicateReviewsRequest 疑似重复审核列表请求参数
+type ListDuplicateReviewsRequest struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending merged dismissed all"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// ListDuplicateReviews 获取疑似重复审核列表，默认只返回待审核的记录
+func (h *Handler) ListDuplicateReviews(c *gin.Context) {
+	var req ListDuplicateReviewsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+
+	if req.Status == "" {
+		req.Status = postgres.DuplicateReviewPending
+	}
+	if req.Status == "all" {
+		req.Status = ""
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+
+	items, total, err := h.repo.ListDuplicateReviews(c.Request.Context(), req.Status, req.PageSize, offset)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListDuplicateReviews error", "error", err)
+		renderError(c, errInternal("failed to list duplicate reviews"))
+		return
+	}
+
+	responses := make([]DuplicateReviewResponse, len(items))
+	for i, item := range items {
+		responses[i] = DuplicateReviewResponse{
+			ID:         item.ID,
+			Status:     item.Status,
+			Similarity: item.Similarity,
+			CreatedAt:  item.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if item.ResolvedAt != nil {
+			resolvedAt := item.ResolvedAt.Format("2006-01-02 15:04:05")
+			responses[i].ResolvedAt = &resolvedAt
+		}
+		if item.Article != nil {
+			article := toArticleResponse(*item.Article)
+			responses[i].Article = &article
+		}
+		if item.Candidate != nil {
+			candidate := toArticleResponse(*item.Candidate)
+			responses[i].Candidate = &candidate
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       responses,
+		"pagination": newPagination(req.Page, req.PageSize, total),
+	})
+}
+
+// ConfirmDuplicateReview 确认疑似重复，将新文章合并进最近邻的 Ext
+func (h *Handler) ConfirmDuplicateReview(c *gin.Context) {
+	h.resolveDuplicateReview(c, h.repo.ConfirmDuplicateReview)
+}
+
+// DismissDuplicateReview 驳回疑似重复标记
+func (h *Handler) DismissDuplicateReview(c *gin.Context) {
+	h.resolveDuplicateReview(c, h.repo.DismissDuplicateReview)
+}
+
+func (h *Handler) resolveDuplicateReview(c *gin.Context, resolve func(ctx context.Context, reviewID uint) (*postgres.DuplicateReview, error)) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid review id"))
+		return
+	}
+
+	review, err := resolve(c.Request.Context(), uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, postgres.ErrReviewNotFound), errors.Is(err, postgres.ErrReviewResolved):
+			renderError(c, err)
+		default:
+			slog.ErrorContext(c.Request.Context(), "resolve duplicate review error", "error", err, "id", id)
+			renderError(c, errInternal("failed to resolve review"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"id":           review.ID,
+			"status":       review.Status,
+			"article_id":   review.ArticleID,
+			"candidate_id": review.CandidateID,
+		},
+	})
+}
+
+// MergeArticlesRequest 手动合并请求参数
+type MergeArticlesRequest struct {
+	SourceIDs []uint `json:"source_ids" binding:"required,min=1,max=50"`
+}
+
+// MergeArticles 将一篇或多篇文章手动合并进目标文章
+func (h *Handler) MergeArticles(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid article id"))
+		return
+	}
+
+	var req MergeArticlesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+
+	target, err := h.repo.MergeArticles(c.Request.Context(), uint(id), req.SourceIDs)
+	if err != nil {
+		h.renderMergeError(c, "MergeArticles", err, id)
+		return
+	}
+
+	response := toArticleResponse(*target)
+	response.Duplicates, _ = postgres.ParseArticleExt(target.Ext)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       response,
+		"merged_ids": req.SourceIDs,
+	})
+}
+
+// UnmergeArticleRequest 拆分请求参数
+type UnmergeArticleRequest struct {
+	Index *int `json:"index" binding:"required,min=0"` // 要拆分的重复项在 duplicates 中的下标
+}
+
+// UnmergeArticle 将目标文章中的一个重复项拆分为独立的文章，并重新生成向量
+func (h *Handler) UnmergeArticle(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid article id"))
+		return
+	}
+
+	var req UnmergeArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+
+	article, err := h.repo.GetArticleByID(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, postgres.ErrArticleNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "GetArticleByID error", "error", err, "id", id)
+		renderError(c, errInternal("failed to get article"))
+		return
+	}
+	entries, err := postgres.ParseArticleExt(article.Ext)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ParseArticleExt error", "error", err, "id", id)
+		renderError(c, errInternal("failed to read article duplicates"))
+		return
+	}
+	if *req.Index >= len(entries) {
+		renderError(c, errBadRequest("index out of range"))
+		return
+	}
+
+	// 拆分出的文章作为文档重新向量化
+	entry := entries[*req.Index]
+	set := enrich.InterviewQuestionSet{Questions: []enrich.InterviewQuestion{entry.InterviewQuestion}}
+	vector, err := h.embedder.Embed(usage.WithTask(c.Request.Context(), "", "unmerge"), set.GetEmbeddableTexts()[0])
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Embedding error", "error", err)
+		renderError(c, errInternal("failed to generate embedding"))
+		return
+	}
+
+	newArticle, err := h.repo.UnmergeArticle(c.Request.Context(), uint(id), *req.Index, entry.OriginalQuestion, vector)
+	if err != nil {
+		h.renderMergeError(c, "UnmergeArticle", err, id)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":      toArticleResponse(*newArticle),
+		"source_id": uint(id),
+	})
+}
+
+// renderMergeError 返回合并/拆分的错误，文章不存在和不合法的合并直接返回，其他错误记录日志后返回 500
+func (h *Handler) renderMergeError(c *gin.Context, op string, err error, id uint64) {
+	switch {
+	case errors.Is(err, postgres.ErrArticleNotFound), errors.Is(err, postgres.ErrInvalidMerge):
+		renderError(c, err)
+	default:
+		slog.ErrorContext(c.Request.Context(), op+" error", "error", err, "id", id)
+		renderError(c, errInternal("failed to update articles"))
+	}
+}
+
+// ListOccurrencesRequest 出现记录列表请求参数
+type ListOccurrencesRequest struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// OccurrenceResponse 出现记录响应结构
+type OccurrenceResponse struct {
+	ID         uint           `json:"id"`
+	Kind       string         `json:"kind"` // inserted/merged
+	Question   string         `json:"question"`
+	RawLine    *string        `json:"raw_line,omitempty"`
+	TaskID     *string        `json:"task_id,omitempty"`
+	Source     string         `json:"source"`
+	Metadata   datatypes.JSON `json:"metadata,omitempty"`
+	Similarity *float64       `json:"similarity,omitempty"`
+	SeenAt     string         `json:"seen_at"`
+}
+
+// ListArticleOccurrences 返回文章的 "被问到" 历史：每次提交的任务、来源和元信息
+func (h *Handler) ListArticleOccurrences(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid article id"))
+		return
+	}
+
+	var req ListOccurrencesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	resolvedID, err := h.repo.ResolveArticleID(c.Request.Context(), uint(id))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ResolveArticleID error", "error", err, "id", id)
+		renderError(c, errInternal("failed to list occurrences"))
+		return
+	}
+
+	summary, err := h.repo.GetOccurrenceSummary(c.Request.Context(), resolvedID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "GetOccurrenceSummary error", "error", err, "id", resolvedID)
+		renderError(c, errInternal("failed to list occurrences"))
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	occurrences, total, err := h.repo.ListArticleOccurrences(c.Request.Context(), resolvedID, req.PageSize, offset)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListArticleOccurrences error", "error", err, "id", resolvedID)
+		renderError(c, errInternal("failed to list occurrences"))
+		return
+	}
+
+	responses := make([]OccurrenceResponse, len(occurrences))
+	for i, o := range occurrences {
+		responses[i] = OccurrenceResponse{
+			ID:         o.ID,
+			Kind:       o.Kind,
+			Question:   o.Question,
+			RawLine:    o.RawLine,
+			TaskID:     o.TaskID,
+			Source:     o.Source,
+			Metadata:   o.Metadata,
+			Similarity: o.Similarity,
+			SeenAt:     o.SeenAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+
+	body := gin.H{
+		"data":       responses,
+		"summary":    summary,
+		"pagination": newPagination(req.Page, req.PageSize, total),
+	}
+	if resolvedID != uint(id) {
+		body["redirected_from"] = uint(id)
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// HotQuestionsRequest 热门问题请求参数
+type HotQuestionsRequest struct {
+	Tags     []string `form:"tags[]"`
+	TagLimit int      `form:"tag_limit" binding:"omitempty,min=1,max=50"` // 未指定 tags 时返回的 tag 数量，默认 10
+	Limit    int      `form:"limit" binding:"omitempty,min=1,max=50"`     // 每个 tag 返回的问题数量，默认 5
+
+	OccurrenceQuery
+}
+
+// HotArticleResponse 热门问题响应结构
+type HotArticleResponse struct {
+	ArticleResponse
+	LastSeen string `json:"last_seen"`
+}
+
+// HotTagResponse 单个 tag 的热门问题
+type HotTagResponse struct {
+	Tag             string               `json:"tag"`
+	OccurrenceCount int64                `json:"occurrence_count"`
+	Articles        []HotArticleResponse `json:"articles"`
+}
+
+// HotQuestions 按 tag 返回时间窗口内出现次数最多的问题
+func (h *Handler) HotQuestions(c *gin.Context) {
+	var req HotQuestionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+
+	if req.Days == 0 {
+		req.Days = 30
+	}
+	if req.TagLimit == 0 {
+		req.TagLimit = 10
+	}
+	if req.Limit == 0 {
+		req.Limit = 5
+	}
+
+	tags, err := h.repo.CanonicalizeTags(c.Request.Context(), req.Tags)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "CanonicalizeTags error", "error", err)
+		renderError(c, errInternal("failed to get hot questions"))
+		return
+	}
+
+	hotTags, err := h.repo.GetHotQuestions(c.Request.Context(), postgres.HotQuestionsOptions{
+		Occurrence: req.filter(),
+		Tags:       tags,
+		TagLimit:   req.TagLimit,
+		PerTag:     req.Limit,
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "GetHotQuestions error", "error", err)
+		renderError(c, errInternal("failed to get hot questions"))
+		return
+	}
+
+	responses := make([]HotTagResponse, len(hotTags))
+	for i, hotTag := range hotTags {
+		articles := make([]HotArticleResponse, len(hotTag.Articles))
+		for j, a := range hotTag.Articles {
+			count := a.OccurrenceCount
+			articles[j] = HotArticleResponse{
+				ArticleResponse: toArticleResponse(a.Article),
+				LastSeen:        a.LastSeen.Format("2006-01-02 15:04:05"),
+			}
+			articles[j].OccurrenceCount = &count
+		}
+		responses[i] = HotTagResponse{
+			Tag:             hotTag.Tag,
+			OccurrenceCount: hotTag.OccurrenceCount,
+			Articles:        articles,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": responses,
+		"days": req.Days,
+	})
+}
+
+// AskRequest 问答请求参数
+type AskRequest struct {
+	Question      string  `json:"question" binding:"required"`
+	Limit         int     `json:"limit" binding:"omitempty,min=1,max=20"`        // 检索的文章数，默认取配置值
+	MinSimilarity float64 `json:"min_similarity" binding:"omitempty,gt=0,max=1"` // 作为回答依据的最低相似度，默认取配置值
+	Stream        bool    `json:"stream"`                                        // 以 SSE 流式返回，也可通过 Accept: text/event-stream 开启
+	Enqueue       bool    `json:"enqueue"`                                       // 没有足够相似的文章时直接把问题提交为新任务
+	Source        string  `json:"source"`                                        // 提交任务时的来源，默认 ask
+}
+
+// askTaskSource 是问答接口提交任务的默认来源
+const askTaskSource = "ask"
+
+// Ask 检索相关文章，由 LLM 基于这些文章回答问题并标注引用
+func (h *Handler) Ask(c *gin.Context) {
+	var req AskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if h.answerer == nil {
+		renderError(c, errNotConfigured("ask is not configured"))
+		return
+	}
+	// enqueue 会创建任务，需要 write 权限和工作区 editor 角色
+	if req.Enqueue && !allows(c, auth.ScopeWrite) {
+		renderError(c, newAPIError(http.StatusForbidden, CodeInsufficientScope, "insufficient scope: enqueue requires write"))
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = h.askTopK
+	}
+	if req.MinSimilarity == 0 {
+		req.MinSimilarity = h.askMinSimilarity
+	}
+	if req.Source == "" {
+		req.Source = askTaskSource
+	}
+	stream := req.Stream || strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+
+	ctx := usage.WithTask(c.Request.Context(), "", "ask")
+	vector, err := h.embedder.EmbedQuery(ctx, req.Question)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Embedding error", "error", err)
+		renderError(c, errInternal("failed to generate embedding"))
+		return
+	}
+
+	articles, similarities, err := h.repo.VectorSearchArticles(ctx, vector, req.Limit)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "VectorSearchArticles error", "error", err)
+		renderError(c, errInternal("failed to search articles"))
+		return
+	}
+
+	// 只有达到相似度阈值的文章才作为回答依据
+	sources := make([]ask.Source, 0, len(articles))
+	responses := make([]ArticleResponse, 0, len(articles))
+	closest := make([]ArticleResponse, 0, len(articles))
+	for i, article := range articles {
+		// VectorSearchArticles 返回 1 - 内积距离，即 1 + 余弦相似度；问答的阈值和返回值使用余弦相似度
+		similarity := similarities[i] - 1
+		response := toArticleResponse(article)
+		response.Similarity = &similarity
+		if similarity < req.MinSimilarity {
+			closest = append(closest, response)
+			continue
+		}
+		responses = append(responses, response)
+		sources = append(sources, ask.Source{
+			ID:         article.ID,
+			Question:   askSourceQuestion(article),
+			Answer:     askSourceAnswer(article),
+			Tags:       article.Tags,
+			Similarity: similarity,
+		})
+	}
+
+	if len(sources) == 0 {
+		h.askFallback(c, req, closest, stream)
+		return
+	}
+
+	if !stream {
+		answer, err := h.answerer.Answer(ctx, req.Question, sources)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Ask error", "error", err)
+			renderError(c, errInternal("failed to answer question"))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"answered":  true,
+			"question":  req.Question,
+			"answer":    answer.Text,
+			"citations": citedArticles(answer.Citations, responses),
+			"sources":   responses,
+		})
+		return
+	}
+
+	// SSE: sources -> delta... -> done，失败时发送 error
+	setSSEHeaders(c)
+	c.SSEvent("sources", responses)
+	c.Writer.Flush()
+
+	answer, err := h.answerer.Stream(ctx, req.Question, sources, func(delta string) error {
+		c.SSEvent("delta", gin.H{"text": delta})
+		c.Writer.Flush()
+		// 客户端断开后停止生成
+		return c.Request.Context().Err()
+	})
+	if err != nil {
+		if c.Request.Context().Err() == nil {
+			slog.ErrorContext(c.Request.Context(), "Ask stream error", "error", err)
+			c.SSEvent("error", errorBody(c, errInternal("failed to answer question")))
+			c.Writer.Flush()
+		}
+		return
+	}
+	c.SSEvent("done", gin.H{
+		"answered":  true,
+		"answer":    answer.Text,
+		"citations": citedArticles(answer.Citations, responses),
+	})
+	c.Writer.Flush()
+}
+
+// askFallback 在没有足够相似的文章时返回最接近的文章，并建议 (或直接) 把问题提交为新任务
+func (h *Handler) askFallback(c *gin.Context, req AskRequest, closest []ArticleResponse, stream bool) {
+	taskBody := gin.H{"raw_questions": req.Question, "source": req.Source}
+	body := gin.H{
+		"answered":       false,
+		"question":       req.Question,
+		"message":        "no sufficiently similar article found",
+		"min_similarity": req.MinSimilarity,
+		"closest":        closest,
+		"suggestion": gin.H{
+			"method": http.MethodPost,
+			"path":   "/api/v1/tasks",
+			"body":   taskBody,
+		},
+	}
+
+	if req.Enqueue {
+		task := processor.Task{
+			RawQuestions: req.Question,
+			Source:       req.Source,
+		}
+		if p := principalFrom(c); p != nil {
+			task.CreatedBy = p.UserName
+		}
+		task.FillMetadata()
+		questions := len(enrich.SplitQuestionLines(req.Question))
+		if !h.consumeQuota(c, questions) {
+			return
+		}
+		if err := h.taskProcessor.NewTask(c.Request.Context(), "enrich_questions", task); err != nil {
+			h.releaseQuota(c, questions)
+			slog.ErrorContext(c.Request.Context(), "CreateTask error", "error", err)
+			renderError(c, errInternal("failed to create task"))
+			return
+		}
+		markMutating(c)
+		body["task_id"] = task.TaskID
+		delete(body, "suggestion")
+	}
+
+	if stream {
+		setSSEHeaders(c)
+		c.SSEvent("fallback", body)
+		c.Writer.Flush()
+		return
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// setSSEHeaders 设置 Server-Sent Events 响应头
+func setSSEHeaders(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭 nginx 缓冲
+	c.Status(http.StatusOK)
+}
+
+// askSourceQuestion 优先使用专业化的问题描述
+func askSourceQuestion(article postgres.Article) string {
+	if article.DetailedQuestion != nil && strings.TrimSpace(*article.DetailedQuestion) != "" {
+		return *article.DetailedQuestion
+	}
+	return article.OriginalQuestion
+}
+
+func askSourceAnswer(article postgres.Article) string {
+	if article.ConciseAnswer == nil {
+		return ""
+	}
+	return *article.ConciseAnswer
+}
+
+// citedArticles 按引用顺序返回被引用的文章
+func citedArticles(ids []uint, responses []ArticleResponse) []ArticleResponse {
+	byID := make(map[uint]ArticleResponse, len(responses))
+	for _, r := range responses {
+		byID[r.ID] = r
+	}
+	cited := make([]ArticleResponse, 0, len(ids))
+	for _, id := range ids {
+		if r, ok := byID[id]; ok {
+			cited = append(cited, r)
+		}
+	}
+	return cited
+}
+
+// GradeAnswerRequest 作答评分请求参数
+type GradeAnswerRequest struct {
+	Answer string `json:"answer" binding:"required,max=10000"`
+	User   string `json:"user" binding:"max=100"` // 作答人，用于之后按人查看练习记录
+}
+
+// AnswerAttemptResponse 作答记录响应结构
+type AnswerAttemptResponse struct {
+	ID              uint           `json:"id"`
+	ArticleID       uint           `json:"article_id"`
+	User            string         `json:"user,omitempty"`
+	Answer          string         `json:"answer"`
+	Score           int            `json:"score"`
+	CoveredConcepts pq.StringArray `json:"covered_concepts"`
+	MissedConcepts  pq.StringArray `json:"missed_concepts"`
+	FollowUps       pq.StringArray `json:"follow_ups"`
+	Feedback        *string        `json:"feedback,omitempty"`
+	Model           string         `json:"model"`
+	CreatedAt       string         `json:"created_at"`
+}
+
+// toAnswerAttemptResponse 将作答记录转换为响应结构
+func toAnswerAttemptResponse(a postgres.AnswerAttempt) AnswerAttemptResponse {
+	return AnswerAttemptResponse{
+		ID:              a.ID,
+		ArticleID:       a.ArticleID,
+		User:            a.UserName,
+		Answer:          a.Answer,
+		Score:           a.Score,
+		CoveredConcepts: a.CoveredConcepts,
+		MissedConcepts:  a.MissedConcepts,
+		FollowUps:       a.FollowUps,
+		Feedback:        a.Feedback,
+		Model:           a.Model,
+		CreatedAt:       a.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// GradeAnswer 对照文章的参考答案为用户的作答评分，并保存作答记录
+func (h *Handler) GradeAnswer(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid article id"))
+		return
+	}
+
+	var req GradeAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if strings.TrimSpace(req.Answer) == "" {
+		renderError(c, errBadRequest("answer is empty"))
+		return
+	}
+	if h.grader == nil {
+		renderError(c, errNotConfigured("grading is not configured"))
+		return
+	}
+	user, ok := resolveUser(c, req.User)
+	if !ok {
+		return
+	}
+
+	resolvedID, err := h.repo.ResolveArticleID(c.Request.Context(), uint(id))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ResolveArticleID error", "error", err, "id", id)
+		renderError(c, errInternal("failed to grade answer"))
+		return
+	}
+	article, err := h.repo.GetArticleByID(c.Request.Context(), resolvedID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrArticleNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "GetArticleByID error", "error", err, "id", resolvedID)
+		renderError(c, errInternal("failed to get article"))
+		return
+	}
+
+	ctx := usage.WithTask(c.Request.Context(), "", "grade")
+	result, err := h.grader.Grade(ctx, grade.Reference{
+		Question:        askSourceQuestion(*article),
+		ReferenceAnswer: askSourceAnswer(*article),
+		Tags:            article.Tags,
+	}, req.Answer)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Grade error", "error", err, "id", article.ID)
+		renderError(c, errInternal("failed to grade answer"))
+		return
+	}
+
+	attempt := &postgres.AnswerAttempt{
+		ArticleID:       article.ID,
+		UserName:        user,
+		Answer:          req.Answer,
+		Score:           result.Score,
+		CoveredConcepts: result.CoveredConcepts,
+		MissedConcepts:  result.MissedConcepts,
+		FollowUps:       result.FollowUps,
+		Model:           h.grader.Model(),
+	}
+	if result.Feedback != "" {
+		attempt.Feedback = &result.Feedback
+	}
+	if err := h.repo.CreateAnswerAttempt(c.Request.Context(), attempt); err != nil {
+		slog.ErrorContext(c.Request.Context(), "CreateAnswerAttempt error", "error", err, "id", article.ID)
+		renderError(c, errInternal("failed to save answer attempt"))
+		return
+	}
+
+	body := gin.H{
+		"data":    toAnswerAttemptResponse(*attempt),
+		"article": toArticleResponse(*article),
+	}
+	if resolvedID != uint(id) {
+		body["redirected_from"] = uint(id)
+	}
+	c.JSON(http.StatusCreated, body)
+}
+
+// ListAnswerAttemptsRequest 作答记录列表请求参数
+type ListAnswerAttemptsRequest struct {
+	Page      int    `form:"page" binding:"omitempty,min=1"`
+	PageSize  int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	ArticleID uint   `form:"article_id"`
+	User      string `form:"user"`
+	MaxScore  *int   `form:"max_score" binding:"omitempty,min=0,max=100"` // 只看得分不高于该值的作答
+}
+
+// ListAnswerAttempts 按时间倒序列出作答记录，可按文章、作答人和分数筛选
+func (h *Handler) ListAnswerAttempts(c *gin.Context) {
+	var req ListAnswerAttemptsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	user, ok := resolveUserFilter(c, req.User)
+	if !ok {
+		return
+	}
+
+	filter := postgres.AttemptFilter{UserName: user, MaxScore: req.MaxScore}
+	if req.ArticleID != 0 {
+		resolvedID, err := h.repo.ResolveArticleID(c.Request.Context(), req.ArticleID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "ResolveArticleID error", "error", err, "id", req.ArticleID)
+			renderError(c, errInternal("failed to list answer attempts"))
+			return
+		}
+		filter.ArticleID = resolvedID
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	attempts, total, err := h.repo.ListAnswerAttempts(c.Request.Context(), filter, req.PageSize, offset)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListAnswerAttempts error", "error", err)
+		renderError(c, errInternal("failed to list answer attempts"))
+		return
+	}
+
+	responses := make([]AnswerAttemptResponse, len(attempts))
+	for i, a := range attempts {
+		responses[i] = toAnswerAttemptResponse(a)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       responses,
+		"pagination": newPagination(req.Page, req.PageSize, total),
+	})
+}
+
+// GetAnswerAttempt 获取单条作答记录
+func (h *Handler) GetAnswerAttempt(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid attempt id"))
+		return
+	}
+
+	attempt, err := h.repo.GetAnswerAttempt(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, postgres.ErrAttemptNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "GetAnswerAttempt error", "error", err, "id", id)
+		renderError(c, errInternal("failed to get answer attempt"))
+		return
+	}
+	if !ownsResource(c, attempt.UserName) {
+		renderError(c, errForbidden("answer attempt belongs to another user"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toAnswerAttemptResponse(*attempt)})
+}
@@ -0,0 +1,441 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"paguu/internal/grade"
+	"paguu/internal/interview"
+	"paguu/internal/storage/postgres"
+	"paguu/internal/usage"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// CreateInterviewRequest 模拟面试组卷请求参数
+type CreateInterviewRequest struct {
+	User   string   `json:"user"`
+	Tags   []string `json:"tags" binding:"required,min=1,max=10"`
+	Level  string   `json:"level" binding:"required,oneof=junior mid senior"`
+	Length int      `json:"length" binding:"omitempty,min=1,max=50"` // 默认 10
+	Days   int      `json:"days" binding:"omitempty,min=1,max=3650"` // 热度统计窗口，默认全部时间
+}
+
+// InterviewItemResponse 面试题目响应结构
+type InterviewItemResponse struct {
+	Position   int              `json:"position"`
+	Tag        string           `json:"tag"`
+	Depth      int              `json:"depth"`
+	Article    *ArticleResponse `json:"article,omitempty"` // 文章已被删除时为空
+	Answer     *string          `json:"answer,omitempty"`
+	AttemptID  *uint            `json:"attempt_id,omitempty"`
+	AnsweredAt *string          `json:"answered_at,omitempty"`
+}
+
+// InterviewResponse 模拟面试响应结构
+type InterviewResponse struct {
+	ID          uint                    `json:"id"`
+	User        string                  `json:"user,omitempty"`
+	Tags        pq.StringArray          `json:"tags"`
+	Level       string                  `json:"level"`
+	Length      int                     `json:"length"`
+	Answered    int64                   `json:"answered"`
+	Status      string                  `json:"status"`
+	ReplayOf    *uint                   `json:"replay_of,omitempty"`
+	CreatedAt   string                  `json:"created_at"`
+	CompletedAt *string                 `json:"completed_at,omitempty"`
+	Items       []InterviewItemResponse `json:"items,omitempty"`
+}
+
+// toInterviewResponse 将面试转换为响应结构，不包含题目
+func toInterviewResponse(session postgres.InterviewSession, answered int64) InterviewResponse {
+	response := InterviewResponse{
+		ID:        session.ID,
+		User:      session.UserName,
+		Tags:      session.Tags,
+		Level:     session.Level,
+		Length:    session.Length,
+		Answered:  answered,
+		Status:    session.Status,
+		ReplayOf:  session.ReplayOf,
+		CreatedAt: session.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if session.CompletedAt != nil {
+		completedAt := session.CompletedAt.Format("2006-01-02 15:04:05")
+		response.CompletedAt = &completedAt
+	}
+	return response
+}
+
+// toInterviewItemResponse 将面试题目转换为响应结构
+// 未作答的题目默认隐藏参考答案，reveal 为 true 时全部展示
+func toInterviewItemResponse(item postgres.InterviewItemDetail, reveal bool) InterviewItemResponse {
+	response := InterviewItemResponse{
+		Position:  item.Position,
+		Tag:       item.Tag,
+		Depth:     item.Depth,
+		Answer:    item.Answer,
+		AttemptID: item.AttemptID,
+	}
+	if item.Article != nil {
+		article := toArticleResponse(*item.Article)
+		if item.AnsweredAt == nil && !reveal {
+			article.ConciseAnswer = nil
+		}
+		response.Article = &article
+	}
+	if item.AnsweredAt != nil {
+		answeredAt := item.AnsweredAt.Format("2006-01-02 15:04:05")
+		response.AnsweredAt = &answeredAt
+	}
+	return response
+}
+
+// toInterviewDetailResponse 将完整面试转换为响应结构
+func toInterviewDetailResponse(detail postgres.InterviewSessionDetail, reveal bool) InterviewResponse {
+	var answered int64
+	items := make([]InterviewItemResponse, len(detail.Items))
+	for i, item := range detail.Items {
+		if item.AnsweredAt != nil {
+			answered++
+		}
+		items[i] = toInterviewItemResponse(item, reveal)
+	}
+	response := toInterviewResponse(detail.Session, answered)
+	response.Items = items
+	return response
+}
+
+// CreateInterview 从目标标签的高频题中组卷，保存为一场新的模拟面试
+func (h *Handler) CreateInterview(c *gin.Context) {
+	var req CreateInterviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if req.Length == 0 {
+		req.Length = 10
+	}
+	user, ok := resolveUser(c, req.User)
+	if !ok {
+		return
+	}
+
+	canonical, err := h.repo.CanonicalizeTags(c.Request.Context(), req.Tags)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "CanonicalizeTags error", "error", err)
+		renderError(c, errInternal("failed to create interview"))
+		return
+	}
+	// 别名映射后可能出现重复的标签
+	tags := make([]string, 0, len(canonical))
+	seen := make(map[string]bool)
+	for _, tag := range canonical {
+		if tag = strings.TrimSpace(tag); tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		renderError(c, errBadRequest("tags are empty"))
+		return
+	}
+
+	ctx := usage.WithTask(c.Request.Context(), "", "interview")
+	planned, err := h.interviewPlanner.Plan(ctx, interview.Options{
+		Tags:   tags,
+		Level:  req.Level,
+		Length: req.Length,
+		Days:   req.Days,
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Plan interview error", "error", err, "tags", tags)
+		renderError(c, errInternal("failed to create interview"))
+		return
+	}
+	if len(planned) == 0 {
+		renderError(c, errUnprocessable("no questions found for the given tags"))
+		return
+	}
+
+	session := &postgres.InterviewSession{
+		UserName: user,
+		Tags:     tags,
+		Level:    req.Level,
+		Status:   postgres.InterviewStatusInProgress,
+	}
+	items := make([]postgres.InterviewItem, len(planned))
+	for i, p := range planned {
+		items[i] = postgres.InterviewItem{
+			Position:  i + 1,
+			ArticleID: p.Article.ID,
+			Tag:       p.Tag,
+			Depth:     p.Depth,
+		}
+	}
+	if err := h.repo.CreateInterviewSession(c.Request.Context(), session, items); err != nil {
+		slog.ErrorContext(c.Request.Context(), "CreateInterviewSession error", "error", err)
+		renderError(c, errInternal("failed to save interview"))
+		return
+	}
+
+	detail, err := h.repo.GetInterviewSession(c.Request.Context(), session.ID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "GetInterviewSession error", "error", err, "id", session.ID)
+		renderError(c, errInternal("failed to get interview"))
+		return
+	}
+
+	body := gin.H{"data": toInterviewDetailResponse(*detail, false)}
+	if len(planned) < req.Length {
+		body["message"] = fmt.Sprintf("only %d questions available for the given tags", len(planned))
+	}
+	c.JSON(http.StatusCreated, body)
+}
+
+// ListInterviewsRequest 模拟面试列表请求参数
+type ListInterviewsRequest struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	User     string `form:"user"`
+}
+
+// ListInterviews 按时间倒序列出模拟面试及作答进度，不包含题目
+func (h *Handler) ListInterviews(c *gin.Context) {
+	var req ListInterviewsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	user, ok := resolveUserFilter(c, req.User)
+	if !ok {
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	sessions, total, err := h.repo.ListInterviewSessions(c.Request.Context(), user, req.PageSize, offset)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListInterviewSessions error", "error", err)
+		renderError(c, errInternal("failed to list interviews"))
+		return
+	}
+
+	responses := make([]InterviewResponse, len(sessions))
+	for i, s := range sessions {
+		responses[i] = toInterviewResponse(s.InterviewSession, s.AnsweredCount)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       responses,
+		"pagination": newPagination(req.Page, req.PageSize, total),
+	})
+}
+
+// GetInterview 获取模拟面试的全部题目和作答，未作答题目的参考答案默认隐藏
+func (h *Handler) GetInterview(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid interview id"))
+		return
+	}
+	reveal := c.Query("reveal") == "true"
+
+	detail, err := h.repo.GetInterviewSession(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, postgres.ErrInterviewNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "GetInterviewSession error", "error", err, "id", id)
+		renderError(c, errInternal("failed to get interview"))
+		return
+	}
+	if !ownsResource(c, detail.Session.UserName) {
+		renderError(c, errForbidden("interview belongs to another user"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toInterviewDetailResponse(*detail, reveal)})
+}
+
+// SubmitInterviewAnswerRequest 模拟面试作答请求参数
+type SubmitInterviewAnswerRequest struct {
+	Position int    `json:"position" binding:"required,min=1"`
+	Answer   string `json:"answer" binding:"required"`
+	Grade    bool   `json:"grade"` // 是否调用 LLM 评分并记录到作答记录
+}
+
+// SubmitInterviewAnswer 记录模拟面试中某道题的作答，可选评分；所有题目作答后面试完成
+func (h *Handler) SubmitInterviewAnswer(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid interview id"))
+		return
+	}
+
+	var req SubmitInterviewAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if strings.TrimSpace(req.Answer) == "" {
+		renderError(c, errBadRequest("answer is empty"))
+		return
+	}
+	if req.Grade && h.grader == nil {
+		renderError(c, errNotConfigured("grading is not configured"))
+		return
+	}
+
+	detail, err := h.repo.GetInterviewSession(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, postgres.ErrInterviewNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "GetInterviewSession error", "error", err, "id", id)
+		renderError(c, errInternal("failed to submit answer"))
+		return
+	}
+	if !ownsResource(c, detail.Session.UserName) {
+		renderError(c, errForbidden("interview belongs to another user"))
+		return
+	}
+	var item *postgres.InterviewItemDetail
+	for i := range detail.Items {
+		if detail.Items[i].Position == req.Position {
+			item = &detail.Items[i]
+			break
+		}
+	}
+	if item == nil {
+		renderError(c, postgres.ErrInterviewItemNotFound)
+		return
+	}
+
+	var attempt *postgres.AnswerAttempt
+	if req.Grade {
+		if item.Article == nil {
+			renderError(c, postgres.ErrArticleNotFound)
+			return
+		}
+		ctx := usage.WithTask(c.Request.Context(), "", "grade")
+		result, err := h.grader.Grade(ctx, grade.Reference{
+			Question:        askSourceQuestion(*item.Article),
+			ReferenceAnswer: askSourceAnswer(*item.Article),
+			Tags:            item.Article.Tags,
+		}, req.Answer)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Grade error", "error", err, "id", item.ArticleID)
+			renderError(c, errInternal("failed to grade answer"))
+			return
+		}
+
+		attempt = &postgres.AnswerAttempt{
+			ArticleID:       item.ArticleID,
+			UserName:        detail.Session.UserName,
+			Answer:          req.Answer,
+			Score:           result.Score,
+			CoveredConcepts: result.CoveredConcepts,
+			MissedConcepts:  result.MissedConcepts,
+			FollowUps:       result.FollowUps,
+			Model:           h.grader.Model(),
+		}
+		if result.Feedback != "" {
+			attempt.Feedback = &result.Feedback
+		}
+		if err := h.repo.CreateAnswerAttempt(c.Request.Context(), attempt); err != nil {
+			slog.ErrorContext(c.Request.Context(), "CreateAnswerAttempt error", "error", err, "id", item.ArticleID)
+			renderError(c, errInternal("failed to save answer attempt"))
+			return
+		}
+	}
+
+	var attemptID *uint
+	if attempt != nil {
+		attemptID = &attempt.ID
+	}
+	session, saved, err := h.repo.RecordInterviewAnswer(c.Request.Context(), uint(id), req.Position, req.Answer, attemptID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrInterviewNotFound) || errors.Is(err, postgres.ErrInterviewItemNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "RecordInterviewAnswer error", "error", err, "id", id, "position", req.Position)
+		renderError(c, errInternal("failed to submit answer"))
+		return
+	}
+
+	item.InterviewItem = *saved
+	body := gin.H{
+		"data":   toInterviewItemResponse(*item, true),
+		"status": session.Status,
+	}
+	if attempt != nil {
+		body["attempt"] = toAnswerAttemptResponse(*attempt)
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// ReplayInterviewRequest 重放模拟面试请求参数
+type ReplayInterviewRequest struct {
+	User string `json:"user"` // 未启用鉴权时默认沿用原面试的作答人
+}
+
+// ReplayInterview 以相同的题目和顺序开始一场新的模拟面试
+func (h *Handler) ReplayInterview(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid interview id"))
+		return
+	}
+
+	// 请求体可以省略
+	var req ReplayInterviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			renderError(c, bindError(err))
+			return
+		}
+	}
+
+	// 启用鉴权时新面试属于调用者本人
+	user, ok := resolveUser(c, req.User)
+	if !ok {
+		return
+	}
+
+	replay, err := h.repo.ReplayInterviewSession(c.Request.Context(), uint(id), user)
+	if err != nil {
+		if errors.Is(err, postgres.ErrInterviewNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "ReplayInterviewSession error", "error", err, "id", id)
+		renderError(c, errInternal("failed to replay interview"))
+		return
+	}
+
+	detail, err := h.repo.GetInterviewSession(c.Request.Context(), replay.ID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "GetInterviewSession error", "error", err, "id", replay.ID)
+		renderError(c, errInternal("failed to get interview"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": toInterviewDetailResponse(*detail, false)})
+}
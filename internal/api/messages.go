@@ -0,0 +1,160 @@
+package api
+
+// zhMessages 是错误消息的中文翻译，键为英文消息 (含 fmt 占位符)
+// 新增错误消息时在这里补充翻译，没有翻译的消息在中文请求中返回英文原文
+var zhMessages = map[string]string{
+	// 通用
+	"internal server error":               "服务器内部错误",
+	"invalid request":                     "请求参数不合法",
+	"invalid request parameters":          "请求参数格式错误",
+	"request body is required":            "缺少请求体",
+	"request body is not valid JSON":      "请求体不是合法的 JSON",
+	"field %s has the wrong type":         "字段 %s 的类型错误",
+	"rate limit exceeded":                 "请求过于频繁，请稍后重试",
+	"daily %s quota exceeded":             "已超出每日 %s 配额",
+	"route not found":                     "接口不存在",
+	"invalid cursor":                      "分页游标不合法",
+	"cursor cannot be combined with page": "cursor 不能与 page 同时使用",
+
+	// 字段校验
+	"%s is required":                                "%s 为必填项",
+	"%s is invalid":                                 "%s 不合法",
+	"%s must be at least %s characters":             "%s 至少需要 %s 个字符",
+	"%s must be at most %s characters":              "%s 最多 %s 个字符",
+	"%s must contain at least %s items":             "%s 至少需要 %s 项",
+	"%s must contain at most %s items":              "%s 最多 %s 项",
+	"%s must be at least %s":                        "%s 不能小于 %s",
+	"%s must be at most %s":                         "%s 不能大于 %s",
+	"%s must be greater than %s":                    "%s 必须大于 %s",
+	"%s must be one of: %s":                         "%s 必须是以下之一: %s",
+	"limit must be between 1 and 100":               "limit 必须在 1 到 100 之间",
+	"from must be YYYY-MM-DD":                       "from 必须是 YYYY-MM-DD 格式",
+	"to must be YYYY-MM-DD":                         "to 必须是 YYYY-MM-DD 格式",
+	"to must not be before from":                    "to 不能早于 from",
+	"index out of range":                            "题目序号超出范围",
+	"invalid article id":                            "文章 ID 不合法",
+	"invalid attempt id":                            "作答记录 ID 不合法",
+	"invalid cluster id":                            "主题簇 ID 不合法",
+	"invalid interview id":                          "面试 ID 不合法",
+	"invalid key id":                                "API key ID 不合法",
+	"invalid review id":                             "审核记录 ID 不合法",
+	"invalid user id":                               "用户 ID 不合法",
+	"invalid rating":                                "评分不合法",
+	"invalid scopes":                                "权限范围不合法",
+	"invalid enrich template":                       "丰富化模板不合法",
+	"answer is empty":                               "回答为空",
+	"name is empty":                                 "名称为空",
+	"tags are empty":                                "标签为空",
+	"user is required":                              "缺少用户",
+	"slug must be lowercase letters, digits or '-'": "标识只能包含小写字母、数字和 '-'",
+	"specify question, model or template_version, or all=true to clear the whole cache": "请指定 question、model 或 template_version，或使用 all=true 清空整个缓存",
+	"enrich template must be a file name in the templates directory":                    "丰富化模板必须是模板目录下的文件名",
+
+	// 鉴权和权限
+	"invalid or expired credentials":             "凭证无效或已过期",
+	"jwt sessions are not enabled":               "未启用 JWT 会话",
+	"tokens can only be issued with an api key":  "只能使用 API key 签发令牌",
+	"authentication is not enabled":              "未启用鉴权",
+	"insufficient scope: requires %s":            "权限不足：需要 %s 权限",
+	"insufficient scope: enqueue requires write": "权限不足：提交任务需要 write 权限",
+	"workspace role %s does not allow %s":        "工作区角色 %s 不允许 %s 操作",
+	"workspace owner role required":              "需要工作区 owner 角色",
+	"not a member of this workspace":             "不是该工作区的成员",
+	"cannot act on behalf of another user":       "不能代替其他用户操作",
+	"cannot change your own membership":          "不能修改自己的成员身份",
+	"cannot disable yourself":                    "不能停用自己",
+	"answer attempt belongs to another user":     "作答记录属于其他用户",
+	"interview belongs to another user":          "面试属于其他用户",
+
+	// 资源不存在和冲突 (含仓储层的领域错误)
+	"article not found":                         "文章不存在",
+	"cluster not found":                         "主题簇不存在",
+	"api key not found":                         "API key 不存在",
+	"user not found":                            "用户不存在",
+	"user already exists":                       "用户已存在",
+	"answer attempt not found":                  "作答记录不存在",
+	"interview session not found":               "面试不存在",
+	"interview item not found":                  "面试题目不存在",
+	"duplicate review not found":                "疑似重复审核记录不存在",
+	"duplicate review already resolved":         "疑似重复审核记录已处理",
+	"invalid merge request":                     "合并请求不合法",
+	"tag not found":                             "标签不存在",
+	"tag conflict":                              "标签冲突",
+	"invalid tag operation":                     "标签操作不合法",
+	"workspace not found":                       "工作区不存在",
+	"workspace already exists":                  "工作区已存在",
+	"workspace member not found":                "工作区成员不存在",
+	"no cluster run yet, run cmd/cluster first": "尚未运行聚类，请先运行 cmd/cluster",
+	"no questions found for the given tags":     "没有找到这些标签下的题目",
+
+	// 可选功能未配置
+	"ask is not configured":     "未配置问答",
+	"grading is not configured": "未配置评分",
+	"rerank is not configured":  "未配置重排序",
+
+	// 内部错误
+	"failed to answer question":             "回答问题失败",
+	"failed to authenticate":                "鉴权失败",
+	"failed to create api key":              "创建 API key 失败",
+	"failed to create interview":            "创建面试失败",
+	"failed to create task":                 "创建任务失败",
+	"failed to create user":                 "创建用户失败",
+	"failed to create workspace":            "创建工作区失败",
+	"failed to find similar articles":       "查找相似文章失败",
+	"failed to generate embedding":          "生成向量失败",
+	"failed to generate openapi document":   "生成 OpenAPI 文档失败",
+	"failed to get answer attempt":          "获取作答记录失败",
+	"failed to get article":                 "获取文章失败",
+	"failed to get cluster":                 "获取主题簇失败",
+	"failed to get embedding cache stats":   "获取向量缓存统计失败",
+	"failed to get enrichment cache stats":  "获取丰富化缓存统计失败",
+	"failed to get hot questions":           "获取热门问题失败",
+	"failed to get interview":               "获取面试失败",
+	"failed to get quota":                   "获取配额失败",
+	"failed to get review stats":            "获取复习统计失败",
+	"failed to get tag co-occurrence":       "获取标签共现失败",
+	"failed to get tag graph":               "获取标签图失败",
+	"failed to get tag stats":               "获取标签统计失败",
+	"failed to get tag taxonomy":            "获取标签分类失败",
+	"failed to get tags":                    "获取标签失败",
+	"failed to get usage":                   "获取用量失败",
+	"failed to get user":                    "获取用户失败",
+	"failed to get workspace":               "获取工作区失败",
+	"failed to grade answer":                "评分失败",
+	"failed to invalidate enrichment cache": "清除丰富化缓存失败",
+	"failed to issue token":                 "签发令牌失败",
+	"failed to list answer attempts":        "获取作答记录列表失败",
+	"failed to list api keys":               "获取 API key 列表失败",
+	"failed to list articles":               "获取文章列表失败",
+	"failed to list audit logs":             "获取审计日志失败",
+	"failed to list cluster articles":       "获取主题簇文章失败",
+	"failed to list clusters":               "获取主题簇列表失败",
+	"failed to list due cards":              "获取待复习卡片失败",
+	"failed to list duplicate reviews":      "获取疑似重复审核列表失败",
+	"failed to list interviews":             "获取面试列表失败",
+	"failed to list occurrences":            "获取出现记录失败",
+	"failed to list tag subscriptions":      "获取标签订阅失败",
+	"failed to list users":                  "获取用户列表失败",
+	"failed to list workspace members":      "获取工作区成员失败",
+	"failed to list workspaces":             "获取工作区列表失败",
+	"failed to normalize tags":              "规范化标签失败",
+	"failed to read article duplicates":     "读取文章重复信息失败",
+	"failed to record review":               "记录复习失败",
+	"failed to remove workspace member":     "移除工作区成员失败",
+	"failed to replay interview":            "回放面试失败",
+	"failed to resolve review":              "处理审核记录失败",
+	"failed to resolve workspace role":      "获取工作区角色失败",
+	"failed to resolve workspace":           "获取工作区失败",
+	"failed to revoke api key":              "吊销 API key 失败",
+	"failed to save answer attempt":         "保存作答记录失败",
+	"failed to save interview":              "保存面试失败",
+	"failed to search articles":             "搜索文章失败",
+	"failed to set workspace member":        "设置工作区成员失败",
+	"failed to submit answer":               "提交回答失败",
+	"failed to subscribe tags":              "订阅标签失败",
+	"failed to unsubscribe tags":            "取消订阅标签失败",
+	"failed to update articles":             "更新文章失败",
+	"failed to update tags":                 "更新标签失败",
+	"failed to update user":                 "更新用户失败",
+	"failed to update workspace":            "更新工作区失败",
+}
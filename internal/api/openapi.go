@@ -0,0 +1,531 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"paguu/internal/auth"
+	"paguu/internal/embedding"
+	"paguu/internal/openapi"
+	"paguu/internal/storage/postgres"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// operation 描述 SetupRouter 中的一个接口，OpenAPI 文档由这些描述和 handler 使用的请求/响应结构体反射生成
+type operation struct {
+	Method  string
+	Path    string // gin 路径
+	ID      string // operationId，同时是生成的客户端方法名
+	Tag     string
+	Summary string
+
+	Query any // 查询参数结构体 (form 标签)
+	Body  any // JSON 请求体结构体
+
+	// 成功响应：Data 为 data 字段的类型，Extra 为与 data 并列的其他字段 (匿名结构体)，Paged 表示带 pagination
+	// Response 为完整的响应体，设置时忽略 Data/Extra；三者都为 nil 时响应结构不固定
+	Data     any
+	Extra    any
+	Response any
+	Paged    bool
+	Status   int    // 成功时的状态码，默认 200
+	HTML     bool   // 响应为 HTML 页面
+	Admin    bool   // 需要 admin 权限
+	Global   bool   // 不属于工作区
+	Public   bool   // 不需要鉴权
+	Notes    string // 补充说明
+	Optional bool   // 请求体可以省略
+}
+
+// 以下结构体只用于文档，对应 handler 中手动读取的查询参数
+
+type similarArticlesQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"` // 默认 10
+}
+
+type usageQuery struct {
+	From string `form:"from"` // YYYY-MM-DD，默认 6 天前
+	To   string `form:"to"`   // YYYY-MM-DD，默认今天
+}
+
+type invalidateEnrichCacheQuery struct {
+	Question        string `form:"question"`
+	Model           string `form:"model"`
+	TemplateVersion string `form:"template_version"`
+	All             bool   `form:"all"` // 不带任何条件时必须为 true
+}
+
+type interviewQuery struct {
+	Reveal bool `form:"reveal"` // 是否返回未作答题目的参考答案
+}
+
+// 以下结构体只用于文档，对应 handler 中以 gin.H 构造的响应
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+type updatedArticlesResponse struct {
+	UpdatedArticles int64 `json:"updated_articles"`
+}
+
+type duplicateResolutionResponse struct {
+	ID          uint   `json:"id"`
+	Status      string `json:"status"`
+	ArticleID   uint   `json:"article_id"`
+	CandidateID uint   `json:"candidate_id"`
+}
+
+type clusterRunResponse struct {
+	ID           uint   `json:"id"`
+	K            int    `json:"k"`
+	ArticleCount int    `json:"article_count"`
+	NamingModel  string `json:"naming_model"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type usageTotalResponse struct {
+	Calls  int64   `json:"calls"`
+	Tokens int64   `json:"tokens"`
+	Cost   float64 `json:"cost"`
+}
+
+// operations 是 SetupRouter 注册的全部接口，新增路由时需要同时在这里登记 (UndocumentedRoutes 会检查)
+var operations = []operation{
+	// 文档
+	{Method: "GET", Path: "/api/v1/openapi.json", ID: "GetOpenAPISpec", Tag: "docs", Summary: "OpenAPI 文档", Global: true, Public: true},
+	{Method: "GET", Path: "/api/v1/docs", ID: "GetAPIDocs", Tag: "docs", Summary: "接口文档页面 (Swagger UI)", HTML: true, Global: true, Public: true},
+
+	// 当前调用者
+	{Method: "GET", Path: "/api/v1/me", ID: "GetMe", Tag: "auth", Summary: "当前调用者", Global: true,
+		Notes: "未启用鉴权时只返回 authenticated=false"},
+	{Method: "GET", Path: "/api/v1/me/quota", ID: "GetMyQuota", Tag: "auth", Summary: "当天的配额用量", Global: true, Data: QuotaResponse{},
+		Notes: "未配置配额或调用者为 admin 时 data 只包含 enabled=false"},
+	{Method: "POST", Path: "/api/v1/auth/token", ID: "IssueAuthToken", Tag: "auth", Summary: "用 API key 换取 JWT", Global: true, Data: AuthTokenResponse{}, Status: http.StatusCreated},
+
+	// 用户和 API key 管理
+	{Method: "GET", Path: "/api/v1/users", ID: "ListUsers", Tag: "users", Summary: "用户列表", Global: true, Admin: true, Data: []UserResponse{}},
+	{Method: "POST", Path: "/api/v1/users", ID: "CreateUser", Tag: "users", Summary: "创建用户", Global: true, Admin: true, Body: CreateUserRequest{}, Data: UserResponse{}, Status: http.StatusCreated},
+	{Method: "POST", Path: "/api/v1/users/:id/disable", ID: "DisableUser", Tag: "users", Summary: "禁用用户", Global: true, Admin: true, Data: UserResponse{}},
+	{Method: "POST", Path: "/api/v1/users/:id/enable", ID: "EnableUser", Tag: "users", Summary: "启用用户", Global: true, Admin: true, Data: UserResponse{}},
+	{Method: "GET", Path: "/api/v1/users/:id/keys", ID: "ListAPIKeys", Tag: "users", Summary: "用户的 API key 列表", Global: true, Admin: true, Data: []APIKeyResponse{}},
+	{Method: "POST", Path: "/api/v1/users/:id/keys", ID: "CreateAPIKey", Tag: "users", Summary: "创建 API key", Global: true, Admin: true, Body: CreateAPIKeyRequest{}, Response: CreateAPIKeyResponse{}, Status: http.StatusCreated,
+		Notes: "明文 key 只在此响应中返回一次"},
+	{Method: "DELETE", Path: "/api/v1/keys/:id", ID: "RevokeAPIKey", Tag: "users", Summary: "吊销 API key (本人或 admin)", Global: true, Data: APIKeyResponse{}},
+	{Method: "GET", Path: "/api/v1/audit", ID: "ListAuditLogs", Tag: "users", Summary: "审计日志", Global: true, Admin: true, Query: ListAuditLogsRequest{}, Data: []AuditLogResponse{}, Paged: true},
+
+	// 缓存和用量
+	{Method: "GET", Path: "/api/v1/enrich-cache", ID: "GetEnrichCacheStats", Tag: "cache", Summary: "丰富化缓存统计", Global: true, Data: []postgres.EnrichmentCacheStats{}},
+	{Method: "DELETE", Path: "/api/v1/enrich-cache", ID: "InvalidateEnrichCache", Tag: "cache", Summary: "清除丰富化缓存", Global: true, Admin: true, Query: invalidateEnrichCacheQuery{},
+		Response: struct {
+			Deleted int64 `json:"deleted"`
+		}{}},
+	{Method: "GET", Path: "/api/v1/embedding-cache", ID: "GetEmbeddingCacheStats", Tag: "cache", Summary: "向量缓存统计", Global: true, Admin: true, Data: embedding.CacheStats{},
+		Extra: struct {
+			StoredEntries int64 `json:"stored_entries"`
+		}{}},
+	{Method: "GET", Path: "/api/v1/usage", ID: "GetUsage", Tag: "cache", Summary: "LLM 与 Embedding 用量", Global: true, Admin: true, Query: usageQuery{}, Data: []postgres.UsageSummary{},
+		Extra: struct {
+			Total usageTotalResponse `json:"total"`
+			From  string             `json:"from"`
+			To    string             `json:"to"`
+		}{}},
+
+	// 工作区和成员管理
+	{Method: "GET", Path: "/api/v1/workspaces", ID: "ListWorkspaces", Tag: "workspaces", Summary: "可访问的工作区", Global: true, Data: []WorkspaceResponse{}},
+	{Method: "POST", Path: "/api/v1/workspaces", ID: "CreateWorkspace", Tag: "workspaces", Summary: "创建工作区", Global: true, Admin: true, Body: CreateWorkspaceRequest{}, Data: WorkspaceResponse{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/api/v1/workspaces/:slug", ID: "GetWorkspace", Tag: "workspaces", Summary: "工作区详情", Global: true, Data: WorkspaceResponse{}},
+	{Method: "PUT", Path: "/api/v1/workspaces/:slug", ID: "UpdateWorkspace", Tag: "workspaces", Summary: "修改工作区", Global: true, Admin: true, Body: UpdateWorkspaceRequest{}, Data: WorkspaceResponse{}},
+	{Method: "GET", Path: "/api/v1/workspaces/:slug/members", ID: "ListWorkspaceMembers", Tag: "workspaces", Summary: "工作区成员", Global: true, Data: []WorkspaceMemberResponse{}},
+	{Method: "PUT", Path: "/api/v1/workspaces/:slug/members/:user", ID: "SetWorkspaceMember", Tag: "workspaces", Summary: "添加成员或修改角色 (owner)", Global: true, Body: SetWorkspaceMemberRequest{}, Data: WorkspaceMemberResponse{}},
+	{Method: "DELETE", Path: "/api/v1/workspaces/:slug/members/:user", ID: "RemoveWorkspaceMember", Tag: "workspaces", Summary: "移除成员 (owner)", Global: true, Response: messageResponse{}},
+
+	// 文章
+	{Method: "GET", Path: "/api/v1/articles", ID: "ListArticles", Tag: "articles", Summary: "文章列表", Query: ListArticlesRequest{}, Data: []ArticleResponse{}, Paged: true,
+		Notes: "可以用 page 按页码分页，或者把 pagination.next_cursor 作为 cursor 按游标分页 (排序方式需与签发游标时一致)；total=estimate 时 total 为估计值，游标分页默认使用估计值"},
+	{Method: "GET", Path: "/api/v1/articles/hot", ID: "HotQuestions", Tag: "articles", Summary: "按标签分组的高频题", Query: HotQuestionsRequest{}, Data: []HotTagResponse{},
+		Extra: struct {
+			Days int `json:"days"`
+		}{}},
+	{Method: "GET", Path: "/api/v1/articles/:id", ID: "GetArticle", Tag: "articles", Summary: "文章详情", Data: ArticleResponse{},
+		Extra: struct {
+			RedirectedFrom uint `json:"redirected_from,omitempty"` // 请求的文章已被合并时为原 ID
+		}{}},
+	{Method: "GET", Path: "/api/v1/articles/:id/similar", ID: "FindSimilarArticles", Tag: "articles", Summary: "相似文章", Query: similarArticlesQuery{}, Data: []ArticleResponse{},
+		Extra: struct {
+			SourceID uint `json:"source_id"`
+			Limit    int  `json:"limit"`
+		}{}},
+	{Method: "GET", Path: "/api/v1/articles/:id/occurrences", ID: "ListArticleOccurrences", Tag: "articles", Summary: "文章的出现记录", Query: ListOccurrencesRequest{}, Data: []OccurrenceResponse{}, Paged: true,
+		Extra: struct {
+			Summary        postgres.OccurrenceSummary `json:"summary"`
+			RedirectedFrom uint                       `json:"redirected_from,omitempty"`
+		}{}},
+	{Method: "POST", Path: "/api/v1/articles/search", ID: "VectorSearch", Tag: "articles", Summary: "向量搜索", Body: VectorSearchRequest{}, Data: []ArticleResponse{},
+		Extra: struct {
+			Query    string `json:"query"`
+			Reranked bool   `json:"reranked"`
+		}{}},
+	{Method: "POST", Path: "/api/v1/articles/:id/merge", ID: "MergeArticles", Tag: "articles", Summary: "合并文章", Body: MergeArticlesRequest{}, Data: ArticleResponse{},
+		Extra: struct {
+			MergedIDs []uint `json:"merged_ids"`
+		}{}},
+	{Method: "POST", Path: "/api/v1/articles/:id/unmerge", ID: "UnmergeArticle", Tag: "articles", Summary: "拆分重复项为新文章", Body: UnmergeArticleRequest{}, Data: ArticleResponse{}, Status: http.StatusCreated,
+		Extra: struct {
+			SourceID uint `json:"source_id"`
+		}{}},
+	{Method: "POST", Path: "/api/v1/articles/:id/grade", ID: "GradeAnswer", Tag: "articles", Summary: "作答评分", Body: GradeAnswerRequest{}, Data: AnswerAttemptResponse{}, Status: http.StatusCreated,
+		Extra: struct {
+			Article        ArticleResponse `json:"article"`
+			RedirectedFrom uint            `json:"redirected_from,omitempty"`
+		}{}},
+
+	// 问答
+	{Method: "POST", Path: "/api/v1/ask", ID: "Ask", Tag: "ask", Summary: "检索增强问答", Body: AskRequest{},
+		Notes: "stream=true 时以 text/event-stream 返回；没有足够相关的文章时返回 answered=false 和最接近的文章，enqueue=true 时同时创建丰富化任务"},
+
+	// 练习作答记录
+	{Method: "GET", Path: "/api/v1/attempts", ID: "ListAnswerAttempts", Tag: "attempts", Summary: "作答记录", Query: ListAnswerAttemptsRequest{}, Data: []AnswerAttemptResponse{}, Paged: true},
+	{Method: "GET", Path: "/api/v1/attempts/:id", ID: "GetAnswerAttempt", Tag: "attempts", Summary: "作答记录详情", Data: AnswerAttemptResponse{}},
+
+	// 模拟面试
+	{Method: "GET", Path: "/api/v1/interviews", ID: "ListInterviews", Tag: "interviews", Summary: "模拟面试列表", Query: ListInterviewsRequest{}, Data: []InterviewResponse{}, Paged: true},
+	{Method: "POST", Path: "/api/v1/interviews", ID: "CreateInterview", Tag: "interviews", Summary: "开始模拟面试", Body: CreateInterviewRequest{}, Data: InterviewResponse{}, Status: http.StatusCreated,
+		Extra: struct {
+			Message string `json:"message,omitempty"` // 可用题目不足时的提示
+		}{}},
+	{Method: "GET", Path: "/api/v1/interviews/:id", ID: "GetInterview", Tag: "interviews", Summary: "模拟面试详情", Query: interviewQuery{}, Data: InterviewResponse{}},
+	{Method: "POST", Path: "/api/v1/interviews/:id/answers", ID: "SubmitInterviewAnswer", Tag: "interviews", Summary: "提交作答", Body: SubmitInterviewAnswerRequest{}, Data: InterviewItemResponse{},
+		Extra: struct {
+			Status  string                 `json:"status"`
+			Attempt *AnswerAttemptResponse `json:"attempt,omitempty"`
+		}{}},
+	{Method: "POST", Path: "/api/v1/interviews/:id/replay", ID: "ReplayInterview", Tag: "interviews", Summary: "重新开始同一场面试", Body: ReplayInterviewRequest{}, Optional: true, Data: InterviewResponse{}, Status: http.StatusCreated},
+
+	// 间隔重复复习
+	{Method: "GET", Path: "/api/v1/review/due", ID: "ListDueCards", Tag: "review", Summary: "到期的复习卡片", Query: DueCardsRequest{}, Data: []DueCardResponse{},
+		Extra: struct {
+			TotalDue int64 `json:"total_due"`
+		}{}},
+	{Method: "GET", Path: "/api/v1/review/stats", ID: "GetReviewStats", Tag: "review", Summary: "复习统计", Query: ReviewUserRequest{}, Data: postgres.ReviewStats{}},
+	{Method: "GET", Path: "/api/v1/review/subscriptions", ID: "ListTagSubscriptions", Tag: "review", Summary: "订阅的标签", Query: ReviewUserRequest{}, Data: []string{}},
+	{Method: "POST", Path: "/api/v1/review/subscriptions", ID: "SubscribeTags", Tag: "review", Summary: "订阅标签", Body: SubscribeTagsRequest{}, Data: []string{},
+		Extra: struct {
+			Enrolled int64 `json:"enrolled"`
+		}{}},
+	{Method: "DELETE", Path: "/api/v1/review/subscriptions", ID: "UnsubscribeTags", Tag: "review", Summary: "取消订阅标签", Query: UnsubscribeTagsRequest{},
+		Response: struct {
+			Removed int64 `json:"removed"`
+		}{}},
+	{Method: "POST", Path: "/api/v1/review/:id", ID: "RecordReview", Tag: "review", Summary: "记录复习自评", Body: RecordReviewRequest{}, Data: ReviewCardResponse{},
+		Extra: struct {
+			RedirectedFrom uint `json:"redirected_from,omitempty"`
+		}{}},
+
+	// 任务
+	{Method: "POST", Path: "/api/v1/tasks", ID: "CreateTask", Tag: "tasks", Summary: "提交丰富化任务", Body: CreateTaskRequest{}, Data: TaskResponse{}, Status: http.StatusCreated,
+		Extra: struct {
+			Message string `json:"message"`
+			TaskID  string `json:"task_id"`
+		}{}},
+
+	// 标签
+	{Method: "GET", Path: "/api/v1/tags", ID: "GetAllTags", Tag: "tags", Summary: "全部标签", Data: []string{}},
+	{Method: "GET", Path: "/api/v1/tags/stats", ID: "GetTagStats", Tag: "tags", Summary: "标签统计", Data: []postgres.TagStat{},
+		Extra: struct {
+			Cached bool `json:"cached"`
+		}{}},
+	{Method: "GET", Path: "/api/v1/tags/cooccurrence", ID: "GetTagCooccurrence", Tag: "tags", Summary: "标签共现矩阵", Query: TagCooccurrenceRequest{}, Data: TagCooccurrenceResponse{},
+		Extra: struct {
+			Cached bool `json:"cached"`
+		}{}},
+	{Method: "GET", Path: "/api/v1/tags/graph", ID: "GetTagGraph", Tag: "tags", Summary: "标签图", Query: TagGraphRequest{}, Data: postgres.TagGraph{},
+		Extra: struct {
+			Cached bool `json:"cached"`
+		}{}},
+	{Method: "GET", Path: "/api/v1/tags/taxonomy", ID: "GetTagTaxonomy", Tag: "tags", Summary: "标签体系", Data: []postgres.TagInfo{}},
+	{Method: "POST", Path: "/api/v1/tags/normalize", ID: "NormalizeArticleTags", Tag: "tags", Summary: "按标签体系规范化文章标签", Admin: true, Response: updatedArticlesResponse{}},
+	{Method: "POST", Path: "/api/v1/tags/merge", ID: "MergeTags", Tag: "tags", Summary: "合并标签", Admin: true, Body: MergeTagsRequest{}, Response: updatedArticlesResponse{}},
+	{Method: "POST", Path: "/api/v1/tags/rename", ID: "RenameTag", Tag: "tags", Summary: "重命名标签", Admin: true, Body: RenameTagRequest{}, Response: updatedArticlesResponse{}},
+	{Method: "POST", Path: "/api/v1/tags/aliases", ID: "AddTagAlias", Tag: "tags", Summary: "添加标签别名", Admin: true, Body: AddTagAliasRequest{}, Response: messageResponse{}},
+	{Method: "POST", Path: "/api/v1/tags/parent", ID: "SetTagParent", Tag: "tags", Summary: "设置上级标签", Admin: true, Body: SetTagParentRequest{}, Response: messageResponse{}},
+
+	// 主题簇
+	{Method: "GET", Path: "/api/v1/clusters", ID: "ListClusters", Tag: "clusters", Summary: "主题簇列表", Query: ClusterRequest{}, Data: []ClusterResponse{},
+		Extra: struct {
+			Run  clusterRunResponse `json:"run"`
+			Days int                `json:"days"`
+			Sort string             `json:"sort"`
+		}{}},
+	{Method: "GET", Path: "/api/v1/clusters/:id", ID: "GetCluster", Tag: "clusters", Summary: "主题簇详情", Query: ClusterRequest{}, Data: ClusterResponse{},
+		Extra: struct {
+			Days int `json:"days"`
+		}{}},
+	{Method: "GET", Path: "/api/v1/clusters/:id/articles", ID: "ListClusterArticles", Tag: "clusters", Summary: "主题簇的文章", Query: ListClusterArticlesRequest{}, Data: []ArticleResponse{}, Paged: true},
+
+	// 疑似重复审核
+	{Method: "GET", Path: "/api/v1/duplicates", ID: "ListDuplicateReviews", Tag: "duplicates", Summary: "疑似重复列表", Query: ListDuplicateReviewsRequest{}, Data: []DuplicateReviewResponse{}, Paged: true},
+	{Method: "POST", Path: "/api/v1/duplicates/:id/confirm", ID: "ConfirmDuplicateReview", Tag: "duplicates", Summary: "确认重复并合并", Data: duplicateResolutionResponse{}},
+	{Method: "POST", Path: "/api/v1/duplicates/:id/dismiss", ID: "DismissDuplicateReview", Tag: "duplicates", Summary: "驳回疑似重复", Data: duplicateResolutionResponse{}},
+}
+
+// tagDescriptions 是接口分组的说明，顺序即文档中的顺序
+var tagDescriptions = []openapi.Tag{
+	{Name: "docs", Description: "接口文档"},
+	{Name: "auth", Description: "鉴权和当前调用者"},
+	{Name: "users", Description: "用户、API key 和审计日志"},
+	{Name: "cache", Description: "缓存和用量统计"},
+	{Name: "workspaces", Description: "工作区和成员"},
+	{Name: "articles", Description: "文章"},
+	{Name: "ask", Description: "检索增强问答"},
+	{Name: "attempts", Description: "练习作答记录"},
+	{Name: "interviews", Description: "模拟面试"},
+	{Name: "review", Description: "间隔重复复习"},
+	{Name: "tasks", Description: "丰富化任务"},
+	{Name: "tags", Description: "标签"},
+	{Name: "clusters", Description: "主题簇"},
+	{Name: "duplicates", Description: "疑似重复审核"},
+}
+
+// OpenAPIDocument 生成全部接口的 OpenAPI 文档
+func OpenAPIDocument() *openapi.Document {
+	g := openapi.NewGenerator()
+	errorSchema := g.Define("Error", &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"error":      {Type: "string", Description: "按 Accept-Language 本地化的错误消息 (en 或 zh)，仅供展示"},
+			"code":       {Type: "string", Description: "稳定的错误码，如 validation_failed、article_not_found、rate_limited"},
+			"request_id": {Type: "string", Description: "请求 ID，与 X-Request-ID 响应头和服务端日志一致"},
+			"details": {
+				Type:        "array",
+				Description: "字段校验失败 (validation_failed) 时每个字段的错误",
+				Items: &openapi.Schema{
+					Type: "object",
+					Properties: map[string]*openapi.Schema{
+						"field": {Type: "string"},
+						"rule":  {Type: "string"},
+						"param": {Type: "string"},
+					},
+					Required:      []string{"field", "rule"},
+					PropertyOrder: []string{"field", "rule", "param"},
+				},
+			},
+			"retry_after": {Type: "integer", Format: "int64", Description: "限流或超出配额时，建议的重试等待秒数"},
+		},
+		Required:      []string{"error", "code", "request_id"},
+		PropertyOrder: []string{"error", "code", "request_id", "details", "retry_after"},
+	})
+	g.Schema(reflect.TypeOf(Pagination{}))
+
+	secured := []map[string][]string{{"bearerAuth": {}}, {"apiKeyAuth": {}}}
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "paguu API",
+			Description: "面试题库的 REST 接口。启用鉴权时 GET 需要 read 权限，写操作需要 write 权限；题库相关的接口属于 X-Workspace 头选择的工作区",
+			Version:     "v1",
+		},
+		Tags:     tagDescriptions,
+		Paths:    make(map[string]openapi.PathItem),
+		Security: secured,
+	}
+
+	for _, op := range operations {
+		path, params := openapi.PathParameters(op.Path)
+		if op.Query != nil {
+			params = append(params, g.QueryParameters(reflect.TypeOf(op.Query))...)
+		}
+		if !op.Global {
+			params = append(params, &openapi.Parameter{
+				Name:        "X-Workspace",
+				In:          "header",
+				Description: "工作区标识，默认为 default；也可以用 workspace 查询参数指定",
+				Schema:      &openapi.Schema{Type: "string"},
+			})
+		}
+
+		o := &openapi.Operation{
+			OperationID: op.ID,
+			Summary:     op.Summary,
+			Description: op.Notes,
+			Tags:        []string{op.Tag},
+			Parameters:  params,
+			Responses: map[string]*openapi.Response{
+				"default": {Description: "错误", Content: openapi.JSONContent(errorSchema)},
+			},
+			Security:  secured,
+			Scope:     op.scope(),
+			Paginated: op.Paged,
+		}
+		if op.Public {
+			o.Security = []map[string][]string{}
+			o.Scope = ""
+		}
+		if op.Body != nil {
+			o.RequestBody = &openapi.RequestBody{
+				Required: !op.Optional,
+				Content:  openapi.JSONContent(g.Schema(reflect.TypeOf(op.Body))),
+			}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := &openapi.Response{Description: http.StatusText(status)}
+		if op.HTML {
+			success.Content = map[string]openapi.MediaType{"text/html": {Schema: &openapi.Schema{Type: "string"}}}
+		} else {
+			success.Content = openapi.JSONContent(op.responseSchema(g))
+		}
+		o.Responses[strconv.Itoa(status)] = success
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(openapi.PathItem)
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(op.Method)] = o
+	}
+
+	doc.Components = openapi.Components{
+		Schemas: g.Schemas(),
+		SecuritySchemes: map[string]*openapi.SecurityScheme{
+			"bearerAuth": {Type: "http", Scheme: "bearer", Description: "API key 或 POST /auth/token 签发的 JWT"},
+			"apiKeyAuth": {Type: "apiKey", Name: "X-API-Key", In: "header"},
+		},
+	}
+	return doc
+}
+
+// scope 返回接口需要的 API key 权限范围，与 authenticate 和 requireScope 的检查一致
+func (op operation) scope() string {
+	switch {
+	case op.Admin:
+		return auth.ScopeAdmin
+	case op.Method == http.MethodGet, readOnlyRoutes[op.Method+" "+op.Path]:
+		return auth.ScopeRead
+	}
+	return auth.ScopeWrite
+}
+
+// responseSchema 返回成功响应的结构：{data, pagination, ...Extra}
+// 带有 data 之外字段的响应登记为 <ID>Response 组件，便于生成的客户端使用
+func (op operation) responseSchema(g *openapi.Generator) *openapi.Schema {
+	if op.Response != nil {
+		schema := g.Schema(reflect.TypeOf(op.Response))
+		if schema.Ref != "" {
+			return schema
+		}
+		return g.Define(op.ID+"Response", schema)
+	}
+	if op.Data == nil {
+		return &openapi.Schema{Type: "object", Description: "结构不固定"}
+	}
+
+	schema := &openapi.Schema{
+		Type:          "object",
+		Properties:    map[string]*openapi.Schema{"data": g.Schema(reflect.TypeOf(op.Data))},
+		Required:      []string{"data"},
+		PropertyOrder: []string{"data"},
+	}
+	if op.Paged {
+		schema.Properties["pagination"] = openapi.Ref("Pagination")
+		schema.Required = append(schema.Required, "pagination")
+		schema.PropertyOrder = append(schema.PropertyOrder, "pagination")
+	}
+	if op.Extra == nil {
+		return schema
+	}
+
+	extra := g.Schema(reflect.TypeOf(op.Extra))
+	for _, name := range extra.PropertyOrder {
+		schema.Properties[name] = extra.Properties[name]
+		schema.PropertyOrder = append(schema.PropertyOrder, name)
+	}
+	schema.Required = append(schema.Required, extra.Required...)
+	return g.Define(op.ID+"Response", schema)
+}
+
+// UndocumentedRoutes 对比路由与 operations：missing 是没有登记的路由，stale 是登记了但没有注册的接口
+func UndocumentedRoutes(r *gin.Engine) (missing, stale []string) {
+	documented := make(map[string]bool, len(operations))
+	for _, op := range operations {
+		documented[op.Method+" "+op.Path] = true
+	}
+	for _, route := range r.Routes() {
+		key := route.Method + " " + route.Path
+		if documented[key] {
+			delete(documented, key)
+			continue
+		}
+		missing = append(missing, key)
+	}
+	for key := range documented {
+		stale = append(stale, key)
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	return missing, stale
+}
+
+// checkDocumentedRoutes 在路由与 operations 不一致时记录警告
+func checkDocumentedRoutes(r *gin.Engine) {
+	missing, stale := UndocumentedRoutes(r)
+	if len(missing) > 0 {
+		slog.Warn("路由没有登记到 OpenAPI 文档", "routes", missing)
+	}
+	if len(stale) > 0 {
+		slog.Warn("OpenAPI 文档中的接口没有注册路由", "routes", stale)
+	}
+}
+
+var (
+	openAPIOnce sync.Once
+	openAPIJSON []byte
+)
+
+// GetOpenAPISpec 返回 OpenAPI 文档，首次请求时生成
+func (h *Handler) GetOpenAPISpec(c *gin.Context) {
+	openAPIOnce.Do(func() {
+		var err error
+		openAPIJSON, err = json.Marshal(OpenAPIDocument())
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "OpenAPIDocument marshal error", "error", err)
+		}
+	})
+	if openAPIJSON == nil {
+		renderError(c, errInternal("failed to generate openapi document"))
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openAPIJSON)
+}
+
+// apiDocsPage 是从 CDN 加载 Swagger UI 的文档页面，文档地址为相对路径，经反向代理访问时也能加载
+const apiDocsPage = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+  <meta charset="utf-8">
+  <title>paguu API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "openapi.json", dom_id: "#swagger-ui", persistAuthorization: true });
+  </script>
+</body>
+</html>
+`
+
+// GetAPIDocs 返回接口文档页面
+func (h *Handler) GetAPIDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(apiDocsPage))
+}
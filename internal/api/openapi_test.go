@@ -0,0 +1,114 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"paguu/client"
+	"paguu/internal/api"
+	"paguu/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	return api.SetupRouter(api.NewHandler(nil, nil, nil), api.RouterOptions{})
+}
+
+// ginParam 匹配 gin 路由中的 :name 参数
+var ginParam = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+// TestOpenAPIMatchesRoutes 要求 SetupRouter 注册的路由与文档中的接口一一对应
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	r := newTestRouter(t)
+	doc := api.OpenAPIDocument()
+
+	documented := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for method := range item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	var missing []string
+	registered := make(map[string]bool)
+	for _, route := range r.Routes() {
+		key := route.Method + " " + ginParam.ReplaceAllString(route.Path, "{$1}")
+		registered[key] = true
+		if !documented[key] {
+			missing = append(missing, key)
+		}
+	}
+	var stale []string
+	for key := range documented {
+		if !registered[key] {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+
+	if len(missing) > 0 {
+		t.Errorf("routes missing from the OpenAPI document (add them to operations in openapi.go):\n%s", strings.Join(missing, "\n"))
+	}
+	if len(stale) > 0 {
+		t.Errorf("documented operations without a route:\n%s", strings.Join(stale, "\n"))
+	}
+}
+
+// TestGeneratedClientUpToDate 要求 client/zz_generated.go 与当前文档生成的代码一致
+func TestGeneratedClientUpToDate(t *testing.T) {
+	want, err := openapi.GenerateClient(api.OpenAPIDocument(), "client")
+	if err != nil {
+		t.Fatalf("GenerateClient: %v", err)
+	}
+	got, err := os.ReadFile("../../client/zz_generated.go")
+	if err != nil {
+		t.Fatalf("read generated client: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("client/zz_generated.go is out of date, run go generate ./client")
+	}
+}
+
+// TestClientRoundTrip 通过生成的客户端调用真实的路由，检查请求编码和响应/错误解码
+func TestClientRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(newTestRouter(t))
+	defer srv.Close()
+	c := client.New(srv.URL, client.WithWorkspace("default"))
+	ctx := context.Background()
+
+	me, err := c.GetMe(ctx)
+	if err != nil {
+		t.Fatalf("GetMe: %v", err)
+	}
+	data, _ := me["data"].(map[string]any)
+	if data == nil || data["authenticated"] != false {
+		t.Errorf("GetMe = %v, want data.authenticated = false", me)
+	}
+
+	_, err = c.CreateUser(ctx, client.CreateUserRequest{})
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("CreateUser error = %v, want *client.Error", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Code != api.CodeValidationFailed {
+		t.Errorf("CreateUser error = %d %s, want 400 %s", apiErr.StatusCode, apiErr.Code, api.CodeValidationFailed)
+	}
+	if len(apiErr.Details) != 1 || apiErr.Details[0].Field != "name" || apiErr.Details[0].Rule != "required" {
+		t.Errorf("CreateUser details = %+v, want name/required", apiErr.Details)
+	}
+	if apiErr.RequestID == "" {
+		t.Error("CreateUser error has no request ID")
+	}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"paguu/internal/auth"
+	"paguu/internal/ratelimit"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitIdentity 返回限流的调用者：有凭证时按 API key (JWT 归属于签发它的 key)，否则按客户端 IP
+func rateLimitIdentity(c *gin.Context) string {
+	if p := principalFrom(c); p != nil {
+		return "key:" + strconv.FormatUint(uint64(p.KeyID), 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// quotaSubject 返回每日配额的归属：有凭证时按用户 (同一用户的多个 key 共享配额)，否则按客户端 IP
+func quotaSubject(c *gin.Context) string {
+	if p := principalFrom(c); p != nil {
+		return "user:" + strconv.FormatUint(uint64(p.UserID), 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// rateLimit 从 bucket 令牌桶中为调用者取出一个令牌，令牌不足时返回 429
+// limiter 为 nil 或 bucket 未配置时不限流；多个令牌桶叠加时响应头反映最后检查的一个
+func rateLimit(limiter *ratelimit.Limiter, bucket string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil {
+			c.Next()
+			return
+		}
+		decision := limiter.Allow(c.Request.Context(), bucket, rateLimitIdentity(c))
+		if decision == nil {
+			c.Next()
+			return
+		}
+
+		setRateLimitHeaders(c, decision)
+		if !decision.Allowed {
+			retryAfter := ceilSeconds(decision.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			renderError(c, newAPIError(http.StatusTooManyRequests, CodeRateLimited, "rate limit exceeded").with(gin.H{
+				"bucket":      bucket,
+				"retry_after": retryAfter,
+			}))
+			return
+		}
+		c.Next()
+	}
+}
+
+// setRateLimitHeaders 按 IETF RateLimit 头字段草案写入 RateLimit-Limit/Remaining/Reset/Policy
+func setRateLimitHeaders(c *gin.Context, d *ratelimit.Decision) {
+	c.Header("RateLimit-Limit", strconv.Itoa(d.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.Reset)))
+	c.Header("RateLimit-Policy", strconv.Itoa(d.Limit)+";w="+strconv.Itoa(ceilSeconds(d.Window)))
+}
+
+// consumeQuota 为提交 questions 行问题的任务扣减调用者的每日配额，admin 不受配额限制
+// 超出配额时已写入 429 响应并返回 false；任务最终没有创建成功时应调用 releaseQuota 退还
+func (h *Handler) consumeQuota(c *gin.Context, questions int) bool {
+	if h.limiter == nil || !h.limiter.QuotaEnabled() {
+		return true
+	}
+	if p := principalFrom(c); p != nil && p.Has(auth.ScopeAdmin) {
+		return true
+	}
+
+	decision := h.limiter.ConsumeQuota(c.Request.Context(), quotaSubject(c), questions)
+	if decision.Allowed {
+		return true
+	}
+	retryAfter := ceilSeconds(time.Until(decision.ResetAt))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	renderError(c, newAPIError(http.StatusTooManyRequests, CodeQuotaExceeded, "daily %s quota exceeded", decision.Exceeded).with(gin.H{
+		"quota":       toQuotaResponse(decision),
+		"retry_after": retryAfter,
+	}))
+	return false
+}
+
+// releaseQuota 退还 consumeQuota 扣减的配额
+func (h *Handler) releaseQuota(c *gin.Context, questions int) {
+	if h.limiter == nil || !h.limiter.QuotaEnabled() {
+		return
+	}
+	if p := principalFrom(c); p != nil && p.Has(auth.ScopeAdmin) {
+		return
+	}
+	h.limiter.ReleaseQuota(c.Request.Context(), quotaSubject(c), questions)
+}
+
+// QuotaResponse 每日配额响应，limit 为 0 表示不限制
+type QuotaResponse struct {
+	Tasks          int    `json:"tasks"`
+	TasksLimit     int    `json:"tasks_limit"`
+	Questions      int    `json:"questions"`
+	QuestionsLimit int    `json:"questions_limit"`
+	ResetsAt       string `json:"resets_at"`
+}
+
+func toQuotaResponse(d ratelimit.QuotaDecision) QuotaResponse {
+	return QuotaResponse{
+		Tasks:          d.Usage.Tasks,
+		TasksLimit:     d.Quota.DailyTasks,
+		Questions:      d.Usage.Questions,
+		QuestionsLimit: d.Quota.DailyQuestions,
+		ResetsAt:       d.ResetAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// ceilSeconds 将时间向上取整为秒
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// requestIDHeader 是请求 ID 的请求头和响应头，调用方可以传入自己的 ID 以串联上下游日志
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength 是接受的请求 ID 的最大长度，更长或包含其他字符时重新生成
+const maxRequestIDLength = 128
+
+type requestIDContextKey struct{}
+
+// requestID 为每个请求分配 ID：写入响应头、错误响应和请求的 context，经 RequestIDLogHandler 出现在日志中
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Header(requestIDHeader, id)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestIDContextKey{}, id))
+		c.Next()
+	}
+}
+
+// validRequestID 只接受字母、数字和 -_.: 组成的 ID，避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDFrom 返回当前请求的 ID
+func requestIDFrom(c *gin.Context) string {
+	id, _ := c.Request.Context().Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// RequestIDLogHandler 包装 slog.Handler，为带有请求 context 的日志 (slog.ErrorContext 等) 加上 request_id
+func RequestIDLogHandler(next slog.Handler) slog.Handler {
+	return requestIDLogHandler{next}
+}
+
+type requestIDLogHandler struct {
+	slog.Handler
+}
+
+func (h requestIDLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id, ok := ctx.Value(requestIDContextKey{}).(string); ok {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h requestIDLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIDLogHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestIDLogHandler) WithGroup(name string) slog.Handler {
+	return requestIDLogHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"paguu/internal/srs"
+	"paguu/internal/storage/postgres"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewCardResponse 复习卡片响应结构
+type ReviewCardResponse struct {
+	ArticleID      uint    `json:"article_id"`
+	Ease           float64 `json:"ease"`
+	IntervalDays   int     `json:"interval_days"`
+	Repetitions    int     `json:"repetitions"`
+	Lapses         int     `json:"lapses"`
+	DueAt          string  `json:"due_at"`
+	LastReviewedAt *string `json:"last_reviewed_at,omitempty"`
+}
+
+// toReviewCardResponse 将复习卡片转换为响应结构
+func toReviewCardResponse(card postgres.ReviewCard) ReviewCardResponse {
+	response := ReviewCardResponse{
+		ArticleID:    card.ArticleID,
+		Ease:         card.Ease,
+		IntervalDays: card.IntervalDays,
+		Repetitions:  card.Repetitions,
+		Lapses:       card.Lapses,
+		DueAt:        card.DueAt.Format("2006-01-02 15:04:05"),
+	}
+	if card.LastReviewedAt != nil {
+		reviewedAt := card.LastReviewedAt.Format("2006-01-02 15:04:05")
+		response.LastReviewedAt = &reviewedAt
+	}
+	return response
+}
+
+// DueCardsRequest 到期卡片请求参数
+type DueCardsRequest struct {
+	User  string   `form:"user"` // 启用鉴权时默认为调用者本人
+	Tags  []string `form:"tags[]"`
+	Limit int      `form:"limit" binding:"omitempty,min=1,max=100"` // 默认 20
+}
+
+// DueCardResponse 到期卡片及其文章
+type DueCardResponse struct {
+	Card    ReviewCardResponse `json:"card"`
+	Article ArticleResponse    `json:"article"`
+}
+
+// ListDueCards 按到期时间返回用户需要复习的卡片，可按标签筛选
+func (h *Handler) ListDueCards(c *gin.Context) {
+	var req DueCardsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	user, ok := requireUser(c, req.User)
+	if !ok {
+		return
+	}
+	req.User = user
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	tags, err := h.repo.CanonicalizeTags(c.Request.Context(), req.Tags)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "CanonicalizeTags error", "error", err)
+		renderError(c, errInternal("failed to list due cards"))
+		return
+	}
+
+	cards, total, err := h.repo.ListDueCards(c.Request.Context(), postgres.DueCardsOptions{
+		UserName: req.User,
+		Tags:     tags,
+		Limit:    req.Limit,
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListDueCards error", "error", err, "user", req.User)
+		renderError(c, errInternal("failed to list due cards"))
+		return
+	}
+
+	responses := make([]DueCardResponse, len(cards))
+	for i, card := range cards {
+		responses[i] = DueCardResponse{
+			Card:    toReviewCardResponse(card.Card),
+			Article: toArticleResponse(card.Article),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":      responses,
+		"total_due": total,
+	})
+}
+
+// RecordReviewRequest 复习自评请求参数
+type RecordReviewRequest struct {
+	User   string `json:"user"` // 启用鉴权时默认为调用者本人
+	Rating string `json:"rating" binding:"required,oneof=again hard good easy"`
+}
+
+// RecordReview 记录一次复习自评，按 SM-2 安排下次复习时间
+func (h *Handler) RecordReview(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid article id"))
+		return
+	}
+
+	var req RecordReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	user, ok := requireUser(c, req.User)
+	if !ok {
+		return
+	}
+	req.User = user
+	rating, err := srs.ParseRating(req.Rating)
+	if err != nil {
+		renderError(c, errBadRequest("invalid rating").withDetail(err.Error()))
+		return
+	}
+
+	resolvedID, err := h.repo.ResolveArticleID(c.Request.Context(), uint(id))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ResolveArticleID error", "error", err, "id", id)
+		renderError(c, errInternal("failed to record review"))
+		return
+	}
+
+	card, err := h.repo.RecordReview(c.Request.Context(), req.User, resolvedID, rating, time.Now())
+	if err != nil {
+		if errors.Is(err, postgres.ErrArticleNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "RecordReview error", "error", err, "id", resolvedID, "user", req.User)
+		renderError(c, errInternal("failed to record review"))
+		return
+	}
+
+	body := gin.H{"data": toReviewCardResponse(*card)}
+	if resolvedID != uint(id) {
+		body["redirected_from"] = uint(id)
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// ReviewUserRequest 只需要用户的复习接口请求参数
+type ReviewUserRequest struct {
+	User string `form:"user"` // 启用鉴权时默认为调用者本人
+}
+
+// GetReviewStats 返回用户卡组的卡片数、到期数、新卡片数和最近 24 小时的复习次数
+func (h *Handler) GetReviewStats(c *gin.Context) {
+	var req ReviewUserRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	user, ok := requireUser(c, req.User)
+	if !ok {
+		return
+	}
+	req.User = user
+
+	stats, err := h.repo.GetReviewStats(c.Request.Context(), req.User)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "GetReviewStats error", "error", err, "user", req.User)
+		renderError(c, errInternal("failed to get review stats"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
+
+// ListTagSubscriptions 返回用户订阅的标签
+func (h *Handler) ListTagSubscriptions(c *gin.Context) {
+	var req ReviewUserRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	user, ok := requireUser(c, req.User)
+	if !ok {
+		return
+	}
+	req.User = user
+
+	tags, err := h.repo.ListTagSubscriptions(c.Request.Context(), req.User)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListTagSubscriptions error", "error", err, "user", req.User)
+		renderError(c, errInternal("failed to list tag subscriptions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// SubscribeTagsRequest 订阅标签请求参数
+type SubscribeTagsRequest struct {
+	User   string   `json:"user"` // 启用鉴权时默认为调用者本人
+	Tags   []string `json:"tags" binding:"required,min=1"`
+	Enroll *bool    `json:"enroll"` // 是否把已有的带这些标签的文章加入卡组，默认 true
+}
+
+// SubscribeTags 订阅标签，之后入库的带这些标签的新文章会自动加入用户的卡组
+func (h *Handler) SubscribeTags(c *gin.Context) {
+	var req SubscribeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	user, ok := requireUser(c, req.User)
+	if !ok {
+		return
+	}
+	req.User = user
+	enroll := req.Enroll == nil || *req.Enroll
+
+	// 订阅保存规范名，与 Article.Tags 保持一致
+	tags, err := h.repo.NormalizeTags(c.Request.Context(), req.Tags)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "NormalizeTags error", "error", err)
+		renderError(c, errInternal("failed to subscribe tags"))
+		return
+	}
+
+	enrolled, err := h.repo.SubscribeTags(c.Request.Context(), req.User, tags, enroll)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "SubscribeTags error", "error", err, "user", req.User)
+		renderError(c, errInternal("failed to subscribe tags"))
+		return
+	}
+
+	subscriptions, err := h.repo.ListTagSubscriptions(c.Request.Context(), req.User)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListTagSubscriptions error", "error", err, "user", req.User)
+		renderError(c, errInternal("failed to subscribe tags"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":     subscriptions,
+		"enrolled": enrolled,
+	})
+}
+
+// UnsubscribeTagsRequest 取消订阅请求参数
+type UnsubscribeTagsRequest struct {
+	User string   `form:"user"` // 启用鉴权时默认为调用者本人
+	Tags []string `form:"tags[]" binding:"required,min=1"`
+}
+
+// UnsubscribeTags 取消订阅标签，已加入卡组的卡片保留
+func (h *Handler) UnsubscribeTags(c *gin.Context) {
+	var req UnsubscribeTagsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	user, ok := requireUser(c, req.User)
+	if !ok {
+		return
+	}
+	req.User = user
+
+	tags, err := h.repo.CanonicalizeTags(c.Request.Context(), req.Tags)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "CanonicalizeTags error", "error", err)
+		renderError(c, errInternal("failed to unsubscribe tags"))
+		return
+	}
+
+	removed, err := h.repo.UnsubscribeTags(c.Request.Context(), req.User, tags)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "UnsubscribeTags error", "error", err, "user", req.User)
+		renderError(c, errInternal("failed to unsubscribe tags"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"removed": removed})
+}
@@ -1,49 +1,177 @@
 package api
 
 import (
+	"paguu/internal/auth"
+	"paguu/internal/ratelimit"
+
 	"github.com/gin-gonic/gin"
 )
 
-// SetupRouter 设置路由
-func SetupRouter(handler *Handler) *gin.Engine {
-	r := gin.Default()
-
-	// 添加 CORS 中间件
-	r.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// RouterOptions 是路由层的配置
+type RouterOptions struct {
+	CORSOrigins []string // 允许跨域访问的来源，包含 "*" 时允许任意来源，为空时不允许跨域
+}
 
-		// 处理 OPTIONS 预检请求
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+// SetupRouter 设置路由
+// handler 设置了 Authenticator 时所有 API 都需要鉴权：GET 需要 read 权限，写操作需要 write 权限，
+// 用户、审计、缓存清除、用量统计和改写整个工作区标签的接口需要 admin 权限
+// handler 设置了 Limiter 时按 API key (或 IP) 限流，调用付费 API 的接口另外从 paid 令牌桶扣减
+// 题库相关的接口属于 X-Workspace 头 (或 workspace 参数) 选择的工作区，还需要调用者在其中的角色允许该操作
+// 新增路由时需要在 openapi.go 的 operations 中登记，否则启动时会记录警告
+// 错误响应统一由 renderError 写出 (见 errors.go)，每个请求带有 X-Request-ID
+func SetupRouter(handler *Handler, opts RouterOptions) *gin.Engine {
+	registerFieldNames()
+	r := gin.New()
 
-		c.Next()
+	// 请求 ID 和 CORS 中间件，panic 和未注册的路由也返回统一的错误结构
+	r.Use(gin.Logger(), gin.CustomRecovery(func(c *gin.Context, _ any) {
+		renderError(c, errInternal("internal server error"))
+	}), requestID(), cors(opts.CORSOrigins))
+	r.NoRoute(func(c *gin.Context) {
+		renderError(c, errNotFound("route not found"))
 	})
 
+	// 接口文档，不需要鉴权
+	r.GET("/api/v1/openapi.json", handler.GetOpenAPISpec) // GET /api/v1/openapi.json
+	r.GET("/api/v1/docs", handler.GetAPIDocs)             // GET /api/v1/docs
+
 	// API v1 路由组
 	v1 := r.Group("/api/v1")
+	v1.Use(authenticate(handler.authenticator), rateLimit(handler.limiter, ratelimit.BucketAPI), audit(handler.repo))
+	{
+		// 当前调用者
+		v1.GET("/me", handler.GetMe)                   // GET /api/v1/me
+		v1.GET("/me/quota", handler.GetMyQuota)        // GET /api/v1/me/quota
+		v1.POST("/auth/token", handler.IssueAuthToken) // POST /api/v1/auth/token
+
+		// 用户和 API key 管理
+		users := v1.Group("/users", requireScope(auth.ScopeAdmin))
+		{
+			users.GET("", handler.ListUsers)                // GET /api/v1/users
+			users.POST("", handler.CreateUser)              // POST /api/v1/users
+			users.POST("/:id/disable", handler.DisableUser) // POST /api/v1/users/3/disable
+			users.POST("/:id/enable", handler.EnableUser)   // POST /api/v1/users/3/enable
+			users.GET("/:id/keys", handler.ListAPIKeys)     // GET /api/v1/users/3/keys
+			users.POST("/:id/keys", handler.CreateAPIKey)   // POST /api/v1/users/3/keys
+		}
+		v1.DELETE("/keys/:id", handler.RevokeAPIKey)                           // DELETE /api/v1/keys/8 (本人或 admin)
+		v1.GET("/audit", requireScope(auth.ScopeAdmin), handler.ListAuditLogs) // GET /api/v1/audit?user=alice
+
+		// 丰富化缓存
+		enrichCache := v1.Group("/enrich-cache")
+		{
+			enrichCache.GET("", handler.GetEnrichCacheStats)                                     // GET /api/v1/enrich-cache
+			enrichCache.DELETE("", requireScope(auth.ScopeAdmin), handler.InvalidateEnrichCache) // DELETE /api/v1/enrich-cache?question=golang的GC
+		}
+
+		// 向量缓存
+		v1.GET("/embedding-cache", requireScope(auth.ScopeAdmin), handler.GetEmbeddingCacheStats) // GET /api/v1/embedding-cache
+
+		// 用量统计
+		v1.GET("/usage", requireScope(auth.ScopeAdmin), handler.GetUsage) // GET /api/v1/usage?from=2025-10-01&to=2025-10-07
+
+		// 工作区和成员管理
+		workspaces := v1.Group("/workspaces")
+		{
+			workspaces.GET("", handler.ListWorkspaces)                                       // GET /api/v1/workspaces
+			workspaces.POST("", requireScope(auth.ScopeAdmin), handler.CreateWorkspace)      // POST /api/v1/workspaces
+			workspaces.GET("/:slug", handler.GetWorkspace)                                   // GET /api/v1/workspaces/backend
+			workspaces.PUT("/:slug", requireScope(auth.ScopeAdmin), handler.UpdateWorkspace) // PUT /api/v1/workspaces/backend
+			workspaces.GET("/:slug/members", handler.ListWorkspaceMembers)                   // GET /api/v1/workspaces/backend/members
+			workspaces.PUT("/:slug/members/:user", handler.SetWorkspaceMember)               // PUT /api/v1/workspaces/backend/members/alice (owner)
+			workspaces.DELETE("/:slug/members/:user", handler.RemoveWorkspaceMember)         // DELETE /api/v1/workspaces/backend/members/alice (owner)
+		}
+	}
+
+	// 会调用付费 LLM / Embedding API 的接口
+	paid := rateLimit(handler.limiter, ratelimit.BucketPaid)
+
+	// 以下接口限定在请求选择的工作区内
+	scoped := v1.Group("", workspaceScope(handler.repo))
 	{
 		// 文章相关
-		articles := v1.Group("/articles")
+		articles := scoped.Group("/articles")
+		{
+			articles.GET("", handler.ListArticles)                           // GET /api/v1/articles?page=1&page_size=20&tags[]=Go&tags[]=MySQL
+			articles.GET("/hot", handler.HotQuestions)                       // GET /api/v1/articles/hot?days=30&tags[]=Go
+			articles.GET("/:id", handler.GetArticle)                         // GET /api/v1/articles/123
+			articles.GET("/:id/similar", handler.FindSimilarArticles)        // GET /api/v1/articles/123/similar?limit=10
+			articles.GET("/:id/occurrences", handler.ListArticleOccurrences) // GET /api/v1/articles/123/occurrences?page=1
+			articles.POST("/search", paid, handler.VectorSearch)             // POST /api/v1/articles/search
+			articles.POST("/:id/merge", handler.MergeArticles)               // POST /api/v1/articles/123/merge
+			articles.POST("/:id/unmerge", handler.UnmergeArticle)            // POST /api/v1/articles/123/unmerge
+			articles.POST("/:id/grade", paid, handler.GradeAnswer)           // POST /api/v1/articles/123/grade
+		}
+
+		// 检索增强问答
+		scoped.POST("/ask", paid, handler.Ask) // POST /api/v1/ask
+
+		// 练习作答记录
+		attempts := scoped.Group("/attempts")
+		{
+			attempts.GET("", handler.ListAnswerAttempts)   // GET /api/v1/attempts?user=alice&max_score=60
+			attempts.GET("/:id", handler.GetAnswerAttempt) // GET /api/v1/attempts/42
+		}
+
+		// 模拟面试
+		interviews := scoped.Group("/interviews")
+		{
+			interviews.GET("", handler.ListInterviews)                           // GET /api/v1/interviews?user=alice
+			interviews.POST("", paid, handler.CreateInterview)                   // POST /api/v1/interviews
+			interviews.GET("/:id", handler.GetInterview)                         // GET /api/v1/interviews/5?reveal=true
+			interviews.POST("/:id/answers", paid, handler.SubmitInterviewAnswer) // POST /api/v1/interviews/5/answers
+			interviews.POST("/:id/replay", handler.ReplayInterview)              // POST /api/v1/interviews/5/replay
+		}
+
+		// 间隔重复复习
+		review := scoped.Group("/review")
 		{
-			articles.GET("", handler.ListArticles)                    // GET /api/v1/articles?page=1&page_size=20&tags[]=Go&tags[]=MySQL
-			articles.GET("/:id", handler.GetArticle)                  // GET /api/v1/articles/123
-			articles.GET("/:id/similar", handler.FindSimilarArticles) // GET /api/v1/articles/123/similar?limit=10
-			articles.POST("/search", handler.VectorSearch)            // POST /api/v1/articles/search
+			review.GET("/due", handler.ListDueCards)                   // GET /api/v1/review/due?user=alice&tags[]=Go
+			review.GET("/stats", handler.GetReviewStats)               // GET /api/v1/review/stats?user=alice
+			review.GET("/subscriptions", handler.ListTagSubscriptions) // GET /api/v1/review/subscriptions?user=alice
+			review.POST("/subscriptions", handler.SubscribeTags)       // POST /api/v1/review/subscriptions
+			review.DELETE("/subscriptions", handler.UnsubscribeTags)   // DELETE /api/v1/review/subscriptions?user=alice&tags[]=Go
+			review.POST("/:id", handler.RecordReview)                  // POST /api/v1/review/123
 		}
 
 		// 任务相关
-		tasks := v1.Group("/tasks")
+		tasks := scoped.Group("/tasks")
 		{
-			tasks.POST("", handler.CreateTask) // POST /api/v1/tasks
+			tasks.POST("", paid, handler.CreateTask) // POST /api/v1/tasks
 		}
 
 		// Tag 相关
-		v1.GET("/tags", handler.GetAllTags) // GET /api/v1/tags
+		tags := scoped.Group("/tags")
+		{
+			tags.GET("", handler.GetAllTags)                                                     // GET /api/v1/tags
+			tags.GET("/stats", handler.GetTagStats)                                              // GET /api/v1/tags/stats
+			tags.GET("/cooccurrence", handler.GetTagCooccurrence)                                // GET /api/v1/tags/cooccurrence?limit=20
+			tags.GET("/graph", handler.GetTagGraph)                                              // GET /api/v1/tags/graph?limit=50&min_weight=2
+			tags.GET("/taxonomy", handler.GetTagTaxonomy)                                        // GET /api/v1/tags/taxonomy
+			tags.POST("/normalize", requireScope(auth.ScopeAdmin), handler.NormalizeArticleTags) // POST /api/v1/tags/normalize
+			tags.POST("/merge", requireScope(auth.ScopeAdmin), handler.MergeTags)                // POST /api/v1/tags/merge
+			tags.POST("/rename", requireScope(auth.ScopeAdmin), handler.RenameTag)               // POST /api/v1/tags/rename
+			tags.POST("/aliases", requireScope(auth.ScopeAdmin), handler.AddTagAlias)            // POST /api/v1/tags/aliases
+			tags.POST("/parent", requireScope(auth.ScopeAdmin), handler.SetTagParent)            // POST /api/v1/tags/parent
+		}
+
+		// 主题簇
+		clusters := scoped.Group("/clusters")
+		{
+			clusters.GET("", handler.ListClusters)                     // GET /api/v1/clusters?days=30&sort=growth
+			clusters.GET("/:id", handler.GetCluster)                   // GET /api/v1/clusters/12?days=30
+			clusters.GET("/:id/articles", handler.ListClusterArticles) // GET /api/v1/clusters/12/articles?page=1
+		}
+
+		// 疑似重复审核
+		duplicates := scoped.Group("/duplicates")
+		{
+			duplicates.GET("", handler.ListDuplicateReviews)                // GET /api/v1/duplicates?status=pending
+			duplicates.POST("/:id/confirm", handler.ConfirmDuplicateReview) // POST /api/v1/duplicates/7/confirm
+			duplicates.POST("/:id/dismiss", handler.DismissDuplicateReview) // POST /api/v1/duplicates/7/dismiss
+		}
 	}
 
+	checkDocumentedRoutes(r)
 	return r
 }
@@ -0,0 +1,285 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"paguu/internal/storage/postgres"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTagTaxonomy 返回标签体系：规范名、别名和上级标签
+func (h *Handler) GetTagTaxonomy(c *gin.Context) {
+	tags, err := h.repo.ListTagTaxonomy(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListTagTaxonomy error", "error", err)
+		renderError(c, errInternal("failed to get tag taxonomy"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// NormalizeArticleTags 登记未登记的标签，并将所有文章的标签改写为规范名
+func (h *Handler) NormalizeArticleTags(c *gin.Context) {
+	updated, err := h.repo.NormalizeArticleTags(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "NormalizeArticleTags error", "error", err)
+		renderError(c, errInternal("failed to normalize tags"))
+		return
+	}
+	h.tagStatsCache.clear()
+
+	c.JSON(http.StatusOK, gin.H{"updated_articles": updated})
+}
+
+// MergeTagsRequest 合并标签请求参数
+type MergeTagsRequest struct {
+	Source string `json:"source" binding:"required"` // 被合并的标签 (名称或别名)
+	Target string `json:"target" binding:"required"` // 保留的标签 (名称或别名)
+}
+
+// MergeTags 将 source 标签合并到 target
+func (h *Handler) MergeTags(c *gin.Context) {
+	var req MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+
+	updated, err := h.repo.MergeTags(c.Request.Context(), req.Source, req.Target)
+	if err != nil {
+		h.renderTagError(c, "MergeTags", err)
+		return
+	}
+	h.tagStatsCache.clear()
+
+	c.JSON(http.StatusOK, gin.H{"updated_articles": updated})
+}
+
+// RenameTagRequest 重命名标签请求参数
+type RenameTagRequest struct {
+	From string `json:"from" binding:"required"` // 当前名称或别名
+	To   string `json:"to" binding:"required"`   // 新的规范名
+}
+
+// RenameTag 修改标签的规范名，旧名称保留为别名
+func (h *Handler) RenameTag(c *gin.Context) {
+	var req RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+
+	updated, err := h.repo.RenameTag(c.Request.Context(), req.From, req.To)
+	if err != nil {
+		h.renderTagError(c, "RenameTag", err)
+		return
+	}
+	h.tagStatsCache.clear()
+
+	c.JSON(http.StatusOK, gin.H{"updated_articles": updated})
+}
+
+// AddTagAliasRequest 添加别名请求参数
+type AddTagAliasRequest struct {
+	Tag   string `json:"tag" binding:"required"`
+	Alias string `json:"alias" binding:"required"`
+}
+
+// AddTagAlias 为标签登记别名，之后入库的该写法会映射到规范名
+func (h *Handler) AddTagAlias(c *gin.Context) {
+	var req AddTagAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+
+	if err := h.repo.AddTagAlias(c.Request.Context(), req.Tag, req.Alias); err != nil {
+		h.renderTagError(c, "AddTagAlias", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "alias added"})
+}
+
+// SetTagParentRequest 设置上级标签请求参数
+type SetTagParentRequest struct {
+	Tag    string  `json:"tag" binding:"required"`
+	Parent *string `json:"parent"` // null 表示移除上级
+}
+
+// SetTagParent 设置或移除标签的上级标签
+func (h *Handler) SetTagParent(c *gin.Context) {
+	var req SetTagParentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+
+	if err := h.repo.SetTagParent(c.Request.Context(), req.Tag, req.Parent); err != nil {
+		h.renderTagError(c, "SetTagParent", err)
+		return
+	}
+	h.tagStatsCache.clear()
+
+	c.JSON(http.StatusOK, gin.H{"message": "parent updated"})
+}
+
+// renderTagError 返回标签管理的错误，领域错误 (标签不存在、冲突、不合法的操作) 直接返回，其他错误记录日志后返回 500
+func (h *Handler) renderTagError(c *gin.Context, op string, err error) {
+	switch {
+	case isDomainError(err):
+		renderError(c, err)
+	default:
+		slog.ErrorContext(c.Request.Context(), op+" error", "error", err)
+		renderError(c, errInternal("failed to update tags"))
+	}
+}
+
+// workspaceCacheKey 为标签统计缓存的 key 加上当前工作区，不同工作区的结果分开缓存
+func workspaceCacheKey(c *gin.Context, key string) string {
+	return fmt.Sprintf("%d:%s", postgres.WorkspaceFrom(c.Request.Context()), key)
+}
+
+// GetTagStats 返回每个标签的文章数、出现次数和最新文章时间
+func (h *Handler) GetTagStats(c *gin.Context) {
+	cacheKey := workspaceCacheKey(c, "stats")
+	if cached, ok := h.tagStatsCache.get(cacheKey); ok {
+		c.JSON(http.StatusOK, gin.H{"data": cached, "cached": true})
+		return
+	}
+
+	stats, err := h.repo.GetTagStats(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "GetTagStats error", "error", err)
+		renderError(c, errInternal("failed to get tag stats"))
+		return
+	}
+	h.tagStatsCache.set(cacheKey, stats)
+
+	c.JSON(http.StatusOK, gin.H{"data": stats, "cached": false})
+}
+
+// TagCooccurrenceRequest 标签共现矩阵请求参数
+type TagCooccurrenceRequest struct {
+	Tags     []string `form:"tags[]"`                                  // 指定矩阵中的标签，为空时取文章数最多的 limit 个
+	Limit    int      `form:"limit" binding:"omitempty,min=2,max=100"` // 默认 20
+	MinCount int64    `form:"min_count" binding:"omitempty,min=1"`     // 只计入同时出现次数不少于该值的组合
+}
+
+// TagCooccurrenceResponse 标签共现矩阵
+// Matrix[i][j] 为同时带有 Tags[i] 和 Tags[j] 的文章数，对角线为 Tags[i] 的文章数
+type TagCooccurrenceResponse struct {
+	Tags   []string           `json:"tags"`
+	Matrix [][]int64          `json:"matrix"`
+	Pairs  []postgres.TagPair `json:"pairs"`
+}
+
+// GetTagCooccurrence 返回标签共现矩阵
+func (h *Handler) GetTagCooccurrence(c *gin.Context) {
+	var req TagCooccurrenceRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	tags, err := h.repo.CanonicalizeTags(c.Request.Context(), req.Tags)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "CanonicalizeTags error", "error", err)
+		renderError(c, errInternal("failed to get tag co-occurrence"))
+		return
+	}
+
+	cacheKey := workspaceCacheKey(c, fmt.Sprintf("cooccurrence:%d:%d:%s", req.Limit, req.MinCount, strings.Join(tags, "\x00")))
+	if cached, ok := h.tagStatsCache.get(cacheKey); ok {
+		c.JSON(http.StatusOK, gin.H{"data": cached, "cached": true})
+		return
+	}
+
+	stats, err := h.repo.GetTagStats(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "GetTagStats error", "error", err)
+		renderError(c, errInternal("failed to get tag co-occurrence"))
+		return
+	}
+	articleCount := make(map[string]int64, len(stats))
+	for _, s := range stats {
+		articleCount[s.Name] = s.ArticleCount
+	}
+	if len(tags) == 0 {
+		for _, s := range stats[:min(req.Limit, len(stats))] {
+			tags = append(tags, s.Name)
+		}
+	}
+
+	pairs, err := h.repo.GetTagCooccurrence(c.Request.Context(), tags, req.MinCount)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "GetTagCooccurrence error", "error", err)
+		renderError(c, errInternal("failed to get tag co-occurrence"))
+		return
+	}
+
+	index := make(map[string]int, len(tags))
+	matrix := make([][]int64, len(tags))
+	for i, tag := range tags {
+		index[tag] = i
+		matrix[i] = make([]int64, len(tags))
+		matrix[i][i] = articleCount[tag]
+	}
+	for _, p := range pairs {
+		i, j := index[p.A], index[p.B]
+		matrix[i][j] = p.Count
+		matrix[j][i] = p.Count
+	}
+
+	response := TagCooccurrenceResponse{Tags: tags, Matrix: matrix, Pairs: pairs}
+	if response.Tags == nil {
+		response.Tags = []string{}
+	}
+	h.tagStatsCache.set(cacheKey, response)
+
+	c.JSON(http.StatusOK, gin.H{"data": response, "cached": false})
+}
+
+// TagGraphRequest 标签图请求参数
+type TagGraphRequest struct {
+	Limit     int   `form:"limit" binding:"omitempty,min=1,max=500"` // 节点数，默认 50
+	MinWeight int64 `form:"min_weight" binding:"omitempty,min=1"`    // 共现边的最小权重，默认 2
+}
+
+// GetTagGraph 返回用于可视化的标签图 (节点 + 共现边 + 层级边)
+func (h *Handler) GetTagGraph(c *gin.Context) {
+	var req TagGraphRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 50
+	}
+	if req.MinWeight == 0 {
+		req.MinWeight = 2
+	}
+
+	cacheKey := workspaceCacheKey(c, fmt.Sprintf("graph:%d:%d", req.Limit, req.MinWeight))
+	if cached, ok := h.tagStatsCache.get(cacheKey); ok {
+		c.JSON(http.StatusOK, gin.H{"data": cached, "cached": true})
+		return
+	}
+
+	graph, err := h.repo.GetTagGraph(c.Request.Context(), req.Limit, req.MinWeight)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "GetTagGraph error", "error", err)
+		renderError(c, errInternal("failed to get tag graph"))
+		return
+	}
+	h.tagStatsCache.set(cacheKey, graph)
+
+	c.JSON(http.StatusOK, gin.H{"data": graph, "cached": false})
+}
@@ -0,0 +1,435 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"paguu/internal/auth"
+	"paguu/internal/storage/postgres"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// UserResponse 用户响应结构
+type UserResponse struct {
+	ID         uint    `json:"id"`
+	Name       string  `json:"name"`
+	CreatedAt  string  `json:"created_at"`
+	DisabledAt *string `json:"disabled_at,omitempty"`
+}
+
+// toUserResponse 将用户转换为响应结构
+func toUserResponse(user postgres.User) UserResponse {
+	response := UserResponse{
+		ID:        user.ID,
+		Name:      user.Name,
+		CreatedAt: user.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if user.DisabledAt != nil {
+		disabledAt := user.DisabledAt.Format("2006-01-02 15:04:05")
+		response.DisabledAt = &disabledAt
+	}
+	return response
+}
+
+// APIKeyResponse API key 响应结构，不包含明文和哈希
+type APIKeyResponse struct {
+	ID         uint           `json:"id"`
+	UserID     uint           `json:"user_id"`
+	Name       string         `json:"name,omitempty"`
+	Prefix     string         `json:"prefix"`
+	Scopes     pq.StringArray `json:"scopes"`
+	ExpiresAt  *string        `json:"expires_at,omitempty"`
+	LastUsedAt *string        `json:"last_used_at,omitempty"`
+	RevokedAt  *string        `json:"revoked_at,omitempty"`
+	CreatedAt  string         `json:"created_at"`
+}
+
+// toAPIKeyResponse 将 API key 转换为响应结构
+func toAPIKeyResponse(key postgres.APIKey) APIKeyResponse {
+	format := func(t *time.Time) *string {
+		if t == nil {
+			return nil
+		}
+		s := t.Format("2006-01-02 15:04:05")
+		return &s
+	}
+	return APIKeyResponse{
+		ID:         key.ID,
+		UserID:     key.UserID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  format(key.ExpiresAt),
+		LastUsedAt: format(key.LastUsedAt),
+		RevokedAt:  format(key.RevokedAt),
+		CreatedAt:  key.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// GetMe 返回当前调用者及其权限范围
+func (h *Handler) GetMe(c *gin.Context) {
+	p := principalFrom(c)
+	if p == nil {
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"authenticated": false}})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"authenticated": true,
+			"user_id":       p.UserID,
+			"user":          p.UserName,
+			"key_id":        p.KeyID,
+			"scopes":        p.Scopes,
+			"via_token":     p.FromToken,
+		},
+	})
+}
+
+// GetMyQuota 返回调用者当天的任务和问题配额用量
+func (h *Handler) GetMyQuota(c *gin.Context) {
+	if h.limiter == nil || !h.limiter.QuotaEnabled() {
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"enabled": false}})
+		return
+	}
+	if p := principalFrom(c); p != nil && p.Has(auth.ScopeAdmin) {
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"enabled": false, "exempt": true}})
+		return
+	}
+
+	status, err := h.limiter.QuotaStatus(c.Request.Context(), quotaSubject(c))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "QuotaStatus error", "error", err)
+		renderError(c, errInternal("failed to get quota"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": toQuotaResponse(status)})
+}
+
+// AuthTokenResponse 换取 JWT 响应
+type AuthTokenResponse struct {
+	Token     string `json:"token"`
+	TokenType string `json:"token_type"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// IssueAuthToken 用 API key 换取短期 JWT，权限范围与该 key 相同
+func (h *Handler) IssueAuthToken(c *gin.Context) {
+	p := principalFrom(c)
+	if h.authenticator == nil || p == nil {
+		renderError(c, errNotConfigured("authentication is not enabled"))
+		return
+	}
+
+	token, expiresAt, err := h.authenticator.IssueToken(p)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrJWTDisabled), errors.Is(err, auth.ErrTokenRenewal):
+			renderError(c, err)
+		default:
+			slog.ErrorContext(c.Request.Context(), "IssueToken error", "error", err, "user", p.UserName)
+			renderError(c, errInternal("failed to issue token"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": AuthTokenResponse{
+			Token:     token,
+			TokenType: "Bearer",
+			ExpiresAt: expiresAt.Format("2006-01-02 15:04:05"),
+		},
+	})
+}
+
+// ListUsers 列出所有用户
+func (h *Handler) ListUsers(c *gin.Context) {
+	users, err := h.repo.ListUsers(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListUsers error", "error", err)
+		renderError(c, errInternal("failed to list users"))
+		return
+	}
+
+	responses := make([]UserResponse, len(users))
+	for i, u := range users {
+		responses[i] = toUserResponse(u)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+// CreateUserRequest 创建用户请求参数
+type CreateUserRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// CreateUser 创建用户，用户名与练习、复习、面试等接口中的 user 对应
+func (h *Handler) CreateUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		renderError(c, errBadRequest("name is empty"))
+		return
+	}
+
+	user, err := h.repo.CreateUser(c.Request.Context(), name)
+	if err != nil {
+		if errors.Is(err, postgres.ErrUserExists) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "CreateUser error", "error", err, "name", name)
+		renderError(c, errInternal("failed to create user"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": toUserResponse(*user)})
+}
+
+// DisableUser 停用用户，其所有 API key 立即失效
+func (h *Handler) DisableUser(c *gin.Context) {
+	h.setUserDisabled(c, true)
+}
+
+// EnableUser 重新启用用户
+func (h *Handler) EnableUser(c *gin.Context) {
+	h.setUserDisabled(c, false)
+}
+
+func (h *Handler) setUserDisabled(c *gin.Context, disabled bool) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid user id"))
+		return
+	}
+	if p := principalFrom(c); disabled && p != nil && p.UserID == uint(id) {
+		renderError(c, errBadRequest("cannot disable yourself"))
+		return
+	}
+
+	user, err := h.repo.SetUserDisabled(c.Request.Context(), uint(id), disabled)
+	if err != nil {
+		if errors.Is(err, postgres.ErrUserNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "SetUserDisabled error", "error", err, "id", id)
+		renderError(c, errInternal("failed to update user"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toUserResponse(*user)})
+}
+
+// ListAPIKeys 列出用户的 API key (不含明文)
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid user id"))
+		return
+	}
+
+	keys, err := h.repo.ListAPIKeys(c.Request.Context(), uint(id))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListAPIKeys error", "error", err, "user_id", id)
+		renderError(c, errInternal("failed to list api keys"))
+		return
+	}
+
+	responses := make([]APIKeyResponse, len(keys))
+	for i, k := range keys {
+		responses[i] = toAPIKeyResponse(k)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+// CreateAPIKeyRequest 创建 API key 请求参数
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`                    // read/write/admin
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=3650"` // 默认不过期
+}
+
+// CreateAPIKeyResponse 创建 API key 响应，明文 key 只在此返回一次
+type CreateAPIKeyResponse struct {
+	Data    APIKeyResponse `json:"data"`
+	Key     string         `json:"key"`
+	Message string         `json:"message"`
+}
+
+// CreateAPIKey 为用户创建 API key，明文只在响应中返回一次
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid user id"))
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	scopes, err := auth.NormalizeScopes(req.Scopes)
+	if err != nil {
+		renderError(c, errBadRequest("invalid scopes").withDetail(err.Error()))
+		return
+	}
+
+	user, err := h.repo.GetUser(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, postgres.ErrUserNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "GetUser error", "error", err, "id", id)
+		renderError(c, errInternal("failed to create api key"))
+		return
+	}
+
+	plain, prefix, hash, err := auth.GenerateKey()
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "GenerateKey error", "error", err)
+		renderError(c, errInternal("failed to create api key"))
+		return
+	}
+	key := &postgres.APIKey{
+		UserID:  user.ID,
+		Name:    strings.TrimSpace(req.Name),
+		Prefix:  prefix,
+		KeyHash: hash,
+		Scopes:  scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := h.repo.CreateAPIKey(c.Request.Context(), key); err != nil {
+		slog.ErrorContext(c.Request.Context(), "CreateAPIKey error", "error", err, "user_id", user.ID)
+		renderError(c, errInternal("failed to create api key"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{
+		Data:    toAPIKeyResponse(*key),
+		Key:     plain,
+		Message: "store this key now, it will not be shown again",
+	})
+}
+
+// RevokeAPIKey 吊销 API key，本人可以吊销自己的 key，admin 可以吊销任意 key
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		renderError(c, errBadRequest("invalid key id"))
+		return
+	}
+
+	if p := principalFrom(c); p != nil && !p.Has(auth.ScopeAdmin) {
+		keys, err := h.repo.ListAPIKeys(c.Request.Context(), p.UserID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "ListAPIKeys error", "error", err, "user_id", p.UserID)
+			renderError(c, errInternal("failed to revoke api key"))
+			return
+		}
+		if !slices.ContainsFunc(keys, func(k postgres.APIKey) bool { return k.ID == uint(id) }) {
+			renderError(c, postgres.ErrAPIKeyNotFound)
+			return
+		}
+	}
+
+	key, err := h.repo.RevokeAPIKey(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, postgres.ErrAPIKeyNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "RevokeAPIKey error", "error", err, "id", id)
+		renderError(c, errInternal("failed to revoke api key"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toAPIKeyResponse(*key)})
+}
+
+// ListAuditLogsRequest 审计日志请求参数
+type ListAuditLogsRequest struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	User     string `form:"user"`
+	Route    string `form:"route"` // 路由模板，例如 /api/v1/articles/:id/merge
+	Days     int    `form:"days" binding:"omitempty,min=1,max=3650"`
+}
+
+// AuditLogResponse 审计日志响应
+type AuditLogResponse struct {
+	ID        uint   `json:"id"`
+	User      string `json:"user"`
+	KeyID     *uint  `json:"key_id"`
+	Method    string `json:"method"`
+	Route     string `json:"route"`
+	Path      string `json:"path"`
+	Status    int    `json:"status"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ListAuditLogs 按时间倒序列出写请求的审计日志
+func (h *Handler) ListAuditLogs(c *gin.Context) {
+	var req ListAuditLogsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	filter := postgres.AuditLogFilter{UserName: req.User, Route: req.Route}
+	if req.Days > 0 {
+		since := time.Now().AddDate(0, 0, -req.Days)
+		filter.Since = &since
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	logs, total, err := h.repo.ListAuditLogs(c.Request.Context(), filter, req.PageSize, offset)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListAuditLogs error", "error", err)
+		renderError(c, errInternal("failed to list audit logs"))
+		return
+	}
+
+	responses := make([]AuditLogResponse, len(logs))
+	for i, l := range logs {
+		responses[i] = AuditLogResponse{
+			ID:        l.ID,
+			User:      l.UserName,
+			KeyID:     l.KeyID,
+			Method:    l.Method,
+			Route:     l.Route,
+			Path:      l.Path,
+			Status:    l.Status,
+			CreatedAt: l.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       responses,
+		"pagination": newPagination(req.Page, req.PageSize, total),
+	})
+}
@@ -0,0 +1,337 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"paguu/internal/auth"
+	"paguu/internal/enrich"
+	"paguu/internal/storage/postgres"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// workspaceSlugPattern 是工作区标识的格式：小写字母、数字和 '-'，以字母或数字开头
+var workspaceSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// WorkspaceResponse 工作区响应
+type WorkspaceResponse struct {
+	ID                 uint    `json:"id"`
+	Slug               string  `json:"slug"`
+	Name               string  `json:"name"`
+	EnrichModel        *string `json:"enrich_model,omitempty"`         // 为空时使用全局配置
+	EnrichTemplatePath *string `json:"enrich_template_path,omitempty"` // 为空时使用全局配置
+	Role               string  `json:"role,omitempty"`                 // 调用者在工作区中的角色
+	CreatedAt          string  `json:"created_at"`
+}
+
+func toWorkspaceResponse(workspace postgres.Workspace, role string) WorkspaceResponse {
+	return WorkspaceResponse{
+		ID:                 workspace.ID,
+		Slug:               workspace.Slug,
+		Name:               workspace.Name,
+		EnrichModel:        workspace.EnrichModel,
+		EnrichTemplatePath: workspace.EnrichTemplatePath,
+		Role:               role,
+		CreatedAt:          workspace.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// WorkspaceMemberResponse 工作区成员响应
+type WorkspaceMemberResponse struct {
+	UserID    uint   `json:"user_id"`
+	User      string `json:"user"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ListWorkspaces 列出调用者所属的工作区，未启用鉴权或调用者有 admin 权限时列出全部
+func (h *Handler) ListWorkspaces(c *gin.Context) {
+	var userID *uint
+	if p := principalFrom(c); p != nil && !p.Has(auth.ScopeAdmin) {
+		userID = &p.UserID
+	}
+
+	workspaces, err := h.repo.ListWorkspaces(c.Request.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListWorkspaces error", "error", err)
+		renderError(c, errInternal("failed to list workspaces"))
+		return
+	}
+
+	responses := make([]WorkspaceResponse, len(workspaces))
+	for i, w := range workspaces {
+		responses[i] = toWorkspaceResponse(w.Workspace, w.Role)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+// CreateWorkspaceRequest 创建工作区请求
+type CreateWorkspaceRequest struct {
+	Slug               string  `json:"slug" binding:"required"`
+	Name               string  `json:"name" binding:"max=100"` // 默认与 slug 相同
+	EnrichModel        *string `json:"enrich_model"`
+	EnrichTemplatePath *string `json:"enrich_template_path"`
+}
+
+// CreateWorkspace 创建工作区，调用者成为 owner
+func (h *Handler) CreateWorkspace(c *gin.Context) {
+	var req CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	slug := strings.TrimSpace(req.Slug)
+	if !workspaceSlugPattern.MatchString(slug) {
+		renderError(c, errBadRequest("slug must be lowercase letters, digits or '-'"))
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = slug
+	}
+	if !h.validWorkspaceTemplate(c, req.EnrichTemplatePath) {
+		return
+	}
+
+	workspace := &postgres.Workspace{
+		Slug:               slug,
+		Name:               name,
+		EnrichModel:        trimmedOrNil(req.EnrichModel),
+		EnrichTemplatePath: trimmedOrNil(req.EnrichTemplatePath),
+	}
+	var ownerID *uint
+	role := ""
+	if p := principalFrom(c); p != nil {
+		ownerID = &p.UserID
+		role = postgres.WorkspaceRoleOwner
+	}
+	if err := h.repo.CreateWorkspace(c.Request.Context(), workspace, ownerID); err != nil {
+		if errors.Is(err, postgres.ErrWorkspaceExists) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "CreateWorkspace error", "error", err, "slug", slug)
+		renderError(c, errInternal("failed to create workspace"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": toWorkspaceResponse(*workspace, role)})
+}
+
+// GetWorkspace 返回工作区及调用者的角色，需要是成员
+func (h *Handler) GetWorkspace(c *gin.Context) {
+	workspace, role, ok := h.workspaceParam(c, false)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": toWorkspaceResponse(*workspace, role)})
+}
+
+// UpdateWorkspaceRequest 修改工作区请求，省略的字段不修改；模型和模板传空字符串表示恢复全局配置
+type UpdateWorkspaceRequest struct {
+	Name               *string `json:"name" binding:"omitempty,max=100"`
+	EnrichModel        *string `json:"enrich_model"`
+	EnrichTemplatePath *string `json:"enrich_template_path"`
+}
+
+// UpdateWorkspace 修改工作区名称和丰富化模型/模板，新任务立即生效
+func (h *Handler) UpdateWorkspace(c *gin.Context) {
+	var req UpdateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		renderError(c, errBadRequest("name is empty"))
+		return
+	}
+	if !h.validWorkspaceTemplate(c, req.EnrichTemplatePath) {
+		return
+	}
+	workspace, role, ok := h.workspaceParam(c, false)
+	if !ok {
+		return
+	}
+
+	updated, err := h.repo.UpdateWorkspace(c.Request.Context(), workspace.ID, postgres.WorkspaceUpdate{
+		Name:               req.Name,
+		EnrichModel:        req.EnrichModel,
+		EnrichTemplatePath: req.EnrichTemplatePath,
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "UpdateWorkspace error", "error", err, "workspace", workspace.Slug)
+		renderError(c, errInternal("failed to update workspace"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toWorkspaceResponse(*updated, role)})
+}
+
+// ListWorkspaceMembers 列出工作区成员，需要是成员
+func (h *Handler) ListWorkspaceMembers(c *gin.Context) {
+	workspace, _, ok := h.workspaceParam(c, false)
+	if !ok {
+		return
+	}
+
+	members, err := h.repo.ListWorkspaceMembers(c.Request.Context(), workspace.ID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "ListWorkspaceMembers error", "error", err, "workspace", workspace.Slug)
+		renderError(c, errInternal("failed to list workspace members"))
+		return
+	}
+
+	responses := make([]WorkspaceMemberResponse, len(members))
+	for i, m := range members {
+		responses[i] = WorkspaceMemberResponse{
+			UserID:    m.UserID,
+			User:      m.UserName,
+			Role:      m.Role,
+			CreatedAt: m.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+// SetWorkspaceMemberRequest 添加成员或修改角色请求
+type SetWorkspaceMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=viewer editor owner"`
+}
+
+// SetWorkspaceMember 添加成员或修改成员角色，需要是 owner
+func (h *Handler) SetWorkspaceMember(c *gin.Context) {
+	var req SetWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, bindError(err))
+		return
+	}
+	workspace, _, ok := h.workspaceParam(c, true)
+	if !ok {
+		return
+	}
+	user, ok := h.workspaceMemberParam(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.repo.SetWorkspaceMember(c.Request.Context(), workspace.ID, user.ID, req.Role)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "SetWorkspaceMember error", "error", err, "workspace", workspace.Slug, "user", user.Name)
+		renderError(c, errInternal("failed to set workspace member"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": WorkspaceMemberResponse{
+		UserID:    member.UserID,
+		User:      user.Name,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt.Format("2006-01-02 15:04:05"),
+	}})
+}
+
+// RemoveWorkspaceMember 将用户移出工作区，需要是 owner
+func (h *Handler) RemoveWorkspaceMember(c *gin.Context) {
+	workspace, _, ok := h.workspaceParam(c, true)
+	if !ok {
+		return
+	}
+	user, ok := h.workspaceMemberParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.RemoveWorkspaceMember(c.Request.Context(), workspace.ID, user.ID); err != nil {
+		if errors.Is(err, postgres.ErrMemberNotFound) {
+			renderError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "RemoveWorkspaceMember error", "error", err, "workspace", workspace.Slug, "user", user.Name)
+		renderError(c, errInternal("failed to remove workspace member"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member removed"})
+}
+
+// workspaceParam 按路径中的 :slug 加载工作区并返回调用者的角色，needOwner 为 true 时要求 owner
+// 返回 false 时已写入错误响应
+func (h *Handler) workspaceParam(c *gin.Context, needOwner bool) (*postgres.Workspace, string, bool) {
+	workspace, err := h.repo.GetWorkspaceBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		if errors.Is(err, postgres.ErrWorkspaceNotFound) {
+			renderError(c, err)
+			return nil, "", false
+		}
+		slog.ErrorContext(c.Request.Context(), "GetWorkspaceBySlug error", "error", err, "slug", c.Param("slug"))
+		renderError(c, errInternal("failed to get workspace"))
+		return nil, "", false
+	}
+
+	role, ok := workspaceRole(c, h.repo, workspace.ID)
+	if !ok {
+		return nil, "", false
+	}
+	if needOwner && role != postgres.WorkspaceRoleOwner {
+		renderError(c, errForbidden("workspace owner role required"))
+		return nil, "", false
+	}
+	return workspace, role, true
+}
+
+// workspaceMemberParam 按路径中的 :user 加载用户，不允许修改自己的成员关系
+// 返回 false 时已写入错误响应
+func (h *Handler) workspaceMemberParam(c *gin.Context) (*postgres.User, bool) {
+	name := strings.TrimSpace(c.Param("user"))
+	if p := principalFrom(c); p != nil && p.UserName == name {
+		renderError(c, errBadRequest("cannot change your own membership"))
+		return nil, false
+	}
+
+	user, err := h.repo.GetUserByName(c.Request.Context(), name)
+	if err != nil {
+		if errors.Is(err, postgres.ErrUserNotFound) {
+			renderError(c, err)
+			return nil, false
+		}
+		slog.ErrorContext(c.Request.Context(), "GetUserByName error", "error", err, "user", name)
+		renderError(c, errInternal("failed to get user"))
+		return nil, false
+	}
+	return user, true
+}
+
+// validWorkspaceTemplate 检查工作区的丰富化模板是模板目录下的文件名且能够加载，空值表示使用全局配置
+// 不接受路径，避免通过 API 读取服务器上的任意文件；加载失败的原因只记录日志，不返回给调用者
+// 返回 false 时已写入 400 响应
+func (h *Handler) validWorkspaceTemplate(c *gin.Context, templatePath *string) bool {
+	if templatePath == nil || strings.TrimSpace(*templatePath) == "" {
+		return true
+	}
+	name := strings.TrimSpace(*templatePath)
+	if h.enrichTemplateDir == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		renderError(c, errBadRequest("enrich template must be a file name in the templates directory"))
+		return false
+	}
+	if err := enrich.ValidateTemplate(filepath.Join(h.enrichTemplateDir, name)); err != nil {
+		slog.WarnContext(c.Request.Context(), "invalid workspace enrich template", "template", name, "error", err)
+		renderError(c, errBadRequest("invalid enrich template"))
+		return false
+	}
+	return true
+}
+
+// trimmedOrNil 去掉首尾空白，结果为空时返回 nil
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*s)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
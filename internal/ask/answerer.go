@@ -0,0 +1,219 @@
+package ask
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"paguu/internal/enrich"
+	"paguu/internal/usage"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime"
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model/responses"
+)
+
+// Source 是回答所依据的一篇文章
+type Source struct {
+	ID         uint
+	Question   string
+	Answer     string
+	Tags       []string
+	Similarity float64
+}
+
+// Answer 是 LLM 基于检索结果给出的回答
+type Answer struct {
+	Text      string
+	Citations []uint // 回答中引用的文章 ID，按首次引用顺序，只包含 sources 中的文章
+}
+
+// Answerer 通过丰富化所用的 Ark 模型，基于检索到的文章回答问题
+type Answerer struct {
+	client         *arkruntime.Client
+	modelName      string
+	promptTemplate *template.Template
+	recorder       usage.Recorder
+}
+
+func NewAnswerer(client *arkruntime.Client, templatePath string, modelName string) (*Answerer, error) {
+	if client == nil {
+		return nil, fmt.Errorf("ark client is required")
+	}
+	if modelName == "" {
+		return nil, fmt.Errorf("ask model name is required")
+	}
+
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(templatePath).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Answerer{
+		client:         client,
+		modelName:      modelName,
+		promptTemplate: tmpl,
+	}, nil
+}
+
+// SetUsageRecorder 设置用量记录器，为 nil 时不记录
+func (a *Answerer) SetUsageRecorder(recorder usage.Recorder) {
+	a.recorder = recorder
+}
+
+// Answer 一次性生成回答
+func (a *Answerer) Answer(ctx context.Context, question string, sources []Source) (*Answer, error) {
+	prompt, err := a.buildPrompt(question, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	resp, err := a.client.CreateResponses(ctx, &responses.ResponsesRequest{
+		Model: a.modelName,
+		Input: &responses.ResponsesInput{Union: &responses.ResponsesInput_StringValue{StringValue: prompt}},
+	})
+	var respUsage *responses.Usage
+	if resp != nil {
+		respUsage = resp.Usage
+	}
+	a.recordUsage(ctx, respUsage, prompt, "", time.Since(startedAt), err)
+	if err != nil {
+		return nil, fmt.Errorf("ask request error: %w", err)
+	}
+
+	text := strings.TrimSpace(enrich.ExtractResponseText(resp))
+	if text == "" {
+		return nil, fmt.Errorf("empty ask response text")
+	}
+	return &Answer{Text: text, Citations: extractCitations(text, sources)}, nil
+}
+
+// Stream 流式生成回答，每收到一段文本调用一次 onDelta
+// onDelta 返回错误时 (例如客户端断开) 停止生成并返回该错误
+func (a *Answerer) Stream(ctx context.Context, question string, sources []Source, onDelta func(delta string) error) (*Answer, error) {
+	prompt, err := a.buildPrompt(question, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	stream, err := a.client.CreateResponsesStream(ctx, &responses.ResponsesRequest{
+		Model: a.modelName,
+		Input: &responses.ResponsesInput{Union: &responses.ResponsesInput_StringValue{StringValue: prompt}},
+	})
+	if err != nil {
+		a.recordUsage(ctx, nil, prompt, "", time.Since(startedAt), err)
+		return nil, fmt.Errorf("ask stream request error: %w", err)
+	}
+	defer stream.Close()
+
+	var text strings.Builder
+	var respUsage *responses.Usage
+	err = func() error {
+		for {
+			event, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("ask stream receive error: %w", err)
+			}
+
+			if e := event.GetError(); e != nil {
+				return fmt.Errorf("ask stream error: %s", e.Message)
+			}
+			if t := event.GetText(); t != nil && t.Type == responses.EventType_response_output_text_delta && t.Delta != nil {
+				text.WriteString(*t.Delta)
+				if err := onDelta(*t.Delta); err != nil {
+					return err
+				}
+			}
+			if r := event.GetResponse(); r != nil && r.Type == responses.EventType_response_completed && r.Response != nil {
+				respUsage = r.Response.Usage
+			}
+		}
+	}()
+	a.recordUsage(ctx, respUsage, prompt, text.String(), time.Since(startedAt), err)
+	if err != nil {
+		return nil, err
+	}
+
+	answer := strings.TrimSpace(text.String())
+	if answer == "" {
+		return nil, fmt.Errorf("empty ask response text")
+	}
+	return &Answer{Text: answer, Citations: extractCitations(answer, sources)}, nil
+}
+
+func (a *Answerer) buildPrompt(question string, sources []Source) (string, error) {
+	var buf bytes.Buffer
+	err := a.promptTemplate.Execute(&buf, map[string]interface{}{
+		"Question": question,
+		"Sources":  sources,
+	})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// citationPattern 匹配回答中的引用标记，例如 [#123]
+var citationPattern = regexp.MustCompile(`\[#(\d+)\]`)
+
+// extractCitations 提取回答中引用的文章 ID，忽略不在 sources 中的 ID (模型编造的引用)
+func extractCitations(text string, sources []Source) []uint {
+	known := make(map[uint]bool, len(sources))
+	for _, s := range sources {
+		known[s.ID] = true
+	}
+
+	citations := make([]uint, 0)
+	seen := make(map[uint]bool)
+	for _, match := range citationPattern.FindAllStringSubmatch(text, -1) {
+		id, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil {
+			continue
+		}
+		if known[uint(id)] && !seen[uint(id)] {
+			seen[uint(id)] = true
+			citations = append(citations, uint(id))
+		}
+	}
+	return citations
+}
+
+// recordUsage 记录一次问答调用的用量，流式响应未返回用量时按文本长度估算
+func (a *Answerer) recordUsage(ctx context.Context, respUsage *responses.Usage, prompt, output string, latency time.Duration, err error) {
+	if a.recorder == nil {
+		return
+	}
+	event := usage.Event{
+		Provider:  usage.ProviderArk,
+		Operation: usage.OperationAsk,
+		Model:     a.modelName,
+		Latency:   latency,
+		Err:       err,
+	}
+	if respUsage != nil {
+		event.PromptTokens = respUsage.InputTokens
+		event.CompletionTokens = respUsage.OutputTokens
+		event.TotalTokens = respUsage.TotalTokens
+	} else if output != "" {
+		event.PromptTokens = usage.EstimateTokens(prompt)
+		event.CompletionTokens = usage.EstimateTokens(output)
+		event.TotalTokens = event.PromptTokens + event.CompletionTokens
+		event.Estimated = true
+	}
+	a.recorder.Record(ctx, event)
+}
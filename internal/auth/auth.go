@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"paguu/internal/storage/postgres"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 权限范围：admin 包含 write，write 包含 read
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+// scopeRank 是权限范围的包含关系
+var scopeRank = map[string]int{
+	ScopeRead:  1,
+	ScopeWrite: 2,
+	ScopeAdmin: 3,
+}
+
+// ValidScope 判断权限范围是否合法
+func ValidScope(scope string) bool {
+	_, ok := scopeRank[scope]
+	return ok
+}
+
+// roleScope 是工作区角色允许的最高权限范围；owner 额外可以管理成员，admin 只能来自 API key
+var roleScope = map[string]string{
+	postgres.WorkspaceRoleViewer: ScopeRead,
+	postgres.WorkspaceRoleEditor: ScopeWrite,
+	postgres.WorkspaceRoleOwner:  ScopeWrite,
+}
+
+// RoleAllows 判断工作区角色是否允许某个权限范围
+// 调用者在工作区中的实际权限是 API key 权限范围与角色的交集
+func RoleAllows(role, scope string) bool {
+	allowed, ok := roleScope[role]
+	return ok && scopeRank[allowed] >= scopeRank[scope]
+}
+
+var (
+	// ErrUnauthorized 表示凭证缺失、无效、已吊销或已过期
+	ErrUnauthorized = errors.New("invalid or expired credentials")
+	// ErrJWTDisabled 表示未配置 JWT 签名密钥
+	ErrJWTDisabled = errors.New("jwt sessions are not enabled")
+	// ErrTokenRenewal 表示试图用 JWT 换取新的 JWT
+	ErrTokenRenewal = errors.New("tokens can only be issued with an api key")
+)
+
+// keyPrefix 是 API key 明文的固定前缀，便于在日志和代码中识别泄露的 key
+const keyPrefix = "pg_"
+
+// Principal 是通过鉴权的调用者
+type Principal struct {
+	UserID    uint
+	UserName  string
+	KeyID     uint
+	Scopes    []string
+	FromToken bool // 通过 JWT 而不是 API key 鉴权
+}
+
+// Has 判断调用者是否拥有某个权限范围 (包括被更高的权限范围包含)
+func (p *Principal) Has(scope string) bool {
+	need := scopeRank[scope]
+	for _, s := range p.Scopes {
+		if scopeRank[s] >= need {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateKey 生成一个新的 API key，返回明文、用于辨认的前缀和哈希
+func GenerateKey() (key, prefix, hash string, err error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key = keyPrefix + hex.EncodeToString(buf)
+	return key, key[:len(keyPrefix)+8], HashKey(key), nil
+}
+
+// HashKey 计算 API key 的 SHA-256 哈希
+// key 为高熵随机串，不需要 bcrypt 之类的慢哈希
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeScopes 校验并去重权限范围，按 read/write/admin 的顺序返回
+func NormalizeScopes(scopes []string) ([]string, error) {
+	result := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if !ValidScope(s) {
+			return nil, fmt.Errorf("unknown scope %q", s)
+		}
+		if !slices.Contains(result, s) {
+			result = append(result, s)
+		}
+	}
+	slices.SortFunc(result, func(a, b string) int {
+		return scopeRank[a] - scopeRank[b]
+	})
+	return result, nil
+}
+
+// KeyStore 是鉴权所需的存储接口，由 *postgres.Repository 实现
+type KeyStore interface {
+	FindActiveAPIKey(ctx context.Context, keyHash string) (*postgres.ActiveAPIKey, error)
+	GetActiveAPIKey(ctx context.Context, id uint) (*postgres.ActiveAPIKey, error)
+	TouchAPIKey(ctx context.Context, id uint) error
+}
+
+// Authenticator 校验 API key 和由其签发的 JWT
+type Authenticator struct {
+	store     KeyStore
+	jwtSecret []byte
+	jwtTTL    time.Duration
+}
+
+func NewAuthenticator(store KeyStore) *Authenticator {
+	return &Authenticator{store: store}
+}
+
+// defaultJWTTTL 是 JWT 的默认有效期
+const defaultJWTTTL = time.Hour
+
+// SetJWT 设置 JWT 签名密钥和有效期，secret 为空时不支持 JWT，ttl 为 0 时使用默认 1 小时
+func (a *Authenticator) SetJWT(secret string, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = defaultJWTTTL
+	}
+	a.jwtSecret = []byte(secret)
+	a.jwtTTL = ttl
+}
+
+// JWTEnabled 判断是否支持 JWT
+func (a *Authenticator) JWTEnabled() bool {
+	return len(a.jwtSecret) > 0
+}
+
+// Authenticate 校验凭证：以 keyPrefix 开头的视为 API key，否则视为 JWT
+func (a *Authenticator) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	if credential == "" {
+		return nil, ErrUnauthorized
+	}
+	if !strings.HasPrefix(credential, keyPrefix) {
+		return a.parseToken(ctx, credential, time.Now())
+	}
+
+	active, err := a.store.FindActiveAPIKey(ctx, HashKey(credential))
+	if err != nil {
+		if errors.Is(err, postgres.ErrAPIKeyNotFound) {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	if err := a.store.TouchAPIKey(ctx, active.Key.ID); err != nil {
+		slog.Warn("更新 API key 使用时间失败", "error", err, "key_id", active.Key.ID)
+	}
+
+	return &Principal{
+		UserID:   active.User.ID,
+		UserName: active.User.Name,
+		KeyID:    active.Key.ID,
+		Scopes:   active.Key.Scopes,
+	}, nil
+}
+
+// IssueToken 为使用 API key 鉴权的调用者签发 JWT，权限范围与其 API key 相同
+// 不允许用 JWT 换取新的 JWT，否则吊销 API key 后可以无限续期
+func (a *Authenticator) IssueToken(p *Principal) (string, time.Time, error) {
+	if !a.JWTEnabled() {
+		return "", time.Time{}, ErrJWTDisabled
+	}
+	if p.FromToken {
+		return "", time.Time{}, ErrTokenRenewal
+	}
+	now := time.Now()
+	expiresAt := now.Add(a.jwtTTL)
+	token, err := a.signToken(claims{
+		Subject:  p.UserID,
+		Name:     p.UserName,
+		KeyID:    p.KeyID,
+		Scopes:   p.Scopes,
+		IssuedAt: now.Unix(),
+		Expires:  expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
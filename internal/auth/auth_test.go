@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"paguu/internal/storage/postgres"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeKeyStore 在内存中保存有效的 API key，吊销即从中删除
+type fakeKeyStore struct {
+	keys map[string]*postgres.ActiveAPIKey // key 为哈希
+}
+
+func (s *fakeKeyStore) FindActiveAPIKey(_ context.Context, keyHash string) (*postgres.ActiveAPIKey, error) {
+	if active, ok := s.keys[keyHash]; ok {
+		return active, nil
+	}
+	return nil, postgres.ErrAPIKeyNotFound
+}
+
+func (s *fakeKeyStore) GetActiveAPIKey(_ context.Context, id uint) (*postgres.ActiveAPIKey, error) {
+	for _, active := range s.keys {
+		if active.Key.ID == id {
+			return active, nil
+		}
+	}
+	return nil, postgres.ErrAPIKeyNotFound
+}
+
+func (s *fakeKeyStore) TouchAPIKey(context.Context, uint) error {
+	return nil
+}
+
+// newTestAuthenticator 创建带有一个 write 权限 key 的 Authenticator，返回 key 明文
+func newTestAuthenticator(t *testing.T) (*Authenticator, *fakeKeyStore, string) {
+	t.Helper()
+	key, _, hash, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	active := &postgres.ActiveAPIKey{
+		Key:  postgres.APIKey{ID: 8, UserID: 3, Scopes: []string{ScopeRead, ScopeWrite}},
+		User: postgres.User{ID: 3, Name: "alice"},
+	}
+	active.Key.KeyHash = hash
+	store := &fakeKeyStore{keys: map[string]*postgres.ActiveAPIKey{hash: active}}
+	a := NewAuthenticator(store)
+	a.SetJWT("secret", time.Hour)
+	return a, store, key
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	a, _, key := newTestAuthenticator(t)
+
+	tests := []struct {
+		name       string
+		credential string
+		wantErr    error
+	}{
+		{"valid key", key, nil},
+		{"unknown key", keyPrefix + strings.Repeat("0", 48), ErrUnauthorized},
+		{"empty credential", "", ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := a.Authenticate(context.Background(), tt.credential)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (p.UserName != "alice" || p.KeyID != 8 || p.FromToken) {
+				t.Errorf("principal = %+v", p)
+			}
+		})
+	}
+}
+
+func TestParseToken(t *testing.T) {
+	a, store, key := newTestAuthenticator(t)
+	now := time.Now()
+	p, err := a.Authenticate(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := a.IssueToken(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+
+	sign := func(c claims) string {
+		s, err := a.signToken(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	valid := claims{Subject: 3, Name: "alice", KeyID: 8, Scopes: []string{ScopeRead, ScopeWrite}, IssuedAt: now.Unix(), Expires: now.Add(time.Hour).Unix()}
+	escalated := valid
+	escalated.Scopes = []string{ScopeAdmin}
+	expired := valid
+	expired.Expires = now.Add(-time.Second).Unix()
+	otherUser := valid
+	otherUser.Subject = 4
+	otherKey := valid
+	otherKey.KeyID = 9
+
+	header := func(h string) string { return base64.RawURLEncoding.EncodeToString([]byte(h)) }
+	other := &Authenticator{store: store}
+	other.SetJWT("other secret", time.Hour)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"issued token", token, false},
+		{"expired", sign(expired), true},
+		{"tampered payload", parts[0] + "." + strings.Split(sign(escalated), ".")[1] + "." + parts[2], true},
+		{"bad signature", parts[0] + "." + parts[1] + ".invalid", true},
+		{"signed with another secret", func() string { s, _ := other.signToken(valid); return s }(), true},
+		{"alg none", header(`{"alg":"none","typ":"JWT"}`) + "." + parts[1] + ".", true},
+		{"alg HS512 header", header(`{"alg":"HS512","typ":"JWT"}`) + "." + parts[1] + "." + parts[2], true},
+		{"subject does not own key", sign(otherUser), true},
+		{"unknown key", sign(otherKey), true},
+		{"malformed", "not-a-jwt", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := a.parseToken(context.Background(), tt.token, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseToken error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnauthorized) {
+					t.Errorf("error = %v, want ErrUnauthorized", err)
+				}
+				return
+			}
+			if p.UserName != "alice" || !p.FromToken || !reflect.DeepEqual(p.Scopes, valid.Scopes) {
+				t.Errorf("principal = %+v", p)
+			}
+		})
+	}
+
+	// 吊销签发用的 key 后，由其签发的 JWT 随即失效
+	clear(store.keys)
+	if _, err := a.Authenticate(context.Background(), token); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("token of revoked key: error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestIssueToken(t *testing.T) {
+	a, _, _ := newTestAuthenticator(t)
+	disabled := NewAuthenticator(&fakeKeyStore{})
+
+	tests := []struct {
+		name    string
+		a       *Authenticator
+		p       *Principal
+		wantErr error
+	}{
+		{"api key caller", a, &Principal{UserID: 3, KeyID: 8}, nil},
+		{"token cannot renew itself", a, &Principal{UserID: 3, KeyID: 8, FromToken: true}, ErrTokenRenewal},
+		{"jwt disabled", disabled, &Principal{UserID: 3, KeyID: 8}, ErrJWTDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, expiresAt, err := tt.a.IssueToken(tt.p)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("IssueToken error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (token == "" || time.Until(expiresAt) <= 0) {
+				t.Errorf("token = %q, expires at %v", token, expiresAt)
+			}
+		})
+	}
+}
+
+func TestScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		role   string
+		scope  string
+		wantP  bool
+		wantR  bool
+	}{
+		{"read key reads", []string{ScopeRead}, postgres.WorkspaceRoleViewer, ScopeRead, true, true},
+		{"read key cannot write", []string{ScopeRead}, postgres.WorkspaceRoleEditor, ScopeWrite, false, true},
+		{"write includes read", []string{ScopeWrite}, postgres.WorkspaceRoleOwner, ScopeRead, true, true},
+		{"viewer cannot write", []string{ScopeWrite}, postgres.WorkspaceRoleViewer, ScopeWrite, true, false},
+		{"admin includes write", []string{ScopeAdmin}, postgres.WorkspaceRoleEditor, ScopeWrite, true, true},
+		{"roles never grant admin", []string{ScopeWrite}, postgres.WorkspaceRoleOwner, ScopeAdmin, false, false},
+		{"unknown role", []string{ScopeAdmin}, "guest", ScopeRead, true, false},
+		{"no scopes", nil, postgres.WorkspaceRoleViewer, ScopeRead, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Principal{Scopes: tt.scopes}
+			if got := p.Has(tt.scope); got != tt.wantP {
+				t.Errorf("Has(%q) = %v, want %v", tt.scope, got, tt.wantP)
+			}
+			if got := RoleAllows(tt.role, tt.scope); got != tt.wantR {
+				t.Errorf("RoleAllows(%q, %q) = %v, want %v", tt.role, tt.scope, got, tt.wantR)
+			}
+		})
+	}
+}
+
+func TestNormalizeScopes(t *testing.T) {
+	got, err := NormalizeScopes([]string{" Admin", "read", "admin"})
+	if err != nil || !reflect.DeepEqual(got, []string{ScopeRead, ScopeAdmin}) {
+		t.Errorf("NormalizeScopes = %v, %v", got, err)
+	}
+	if _, err := NormalizeScopes([]string{"root"}); err == nil {
+		t.Error("NormalizeScopes accepted unknown scope")
+	}
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"paguu/internal/storage/postgres"
+	"strings"
+	"time"
+)
+
+// jwtHeader 是固定的 HS256 头部
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// claims 是 JWT 的载荷
+type claims struct {
+	Subject  uint     `json:"sub"`
+	Name     string   `json:"name"`
+	KeyID    uint     `json:"kid"` // 签发时使用的 API key
+	Scopes   []string `json:"scopes"`
+	IssuedAt int64    `json:"iat"`
+	Expires  int64    `json:"exp"`
+}
+
+// signToken 使用 HS256 签发 JWT
+func (a *Authenticator) signToken(c claims) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal jwt claims: %w", err)
+	}
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + a.signature(unsigned), nil
+}
+
+// parseToken 校验 JWT 的签名和有效期，并确认签发它的 API key 仍然有效
+// 吊销 API key、key 过期或停用用户后，由其签发的 JWT 随即失效
+func (a *Authenticator) parseToken(ctx context.Context, token string, now time.Time) (*Principal, error) {
+	if !a.JWTEnabled() {
+		return nil, ErrUnauthorized
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, ErrUnauthorized
+	}
+	expected := a.signature(parts[0] + "." + parts[1])
+	if !hmac.Equal([]byte(parts[2]), []byte(expected)) {
+		return nil, ErrUnauthorized
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrUnauthorized
+	}
+	if now.Unix() >= c.Expires {
+		return nil, ErrUnauthorized
+	}
+
+	active, err := a.store.GetActiveAPIKey(ctx, c.KeyID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrAPIKeyNotFound) {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	if active.User.ID != c.Subject {
+		return nil, ErrUnauthorized
+	}
+
+	return &Principal{
+		UserID:    active.User.ID,
+		UserName:  active.User.Name,
+		KeyID:     active.Key.ID,
+		Scopes:    active.Key.Scopes,
+		FromToken: true,
+	}, nil
+}
+
+// signature 计算 HS256 签名
+func (a *Authenticator) signature(unsigned string) string {
+	mac := hmac.New(sha256.New, a.jwtSecret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
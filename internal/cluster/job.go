@@ -0,0 +1,192 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"paguu/internal/storage/postgres"
+	"sort"
+	"strings"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// Store 是聚类任务所需的存储接口，由 *postgres.Repository 实现
+type Store interface {
+	LoadArticleEmbeddings(ctx context.Context) ([]postgres.ArticleEmbedding, error)
+	SaveClusterRun(ctx context.Context, run *postgres.ClusterRun, clusters []postgres.Cluster, assignments []postgres.ClusterAssignment) error
+	PruneClusterRuns(ctx context.Context, keep int) (int64, error)
+}
+
+// Options 是一次聚类任务的参数
+type Options struct {
+	K       int   // 簇数，0 表示按文章数自动选择 (SuggestK)
+	MaxIter int   // k-means 最大迭代次数
+	Seed    int64 // 随机种子，相同的数据和种子得到相同的结果
+	Samples int   // 命名时提供给 LLM 的代表性问题数
+	TopTags int   // 每个簇记录的常见标签数
+	Keep    int   // 保留最近几次运行的结果
+}
+
+// Result 是一次聚类任务的摘要
+type Result struct {
+	Run      *postgres.ClusterRun
+	Clusters []postgres.Cluster
+	Unnamed  int   // 命名失败、使用标签作为名称的簇数
+	Pruned   int64 // 被清理的旧运行数
+}
+
+// Run 读取所有文章向量，执行 k-means，为每个簇命名并保存结果
+// namer 为 nil 时不调用 LLM，直接使用常见标签作为名称
+func Run(ctx context.Context, store Store, namer Namer, opts Options) (*Result, error) {
+	articles, err := store.LoadArticleEmbeddings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(articles) < 2 {
+		return nil, fmt.Errorf("need at least 2 articles with embeddings to cluster, got %d", len(articles))
+	}
+
+	k := opts.K
+	if k <= 0 {
+		k = SuggestK(len(articles))
+	}
+	k = min(k, len(articles))
+
+	vectors := make([][]float32, len(articles))
+	for i, a := range articles {
+		vectors[i] = a.Vector
+	}
+
+	slog.Info("开始聚类", "articles", len(articles), "k", k)
+	km, err := KMeans(vectors, k, opts.MaxIter, opts.Seed)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("聚类完成", "iterations", km.Iterations, "inertia", km.Inertia)
+
+	// 按簇收集成员下标
+	members := make([][]int, k)
+	for i, label := range km.Assignments {
+		members[label] = append(members[label], i)
+	}
+
+	run := &postgres.ClusterRun{
+		K:            k,
+		ArticleCount: len(articles),
+		Iterations:   km.Iterations,
+		Inertia:      km.Inertia,
+	}
+	if m, ok := namer.(interface{ Model() string }); ok {
+		run.NamingModel = m.Model()
+	}
+
+	result := &Result{Run: run}
+	clusters := make([]postgres.Cluster, 0, k)
+	for label, idx := range members {
+		if len(idx) == 0 {
+			continue
+		}
+		tags := topTags(articles, idx, opts.TopTags)
+
+		c := postgres.Cluster{
+			Label:    label,
+			TopTags:  tags,
+			Size:     len(idx),
+			Centroid: pgvector.NewVector(km.Centroids[label]),
+		}
+
+		var naming *Naming
+		if namer != nil {
+			naming, err = namer.Name(ctx, tags, samples(articles, idx, km.Similarities, opts.Samples))
+			if err != nil {
+				slog.Warn("簇命名失败，使用标签作为名称", "label", label, "error", err)
+			}
+		}
+		if naming != nil {
+			c.Name = naming.Name
+			if naming.Summary != "" {
+				c.Summary = &naming.Summary
+			}
+		} else {
+			c.Name = fallbackName(tags, label)
+			result.Unnamed++
+		}
+		clusters = append(clusters, c)
+	}
+
+	assignments := make([]postgres.ClusterAssignment, len(articles))
+	for i, a := range articles {
+		assignments[i] = postgres.ClusterAssignment{
+			ArticleID:  a.ID,
+			Label:      km.Assignments[i],
+			Similarity: km.Similarities[i],
+		}
+	}
+
+	if err := store.SaveClusterRun(ctx, run, clusters, assignments); err != nil {
+		return nil, err
+	}
+	result.Clusters = clusters
+
+	if opts.Keep > 0 {
+		result.Pruned, err = store.PruneClusterRuns(ctx, opts.Keep)
+		if err != nil {
+			// 结果已保存，清理失败不影响本次运行
+			slog.Warn("清理旧的聚类结果失败", "error", err)
+		}
+	}
+	return result, nil
+}
+
+// topTags 返回簇内出现次数最多的 limit 个标签
+func topTags(articles []postgres.ArticleEmbedding, idx []int, limit int) []string {
+	counts := make(map[string]int)
+	for _, i := range idx {
+		for _, tag := range articles[i].Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(a, b int) bool {
+		if counts[tags[a]] != counts[tags[b]] {
+			return counts[tags[a]] > counts[tags[b]]
+		}
+		return tags[a] < tags[b]
+	})
+	if limit > 0 && len(tags) > limit {
+		tags = tags[:limit]
+	}
+	return tags
+}
+
+// samples 返回最接近簇中心的 limit 个问题，优先使用专业化的问题描述
+func samples(articles []postgres.ArticleEmbedding, idx []int, similarities []float64, limit int) []string {
+	sorted := append([]int(nil), idx...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return similarities[sorted[a]] > similarities[sorted[b]]
+	})
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+
+	questions := make([]string, len(sorted))
+	for i, j := range sorted {
+		questions[i] = articles[j].OriginalQuestion
+		if d := articles[j].DetailedQuestion; d != nil && strings.TrimSpace(*d) != "" {
+			questions[i] = *d
+		}
+	}
+	return questions
+}
+
+// fallbackName 在无法调用 LLM 时用常见标签拼出簇名
+func fallbackName(tags []string, label int) string {
+	if len(tags) == 0 {
+		return fmt.Sprintf("cluster-%d", label)
+	}
+	return strings.Join(tags[:min(len(tags), 3)], " / ")
+}
@@ -0,0 +1,209 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// KMeansResult 是一次 k-means 聚类的结果
+type KMeansResult struct {
+	Assignments  []int       // 每个向量所属的簇下标
+	Similarities []float64   // 每个向量与所属簇中心的余弦相似度
+	Centroids    [][]float32 // 归一化后的簇中心
+	Iterations   int
+	Inertia      float64 // 所有向量的 (1 - 相似度) 之和，越小越紧凑
+}
+
+// KMeans 对已归一化的向量做球面 k-means (余弦相似度)，使用 k-means++ 初始化
+// seed 固定时结果可复现；迭代在分配不再变化或达到 maxIter 时停止
+// 返回的分配和相似度总是基于返回的 Centroids 计算
+func KMeans(vectors [][]float32, k int, maxIter int, seed int64) (*KMeansResult, error) {
+	n := len(vectors)
+	if n == 0 {
+		return nil, fmt.Errorf("no vectors to cluster")
+	}
+	if k < 1 || k > n {
+		return nil, fmt.Errorf("k must be between 1 and %d, got %d", n, k)
+	}
+	if maxIter < 1 {
+		return nil, fmt.Errorf("maxIter must be at least 1, got %d", maxIter)
+	}
+	dim := len(vectors[0])
+	for i, v := range vectors {
+		if len(v) != dim {
+			return nil, fmt.Errorf("vector %d has dimension %d, want %d", i, len(v), dim)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	centroids := initCentroids(vectors, k, rng)
+
+	result := &KMeansResult{
+		Assignments:  make([]int, n),
+		Similarities: make([]float64, n),
+	}
+	for i := range result.Assignments {
+		result.Assignments[i] = -1
+	}
+
+	// assign 把每个向量分配到最近的中心，返回分配发生变化的向量数
+	assign := func() int {
+		changed := 0
+		for i, v := range vectors {
+			best, bestSim := nearest(v, centroids)
+			if result.Assignments[i] != best {
+				result.Assignments[i] = best
+				changed++
+			}
+			result.Similarities[i] = bestSim
+		}
+		return changed
+	}
+
+	converged := false
+	for iter := 1; iter <= maxIter; iter++ {
+		result.Iterations = iter
+
+		if assign() == 0 {
+			converged = true
+			break
+		}
+
+		centroids = recomputeCentroids(vectors, result.Assignments, k, dim)
+		// 空簇用离当前中心最远的向量重新播种
+		for c := range centroids {
+			if centroids[c] == nil {
+				far := farthest(result.Similarities)
+				centroids[c] = copyVector(vectors[far])
+				result.Similarities[far] = 1
+			}
+		}
+	}
+
+	// 达到 maxIter 时中心已在最后一次分配之后重新计算，按最终的中心再分配一次，避免相似度过期
+	if !converged {
+		assign()
+	}
+
+	result.Centroids = centroids
+	for _, sim := range result.Similarities {
+		result.Inertia += 1 - sim
+	}
+	return result, nil
+}
+
+// initCentroids 使用 k-means++ 选择初始中心：按与已选中心距离的平方加权抽样
+func initCentroids(vectors [][]float32, k int, rng *rand.Rand) [][]float32 {
+	centroids := make([][]float32, 0, k)
+	centroids = append(centroids, copyVector(vectors[rng.Intn(len(vectors))]))
+
+	dist := make([]float64, len(vectors))
+	for i, v := range vectors {
+		dist[i] = cosineDistance(v, centroids[0])
+	}
+
+	for len(centroids) < k {
+		var total float64
+		for _, d := range dist {
+			total += d * d
+		}
+
+		next := rng.Intn(len(vectors))
+		if total > 0 {
+			target := rng.Float64() * total
+			for i, d := range dist {
+				target -= d * d
+				if target <= 0 {
+					next = i
+					break
+				}
+			}
+		}
+		centroids = append(centroids, copyVector(vectors[next]))
+
+		for i, v := range vectors {
+			dist[i] = math.Min(dist[i], cosineDistance(v, centroids[len(centroids)-1]))
+		}
+	}
+	return centroids
+}
+
+// recomputeCentroids 计算每个簇的均值并归一化，空簇返回 nil
+func recomputeCentroids(vectors [][]float32, assignments []int, k, dim int) [][]float32 {
+	sums := make([][]float64, k)
+	for i, c := range assignments {
+		if sums[c] == nil {
+			sums[c] = make([]float64, dim)
+		}
+		for j, x := range vectors[i] {
+			sums[c][j] += float64(x)
+		}
+	}
+
+	centroids := make([][]float32, k)
+	for c, sum := range sums {
+		if sum == nil {
+			continue
+		}
+		var norm float64
+		for _, x := range sum {
+			norm += x * x
+		}
+		norm = math.Sqrt(norm)
+		if norm == 0 {
+			continue
+		}
+		centroid := make([]float32, dim)
+		for j, x := range sum {
+			centroid[j] = float32(x / norm)
+		}
+		centroids[c] = centroid
+	}
+	return centroids
+}
+
+// nearest 返回与 v 余弦相似度最高的中心
+func nearest(v []float32, centroids [][]float32) (int, float64) {
+	best, bestSim := 0, math.Inf(-1)
+	for c, centroid := range centroids {
+		if sim := dot(v, centroid); sim > bestSim {
+			best, bestSim = c, sim
+		}
+	}
+	return best, bestSim
+}
+
+// farthest 返回相似度最低 (离所属中心最远) 的向量下标
+func farthest(similarities []float64) int {
+	idx := 0
+	for i, sim := range similarities {
+		if sim < similarities[idx] {
+			idx = i
+		}
+	}
+	return idx
+}
+
+// dot 计算内积，向量已归一化时即余弦相似度
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func cosineDistance(a, b []float32) float64 {
+	return math.Max(0, 1-dot(a, b))
+}
+
+func copyVector(v []float32) []float32 {
+	return append([]float32(nil), v...)
+}
+
+// SuggestK 根据样本数给出默认的簇数：sqrt(n/2)，限制在 [2, 100]
+func SuggestK(n int) int {
+	k := int(math.Round(math.Sqrt(float64(n) / 2)))
+	return min(max(k, 2), 100, n)
+}
@@ -0,0 +1,147 @@
+package cluster
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// unit 返回归一化后的二维向量
+func unit(x, y float64) []float32 {
+	n := math.Hypot(x, y)
+	return []float32{float32(x / n), float32(y / n)}
+}
+
+func TestKMeansInvalidInput(t *testing.T) {
+	vectors := [][]float32{unit(1, 0), unit(0, 1)}
+	tests := []struct {
+		name    string
+		vectors [][]float32
+		k       int
+		maxIter int
+	}{
+		{"no vectors", nil, 1, 10},
+		{"k zero", vectors, 0, 10},
+		{"k negative", vectors, -1, 10},
+		{"k greater than n", vectors, 3, 10},
+		{"maxIter zero", vectors, 2, 0},
+		{"maxIter negative", vectors, 2, -5},
+		{"dimension mismatch", [][]float32{unit(1, 0), {1, 0, 0}}, 1, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := KMeans(tt.vectors, tt.k, tt.maxIter, 1); err == nil {
+				t.Errorf("KMeans(k=%d, maxIter=%d) succeeded, want error", tt.k, tt.maxIter)
+			}
+		})
+	}
+}
+
+func TestKMeans(t *testing.T) {
+	// 两组方向明显不同的向量
+	vectors := [][]float32{
+		unit(1, 0.05), unit(1, -0.05), unit(1, 0.1),
+		unit(0.05, 1), unit(-0.05, 1), unit(0.1, 1),
+	}
+	wantGroups := [][]int{{0, 1, 2}, {3, 4, 5}}
+
+	tests := []struct {
+		name    string
+		k       int
+		maxIter int
+		groups  [][]int // nil 表示不检查分组
+	}{
+		{"converges", 2, 50, wantGroups},
+		{"single iteration", 2, 1, nil},
+		{"single cluster", 1, 10, [][]int{{0, 1, 2, 3, 4, 5}}},
+		{"one cluster per vector", 6, 10, [][]int{{0}, {1}, {2}, {3}, {4}, {5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := KMeans(vectors, tt.k, tt.maxIter, 42)
+			if err != nil {
+				t.Fatalf("KMeans: %v", err)
+			}
+			if result.Iterations < 1 || result.Iterations > tt.maxIter {
+				t.Errorf("Iterations = %d, want 1-%d", result.Iterations, tt.maxIter)
+			}
+			if len(result.Centroids) != tt.k {
+				t.Fatalf("len(Centroids) = %d, want %d", len(result.Centroids), tt.k)
+			}
+
+			// 分配和相似度必须基于返回的中心
+			var inertia float64
+			for i, v := range vectors {
+				label := result.Assignments[i]
+				if label < 0 || label >= tt.k {
+					t.Fatalf("Assignments[%d] = %d, out of range", i, label)
+				}
+				if best, _ := nearest(v, result.Centroids); best != label {
+					t.Errorf("vector %d assigned to %d, nearest final centroid is %d", i, label, best)
+				}
+				if sim := dot(v, result.Centroids[label]); math.Abs(sim-result.Similarities[i]) > 1e-6 {
+					t.Errorf("Similarities[%d] = %v, want %v against the final centroid", i, result.Similarities[i], sim)
+				}
+				inertia += 1 - result.Similarities[i]
+			}
+			if math.Abs(inertia-result.Inertia) > 1e-9 {
+				t.Errorf("Inertia = %v, want %v", result.Inertia, inertia)
+			}
+
+			if tt.groups != nil {
+				if got := groups(result.Assignments, tt.k); !reflect.DeepEqual(got, tt.groups) {
+					t.Errorf("groups = %v, want %v", got, tt.groups)
+				}
+			}
+		})
+	}
+}
+
+func TestKMeansDeterministic(t *testing.T) {
+	vectors := [][]float32{unit(1, 0), unit(1, 1), unit(0, 1), unit(-1, 1), unit(-1, 0), unit(1, -1)}
+	a, err := KMeans(vectors, 3, 20, 7)
+	if err != nil {
+		t.Fatalf("KMeans: %v", err)
+	}
+	b, err := KMeans(vectors, 3, 20, 7)
+	if err != nil {
+		t.Fatalf("KMeans: %v", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("KMeans with the same seed differs:\n%+v\n%+v", a, b)
+	}
+}
+
+// groups 按簇收集成员下标，并按首个成员排序，使结果与簇编号无关
+func groups(assignments []int, k int) [][]int {
+	byLabel := make([][]int, k)
+	for i, label := range assignments {
+		byLabel[label] = append(byLabel[label], i)
+	}
+	var out [][]int
+	for i := range assignments {
+		for label, members := range byLabel {
+			if len(members) > 0 && members[0] == i {
+				out = append(out, members)
+				byLabel[label] = nil
+			}
+		}
+	}
+	return out
+}
+
+func TestSuggestK(t *testing.T) {
+	tests := []struct{ n, want int }{
+		{1, 1},
+		{2, 2},
+		{8, 2},
+		{50, 5},
+		{200, 10},
+		{100000, 100},
+	}
+	for _, tt := range tests {
+		if got := SuggestK(tt.n); got != tt.want {
+			t.Errorf("SuggestK(%d) = %d, want %d", tt.n, got, tt.want)
+		}
+	}
+}
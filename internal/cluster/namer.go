@@ -0,0 +1,127 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"paguu/internal/enrich"
+	"paguu/internal/usage"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime"
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model/responses"
+)
+
+// Naming 是 LLM 为簇生成的名称和概述
+type Naming struct {
+	Name    string `json:"name"`
+	Summary string `json:"summary"`
+}
+
+// Namer 为一个簇命名，questions 为最接近簇中心的若干问题
+type Namer interface {
+	Name(ctx context.Context, topTags []string, questions []string) (*Naming, error)
+}
+
+// LLMNamer 通过丰富化所用的 Ark 模型为簇命名
+type LLMNamer struct {
+	client         *arkruntime.Client
+	modelName      string
+	promptTemplate *template.Template
+	recorder       usage.Recorder
+}
+
+func NewLLMNamer(client *arkruntime.Client, templatePath string, modelName string) (*LLMNamer, error) {
+	if client == nil {
+		return nil, fmt.Errorf("ark client is required")
+	}
+	if modelName == "" {
+		return nil, fmt.Errorf("cluster naming model name is required")
+	}
+
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(templatePath).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	return &LLMNamer{
+		client:         client,
+		modelName:      modelName,
+		promptTemplate: tmpl,
+	}, nil
+}
+
+// SetUsageRecorder 设置用量记录器，为 nil 时不记录
+func (n *LLMNamer) SetUsageRecorder(recorder usage.Recorder) {
+	n.recorder = recorder
+}
+
+// Model 返回命名使用的模型名
+func (n *LLMNamer) Model() string {
+	return n.modelName
+}
+
+func (n *LLMNamer) Name(ctx context.Context, topTags []string, questions []string) (*Naming, error) {
+	var buf bytes.Buffer
+	err := n.promptTemplate.Execute(&buf, map[string]interface{}{
+		"TopTags":   topTags,
+		"Questions": questions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	resp, err := n.client.CreateResponses(ctx, &responses.ResponsesRequest{
+		Model: n.modelName,
+		Input: &responses.ResponsesInput{Union: &responses.ResponsesInput_StringValue{StringValue: buf.String()}},
+	})
+	n.recordUsage(ctx, resp, time.Since(startedAt), err)
+	if err != nil {
+		return nil, fmt.Errorf("cluster naming request error: %w", err)
+	}
+
+	text := enrich.ExtractResponseText(resp)
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("empty cluster naming response text")
+	}
+
+	var naming Naming
+	if err := json.Unmarshal([]byte(text), &naming); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cluster naming response JSON: %w", err)
+	}
+	naming.Name = strings.TrimSpace(naming.Name)
+	naming.Summary = strings.TrimSpace(naming.Summary)
+	if naming.Name == "" {
+		return nil, fmt.Errorf("cluster naming response has empty name")
+	}
+	return &naming, nil
+}
+
+// recordUsage 记录一次命名调用的用量
+func (n *LLMNamer) recordUsage(ctx context.Context, resp *responses.ResponseObject, latency time.Duration, err error) {
+	if n.recorder == nil {
+		return
+	}
+	event := usage.Event{
+		Provider:  usage.ProviderArk,
+		Operation: usage.OperationClusterName,
+		Model:     n.modelName,
+		Latency:   latency,
+		Err:       err,
+	}
+	if resp != nil && resp.Usage != nil {
+		event.PromptTokens = resp.Usage.InputTokens
+		event.CompletionTokens = resp.Usage.OutputTokens
+		event.TotalTokens = resp.Usage.TotalTokens
+	}
+	n.recorder.Record(ctx, event)
+}
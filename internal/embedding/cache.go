@@ -0,0 +1,105 @@
+package embedding
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// CachedEmbedding 是一条持久化缓存的向量
+type CachedEmbedding struct {
+	Key       string
+	Model     string
+	Dimension int
+	Vector    []float32
+}
+
+// VectorStore 是向量缓存的持久化层
+type VectorStore interface {
+	GetCachedEmbeddings(ctx context.Context, keys []string) (map[string][]float32, error)
+	PutCachedEmbeddings(ctx context.Context, entries []CachedEmbedding) error
+}
+
+// CacheStats 是向量缓存的命中统计 (进程启动以来)
+type CacheStats struct {
+	MemoryHits int64 `json:"memory_hits"`
+	StoreHits  int64 `json:"store_hits"`
+	Misses     int64 `json:"misses"`
+	MemorySize int   `json:"memory_size"`
+	MemoryCap  int   `json:"memory_capacity"`
+}
+
+// contentKey 由模型、维度、嵌入任务类型和文本内容计算缓存 key
+// 区分任务类型之前所有向量都是文档向量，文档向量的 key 不含任务类型，以便 embedding_cache 中已有的记录继续命中
+func contentKey(model string, dimension int, taskType TaskType, text string) string {
+	input := model + "\x00" + strconv.Itoa(dimension) + "\x00"
+	if taskType != TaskTypeDocument {
+		input += string(taskType) + "\x00"
+	}
+	sum := sha256.Sum256([]byte(input + text))
+	return hex.EncodeToString(sum[:])
+}
+
+// lruCache 是一个并发安全的定长 LRU 缓存
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key    string
+	vector []float32
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) get(key string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		return el.Value.(*lruEntry).vector, true
+	}
+	return nil, false
+}
+
+func (c *lruCache) put(key string, vector []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruEntry).vector = vector
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, vector: vector})
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// cacheCounters 记录命中统计
+type cacheCounters struct {
+	memoryHits atomic.Int64
+	storeHits  atomic.Int64
+	misses     atomic.Int64
+}
@@ -0,0 +1,172 @@
+package embedding
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+// memoryStore 是内存中的 VectorStore 实现
+type memoryStore struct {
+	vectors map[string][]float32
+	puts    int
+}
+
+func (s *memoryStore) GetCachedEmbeddings(_ context.Context, keys []string) (map[string][]float32, error) {
+	hits := make(map[string][]float32)
+	for _, key := range keys {
+		if v, ok := s.vectors[key]; ok {
+			hits[key] = v
+		}
+	}
+	return hits, nil
+}
+
+func (s *memoryStore) PutCachedEmbeddings(_ context.Context, entries []CachedEmbedding) error {
+	for _, e := range entries {
+		s.vectors[e.Key] = e.Vector
+		s.puts++
+	}
+	return nil
+}
+
+// fakeRemote 用文本长度生成向量 (查询向量取负)，并记录每次请求的文本
+type fakeRemote struct {
+	calls [][]string
+}
+
+func (r *fakeRemote) embed(_ context.Context, texts []string, taskType TaskType) ([][]float32, error) {
+	r.calls = append(r.calls, texts)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text))}
+		if taskType == TaskTypeQuery {
+			vectors[i][0] = -vectors[i][0]
+		}
+	}
+	return vectors, nil
+}
+
+func TestContentKey(t *testing.T) {
+	base := contentKey("model-a", 1536, TaskTypeDocument, "text")
+	tests := []struct {
+		name     string
+		key      string
+		wantSame bool
+	}{
+		{"same input", contentKey("model-a", 1536, TaskTypeDocument, "text"), true},
+		{"different text", contentKey("model-a", 1536, TaskTypeDocument, "text2"), false},
+		{"different model", contentKey("model-b", 1536, TaskTypeDocument, "text"), false},
+		{"different dimension", contentKey("model-a", 768, TaskTypeDocument, "text"), false},
+		{"different task type", contentKey("model-a", 1536, TaskTypeQuery, "text"), false},
+	}
+	for _, tt := range tests {
+		if got := tt.key == base; got != tt.wantSame {
+			t.Errorf("%s: same key = %v, want %v", tt.name, got, tt.wantSame)
+		}
+	}
+
+	// 文档向量沿用区分任务类型之前的 key，embedding_cache 中已有的记录继续命中
+	sum := sha256.Sum256([]byte("model-a\x00" + "1536\x00" + "text"))
+	if legacy := hex.EncodeToString(sum[:]); base != legacy {
+		t.Errorf("document key = %s, want legacy key %s", base, legacy)
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	c := newLRUCache(2)
+	c.put("a", []float32{1})
+	c.put("b", []float32{2})
+	c.get("a") // a 变为最近使用
+	c.put("c", []float32{3})
+	c.put("a", []float32{4})
+
+	tests := []struct {
+		key    string
+		want   []float32
+		wantOK bool
+	}{
+		{"a", []float32{4}, true},
+		{"b", nil, false},
+		{"c", []float32{3}, true},
+	}
+	for _, tt := range tests {
+		got, ok := c.get(tt.key)
+		if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("get(%q) = %v, %v, want %v, %v", tt.key, got, ok, tt.want, tt.wantOK)
+		}
+	}
+	if c.len() != 2 {
+		t.Errorf("len = %d, want 2", c.len())
+	}
+}
+
+func TestEmbedBatchCache(t *testing.T) {
+	remote := &fakeRemote{}
+	store := &memoryStore{vectors: map[string][]float32{}}
+	e := &Embedder{modelName: "model-a", queryTaskType: TaskTypeQuery, remote: remote.embed}
+	e.SetCache(store, 10)
+	store.vectors[contentKey("model-a", DIMENSION, TaskTypeDocument, "stored")] = []float32{42}
+
+	tests := []struct {
+		name      string
+		query     bool
+		texts     []string
+		want      [][]float32
+		wantCalls [][]string
+		wantStats CacheStats
+	}{
+		{
+			name:      "misses are deduplicated and stored",
+			texts:     []string{"stored", "ab", "ab", "abc"},
+			want:      [][]float32{{42}, {2}, {2}, {3}},
+			wantCalls: [][]string{{"ab", "abc"}},
+			wantStats: CacheStats{StoreHits: 1, Misses: 2, MemorySize: 3, MemoryCap: 10},
+		},
+		{
+			name:      "second batch hits memory",
+			texts:     []string{"abc", "stored"},
+			want:      [][]float32{{3}, {42}},
+			wantStats: CacheStats{MemoryHits: 2, StoreHits: 1, Misses: 2, MemorySize: 3, MemoryCap: 10},
+		},
+		{
+			name:      "query vectors are cached separately",
+			query:     true,
+			texts:     []string{"abc"},
+			want:      [][]float32{{-3}},
+			wantCalls: [][]string{{"abc"}},
+			wantStats: CacheStats{MemoryHits: 2, StoreHits: 1, Misses: 3, MemorySize: 4, MemoryCap: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote.calls = nil
+			var got [][]float32
+			var err error
+			if tt.query {
+				var v []float32
+				v, err = e.EmbedQuery(context.Background(), tt.texts[0])
+				got = [][]float32{v}
+			} else {
+				got, err = e.EmbedBatch(context.Background(), tt.texts)
+			}
+			if err != nil {
+				t.Fatalf("embed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("vectors = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(remote.calls, tt.wantCalls) {
+				t.Errorf("remote calls = %q, want %q", remote.calls, tt.wantCalls)
+			}
+			if stats := e.CacheStats(); stats != tt.wantStats {
+				t.Errorf("stats = %+v, want %+v", stats, tt.wantStats)
+			}
+		})
+	}
+	if store.puts != 3 {
+		t.Errorf("stored %d vectors, want 3", store.puts)
+	}
+}
@@ -4,16 +4,42 @@ package embedding
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"math"
+	"paguu/internal/usage"
+	"time"
 
 	"google.golang.org/genai"
 )
 
 const DIMENSION = 1536
 
+// TaskType 是 Gemini 的嵌入任务类型
+// 检索场景下文档和查询使用不同的类型 (非对称嵌入)，短查询的召回效果明显更好
+type TaskType string
+
+const (
+	// TaskTypeDocument 用于入库的文章以及文章之间的去重比较
+	TaskTypeDocument TaskType = "RETRIEVAL_DOCUMENT"
+	// TaskTypeQuery 用于用户输入的搜索查询
+	TaskTypeQuery TaskType = "RETRIEVAL_QUERY"
+)
+
 type Embedder struct {
 	client    *genai.Client
 	modelName string
+	recorder  usage.Recorder
+	// queryTaskType 是 EmbedQuery 使用的任务类型，默认 TaskTypeQuery
+	// 设为 TaskTypeDocument 即退回对称嵌入，便于评测对比
+	queryTaskType TaskType
+
+	// 向量缓存，均为可选
+	lru      *lruCache
+	store    VectorStore
+	counters cacheCounters
+
+	// remote 调用 Embedding API，默认为 embedRemote，测试中可替换
+	remote func(ctx context.Context, texts []string, taskType TaskType) ([][]float32, error)
 }
 
 func NewEmbedder(modelName string, client *genai.Client) (*Embedder, error) {
@@ -25,14 +51,63 @@ func NewEmbedder(modelName string, client *genai.Client) (*Embedder, error) {
 		return nil, fmt.Errorf("embedding model name is required")
 	}
 
-	return &Embedder{
-		client:    client,
-		modelName: modelName,
-	}, nil
+	e := &Embedder{
+		client:        client,
+		modelName:     modelName,
+		queryTaskType: TaskTypeQuery,
+	}
+	e.remote = e.embedRemote
+	return e, nil
+}
+
+// SetUsageRecorder 设置用量记录器，为 nil 时不记录
+func (e *Embedder) SetUsageRecorder(recorder usage.Recorder) {
+	e.recorder = recorder
+}
+
+// SetCache 设置向量缓存：lruSize > 0 时启用进程内 LRU，store 不为 nil 时启用持久化缓存
+func (e *Embedder) SetCache(store VectorStore, lruSize int) {
+	e.store = store
+	e.lru = nil
+	if lruSize > 0 {
+		e.lru = newLRUCache(lruSize)
+	}
+}
+
+// CacheStats 返回向量缓存的命中统计
+func (e *Embedder) CacheStats() CacheStats {
+	stats := CacheStats{
+		MemoryHits: e.counters.memoryHits.Load(),
+		StoreHits:  e.counters.storeHits.Load(),
+		Misses:     e.counters.misses.Load(),
+	}
+	if e.lru != nil {
+		stats.MemorySize = e.lru.len()
+		stats.MemoryCap = e.lru.capacity
+	}
+	return stats
+}
+
+// SetQueryTaskType 设置查询嵌入使用的任务类型，空值表示默认的 TaskTypeQuery
+func (e *Embedder) SetQueryTaskType(taskType TaskType) {
+	if taskType == "" {
+		taskType = TaskTypeQuery
+	}
+	e.queryTaskType = taskType
 }
 
+// Embed 生成单个文档向量 (入库、去重使用)
 func (e *Embedder) Embed(ctx context.Context, text string) ([]float32, error) {
-	batchResults, err := e.EmbedBatch(ctx, []string{text})
+	return e.embedOne(ctx, text, TaskTypeDocument)
+}
+
+// EmbedQuery 生成单个查询向量 (搜索请求使用)
+func (e *Embedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return e.embedOne(ctx, text, e.queryTaskType)
+}
+
+func (e *Embedder) embedOne(ctx context.Context, text string, taskType TaskType) ([]float32, error) {
+	batchResults, err := e.embedBatch(ctx, []string{text}, taskType)
 	if err != nil {
 		return nil, err
 	}
@@ -42,11 +117,106 @@ func (e *Embedder) Embed(ctx context.Context, text string) ([]float32, error) {
 	return batchResults[0], nil
 }
 
+// EmbedBatch 批量生成归一化的文档向量
 func (e *Embedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	return e.embedBatch(ctx, texts, TaskTypeDocument)
+}
+
+// embedBatch 批量生成归一化向量
+// 依次查询进程内 LRU 和持久化缓存，只有都未命中的文本 (去重后) 才会调用 Gemini
+func (e *Embedder) embedBatch(ctx context.Context, texts []string, taskType TaskType) ([][]float32, error) {
 	if len(texts) == 0 {
 		return [][]float32{}, nil
 	}
+	if e.lru == nil && e.store == nil {
+		return e.remote(ctx, texts, taskType)
+	}
+
+	results := make([][]float32, len(texts))
+	keys := make([]string, len(texts))
+	var storeLookup []string
+	for i, text := range texts {
+		keys[i] = contentKey(e.modelName, DIMENSION, taskType, text)
+		if e.lru != nil {
+			if vector, ok := e.lru.get(keys[i]); ok {
+				results[i] = vector
+				e.counters.memoryHits.Add(1)
+				continue
+			}
+		}
+		storeLookup = append(storeLookup, keys[i])
+	}
+
+	if e.store != nil && len(storeLookup) > 0 {
+		stored, err := e.store.GetCachedEmbeddings(ctx, storeLookup)
+		if err != nil {
+			// 缓存不可用时直接调用 API
+			slog.Error("embedding cache lookup error", "error", err)
+		}
+		for i := range texts {
+			if results[i] != nil {
+				continue
+			}
+			if vector, ok := stored[keys[i]]; ok {
+				results[i] = vector
+				e.counters.storeHits.Add(1)
+				if e.lru != nil {
+					e.lru.put(keys[i], vector)
+				}
+			}
+		}
+	}
+
+	// 收集未命中的文本，相同文本只请求一次
+	missIndex := make(map[string][]int)
+	var missTexts []string
+	var missKeys []string
+	for i, text := range texts {
+		if results[i] != nil {
+			continue
+		}
+		if _, ok := missIndex[keys[i]]; !ok {
+			missTexts = append(missTexts, text)
+			missKeys = append(missKeys, keys[i])
+		}
+		missIndex[keys[i]] = append(missIndex[keys[i]], i)
+	}
+	if len(missTexts) == 0 {
+		return results, nil
+	}
+	e.counters.misses.Add(int64(len(missTexts)))
+
+	vectors, err := e.remote(ctx, missTexts, taskType)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]CachedEmbedding, len(vectors))
+	for j, vector := range vectors {
+		for _, i := range missIndex[missKeys[j]] {
+			results[i] = vector
+		}
+		if e.lru != nil {
+			e.lru.put(missKeys[j], vector)
+		}
+		entries[j] = CachedEmbedding{
+			Key:       missKeys[j],
+			Model:     e.modelName,
+			Dimension: DIMENSION,
+			Vector:    vector,
+		}
+	}
+	if e.store != nil {
+		if err := e.store.PutCachedEmbeddings(ctx, entries); err != nil {
+			slog.Error("embedding cache store error", "error", err)
+		}
+	}
+
+	return results, nil
+}
 
+// embedRemote 调用 Gemini Embedding API 并归一化结果
+func (e *Embedder) embedRemote(ctx context.Context, texts []string, taskType TaskType) ([][]float32, error) {
 	// 将文本转换为 genai.Content 格式
 	contents := make([]*genai.Content, len(texts))
 	for i, text := range texts {
@@ -57,10 +227,12 @@ func (e *Embedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32,
 
 	// 调用 Gemini Embedding API，指定输出维度
 	outputDim := int32(DIMENSION)
+	startedAt := time.Now()
 	result, err := e.client.Models.EmbedContent(ctx, e.modelName, contents, &genai.EmbedContentConfig{
 		OutputDimensionality: &outputDim,
-		TaskType:             "RETRIEVAL_DOCUMENT", // 用于文档检索的嵌入
+		TaskType:             string(taskType),
 	})
+	e.recordUsage(ctx, texts, result, time.Since(startedAt), err)
 	if err != nil {
 		return nil, fmt.Errorf("gemini embedding API error: %w", err)
 	}
@@ -77,6 +249,33 @@ func (e *Embedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32,
 	return results, nil
 }
 
+// recordUsage 记录一次 Gemini Embedding 调用的用量
+// Gemini API 后端不返回 token 数 (仅 Vertex 返回)，此时按文本长度估算
+func (e *Embedder) recordUsage(ctx context.Context, texts []string, result *genai.EmbedContentResponse, latency time.Duration, err error) {
+	if e.recorder == nil {
+		return
+	}
+	event := usage.Event{
+		Provider:  usage.ProviderGemini,
+		Operation: usage.OperationEmbed,
+		Model:     e.modelName,
+		Latency:   latency,
+		Err:       err,
+	}
+	if result != nil {
+		for _, embedding := range result.Embeddings {
+			if embedding != nil && embedding.Statistics != nil {
+				event.PromptTokens += int64(embedding.Statistics.TokenCount)
+			}
+		}
+	}
+	if event.PromptTokens == 0 {
+		event.PromptTokens = usage.EstimateTokens(texts...)
+		event.Estimated = true
+	}
+	e.recorder.Record(ctx, event)
+}
+
 // normalize 对向量进行 L2 归一化 (使其长度为 1)
 // 这是使用 pgvector 内积 (<#>) 查询所必需的
 func (e *Embedder) normalize(v []float32) []float32 {
@@ -0,0 +1,100 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// ErrNotCached 表示离线模式下查询向量不在持久化缓存中
+var ErrNotCached = errors.New("embedding not cached")
+
+// StoreEmbedder 只从持久化向量缓存读取查询向量，不调用 Gemini
+// 缓存 key 与 Embedder 一致，因此可以离线复现之前在线跑过的评测
+type StoreEmbedder struct {
+	store     VectorStore
+	modelName string
+	taskType  TaskType
+}
+
+func NewStoreEmbedder(store VectorStore, modelName string, taskType TaskType) (*StoreEmbedder, error) {
+	if store == nil {
+		return nil, fmt.Errorf("vector store is required")
+	}
+	if modelName == "" {
+		return nil, fmt.Errorf("embedding model name is required")
+	}
+	if taskType == "" {
+		taskType = TaskTypeQuery
+	}
+	return &StoreEmbedder{store: store, modelName: modelName, taskType: taskType}, nil
+}
+
+func (e *StoreEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	key := contentKey(e.modelName, DIMENSION, e.taskType, text)
+	found, err := e.store.GetCachedEmbeddings(ctx, []string{key})
+	if err != nil {
+		return nil, fmt.Errorf("embedding cache lookup error: %w", err)
+	}
+	vector, ok := found[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %q", ErrNotCached, e.taskType, text)
+	}
+	return vector, nil
+}
+
+// FixtureEmbedder 是确定性的离线嵌入：把字符 bigram 哈希到固定维度后 L2 归一化
+// 向量与 Gemini 向量不在同一空间，只用于在没有 API key 的环境里跑通评测流程
+type FixtureEmbedder struct{}
+
+func (FixtureEmbedder) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	return fixtureVector(text), nil
+}
+
+func (FixtureEmbedder) EmbedBatch(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = fixtureVector(text)
+	}
+	return vectors, nil
+}
+
+func fixtureVector(text string) []float32 {
+	runes := []rune(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, text))
+
+	vector := make([]float32, DIMENSION)
+	add := func(gram string) {
+		h := fnv.New32a()
+		h.Write([]byte(gram))
+		vector[h.Sum32()%DIMENSION]++
+	}
+	if len(runes) == 1 {
+		add(string(runes))
+	}
+	for i := 0; i+1 < len(runes); i++ {
+		add(string(runes[i : i+2]))
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		vector[0] = 1
+		return vector
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vector {
+		vector[i] *= scale
+	}
+	return vector
+}
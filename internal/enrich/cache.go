@@ -0,0 +1,61 @@
+package enrich
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CacheEntry 是一条缓存的丰富化结果
+type CacheEntry struct {
+	Key                string
+	NormalizedQuestion string
+	Model              string
+	TemplateVersion    string
+	Question           InterviewQuestion
+}
+
+// Cache 保存已丰富化的问题，避免相同问题重复调用 LLM
+type Cache interface {
+	// GetCachedEnrichments 按 key 批量查询未超过 maxAge 的缓存 (maxAge 为 0 表示不过期)
+	GetCachedEnrichments(ctx context.Context, keys []string, maxAge time.Duration) (map[string]InterviewQuestion, error)
+	// PutCachedEnrichments 写入或覆盖缓存
+	PutCachedEnrichments(ctx context.Context, entries []CacheEntry) error
+}
+
+// EnrichOptions 控制单次丰富化的行为
+type EnrichOptions struct {
+	// BypassCache 为 true 时不读取缓存，所有行都重新请求 LLM，结果仍会写回缓存 (即刷新缓存)
+	BypassCache bool
+}
+
+// CacheKey 由归一化后的问题、模型名、模板版本和规范标签版本生成缓存 key
+// 规范标签会写入 prompt，标签表变化后旧结果不再复用；没有规范标签时 key 与引入该字段前一致
+func CacheKey(normalizedQuestion, model, templateVersion, vocabularyVersion string) string {
+	input := normalizedQuestion + "\x00" + model + "\x00" + templateVersion
+	if vocabularyVersion != "" {
+		input += "\x00" + vocabularyVersion
+	}
+	sum := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(sum[:])
+}
+
+// VocabularyVersion 取规范标签集合的 sha256 前 12 位，与标签顺序无关；没有标签时为空
+func VocabularyVersion(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	sorted := slices.Clone(tags)
+	slices.Sort(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\x00")))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// templateVersion 取模板内容的 sha256 前 12 位，模板修改后旧缓存自然失效
+func templateVersion(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])[:12]
+}
@@ -0,0 +1,197 @@
+package enrich
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryCache 是内存中的 Cache 实现
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string]InterviewQuestion
+	maxAge  time.Duration
+}
+
+func (c *memoryCache) GetCachedEnrichments(_ context.Context, keys []string, maxAge time.Duration) (map[string]InterviewQuestion, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxAge = maxAge
+	hits := make(map[string]InterviewQuestion)
+	for _, key := range keys {
+		if q, ok := c.entries[key]; ok {
+			hits[key] = q
+		}
+	}
+	return hits, nil
+}
+
+func (c *memoryCache) PutCachedEnrichments(_ context.Context, entries []CacheEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]InterviewQuestion)
+	}
+	for _, e := range entries {
+		c.entries[e.Key] = e.Question
+	}
+	return nil
+}
+
+func TestCacheKey(t *testing.T) {
+	base := CacheKey("go的gc", "model-a", "v1", "")
+	tests := []struct {
+		name     string
+		key      string
+		wantSame bool
+	}{
+		{"same input", CacheKey("go的gc", "model-a", "v1", ""), true},
+		{"different question", CacheKey("go的channel", "model-a", "v1", ""), false},
+		{"different model", CacheKey("go的gc", "model-b", "v1", ""), false},
+		{"different template", CacheKey("go的gc", "model-a", "v2", ""), false},
+		{"with vocabulary", CacheKey("go的gc", "model-a", "v1", VocabularyVersion([]string{"Go"})), false},
+		{"fields do not run together", CacheKey("go的gcmodel-a", "", "v1", ""), false},
+	}
+	for _, tt := range tests {
+		if got := tt.key == base; got != tt.wantSame {
+			t.Errorf("%s: same key = %v, want %v", tt.name, got, tt.wantSame)
+		}
+	}
+}
+
+func TestVocabularyVersion(t *testing.T) {
+	base := VocabularyVersion([]string{"Go", "Redis"})
+	tests := []struct {
+		name     string
+		tags     []string
+		wantSame bool
+	}{
+		{"same tags", []string{"Go", "Redis"}, true},
+		{"order does not matter", []string{"Redis", "Go"}, true},
+		{"added tag", []string{"Go", "Redis", "MySQL"}, false},
+		{"removed tag", []string{"Go"}, false},
+		{"tags do not run together", []string{"GoRedis"}, false},
+	}
+	for _, tt := range tests {
+		if got := VocabularyVersion(tt.tags) == base; got != tt.wantSame {
+			t.Errorf("%s: same version = %v, want %v", tt.name, got, tt.wantSame)
+		}
+	}
+	if v := VocabularyVersion(nil); v != "" {
+		t.Errorf("VocabularyVersion(nil) = %q, want empty", v)
+	}
+}
+
+// staticVocabulary 是返回固定规范标签的 TagVocabulary
+type staticVocabulary []string
+
+func (v *staticVocabulary) CanonicalTags(context.Context, int) ([]string, error) {
+	return *v, nil
+}
+
+func TestEnrichQuestionsCacheVocabulary(t *testing.T) {
+	llm := &fakeLLM{}
+	vocabulary := &staticVocabulary{"Go"}
+	qe := &QuestionsEnricher{modelName: "model-a", version: "v1", maxAttempts: 3, chunkSize: 20, concurrency: 1, request: llm.request}
+	qe.SetCache(&memoryCache{}, 0)
+	qe.SetTagVocabulary(vocabulary, 10)
+
+	run := func() bool {
+		t.Helper()
+		_, report, err := qe.EnrichQuestions(context.Background(), "Go 的 GC", EnrichOptions{})
+		if err != nil {
+			t.Fatalf("EnrichQuestions: %v", err)
+		}
+		return report.Lines[0].Cached
+	}
+
+	if run() {
+		t.Fatal("first run hit an empty cache")
+	}
+	if !run() {
+		t.Error("same vocabulary: want a cache hit")
+	}
+	// 规范标签变化后 prompt 不同，旧结果不再复用
+	*vocabulary = append(*vocabulary, "Redis")
+	if run() {
+		t.Error("changed vocabulary: want a cache miss")
+	}
+	if len(llm.calls) != 2 {
+		t.Errorf("LLM calls = %d, want 2", len(llm.calls))
+	}
+}
+
+func TestEnrichQuestionsCache(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       EnrichOptions
+		wantCached []bool
+		wantCalls  [][]string
+	}{
+		{
+			name:       "cached lines skip the LLM",
+			wantCached: []bool{true, false, true},
+			wantCalls:  [][]string{{"2. Redis 持久化"}},
+		},
+		{
+			name:       "bypass re-requests every line",
+			opts:       EnrichOptions{BypassCache: true},
+			wantCached: []bool{false, false, false},
+			wantCalls:  [][]string{{"1. Go 的 GC", "2. Redis 持久化", "什么是 channel"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &fakeLLM{}
+			cache := &memoryCache{}
+			qe := &QuestionsEnricher{modelName: "model-a", version: "v1", maxAttempts: 3, chunkSize: 20, concurrency: 1, request: llm.request}
+			qe.SetCache(cache, time.Hour)
+
+			// 预先缓存两个问题，输入行的写法与缓存时不同但归一化后一致
+			if err := cache.PutCachedEnrichments(context.Background(), []CacheEntry{
+				{Key: qe.cacheKeyFor("Go的GC", ""), Question: InterviewQuestion{OriginalQuestion: "Go的GC", DetailedQuestion: "cached gc"}},
+				{Key: qe.cacheKeyFor("什么是Channel", ""), Question: InterviewQuestion{OriginalQuestion: "什么是Channel", DetailedQuestion: "cached channel"}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			set, report, err := qe.EnrichQuestions(context.Background(), "1. Go 的 GC\n2. Redis 持久化\n什么是 channel", tt.opts)
+			if err != nil {
+				t.Fatalf("EnrichQuestions: %v", err)
+			}
+			if !reflect.DeepEqual(llm.calls, tt.wantCalls) {
+				t.Errorf("LLM calls = %q, want %q", llm.calls, tt.wantCalls)
+			}
+
+			var cached []bool
+			for _, m := range report.Lines {
+				cached = append(cached, m.Cached)
+				q := set.Questions[m.QuestionIndex]
+				if q.OriginalQuestion != m.Line {
+					t.Errorf("OriginalQuestion = %q, want input line %q", q.OriginalQuestion, m.Line)
+				}
+				if m.Cached && m.Attempts != 0 {
+					t.Errorf("cached line %q has %d attempts, want 0", m.Line, m.Attempts)
+				}
+			}
+			if !reflect.DeepEqual(cached, tt.wantCached) {
+				t.Errorf("cached = %v, want %v", cached, tt.wantCached)
+			}
+			if !tt.opts.BypassCache && cache.maxAge != time.Hour {
+				t.Errorf("lookup maxAge = %v, want %v", cache.maxAge, time.Hour)
+			}
+
+			// 新丰富化的行写回缓存，再次请求时全部命中
+			llm.calls = nil
+			_, report, err = qe.EnrichQuestions(context.Background(), "Redis持久化", EnrichOptions{})
+			if err != nil {
+				t.Fatalf("second EnrichQuestions: %v", err)
+			}
+			if len(llm.calls) != 0 || !report.Lines[0].Cached {
+				t.Errorf("second run made %d LLM calls, cached = %v; want a cache hit", len(llm.calls), report.Lines[0].Cached)
+			}
+		})
+	}
+}
@@ -4,53 +4,408 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"paguu/internal/usage"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
+	"unicode/utf8"
 
 	"github.com/volcengine/volcengine-go-sdk/service/arkruntime"
 	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model/responses"
 )
 
-func loadTemplate(templatePath string) (*template.Template, error) {
+// loadTemplate 加载 prompt 模板，并返回模板内容对应的版本号
+func loadTemplate(templatePath string) (*template.Template, string, error) {
 	content, err := os.ReadFile(templatePath)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	tmpl, err := template.New(templatePath).Parse(string(content))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return tmpl, nil
+	return tmpl, templateVersion(content), nil
+}
+
+// ValidateTemplate 检查 prompt 模板能否读取和解析，用于在保存工作区配置前提前发现错误
+func ValidateTemplate(templatePath string) error {
+	_, _, err := loadTemplate(templatePath)
+	return err
 }
 
+const (
+	// defaultMaxAttempts 是单个分块的最大请求次数 (首次请求 + 失败或缺失行的重新请求)
+	defaultMaxAttempts = 3
+	// defaultChunkSize 是单次 LLM 请求包含的最大问题行数
+	defaultChunkSize = 20
+	// defaultConcurrency 是同时进行中的分块请求上限
+	defaultConcurrency = 3
+)
+
 type QuestionsEnricher struct {
 	modelName      string
 	client         *arkruntime.Client
 	templatePath   string
 	promptTemplate *template.Template
+	version        string // 模板版本，参与缓存 key
+	maxAttempts    int
+	chunkSize      int
+	concurrency    int
+	retryBackoff   time.Duration // 第 n 次重试前等待 n*retryBackoff
+	recorder       usage.Recorder
+	cache          Cache
+	cacheMaxAge    time.Duration
+	vocabulary     TagVocabulary
+	vocabularySize int
+
+	// request 对一组问题行发起一次 LLM 请求，默认为 requestQuestions，测试中可替换
+	request func(ctx context.Context, lines []string, canonicalTags []string) (InterviewQuestionSet, error)
+}
+
+// TagVocabulary 提供已有的规范标签，写入 prompt 以引导 LLM 复用而不是自造标签
+type TagVocabulary interface {
+	CanonicalTags(ctx context.Context, limit int) ([]string, error)
 }
 
 func NewQuestionsEnricher(client *arkruntime.Client, templatePath string, modelName string) (*QuestionsEnricher, error) {
-	tp, err := loadTemplate(templatePath)
+	tp, version, err := loadTemplate(templatePath)
 	if err != nil {
 		return nil, err
 	}
-	return &QuestionsEnricher{
+	qe := &QuestionsEnricher{
 		templatePath:   templatePath,
 		promptTemplate: tp,
+		version:        version,
 		client:         client,
 		modelName:      modelName,
-	}, nil
+		maxAttempts:    defaultMaxAttempts,
+		chunkSize:      defaultChunkSize,
+		concurrency:    defaultConcurrency,
+		retryBackoff:   time.Second,
+	}
+	qe.request = qe.requestQuestions
+	return qe, nil
+}
+
+// SetChunking 设置分块大小和分块并发数，非正数表示使用默认值
+func (qe *QuestionsEnricher) SetChunking(chunkSize, concurrency int) {
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	qe.chunkSize = chunkSize
+	qe.concurrency = concurrency
+}
+
+// SetUsageRecorder 设置用量记录器，为 nil 时不记录
+func (qe *QuestionsEnricher) SetUsageRecorder(recorder usage.Recorder) {
+	qe.recorder = recorder
+}
+
+// SetCache 设置丰富化结果缓存，maxAge 为 0 表示缓存不过期；cache 为 nil 时不使用缓存
+func (qe *QuestionsEnricher) SetCache(cache Cache, maxAge time.Duration) {
+	qe.cache = cache
+	qe.cacheMaxAge = maxAge
+}
+
+// SetTagVocabulary 设置规范标签来源，size 为写入 prompt 的标签数上限；vocabulary 为 nil 时不提供
+func (qe *QuestionsEnricher) SetTagVocabulary(vocabulary TagVocabulary, size int) {
+	qe.vocabulary = vocabulary
+	qe.vocabularySize = size
 }
 
-func (qe *QuestionsEnricher) EnrichQuestions(ctx context.Context, questions string) (InterviewQuestionSet, error) {
+// canonicalTags 读取写入 prompt 的规范标签，失败时只记录日志
+func (qe *QuestionsEnricher) canonicalTags(ctx context.Context) []string {
+	if qe.vocabulary == nil || qe.vocabularySize <= 0 {
+		return nil
+	}
+	tags, err := qe.vocabulary.CanonicalTags(ctx, qe.vocabularySize)
+	if err != nil {
+		slog.Error("load canonical tags error", "error", err)
+		return nil
+	}
+	return tags
+}
+
+// TemplateVersion 返回当前 prompt 模板的版本号
+func (qe *QuestionsEnricher) TemplateVersion() string {
+	return qe.version
+}
+
+// EnrichQuestions 丰富化一批原始问题
+// 输入按行确定性拆分，命中缓存的行直接复用已保存的结果，其余行按 chunkSize 分块并发请求 LLM
+// (并发数受 concurrency 限制)。每个分块的输出会被逐条匹配回输入行：请求失败或缺失的行会在该分块内单独重新请求，
+// 无法对应到任何输入行的输出会记录在 EnrichReport.Hallucinated 中。
+// 返回的问题按输入行顺序排列，且 OriginalQuestion 统一取自输入行原文。
+// 任一分块在用尽重试次数后仍请求失败时，整批返回错误。
+func (qe *QuestionsEnricher) EnrichQuestions(ctx context.Context, questions string, opts EnrichOptions) (InterviewQuestionSet, EnrichReport, error) {
+	lines := SplitQuestionLines(questions)
+	if len(lines) == 0 {
+		return InterviewQuestionSet{}, EnrichReport{}, fmt.Errorf("no question lines in input")
+	}
+
+	// 规范标签写入 prompt，也参与缓存 key，需在查询缓存前读取
+	canonicalTags := qe.canonicalTags(ctx)
+	vocabulary := VocabularyVersion(canonicalTags)
+
+	matched := make([]*InterviewQuestion, len(lines))
+	attempts := make([]int, len(lines))
+	cached := qe.lookupCache(ctx, lines, vocabulary, matched, opts)
+
+	// 未命中缓存的行按输入顺序分块
+	var pending []int
+	for i := range lines {
+		if matched[i] == nil {
+			pending = append(pending, i)
+		}
+	}
+	var chunks [][]int
+	for start := 0; start < len(pending); start += qe.chunkSize {
+		end := min(start+qe.chunkSize, len(pending))
+		chunks = append(chunks, pending[start:end])
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]chunkResult, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, qe.concurrency)
+	var wg sync.WaitGroup
+
+	for i, chunk := range chunks {
+		chunkLines := make([]string, len(chunk))
+		for j, idx := range chunk {
+			chunkLines[j] = lines[idx]
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = qe.enrichChunk(ctx, i, chunkLines, canonicalTags)
+			if errs[i] != nil {
+				cancel() // 整批将失败，提前取消其余分块
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return InterviewQuestionSet{}, EnrichReport{}, fmt.Errorf("enrich chunk %d/%d failed: %w", i+1, len(chunks), err)
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return InterviewQuestionSet{}, EnrichReport{}, err
+		}
+	}
+
+	// 将各分块结果放回对应的输入行
+	var report EnrichReport
+	var fresh []int
+	for i, cr := range results {
+		report.Hallucinated = append(report.Hallucinated, cr.hallucinated...)
+		for j, idx := range chunks[i] {
+			matched[idx] = cr.matched[j]
+			attempts[idx] = cr.attempts[j]
+			if cr.matched[j] != nil {
+				fresh = append(fresh, idx)
+			}
+		}
+	}
+	qe.storeCache(ctx, lines, vocabulary, matched, fresh)
+
+	// 按输入顺序合并
+	var result InterviewQuestionSet
+	report.Lines = make([]LineMapping, len(lines))
+	for i, line := range lines {
+		mapping := LineMapping{
+			LineNo:        i + 1,
+			Line:          line,
+			Status:        LineStatusMissing,
+			Attempts:      attempts[i],
+			Cached:        cached[i],
+			QuestionIndex: -1,
+		}
+		if matched[i] != nil {
+			mapping.Status = LineStatusMatched
+			mapping.QuestionIndex = len(result.Questions)
+			result.Questions = append(result.Questions, *matched[i])
+		}
+		report.Lines[i] = mapping
+	}
+
+	if len(result.Questions) == 0 {
+		return InterviewQuestionSet{}, report, fmt.Errorf("LLM output matched none of the %d input lines", len(lines))
+	}
+
+	return result, report, nil
+}
+
+// cacheKeyFor 返回一行输入在给定规范标签版本下对应的缓存 key
+func (qe *QuestionsEnricher) cacheKeyFor(line, vocabulary string) string {
+	return CacheKey(NormalizeQuestion(line), qe.modelName, qe.version, vocabulary)
+}
+
+// lookupCache 用缓存填充 matched，返回每一行是否命中缓存
+// 缓存查询失败只记录日志，所有行按未命中处理
+func (qe *QuestionsEnricher) lookupCache(ctx context.Context, lines []string, vocabulary string, matched []*InterviewQuestion, opts EnrichOptions) []bool {
+	cached := make([]bool, len(lines))
+	if qe.cache == nil || opts.BypassCache {
+		return cached
+	}
+
+	keys := make([]string, len(lines))
+	for i, line := range lines {
+		keys[i] = qe.cacheKeyFor(line, vocabulary)
+	}
+
+	hits, err := qe.cache.GetCachedEnrichments(ctx, keys, qe.cacheMaxAge)
+	if err != nil {
+		slog.Error("enrichment cache lookup error", "error", err)
+		return cached
+	}
+
+	for i, line := range lines {
+		q, ok := hits[keys[i]]
+		if !ok {
+			continue
+		}
+		q.OriginalQuestion = line
+		matched[i] = &q
+		cached[i] = true
+	}
+	if len(hits) > 0 {
+		slog.Info("enrichment cache hit", "hits", len(hits), "lines", len(lines))
+	}
+	return cached
+}
+
+// storeCache 将本次新丰富化的行写入缓存，写入失败只记录日志
+func (qe *QuestionsEnricher) storeCache(ctx context.Context, lines []string, vocabulary string, matched []*InterviewQuestion, fresh []int) {
+	if qe.cache == nil || len(fresh) == 0 {
+		return
+	}
+
+	entries := make([]CacheEntry, 0, len(fresh))
+	for _, idx := range fresh {
+		normalized := NormalizeQuestion(lines[idx])
+		entries = append(entries, CacheEntry{
+			Key:                CacheKey(normalized, qe.modelName, qe.version, vocabulary),
+			NormalizedQuestion: normalized,
+			Model:              qe.modelName,
+			TemplateVersion:    qe.version,
+			Question:           *matched[idx],
+		})
+	}
+
+	if err := qe.cache.PutCachedEnrichments(ctx, entries); err != nil {
+		slog.Error("enrichment cache store error", "error", err)
+	}
+}
+
+// chunkResult 是单个分块的丰富化结果，matched/attempts 与 lines 一一对应
+type chunkResult struct {
+	lines        []string
+	matched      []*InterviewQuestion
+	attempts     []int
+	hallucinated []InterviewQuestion
+}
+
+// enrichChunk 丰富化一个分块，并在分块内重试失败的请求和缺失的行
+// 仅当所有请求都失败 (没有拿到任何输出) 时返回错误
+func (qe *QuestionsEnricher) enrichChunk(ctx context.Context, chunkIndex int, lines []string, canonicalTags []string) (chunkResult, error) {
+	cr := chunkResult{
+		lines:    lines,
+		matched:  make([]*InterviewQuestion, len(lines)),
+		attempts: make([]int, len(lines)),
+	}
+
+	pending := make([]int, len(lines))
+	for i := range lines {
+		pending[i] = i
+	}
+
+	var lastErr error
+	succeeded := false
+	for attempt := 1; attempt <= qe.maxAttempts && len(pending) > 0; attempt++ {
+		if attempt > 1 {
+			// 简单的线性退避，避免立即重试
+			select {
+			case <-time.After(time.Duration(attempt-1) * qe.retryBackoff):
+			case <-ctx.Done():
+				return cr, ctx.Err()
+			}
+		}
+
+		batch := make([]string, len(pending))
+		for i, idx := range pending {
+			batch[i] = lines[idx]
+			cr.attempts[idx] = attempt
+		}
+
+		questionSet, err := qe.request(ctx, batch, canonicalTags)
+		if err != nil {
+			lastErr = err
+			slog.Warn("enrich chunk request failed", "chunk", chunkIndex, "attempt", attempt, "lines", len(pending), "error", err)
+			continue
+		}
+		succeeded = true
+
+		matcher := newLineMatcher(lines, pending)
+		for _, q := range questionSet.Questions {
+			idx := matcher.match(q.OriginalQuestion)
+			if idx < 0 {
+				slog.Warn("LLM returned question not in input", "chunk", chunkIndex, "original_question", q.OriginalQuestion, "attempt", attempt)
+				cr.hallucinated = append(cr.hallucinated, q)
+				continue
+			}
+			q.OriginalQuestion = lines[idx]
+			cr.matched[idx] = &q
+		}
+
+		stillPending := pending[:0]
+		for _, idx := range pending {
+			if cr.matched[idx] == nil {
+				stillPending = append(stillPending, idx)
+			}
+		}
+		pending = stillPending
+
+		if len(pending) > 0 {
+			slog.Warn("LLM output missing input lines", "chunk", chunkIndex, "attempt", attempt, "missing", len(pending))
+		}
+	}
+
+	if !succeeded && lastErr != nil {
+		return cr, lastErr
+	}
+	return cr, nil
+}
+
+// requestQuestions 对给定的问题行发起一次 LLM 请求，canonicalTags 为空时 prompt 中不包含标签列表
+func (qe *QuestionsEnricher) requestQuestions(ctx context.Context, lines []string, canonicalTags []string) (InterviewQuestionSet, error) {
 	data := map[string]string{
-		"InputText": questions,
+		"InputText":     strings.Join(lines, "\n"),
+		"CanonicalTags": strings.Join(canonicalTags, ", "),
 	}
 
 	var buf bytes.Buffer
@@ -61,22 +416,24 @@ func (qe *QuestionsEnricher) EnrichQuestions(ctx context.Context, questions stri
 	}
 
 	promptString := buf.String()
+	startedAt := time.Now()
 	resp, err := qe.client.CreateResponses(ctx, &responses.ResponsesRequest{
 		Model: qe.modelName,
 		Input: &responses.ResponsesInput{Union: &responses.ResponsesInput_StringValue{StringValue: promptString}},
 	})
+	qe.recordUsage(ctx, resp, time.Since(startedAt), err)
 	if err != nil {
-		fmt.Printf("response error: %v\n", err)
+		slog.ErrorContext(ctx, "enrich response error", "error", err, "model", qe.modelName, "lines", len(lines))
 		return InterviewQuestionSet{}, err
 	}
 
 	// Extract JSON text content from the response and unmarshal
-	text := extractTextFromSingleResponse(resp)
+	text := ExtractResponseText(resp)
 	if strings.TrimSpace(text) == "" {
 		return InterviewQuestionSet{}, fmt.Errorf("empty response text")
 	}
 
-	slog.Info(text)
+	slog.DebugContext(ctx, "enrich response", "length", len(text), "text", truncateForLog(text, logTextLimit))
 
 	var questionSet InterviewQuestionSet
 	if err := json.Unmarshal([]byte(text), &questionSet); err != nil {
@@ -86,7 +443,40 @@ func (qe *QuestionsEnricher) EnrichQuestions(ctx context.Context, questions stri
 	return questionSet, nil
 }
 
-func extractTextFromSingleResponse(resp *responses.ResponseObject) string {
+// logTextLimit 是调试日志中模型输出的最大字符数
+const logTextLimit = 500
+
+// truncateForLog 将 s 截断为最多 limit 个字符，截断时追加省略号
+func truncateForLog(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:limit]) + "…"
+}
+
+// recordUsage 记录一次 Ark 调用的用量
+func (qe *QuestionsEnricher) recordUsage(ctx context.Context, resp *responses.ResponseObject, latency time.Duration, err error) {
+	if qe.recorder == nil {
+		return
+	}
+	event := usage.Event{
+		Provider:  usage.ProviderArk,
+		Operation: usage.OperationEnrich,
+		Model:     qe.modelName,
+		Latency:   latency,
+		Err:       err,
+	}
+	if resp != nil && resp.Usage != nil {
+		event.PromptTokens = resp.Usage.InputTokens
+		event.CompletionTokens = resp.Usage.OutputTokens
+		event.TotalTokens = resp.Usage.TotalTokens
+	}
+	qe.recorder.Record(ctx, event)
+}
+
+// ExtractResponseText 拼接 Ark Responses 返回中的所有输出文本
+func ExtractResponseText(resp *responses.ResponseObject) string {
 	if resp == nil {
 		return ""
 	}
@@ -0,0 +1,174 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// fakeLLM 为每个输入行返回一个问题，并按配置让请求失败或漏掉某些行
+type fakeLLM struct {
+	mu    sync.Mutex
+	fail  map[string]int    // 包含该行的请求还要失败的次数
+	drop  map[string]int    // 该行还要从输出中漏掉的次数
+	extra map[string]string // 包含该行的请求额外返回一个不在输入中的问题
+	calls [][]string
+}
+
+func (f *fakeLLM) request(_ context.Context, lines []string, _ []string) (InterviewQuestionSet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, append([]string(nil), lines...))
+
+	for _, line := range lines {
+		if f.fail[line] > 0 {
+			f.fail[line]--
+			return InterviewQuestionSet{}, errors.New("llm unavailable")
+		}
+	}
+
+	var set InterviewQuestionSet
+	for _, line := range lines {
+		if extra, ok := f.extra[line]; ok {
+			set.Questions = append(set.Questions, InterviewQuestion{OriginalQuestion: extra})
+		}
+		if f.drop[line] > 0 {
+			f.drop[line]--
+			continue
+		}
+		set.Questions = append(set.Questions, InterviewQuestion{OriginalQuestion: line, DetailedQuestion: "详细: " + line})
+	}
+	return set, nil
+}
+
+func TestEnrichQuestionsChunks(t *testing.T) {
+	input := "a\nb\nc\nd\ne"
+
+	tests := []struct {
+		name             string
+		llm              *fakeLLM
+		wantErr          bool
+		wantStatus       []string
+		wantAttempts     []int
+		wantCalls        int // -1 表示不检查
+		wantHallucinated int
+	}{
+		{
+			name:         "chunks in input order",
+			wantStatus:   []string{"matched", "matched", "matched", "matched", "matched"},
+			wantAttempts: []int{1, 1, 1, 1, 1},
+			wantCalls:    3,
+		},
+		{
+			name:         "missing line re-requested alone",
+			llm:          &fakeLLM{drop: map[string]int{"b": 1}},
+			wantStatus:   []string{"matched", "matched", "matched", "matched", "matched"},
+			wantAttempts: []int{1, 2, 1, 1, 1},
+			wantCalls:    4,
+		},
+		{
+			name:         "line still missing after all attempts",
+			llm:          &fakeLLM{drop: map[string]int{"b": 3}},
+			wantStatus:   []string{"matched", "missing", "matched", "matched", "matched"},
+			wantAttempts: []int{1, 3, 1, 1, 1},
+			wantCalls:    5,
+		},
+		{
+			name:         "failed request retries the whole chunk",
+			llm:          &fakeLLM{fail: map[string]int{"c": 1}},
+			wantStatus:   []string{"matched", "matched", "matched", "matched", "matched"},
+			wantAttempts: []int{1, 1, 2, 2, 1},
+			wantCalls:    4,
+		},
+		{
+			name:      "chunk failing every attempt fails the batch",
+			llm:       &fakeLLM{fail: map[string]int{"c": 3}},
+			wantErr:   true,
+			wantCalls: -1,
+		},
+		{
+			name:             "hallucinated output is reported",
+			llm:              &fakeLLM{extra: map[string]string{"a": "完全无关的问题"}},
+			wantStatus:       []string{"matched", "matched", "matched", "matched", "matched"},
+			wantAttempts:     []int{1, 1, 1, 1, 1},
+			wantCalls:        3,
+			wantHallucinated: 1,
+		},
+		{
+			name:      "nothing matched",
+			llm:       &fakeLLM{drop: map[string]int{"a": 3, "b": 3, "c": 3, "d": 3, "e": 3}},
+			wantErr:   true,
+			wantCalls: 9,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := tt.llm
+			if llm == nil {
+				llm = &fakeLLM{}
+			}
+			qe := &QuestionsEnricher{maxAttempts: 3, chunkSize: 2, concurrency: 2, request: llm.request}
+
+			set, report, err := qe.EnrichQuestions(context.Background(), input, EnrichOptions{})
+			if tt.wantCalls >= 0 && len(llm.calls) != tt.wantCalls {
+				t.Errorf("made %d requests, want %d: %q", len(llm.calls), tt.wantCalls, llm.calls)
+			}
+			for _, call := range llm.calls {
+				if len(call) > qe.chunkSize {
+					t.Errorf("request with %d lines exceeds chunk size %d", len(call), qe.chunkSize)
+				}
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("EnrichQuestions succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EnrichQuestions: %v", err)
+			}
+
+			var status []string
+			var attempts []int
+			for i, m := range report.Lines {
+				status = append(status, m.Status)
+				attempts = append(attempts, m.Attempts)
+				if m.LineNo != i+1 {
+					t.Errorf("Lines[%d].LineNo = %d, want %d", i, m.LineNo, i+1)
+				}
+				if m.QuestionIndex >= 0 && set.Questions[m.QuestionIndex].OriginalQuestion != m.Line {
+					t.Errorf("line %q maps to question %q", m.Line, set.Questions[m.QuestionIndex].OriginalQuestion)
+				}
+			}
+			if !reflect.DeepEqual(status, tt.wantStatus) {
+				t.Errorf("status = %v, want %v", status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(attempts, tt.wantAttempts) {
+				t.Errorf("attempts = %v, want %v", attempts, tt.wantAttempts)
+			}
+			if len(report.Hallucinated) != tt.wantHallucinated {
+				t.Errorf("hallucinated = %d, want %d", len(report.Hallucinated), tt.wantHallucinated)
+			}
+		})
+	}
+}
+
+func TestTruncateForLog(t *testing.T) {
+	tests := []struct {
+		s     string
+		limit int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"truncated text", 9, "truncated…"},
+		{"中文不会被截成半个字符", 4, "中文不会…"},
+	}
+	for _, tt := range tests {
+		if got := truncateForLog(tt.s, tt.limit); got != tt.want {
+			t.Errorf("truncateForLog(%q, %d) = %q, want %q", tt.s, tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package enrich
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 行映射状态
+const (
+	LineStatusMatched = "matched" // LLM 输出中找到了对应问题
+	LineStatusMissing = "missing" // 重试后仍未找到对应问题
+)
+
+// LineMapping 记录一行输入与 LLM 输出之间的对应关系
+type LineMapping struct {
+	LineNo        int    `json:"line_no"` // 非空行序号，从 1 开始
+	Line          string `json:"line"`
+	Status        string `json:"status"`         // matched/missing
+	Attempts      int    `json:"attempts"`       // 第几次请求时匹配成功 (missing 时为总请求次数，命中缓存时为 0)
+	Cached        bool   `json:"cached"`         // 是否直接复用了缓存的丰富化结果
+	QuestionIndex int    `json:"question_index"` // 在返回的 InterviewQuestionSet 中的下标，missing 时为 -1
+}
+
+// EnrichReport 描述一次丰富化的覆盖情况
+type EnrichReport struct {
+	Lines []LineMapping `json:"lines"`
+	// Hallucinated 是 LLM 返回的、无法对应到任何输入行的问题
+	Hallucinated []InterviewQuestion `json:"hallucinated,omitempty"`
+}
+
+// MissingLines 返回仍未被覆盖的输入行
+func (r *EnrichReport) MissingLines() []string {
+	var missing []string
+	for _, l := range r.Lines {
+		if l.Status == LineStatusMissing {
+			missing = append(missing, l.Line)
+		}
+	}
+	return missing
+}
+
+// SplitQuestionLines 将原始输入确定性地拆分为问题行：按换行切分、去除首尾空白并丢弃空行
+func SplitQuestionLines(raw string) []string {
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	lines := make([]string, 0)
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// linePrefixPattern 匹配 "4. "、"8、"、"(3)"、"- " 一类的序号或列表前缀
+var linePrefixPattern = regexp.MustCompile(`^\s*(?:[\(（]?\d+[\)）]?\s*[\.、:：\)）]?|[-*•])\s*`)
+
+// NormalizeQuestion 生成用于宽松匹配和缓存的 key：去掉序号前缀、所有空白并转为小写
+func NormalizeQuestion(line string) string {
+	line = linePrefixPattern.ReplaceAllString(line, "")
+	line = strings.Join(strings.Fields(line), "")
+	return strings.ToLower(line)
+}
+
+// lineMatcher 将 LLM 输出的 original_question 匹配回输入行
+// 先按去除首尾空白后的原文精确匹配，再按 NormalizeQuestion 宽松匹配；重复的输入行按出现顺序依次匹配
+type lineMatcher struct {
+	exact      map[string][]int
+	normalized map[string][]int
+}
+
+func newLineMatcher(lines []string, indexes []int) *lineMatcher {
+	m := &lineMatcher{
+		exact:      make(map[string][]int),
+		normalized: make(map[string][]int),
+	}
+	for _, idx := range indexes {
+		line := lines[idx]
+		m.exact[line] = append(m.exact[line], idx)
+		key := NormalizeQuestion(line)
+		m.normalized[key] = append(m.normalized[key], idx)
+	}
+	return m
+}
+
+// match 返回匹配到的输入行下标，未匹配时返回 -1
+func (m *lineMatcher) match(question string) int {
+	if idx, ok := m.take(m.exact, strings.TrimSpace(question)); ok {
+		return idx
+	}
+	if idx, ok := m.take(m.normalized, NormalizeQuestion(question)); ok {
+		return idx
+	}
+	return -1
+}
+
+// take 从候选队列中取出第一个尚未被占用的行，并同步从另一张表中移除
+func (m *lineMatcher) take(table map[string][]int, key string) (int, bool) {
+	candidates := table[key]
+	if len(candidates) == 0 {
+		return -1, false
+	}
+	idx := candidates[0]
+	table[key] = candidates[1:]
+	m.remove(m.exact, idx)
+	m.remove(m.normalized, idx)
+	return idx, true
+}
+
+func (m *lineMatcher) remove(table map[string][]int, idx int) {
+	for key, candidates := range table {
+		for i, c := range candidates {
+			if c == idx {
+				table[key] = append(candidates[:i:i], candidates[i+1:]...)
+				return
+			}
+		}
+	}
+}
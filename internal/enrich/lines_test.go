@@ -0,0 +1,113 @@
+package enrich
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitQuestionLines(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{"empty", "", []string{}},
+		{"blank lines only", "\n  \n\t\n", []string{}},
+		{"trims and drops blank lines", "  Go 的 GC  \n\n什么是 channel\n", []string{"Go 的 GC", "什么是 channel"}},
+		{"crlf", "a\r\nb\r\n", []string{"a", "b"}},
+		{"keeps duplicates in order", "a\nb\na", []string{"a", "b", "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitQuestionLines(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitQuestionLines(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeQuestion(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"Go 的 GC", "go的gc"},
+		{"4. Go 的 GC", "go的gc"},
+		{"8、Go 的 GC", "go的gc"},
+		{"(3) Go 的 GC", "go的gc"},
+		{"（3）Go 的 GC", "go的gc"},
+		{"12: Go 的 GC", "go的gc"},
+		{"- Go 的 GC", "go的gc"},
+		{"* Go 的 GC", "go的gc"},
+		{"• Go 的 GC", "go的gc"},
+		{"  MySQL   索引\t原理 ", "mysql索引原理"},
+		// 只去掉行首的前缀
+		{"HTTP/2 和 HTTP 1.1 的区别", "http/2和http1.1的区别"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeQuestion(tt.line); got != tt.want {
+			t.Errorf("NormalizeQuestion(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestLineMatcher(t *testing.T) {
+	lines := []string{"Go 的 GC", "1. 什么是 channel", "Go 的 GC", "Redis 持久化"}
+
+	tests := []struct {
+		name      string
+		indexes   []int    // 参与匹配的输入行
+		questions []string // 依次匹配的 LLM 输出
+		want      []int
+	}{
+		{
+			name:      "exact",
+			indexes:   []int{0, 1, 2, 3},
+			questions: []string{"Redis 持久化", "1. 什么是 channel"},
+			want:      []int{3, 1},
+		},
+		{
+			name:      "normalized",
+			indexes:   []int{0, 1, 2, 3},
+			questions: []string{"什么是Channel", "  redis持久化 "},
+			want:      []int{1, 3},
+		},
+		{
+			name:      "duplicate lines match in order",
+			indexes:   []int{0, 1, 2, 3},
+			questions: []string{"Go 的 GC", "go的gc", "Go 的 GC"},
+			want:      []int{0, 2, -1},
+		},
+		{
+			name:      "exact match consumes the normalized candidate",
+			indexes:   []int{1},
+			questions: []string{"1. 什么是 channel", "什么是 channel"},
+			want:      []int{1, -1},
+		},
+		{
+			name:      "only listed indexes",
+			indexes:   []int{2, 3},
+			questions: []string{"Go 的 GC", "什么是 channel", "Go 的 GC"},
+			want:      []int{2, -1, -1},
+		},
+		{
+			name:      "hallucinated",
+			indexes:   []int{0, 1, 2, 3},
+			questions: []string{"Kafka 如何保证顺序"},
+			want:      []int{-1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newLineMatcher(lines, tt.indexes)
+			got := make([]int, len(tt.questions))
+			for i, q := range tt.questions {
+				got[i] = m.match(q)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("match(%q) = %v, want %v", tt.questions, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// 位于: internal/eval/golden.go
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// GoldenQuery 是一条评测查询及其期望命中的文章
+// 期望结果可以用文章 ID 或原始问题文本 (按 enrich.NormalizeQuestion 归一化后比较) 指定，两者可混用
+type GoldenQuery struct {
+	ID                string   `json:"id"`
+	Query             string   `json:"query"`
+	ExpectedIDs       []uint   `json:"expected_ids,omitempty"`
+	ExpectedQuestions []string `json:"expected_questions,omitempty"`
+}
+
+// GoldenSet 是一组评测查询
+type GoldenSet struct {
+	Name    string        `json:"name"`
+	Queries []GoldenQuery `json:"queries"`
+}
+
+// LoadGoldenSet 从 JSON 文件加载评测集
+func LoadGoldenSet(path string) (*GoldenSet, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden file: %w", err)
+	}
+
+	var set GoldenSet
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse golden file: %w", err)
+	}
+
+	for i := range set.Queries {
+		q := &set.Queries[i]
+		if strings.TrimSpace(q.Query) == "" {
+			return nil, fmt.Errorf("golden query #%d has empty query", i+1)
+		}
+		if len(q.ExpectedIDs) == 0 && len(q.ExpectedQuestions) == 0 {
+			return nil, fmt.Errorf("golden query #%d (%q) has no expected results", i+1, q.Query)
+		}
+		if q.ID == "" {
+			q.ID = fmt.Sprintf("q%d", i+1)
+		}
+	}
+	if set.Name == "" {
+		set.Name = path
+	}
+
+	return &set, nil
+}
@@ -0,0 +1,47 @@
+package eval
+
+import "math"
+
+// RecallAtK 返回前 k 个结果中命中的期望结果占全部期望结果的比例
+// relevant[i] 表示第 i 个结果是否相关，expected 为期望结果总数
+func RecallAtK(relevant []bool, expected, k int) float64 {
+	if expected == 0 {
+		return 0
+	}
+	hits := 0
+	for i := 0; i < len(relevant) && i < k; i++ {
+		if relevant[i] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(expected)
+}
+
+// ReciprocalRank 返回第一个相关结果排名的倒数，没有相关结果时为 0
+func ReciprocalRank(relevant []bool) float64 {
+	for i, r := range relevant {
+		if r {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// NDCGAtK 按二元相关性计算 nDCG@k
+func NDCGAtK(relevant []bool, expected, k int) float64 {
+	var dcg float64
+	for i := 0; i < len(relevant) && i < k; i++ {
+		if relevant[i] {
+			dcg += 1 / math.Log2(float64(i+2))
+		}
+	}
+
+	var idcg float64
+	for i := 0; i < expected && i < k; i++ {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+	if idcg == 0 {
+		return 0
+	}
+	return dcg / idcg
+}
@@ -0,0 +1,92 @@
+package eval
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	d := func(rank int) float64 { return 1 / math.Log2(float64(rank+1)) } // 第 rank 名的折损增益
+
+	tests := []struct {
+		name       string
+		relevant   []bool
+		expected   int
+		k          int
+		wantRecall float64
+		wantRR     float64
+		wantNDCG   float64
+	}{
+		{"no hits", nil, 2, 10, 0, 0, 0},
+		{"no expected results", []bool{true}, 0, 10, 0, 1, 0},
+		{"perfect", []bool{true, true, false}, 2, 10, 1, 1, 1},
+		{"first hit at rank 2", []bool{false, true, false}, 1, 10, 1, 0.5, d(2)},
+		{"hits at ranks 1 and 3", []bool{true, false, true}, 2, 3, 1, 1, (d(1) + d(3)) / (d(1) + d(2))},
+		{"hit beyond k", []bool{false, false, true}, 1, 2, 0, 1.0 / 3, 0},
+		{"partial recall", []bool{false, true, false, false}, 4, 4, 0.25, 0.5, d(2) / (d(1) + d(2) + d(3) + d(4))},
+		{"ideal limited by k", []bool{true, true}, 5, 2, 0.4, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RecallAtK(tt.relevant, tt.expected, tt.k); math.Abs(got-tt.wantRecall) > 1e-9 {
+				t.Errorf("RecallAtK = %v, want %v", got, tt.wantRecall)
+			}
+			if got := ReciprocalRank(tt.relevant); math.Abs(got-tt.wantRR) > 1e-9 {
+				t.Errorf("ReciprocalRank = %v, want %v", got, tt.wantRR)
+			}
+			if got := NDCGAtK(tt.relevant, tt.expected, tt.k); math.Abs(got-tt.wantNDCG) > 1e-9 {
+				t.Errorf("NDCGAtK = %v, want %v", got, tt.wantNDCG)
+			}
+		})
+	}
+}
+
+func TestJudge(t *testing.T) {
+	hits := []Hit{
+		{ArticleID: 1, OriginalQuestion: "Go 的 GC"},
+		{ArticleID: 2, OriginalQuestion: "3. 什么是 Channel"},
+		{ArticleID: 3, OriginalQuestion: "Redis 持久化"},
+		{ArticleID: 4, OriginalQuestion: "什么是channel"},
+	}
+
+	tests := []struct {
+		name         string
+		query        GoldenQuery
+		wantRelevant []bool
+		wantExpected int
+	}{
+		{
+			name:         "by id",
+			query:        GoldenQuery{ExpectedIDs: []uint{3, 9}},
+			wantRelevant: []bool{false, false, true, false},
+			wantExpected: 2,
+		},
+		{
+			name:         "by normalized question, counted once",
+			query:        GoldenQuery{ExpectedQuestions: []string{"什么是 channel"}},
+			wantRelevant: []bool{false, true, false, false},
+			wantExpected: 1,
+		},
+		{
+			name:         "mixed",
+			query:        GoldenQuery{ExpectedIDs: []uint{1}, ExpectedQuestions: []string{"redis持久化"}},
+			wantRelevant: []bool{true, false, true, false},
+			wantExpected: 2,
+		},
+		{
+			name:         "duplicate expectations collapse",
+			query:        GoldenQuery{ExpectedIDs: []uint{1, 1}, ExpectedQuestions: []string{"Go的GC", "go 的 gc"}},
+			wantRelevant: []bool{true, false, false, false},
+			wantExpected: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relevant, expected := judge(tt.query, hits)
+			if !reflect.DeepEqual(relevant, tt.wantRelevant) || expected != tt.wantExpected {
+				t.Errorf("judge = %v, %d, want %v, %d", relevant, expected, tt.wantRelevant, tt.wantExpected)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"paguu/internal/enrich"
+	"paguu/internal/rerank"
+	"paguu/internal/storage/postgres"
+	"strings"
+	"text/tabwriter"
+)
+
+// Hit 是一次检索返回的单条结果
+type Hit struct {
+	ArticleID        uint    `json:"article_id"`
+	OriginalQuestion string  `json:"original_question"`
+	Score            float64 `json:"score"`
+	Text             string  `json:"-"` // 供重排序使用的文章文本
+}
+
+// Searcher 是被评测的检索方式 (纯向量、混合、重排序等)
+type Searcher interface {
+	Search(ctx context.Context, query string, k int) ([]Hit, error)
+}
+
+// QueryEmbedder 生成查询向量，embedding.Embedder 或任何离线实现均可
+type QueryEmbedder interface {
+	EmbedQuery(ctx context.Context, text string) ([]float32, error)
+}
+
+// ArticleSearcher 执行向量检索，由 postgres.Repository 实现
+type ArticleSearcher interface {
+	VectorSearchArticles(ctx context.Context, queryVector []float32, limit int) ([]postgres.Article, []float64, error)
+}
+
+// VectorSearcher 通过 VectorSearchArticles 执行纯向量检索
+type VectorSearcher struct {
+	embedder QueryEmbedder
+	repo     ArticleSearcher
+}
+
+func NewVectorSearcher(embedder QueryEmbedder, repo ArticleSearcher) *VectorSearcher {
+	return &VectorSearcher{embedder: embedder, repo: repo}
+}
+
+func (s *VectorSearcher) Search(ctx context.Context, query string, k int) ([]Hit, error) {
+	vector, err := s.embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	articles, similarities, err := s.repo.VectorSearchArticles(ctx, vector, k)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, len(articles))
+	for i, article := range articles {
+		hits[i] = Hit{
+			ArticleID:        article.ID,
+			OriginalQuestion: article.OriginalQuestion,
+			Score:            similarities[i],
+			Text:             rerank.DocumentText(article.OriginalQuestion, article.DetailedQuestion, article.ConciseAnswer),
+		}
+	}
+	return hits, nil
+}
+
+// QueryResult 是单条查询的评测结果
+type QueryResult struct {
+	ID       string  `json:"id"`
+	Query    string  `json:"query"`
+	Hits     []Hit   `json:"hits"`
+	Relevant []bool  `json:"relevant"`
+	Recall   float64 `json:"recall"`
+	RR       float64 `json:"reciprocal_rank"`
+	NDCG     float64 `json:"ndcg"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// Report 是一个配置在整个评测集上的结果，指标为所有成功查询的平均值
+type Report struct {
+	Config  string        `json:"config"`
+	K       int           `json:"k"`
+	Queries []QueryResult `json:"queries"`
+	Recall  float64       `json:"recall_at_k"`
+	MRR     float64       `json:"mrr"`
+	NDCG    float64       `json:"ndcg_at_k"`
+	Errors  int           `json:"errors"`
+}
+
+// Run 用给定的检索方式跑完整个评测集
+func Run(ctx context.Context, configName string, set *GoldenSet, searcher Searcher, k int) *Report {
+	report := &Report{
+		Config:  configName,
+		K:       k,
+		Queries: make([]QueryResult, 0, len(set.Queries)),
+	}
+
+	succeeded := 0
+	for _, gq := range set.Queries {
+		result := QueryResult{ID: gq.ID, Query: gq.Query}
+
+		hits, err := searcher.Search(ctx, gq.Query, k)
+		if err != nil {
+			slog.Error("eval query failed", "config", configName, "query_id", gq.ID, "error", err)
+			result.Error = err.Error()
+			report.Errors++
+			report.Queries = append(report.Queries, result)
+			continue
+		}
+
+		relevant, expected := judge(gq, hits)
+		result.Hits = hits
+		result.Relevant = relevant
+		result.Recall = RecallAtK(relevant, expected, k)
+		result.RR = ReciprocalRank(relevant)
+		result.NDCG = NDCGAtK(relevant, expected, k)
+
+		report.Recall += result.Recall
+		report.MRR += result.RR
+		report.NDCG += result.NDCG
+		succeeded++
+		report.Queries = append(report.Queries, result)
+	}
+
+	if succeeded > 0 {
+		report.Recall /= float64(succeeded)
+		report.MRR /= float64(succeeded)
+		report.NDCG /= float64(succeeded)
+	}
+	return report
+}
+
+// judge 判断每条结果是否相关，每个期望结果最多被命中一次；返回相关性列表和期望结果总数
+func judge(gq GoldenQuery, hits []Hit) ([]bool, int) {
+	ids := make(map[uint]bool, len(gq.ExpectedIDs))
+	for _, id := range gq.ExpectedIDs {
+		ids[id] = true
+	}
+	questions := make(map[string]bool, len(gq.ExpectedQuestions))
+	for _, q := range gq.ExpectedQuestions {
+		questions[enrich.NormalizeQuestion(q)] = true
+	}
+	expected := len(ids) + len(questions)
+
+	relevant := make([]bool, len(hits))
+	for i, hit := range hits {
+		if ids[hit.ArticleID] {
+			delete(ids, hit.ArticleID)
+			relevant[i] = true
+			continue
+		}
+		key := enrich.NormalizeQuestion(hit.OriginalQuestion)
+		if questions[key] {
+			delete(questions, key)
+			relevant[i] = true
+		}
+	}
+	return relevant, expected
+}
+
+// WriteComparison 以表格形式输出一个或多个配置的汇总指标，以及各配置表现不同的查询
+func WriteComparison(w io.Writer, reports ...*Report) error {
+	if len(reports) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := []string{"metric"}
+	for _, r := range reports {
+		header = append(header, r.Config)
+	}
+	// 两个配置对比时额外输出第一个配置相对第二个的差值
+	if len(reports) == 2 {
+		header = append(header, "delta")
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	rows := []struct {
+		name   string
+		metric func(*Report) float64
+		format string
+	}{
+		{fmt.Sprintf("recall@%d", reports[0].K), func(r *Report) float64 { return r.Recall }, "%.4f"},
+		{"mrr", func(r *Report) float64 { return r.MRR }, "%.4f"},
+		{fmt.Sprintf("ndcg@%d", reports[0].K), func(r *Report) float64 { return r.NDCG }, "%.4f"},
+		{"errors", func(r *Report) float64 { return float64(r.Errors) }, "%.0f"},
+	}
+	for _, row := range rows {
+		cells := []string{row.name}
+		for _, r := range reports {
+			cells = append(cells, fmt.Sprintf(row.format, row.metric(r)))
+		}
+		if len(reports) == 2 {
+			cells = append(cells, fmt.Sprintf("%+"+row.format[1:], row.metric(reports[0])-row.metric(reports[1])))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(reports) < 2 {
+		return nil
+	}
+
+	// 列出各配置首个相关结果排名不同的查询，便于定位收益或退化
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header = []string{"query_id", "query"}
+	for _, r := range reports {
+		header = append(header, "rr:"+r.Config)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for i, q := range reports[0].Queries {
+		cells := []string{q.ID, q.Query}
+		differs := false
+		for _, r := range reports {
+			if i >= len(r.Queries) {
+				cells = append(cells, "-")
+				continue
+			}
+			cells = append(cells, fmt.Sprintf("%.3f", r.Queries[i].RR))
+			if r.Queries[i].RR != q.RR {
+				differs = true
+			}
+		}
+		if differs {
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+	}
+	return tw.Flush()
+}
+
+// RerankSearcher 先用向量检索取 candidates 个候选，再用重排序器重新排序
+type RerankSearcher struct {
+	base       *VectorSearcher
+	reranker   rerank.Reranker
+	candidates int
+}
+
+func NewRerankSearcher(base *VectorSearcher, reranker rerank.Reranker, candidates int) *RerankSearcher {
+	return &RerankSearcher{base: base, reranker: reranker, candidates: candidates}
+}
+
+func (s *RerankSearcher) Search(ctx context.Context, query string, k int) ([]Hit, error) {
+	hits, err := s.base.Search(ctx, query, max(s.candidates, k))
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]rerank.Document, len(hits))
+	for i, hit := range hits {
+		docs[i] = rerank.Document{ID: hit.ArticleID, Text: hit.Text}
+	}
+	results, err := rerank.Order(ctx, s.reranker, query, docs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rerank: %w", err)
+	}
+
+	reranked := make([]Hit, 0, min(k, len(results)))
+	for _, r := range results[:min(k, len(results))] {
+		hit := hits[r.Index]
+		hit.Score = r.Score
+		reranked = append(reranked, hit)
+	}
+	return reranked, nil
+}
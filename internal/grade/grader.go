@@ -0,0 +1,135 @@
+package grade
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"paguu/internal/enrich"
+	"paguu/internal/usage"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime"
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model/responses"
+)
+
+// Reference 是评分所依据的题目和参考答案
+type Reference struct {
+	Question        string // 优先使用专业化的问题描述
+	ReferenceAnswer string // 关键词密集的参考答案，可以为空
+	Tags            []string
+}
+
+// Result 是 LLM 对一次作答的评分
+type Result struct {
+	Score           int      `json:"score"` // 0-100
+	CoveredConcepts []string `json:"covered_concepts"`
+	MissedConcepts  []string `json:"missed_concepts"`
+	FollowUps       []string `json:"follow_ups"`
+	Feedback        string   `json:"feedback"`
+}
+
+// Grader 通过丰富化所用的 Ark 模型，对照参考答案为用户的作答评分
+type Grader struct {
+	client         *arkruntime.Client
+	modelName      string
+	promptTemplate *template.Template
+	recorder       usage.Recorder
+}
+
+func NewGrader(client *arkruntime.Client, templatePath string, modelName string) (*Grader, error) {
+	if client == nil {
+		return nil, fmt.Errorf("ark client is required")
+	}
+	if modelName == "" {
+		return nil, fmt.Errorf("grade model name is required")
+	}
+
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(templatePath).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Grader{
+		client:         client,
+		modelName:      modelName,
+		promptTemplate: tmpl,
+	}, nil
+}
+
+// SetUsageRecorder 设置用量记录器，为 nil 时不记录
+func (g *Grader) SetUsageRecorder(recorder usage.Recorder) {
+	g.recorder = recorder
+}
+
+// Model 返回评分使用的模型名
+func (g *Grader) Model() string {
+	return g.modelName
+}
+
+func (g *Grader) Grade(ctx context.Context, ref Reference, answer string) (*Result, error) {
+	var buf bytes.Buffer
+	err := g.promptTemplate.Execute(&buf, map[string]interface{}{
+		"Question":        ref.Question,
+		"ReferenceAnswer": ref.ReferenceAnswer,
+		"Tags":            ref.Tags,
+		"Answer":          answer,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	resp, err := g.client.CreateResponses(ctx, &responses.ResponsesRequest{
+		Model: g.modelName,
+		Input: &responses.ResponsesInput{Union: &responses.ResponsesInput_StringValue{StringValue: buf.String()}},
+	})
+	g.recordUsage(ctx, resp, time.Since(startedAt), err)
+	if err != nil {
+		return nil, fmt.Errorf("grade request error: %w", err)
+	}
+
+	return parseResult(enrich.ExtractResponseText(resp))
+}
+
+// parseResult 解析模型返回的评分 JSON，分数限制在 0-100
+func parseResult(text string) (*Result, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("empty grade response text")
+	}
+
+	var result Result
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal grade response JSON: %w", err)
+	}
+	result.Score = min(max(result.Score, 0), 100)
+	result.Feedback = strings.TrimSpace(result.Feedback)
+	return &result, nil
+}
+
+// recordUsage 记录一次评分调用的用量
+func (g *Grader) recordUsage(ctx context.Context, resp *responses.ResponseObject, latency time.Duration, err error) {
+	if g.recorder == nil {
+		return
+	}
+	event := usage.Event{
+		Provider:  usage.ProviderArk,
+		Operation: usage.OperationGrade,
+		Model:     g.modelName,
+		Latency:   latency,
+		Err:       err,
+	}
+	if resp != nil && resp.Usage != nil {
+		event.PromptTokens = resp.Usage.InputTokens
+		event.CompletionTokens = resp.Usage.OutputTokens
+		event.TotalTokens = resp.Usage.TotalTokens
+	}
+	g.recorder.Record(ctx, event)
+}
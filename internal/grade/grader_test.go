@@ -0,0 +1,69 @@
+package grade
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    *Result
+		wantErr bool
+	}{
+		{
+			name: "full response",
+			text: `{"score": 72, "covered_concepts": ["三色标记"], "missed_concepts": ["写屏障"], "follow_ups": ["STW 发生在哪些阶段？"], "feedback": "  基本正确，缺少写屏障。\n"}`,
+			want: &Result{
+				Score:           72,
+				CoveredConcepts: []string{"三色标记"},
+				MissedConcepts:  []string{"写屏障"},
+				FollowUps:       []string{"STW 发生在哪些阶段？"},
+				Feedback:        "基本正确，缺少写屏障。",
+			},
+		},
+		{
+			name: "missing fields are empty",
+			text: `{"score": 40}`,
+			want: &Result{Score: 40},
+		},
+		{
+			name: "unknown fields are ignored",
+			text: `{"score": 90, "confidence": 0.8}`,
+			want: &Result{Score: 90},
+		},
+		{
+			name: "score above 100 is clamped",
+			text: `{"score": 120}`,
+			want: &Result{Score: 100},
+		},
+		{
+			name: "negative score is clamped",
+			text: `{"score": -5}`,
+			want: &Result{Score: 0},
+		},
+		{name: "empty", text: "", wantErr: true},
+		{name: "whitespace", text: " \n\t", wantErr: true},
+		{name: "not json", text: "得分：80", wantErr: true},
+		{name: "truncated json", text: `{"score": 80, "feedback": "不完`, wantErr: true},
+		{name: "score is not a number", text: `{"score": "80"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseResult(tt.text)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseResult(%q) = %+v, want error", tt.text, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseResult(%q): %v", tt.text, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseResult(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
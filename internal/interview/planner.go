@@ -0,0 +1,338 @@
+package interview
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"paguu/internal/storage/postgres"
+	"sort"
+	"time"
+)
+
+// 候选人级别
+const (
+	LevelJunior = "junior"
+	LevelMid    = "mid"
+	LevelSenior = "senior"
+)
+
+// 题目深度范围
+const (
+	MinDepth = 1
+	MaxDepth = 5
+)
+
+// DefaultDuplicateSimilarity 是两道题被视为近似重复的默认余弦相似度阈值
+const DefaultDuplicateSimilarity = 0.9
+
+// levelTargets 是各级别的目标平均深度，选题时优先靠近该深度
+var levelTargets = map[string]float64{
+	LevelJunior: 2,
+	LevelMid:    3,
+	LevelSenior: 4,
+}
+
+// ValidLevel 判断级别是否合法
+func ValidLevel(level string) bool {
+	_, ok := levelTargets[level]
+	return ok
+}
+
+// Store 是组卷所需的存储接口，由 *postgres.Repository 实现
+type Store interface {
+	ListArticles(ctx context.Context, opts postgres.ListArticlesOptions) ([]postgres.ArticleWithOccurrences, int64, error)
+}
+
+// Options 是一次组卷的参数
+type Options struct {
+	Tags   []string // 目标标签，题目在标签之间均匀分布
+	Level  string   // junior/mid/senior
+	Length int      // 题目数
+	Days   int      // 热度统计的时间窗口 (天)，0 表示全部时间
+}
+
+// Item 是组卷选出的一道题
+type Item struct {
+	Article         postgres.Article
+	Tag             string // 该题所覆盖的目标标签
+	Depth           int    // 1 基础 - 5 深挖
+	OccurrenceCount int64
+}
+
+// Planner 从高频题中组卷：标签均匀覆盖，近似重复的题只保留一道，按从基础到深挖排序
+type Planner struct {
+	store               Store
+	rater               DepthRater
+	duplicateSimilarity float64
+}
+
+// NewPlanner 创建组卷器，rater 为 nil 时按参考答案长度估算深度
+func NewPlanner(store Store, rater DepthRater) *Planner {
+	return &Planner{
+		store:               store,
+		rater:               rater,
+		duplicateSimilarity: DefaultDuplicateSimilarity,
+	}
+}
+
+// SetDuplicateSimilarity 设置近似重复阈值，<= 0 时使用默认值
+func (p *Planner) SetDuplicateSimilarity(threshold float64) {
+	if threshold <= 0 {
+		threshold = DefaultDuplicateSimilarity
+	}
+	p.duplicateSimilarity = threshold
+}
+
+// candidate 是某个标签下的候选题
+type candidate struct {
+	article     postgres.Article
+	tag         string
+	occurrences int64
+	depth       int
+}
+
+// Plan 组卷，返回的题目已按深度升序排列
+// 题库不足时返回的题目数可能少于 opts.Length
+func (p *Planner) Plan(ctx context.Context, opts Options) ([]Item, error) {
+	if len(opts.Tags) == 0 {
+		return nil, fmt.Errorf("at least one tag is required")
+	}
+	if opts.Length <= 0 {
+		return nil, fmt.Errorf("length must be positive")
+	}
+	target, ok := levelTargets[opts.Level]
+	if !ok {
+		return nil, fmt.Errorf("unknown level %q", opts.Level)
+	}
+
+	// 每个标签取若干倍于配额的候选，为去重和深度筛选留出余量
+	perTag := (opts.Length + len(opts.Tags) - 1) / len(opts.Tags)
+	pools := make([][]candidate, len(opts.Tags))
+	for i, tag := range opts.Tags {
+		pool, err := p.loadPool(ctx, tag, opts.Days, perTag*4)
+		if err != nil {
+			return nil, err
+		}
+		pools[i] = pool
+	}
+
+	shortlist := p.shortlist(pools, opts.Length*2)
+	if len(shortlist) == 0 {
+		return []Item{}, nil
+	}
+	p.rateDepths(ctx, opts.Level, shortlist)
+
+	selected := selectBalanced(shortlist, len(opts.Tags), opts.Length, target)
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].depth < selected[j].depth
+	})
+
+	items := make([]Item, len(selected))
+	for i, c := range selected {
+		items[i] = Item{
+			Article:         c.article,
+			Tag:             c.tag,
+			Depth:           c.depth,
+			OccurrenceCount: c.occurrences,
+		}
+	}
+	return items, nil
+}
+
+// loadPool 加载某个标签下的高频题：先取时间窗口内出现过的，再用全部时间的出现次数补足
+func (p *Planner) loadPool(ctx context.Context, tag string, days int, limit int) ([]candidate, error) {
+	seen := make(map[uint]bool)
+	pool := make([]candidate, 0, limit)
+	add := func(articles []postgres.ArticleWithOccurrences) {
+		for _, a := range articles {
+			if len(pool) >= limit || seen[a.ID] || len(a.Embedding.Slice()) == 0 {
+				continue
+			}
+			seen[a.ID] = true
+			pool = append(pool, candidate{article: a.Article, tag: tag, occurrences: a.OccurrenceCount})
+		}
+	}
+
+	if days > 0 {
+		since := time.Now().AddDate(0, 0, -days)
+		recent, _, err := p.store.ListArticles(ctx, postgres.ListArticlesOptions{
+			Tags:       []string{tag},
+			Sort:       postgres.ArticleSortOccurrences,
+			Occurrence: postgres.OccurrenceFilter{Since: &since},
+			Limit:      limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		add(recent)
+	}
+
+	if len(pool) < limit {
+		all, _, err := p.store.ListArticles(ctx, postgres.ListArticlesOptions{
+			Tags:  []string{tag},
+			Sort:  postgres.ArticleSortOccurrences,
+			Limit: limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		add(all)
+	}
+	return pool, nil
+}
+
+// shortlist 在标签之间轮流按热度取题，跳过已选过的文章和与已选题近似重复的题
+func (p *Planner) shortlist(pools [][]candidate, limit int) []candidate {
+	picked := make([]candidate, 0, limit)
+	used := make(map[uint]bool)
+	cursors := make([]int, len(pools))
+
+	for len(picked) < limit {
+		progressed := false
+		for i := range pools {
+			if len(picked) >= limit {
+				break
+			}
+			for cursors[i] < len(pools[i]) {
+				c := pools[i][cursors[i]]
+				cursors[i]++
+				if used[c.article.ID] || p.isDuplicate(c, picked) {
+					continue
+				}
+				used[c.article.ID] = true
+				picked = append(picked, c)
+				progressed = true
+				break
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+	return picked
+}
+
+// isDuplicate 判断候选题是否与任意已选题近似重复
+func (p *Planner) isDuplicate(c candidate, picked []candidate) bool {
+	v := c.article.Embedding.Slice()
+	for _, other := range picked {
+		if cosine(v, other.article.Embedding.Slice()) >= p.duplicateSimilarity {
+			return true
+		}
+	}
+	return false
+}
+
+// rateDepths 为候选题评估深度，LLM 不可用或漏评的题按参考答案长度估算
+func (p *Planner) rateDepths(ctx context.Context, level string, cands []candidate) {
+	var depths []int
+	if p.rater != nil {
+		questions := make([]string, len(cands))
+		for i, c := range cands {
+			questions[i] = questionText(c.article)
+		}
+		rated, err := p.rater.RateDepth(ctx, level, questions)
+		if err != nil {
+			slog.Warn("题目深度评估失败，按答案长度估算", "error", err)
+		} else {
+			depths = rated
+		}
+	}
+
+	estimated := estimateDepths(cands)
+	for i := range cands {
+		if i < len(depths) && depths[i] >= MinDepth {
+			cands[i].depth = depths[i]
+		} else {
+			cands[i].depth = estimated[i]
+		}
+	}
+}
+
+// estimateDepths 按参考答案长度的排名将候选题均匀映射到 1-5
+func estimateDepths(cands []candidate) []int {
+	order := make([]int, len(cands))
+	for i := range order {
+		order[i] = i
+	}
+	answerLen := func(i int) int {
+		if a := cands[i].article.ConciseAnswer; a != nil {
+			return len([]rune(*a))
+		}
+		return 0
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return answerLen(order[a]) < answerLen(order[b])
+	})
+
+	depths := make([]int, len(cands))
+	for rank, i := range order {
+		depths[i] = MinDepth + rank*(MaxDepth-MinDepth+1)/len(cands)
+	}
+	return depths
+}
+
+// selectBalanced 在标签之间轮流取题，每次取该标签下最接近目标深度的题 (深度相同时取更热门的)
+func selectBalanced(shortlist []candidate, tagCount int, length int, target float64) []candidate {
+	byTag := make(map[string][]candidate)
+	tags := make([]string, 0, tagCount)
+	for _, c := range shortlist {
+		if _, ok := byTag[c.tag]; !ok {
+			tags = append(tags, c.tag)
+		}
+		byTag[c.tag] = append(byTag[c.tag], c)
+	}
+
+	selected := make([]candidate, 0, length)
+	for len(selected) < length {
+		progressed := false
+		for _, tag := range tags {
+			if len(selected) >= length {
+				break
+			}
+			pool := byTag[tag]
+			if len(pool) == 0 {
+				continue
+			}
+			best := 0
+			for i := 1; i < len(pool); i++ {
+				if math.Abs(float64(pool[i].depth)-target) < math.Abs(float64(pool[best].depth)-target) {
+					best = i
+				}
+			}
+			selected = append(selected, pool[best])
+			byTag[tag] = append(pool[:best:best], pool[best+1:]...)
+			progressed = true
+		}
+		if !progressed {
+			break
+		}
+	}
+	return selected
+}
+
+// questionText 返回题目的展示文本，优先使用专业化的问题描述
+func questionText(article postgres.Article) string {
+	if article.DetailedQuestion != nil && *article.DetailedQuestion != "" {
+		return *article.DetailedQuestion
+	}
+	return article.OriginalQuestion
+}
+
+// cosine 计算两个向量的余弦相似度
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,165 @@
+package interview
+
+import (
+	"context"
+	"errors"
+	"paguu/internal/storage/postgres"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// fakeStore 按标签返回预设的文章 (已按出现次数降序)，recent 是设置了时间窗口时返回的文章
+type fakeStore struct {
+	all    map[string][]postgres.ArticleWithOccurrences
+	recent map[string][]postgres.ArticleWithOccurrences
+}
+
+func (s *fakeStore) ListArticles(_ context.Context, opts postgres.ListArticlesOptions) ([]postgres.ArticleWithOccurrences, int64, error) {
+	articles := s.all[opts.Tags[0]]
+	if opts.Occurrence.Since != nil {
+		articles = s.recent[opts.Tags[0]]
+	}
+	if len(articles) > opts.Limit {
+		articles = articles[:opts.Limit]
+	}
+	return articles, int64(len(articles)), nil
+}
+
+// fakeRater 返回预设的深度
+type fakeRater struct {
+	depths []int
+	err    error
+}
+
+func (r fakeRater) RateDepth(context.Context, string, []string) ([]int, error) {
+	return r.depths, r.err
+}
+
+func testArticle(id uint, answerLen int, occurrences int64, vector ...float32) postgres.ArticleWithOccurrences {
+	answer := strings.Repeat("答", answerLen)
+	a := postgres.ArticleWithOccurrences{
+		Article:         postgres.Article{ID: id, ConciseAnswer: &answer},
+		OccurrenceCount: occurrences,
+	}
+	if len(vector) > 0 {
+		a.Embedding = pgvector.NewVector(vector)
+	}
+	return a
+}
+
+func TestPlan(t *testing.T) {
+	store := &fakeStore{
+		all: map[string][]postgres.ArticleWithOccurrences{
+			"go": {
+				testArticle(1, 10, 9, 1, 0, 0),
+				testArticle(2, 60, 8, 0.99, 0.05, 0), // 与 1 近似重复
+				testArticle(3, 30, 7, 0, 1, 0),
+				testArticle(4, 50, 6, 0, 0, 1),
+			},
+			"redis": {
+				testArticle(5, 20, 5, 1, 1, 0),
+				testArticle(6, 40, 4, 0, 1, 1),
+				testArticle(7, 70, 3), // 没有向量，跳过
+			},
+		},
+		recent: map[string][]postgres.ArticleWithOccurrences{
+			"go": {testArticle(4, 50, 2, 0, 0, 1)},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		rater     DepthRater
+		opts      Options
+		wantIDs   []uint
+		wantTags  []string
+		wantDepth []int
+	}{
+		{
+			name:      "balanced across tags, sorted by depth",
+			opts:      Options{Tags: []string{"go", "redis"}, Level: LevelMid, Length: 4},
+			wantIDs:   []uint{1, 5, 3, 6},
+			wantTags:  []string{"go", "redis", "go", "redis"},
+			wantDepth: []int{1, 2, 3, 4},
+		},
+		{
+			name:      "rated depths",
+			rater:     fakeRater{depths: []int{5, 5, 5, 5, 5}},
+			opts:      Options{Tags: []string{"go", "redis"}, Level: LevelMid, Length: 4},
+			wantIDs:   []uint{1, 5, 3, 6},
+			wantTags:  []string{"go", "redis", "go", "redis"},
+			wantDepth: []int{5, 5, 5, 5},
+		},
+		{
+			name:      "rater failure falls back to answer length",
+			rater:     fakeRater{err: errors.New("llm unavailable")},
+			opts:      Options{Tags: []string{"go", "redis"}, Level: LevelMid, Length: 4},
+			wantIDs:   []uint{1, 5, 3, 6},
+			wantTags:  []string{"go", "redis", "go", "redis"},
+			wantDepth: []int{1, 2, 3, 4},
+		},
+		{
+			name:      "recent occurrences first",
+			opts:      Options{Tags: []string{"go"}, Level: LevelMid, Length: 2, Days: 30},
+			wantIDs:   []uint{3, 4},
+			wantTags:  []string{"go", "go"},
+			wantDepth: []int{2, 4},
+		},
+		{
+			name:      "pool smaller than length",
+			opts:      Options{Tags: []string{"redis"}, Level: LevelSenior, Length: 5},
+			wantIDs:   []uint{5, 6},
+			wantTags:  []string{"redis", "redis"},
+			wantDepth: []int{1, 3},
+		},
+		{
+			name:    "unknown tag",
+			opts:    Options{Tags: []string{"mysql"}, Level: LevelJunior, Length: 3},
+			wantIDs: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := NewPlanner(store, tt.rater).Plan(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("Plan: %v", err)
+			}
+			var ids []uint
+			var tags []string
+			var depths []int
+			for _, item := range items {
+				ids = append(ids, item.Article.ID)
+				tags = append(tags, item.Tag)
+				depths = append(depths, item.Depth)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if !reflect.DeepEqual(tags, tt.wantTags) {
+				t.Errorf("tags = %v, want %v", tags, tt.wantTags)
+			}
+			if !reflect.DeepEqual(depths, tt.wantDepth) {
+				t.Errorf("depths = %v, want %v", depths, tt.wantDepth)
+			}
+		})
+	}
+}
+
+func TestPlanInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"no tags", Options{Level: LevelMid, Length: 3}},
+		{"zero length", Options{Tags: []string{"go"}, Level: LevelMid}},
+		{"unknown level", Options{Tags: []string{"go"}, Level: "staff", Length: 3}},
+	}
+	for _, tt := range tests {
+		if _, err := NewPlanner(&fakeStore{}, nil).Plan(context.Background(), tt.opts); err == nil {
+			t.Errorf("%s: Plan succeeded, want error", tt.name)
+		}
+	}
+}
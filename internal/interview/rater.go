@@ -0,0 +1,138 @@
+package interview
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"paguu/internal/enrich"
+	"paguu/internal/usage"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime"
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model/responses"
+)
+
+// DepthRater 评估题目的考察深度 (1 基础 - 5 深挖)，返回值与 questions 一一对应
+type DepthRater interface {
+	RateDepth(ctx context.Context, level string, questions []string) ([]int, error)
+}
+
+// LLMRater 通过丰富化所用的 Ark 模型评估题目深度
+type LLMRater struct {
+	client         *arkruntime.Client
+	modelName      string
+	promptTemplate *template.Template
+	recorder       usage.Recorder
+}
+
+func NewLLMRater(client *arkruntime.Client, templatePath string, modelName string) (*LLMRater, error) {
+	if client == nil {
+		return nil, fmt.Errorf("ark client is required")
+	}
+	if modelName == "" {
+		return nil, fmt.Errorf("interview model name is required")
+	}
+
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(templatePath).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	return &LLMRater{
+		client:         client,
+		modelName:      modelName,
+		promptTemplate: tmpl,
+	}, nil
+}
+
+// SetUsageRecorder 设置用量记录器，为 nil 时不记录
+func (lr *LLMRater) SetUsageRecorder(recorder usage.Recorder) {
+	lr.recorder = recorder
+}
+
+// llmQuestion 是传入 prompt 的题目，ID 为从 1 开始的序号
+type llmQuestion struct {
+	ID   int
+	Text string
+}
+
+// llmDepths 是 LLM 返回的深度评估
+type llmDepths struct {
+	Depths []struct {
+		ID    int `json:"id"`
+		Depth int `json:"depth"`
+	} `json:"depths"`
+}
+
+func (lr *LLMRater) RateDepth(ctx context.Context, level string, questions []string) ([]int, error) {
+	items := make([]llmQuestion, len(questions))
+	for i, q := range questions {
+		items[i] = llmQuestion{ID: i + 1, Text: q}
+	}
+
+	var buf bytes.Buffer
+	err := lr.promptTemplate.Execute(&buf, map[string]interface{}{
+		"Level":     level,
+		"Questions": items,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	resp, err := lr.client.CreateResponses(ctx, &responses.ResponsesRequest{
+		Model: lr.modelName,
+		Input: &responses.ResponsesInput{Union: &responses.ResponsesInput_StringValue{StringValue: buf.String()}},
+	})
+	lr.recordUsage(ctx, resp, time.Since(startedAt), err)
+	if err != nil {
+		return nil, fmt.Errorf("depth rating request error: %w", err)
+	}
+
+	text := enrich.ExtractResponseText(resp)
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("empty depth rating response text")
+	}
+
+	var parsed llmDepths
+	if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal depth rating response JSON: %w", err)
+	}
+
+	// 未被评估的题目记为 0，由调用方按启发式补全
+	depths := make([]int, len(questions))
+	for _, d := range parsed.Depths {
+		if d.ID >= 1 && d.ID <= len(questions) {
+			depths[d.ID-1] = min(max(d.Depth, MinDepth), MaxDepth)
+		}
+	}
+	return depths, nil
+}
+
+// recordUsage 记录一次深度评估调用的用量
+func (lr *LLMRater) recordUsage(ctx context.Context, resp *responses.ResponseObject, latency time.Duration, err error) {
+	if lr.recorder == nil {
+		return
+	}
+	event := usage.Event{
+		Provider:  usage.ProviderArk,
+		Operation: usage.OperationInterview,
+		Model:     lr.modelName,
+		Latency:   latency,
+		Err:       err,
+	}
+	if resp != nil && resp.Usage != nil {
+		event.PromptTokens = resp.Usage.InputTokens
+		event.CompletionTokens = resp.Usage.OutputTokens
+		event.TotalTokens = resp.Usage.TotalTokens
+	}
+	lr.recorder.Record(ctx, event)
+}
@@ -0,0 +1,363 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ClientSkipSchemas 是生成客户端时跳过的组件，由客户端包手写 (错误响应对应 client.Error)
+var ClientSkipSchemas = map[string]bool{"Error": true}
+
+// initialisms 是转换为 Go 标识符时整体大写的单词
+var initialisms = map[string]string{
+	"id": "ID", "ids": "IDs", "url": "URL", "api": "API", "json": "JSON",
+	"http": "HTTP", "ip": "IP", "jwt": "JWT", "llm": "LLM", "srs": "SRS",
+}
+
+// methodOrder 是同一路径下接口的生成顺序
+var methodOrder = []string{"get", "post", "put", "patch", "delete"}
+
+// GenerateClient 根据文档生成 Go 客户端代码：每个组件生成一个结构体，每个返回 JSON 的接口生成一个 Client 方法
+// 生成的代码依赖同一个包中手写的 Client.do、envelope 和 Page
+func GenerateClient(doc *Document, pkg string) ([]byte, error) {
+	w := &clientWriter{doc: doc, imports: map[string]bool{"context": true}}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		if !ClientSkipSchemas[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		w.writeType(name, doc.Components.Schemas[name])
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for _, method := range methodOrder {
+			if op, ok := doc.Paths[path][method]; ok {
+				if err := w.writeOperation(method, path, op); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by cmd/openapi from the OpenAPI document (%s %s). DO NOT EDIT.\n\n", doc.Info.Title, doc.Info.Version)
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", pkg)
+	imports := make([]string, 0, len(w.imports))
+	for imp := range w.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	out.WriteString(")\n")
+	out.Write(w.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return out.Bytes(), fmt.Errorf("format generated client: %w", err)
+	}
+	return src, nil
+}
+
+type clientWriter struct {
+	doc     *Document
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+func (w *clientWriter) printf(format string, args ...any) {
+	fmt.Fprintf(&w.buf, format, args...)
+}
+
+// writeType 将组件生成为结构体
+func (w *clientWriter) writeType(name string, schema *Schema) {
+	w.printf("\n")
+	if schema.Description != "" {
+		w.printf("// %s %s\n", name, schema.Description)
+	}
+	if schema.Type != "object" || len(schema.Properties) == 0 && schema.AdditionalProperties != nil {
+		w.printf("type %s = %s\n", name, w.goType(schema))
+		return
+	}
+	w.printf("type %s %s\n", name, w.structType(schema))
+}
+
+// structType 生成结构体类型，字段顺序与服务端结构体一致，非必填字段带 omitempty
+func (w *clientWriter) structType(schema *Schema) string {
+	order := schema.PropertyOrder
+	if len(order) != len(schema.Properties) {
+		order = make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			order = append(order, name)
+		}
+		sort.Strings(order)
+	}
+
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	for _, name := range order {
+		prop := schema.Properties[name]
+		tag := name
+		if !slices.Contains(schema.Required, name) {
+			tag += ",omitempty"
+		}
+		if prop.Description != "" {
+			fmt.Fprintf(&b, "// %s\n", prop.Description)
+		}
+		fmt.Fprintf(&b, "%s %s `json:%q`\n", goName(name), w.goType(prop), tag)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// goType 返回 Schema 对应的 Go 类型
+func (w *clientWriter) goType(schema *Schema) string {
+	if name := schema.RefName(); name != "" {
+		return name
+	}
+	if len(schema.AllOf) == 1 {
+		return "*" + w.goType(schema.AllOf[0])
+	}
+
+	var t string
+	switch schema.Type {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			w.imports["time"] = true
+			t = "time.Time"
+		case "byte":
+			return "[]byte"
+		default:
+			t = "string"
+		}
+	case "integer":
+		t = "int64"
+		if schema.Format == "int32" {
+			t = "int32"
+		}
+	case "number":
+		t = "float64"
+		if schema.Format == "float" {
+			t = "float32"
+		}
+	case "boolean":
+		t = "bool"
+	case "array":
+		return "[]" + w.goType(schema.Items)
+	case "object":
+		switch {
+		case len(schema.Properties) > 0:
+			t = w.structType(schema)
+		case schema.AdditionalProperties != nil:
+			return "map[string]" + w.goType(schema.AdditionalProperties)
+		default:
+			return "map[string]any"
+		}
+	default:
+		return "any"
+	}
+	if schema.Nullable {
+		return "*" + t
+	}
+	return t
+}
+
+// writeOperation 生成接口的查询参数结构体和 Client 方法，响应不是 JSON 的接口跳过
+func (w *clientWriter) writeOperation(method, path string, op *Operation) error {
+	status, response := successResponse(op)
+	if response == nil {
+		return fmt.Errorf("%s %s: no success response", method, path)
+	}
+	content, ok := response.Content["application/json"]
+	if !ok {
+		return nil
+	}
+
+	var pathArgs, queryParams []*Parameter
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			pathArgs = append(pathArgs, p)
+		case "query":
+			queryParams = append(queryParams, p)
+		}
+	}
+	if len(queryParams) > 0 {
+		w.writeParams(op.OperationID+"Params", queryParams)
+	}
+
+	// 方法签名
+	args := []string{"ctx context.Context"}
+	for _, p := range pathArgs {
+		args = append(args, lowerName(p.Name)+" "+w.goType(p.Schema))
+	}
+	body := "nil"
+	if op.RequestBody != nil {
+		args = append(args, "body "+w.goType(op.RequestBody.Content["application/json"].Schema))
+		body = "body"
+	}
+	query := "nil"
+	if len(queryParams) > 0 {
+		args = append(args, "params *"+op.OperationID+"Params")
+		query = "params.values()"
+	}
+
+	result, outType, ret := w.result(op, content.Schema)
+
+	w.printf("\n// %s %s (%s %s", op.OperationID, op.Summary, strings.ToUpper(method), path)
+	if op.Scope != "" {
+		w.printf("，需要 %s 权限", op.Scope)
+	}
+	w.printf("，成功时返回 %d)\n", status)
+	if op.Description != "" {
+		w.printf("// %s\n", op.Description)
+	}
+	w.printf("func (c *Client) %s(%s) (%s, error) {\n", op.OperationID, strings.Join(args, ", "), result)
+	w.printf("var out %s\n", outType)
+	w.printf("err := c.do(ctx, %q, %s, %s, %s, &out)\n", strings.ToUpper(method), w.pathExpr(path, pathArgs), query, body)
+	w.printf("return %s, err\n}\n", ret)
+	return nil
+}
+
+// result 返回方法的返回类型、解码的目标类型和返回表达式：
+// 带分页的返回 Page[T]，只有 data 的返回 data 的类型，带其他字段的返回整个响应结构体，结构不固定时返回 map
+func (w *clientWriter) result(op *Operation, schema *Schema) (result, outType, ret string) {
+	if name := schema.RefName(); name != "" {
+		return name, name, "out"
+	}
+	data, ok := schema.Properties["data"]
+	if !ok {
+		return "map[string]any", "map[string]any", "out"
+	}
+	if op.Paginated && data.Items != nil {
+		t := "Page[" + w.goType(data.Items) + "]"
+		return t, t, "out"
+	}
+	t := w.goType(data)
+	return t, "envelope[" + t + "]", "out.Data"
+}
+
+// pathExpr 生成请求路径的表达式，整数参数用 %d，字符串参数转义后拼接
+func (w *clientWriter) pathExpr(path string, params []*Parameter) string {
+	if len(params) == 0 {
+		return fmt.Sprintf("%q", path)
+	}
+	w.imports["fmt"] = true
+	var args []string
+	for _, p := range params {
+		verb := "%d"
+		arg := lowerName(p.Name)
+		if p.Schema.Type == "string" {
+			w.imports["net/url"] = true
+			verb = "%s"
+			arg = "url.PathEscape(" + arg + ")"
+		}
+		path = strings.Replace(path, "{"+p.Name+"}", verb, 1)
+		args = append(args, arg)
+	}
+	return fmt.Sprintf("fmt.Sprintf(%q, %s)", path, strings.Join(args, ", "))
+}
+
+// writeParams 生成查询参数结构体及其编码方法，未设置 (零值) 的可选参数不发送
+func (w *clientWriter) writeParams(name string, params []*Parameter) {
+	w.imports["net/url"] = true
+	w.printf("\n// %s 是 %s 的查询参数\ntype %s struct {\n", name, strings.TrimSuffix(name, "Params"), name)
+	for _, p := range params {
+		if p.Description != "" {
+			w.printf("// %s\n", p.Description)
+		}
+		w.printf("%s %s\n", goName(p.Name), w.goType(p.Schema))
+	}
+	w.printf("}\n\nfunc (p *%s) values() url.Values {\nv := url.Values{}\nif p == nil {\nreturn v\n}\n", name)
+	for _, p := range params {
+		field := "p." + goName(p.Name)
+		switch {
+		case p.Schema.Type == "array":
+			w.printf("for _, item := range %s {\nv.Add(%q, %s)\n}\n", field, p.Name, w.formatValue("item", p.Schema.Items))
+		case p.Schema.Nullable:
+			w.printf("if %s != nil {\nv.Set(%q, %s)\n}\n", field, p.Name, w.formatValue("*"+field, p.Schema))
+		case p.Required:
+			w.printf("v.Set(%q, %s)\n", p.Name, w.formatValue(field, p.Schema))
+		default:
+			w.printf("if %s != %s {\nv.Set(%q, %s)\n}\n", field, zeroValue(p.Schema), p.Name, w.formatValue(field, p.Schema))
+		}
+	}
+	w.printf("return v\n}\n")
+}
+
+// formatValue 返回将查询参数的值转换为字符串的表达式
+func (w *clientWriter) formatValue(expr string, schema *Schema) string {
+	if schema.Type == "string" && schema.Format == "" {
+		return expr
+	}
+	w.imports["fmt"] = true
+	return "fmt.Sprint(" + expr + ")"
+}
+
+// zeroValue 返回标量类型的零值表达式
+func zeroValue(schema *Schema) string {
+	switch schema.Type {
+	case "string":
+		return `""`
+	case "boolean":
+		return "false"
+	}
+	return "0"
+}
+
+// successResponse 返回接口的 2xx 响应
+func successResponse(op *Operation) (int, *Response) {
+	for status := http.StatusOK; status < 300; status++ {
+		if r, ok := op.Responses[fmt.Sprint(status)]; ok {
+			return status, r
+		}
+	}
+	return 0, nil
+}
+
+// goName 将 snake_case 的名字转换为导出的 Go 标识符，tags[] 等数组参数去掉 []
+func goName(name string) string {
+	name = strings.TrimSuffix(name, "[]")
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	})
+	var b strings.Builder
+	for _, word := range words {
+		if upper, ok := initialisms[strings.ToLower(word)]; ok {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
+
+// lowerName 将 snake_case 的名字转换为非导出的 Go 标识符，用于方法参数
+func lowerName(name string) string {
+	n := goName(name)
+	if upper, ok := initialisms[strings.ToLower(name)]; ok && upper == n {
+		return strings.ToLower(n)
+	}
+	runes := []rune(n)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,290 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// Generator 通过反射从 Go 类型生成 Schema，规则与 encoding/json 和 gin 的 binding 标签一致：
+// json 标签决定属性名 (匿名嵌入的结构体展开)，binding 标签中的 required/min/max/gt/oneof 转换为对应的约束
+// 命名结构体登记为组件并以 $ref 引用，因此文档始终与请求和响应结构体保持一致
+type Generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func NewGenerator() *Generator {
+	return &Generator{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// Schemas 返回已登记的组件
+func (g *Generator) Schemas() map[string]*Schema {
+	return g.schemas
+}
+
+// Define 直接登记一个组件，用于没有对应 Go 类型的结构 (例如错误响应)
+func (g *Generator) Define(name string, schema *Schema) *Schema {
+	g.schemas[name] = schema
+	return Ref(name)
+}
+
+// Schema 返回类型对应的 Schema
+func (g *Generator) Schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		elem := g.Schema(t.Elem())
+		if elem.Ref != "" {
+			return &Schema{AllOf: []*Schema{elem}, Nullable: true}
+		}
+		elem.Nullable = true
+		return elem
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// 自定义序列化的类型 (如 datatypes.JSON) 无法推断结构
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.ref(t)
+	}
+	// interface 等任意类型
+	return &Schema{}
+}
+
+// ref 将命名结构体登记为组件并返回引用，组件名首字母大写；不同包的同名类型以包名区分
+func (g *Generator) ref(t reflect.Type) *Schema {
+	if name, ok := g.names[t]; ok {
+		return Ref(name)
+	}
+	name := exported(t.Name())
+	if _, taken := g.schemas[name]; taken {
+		pkg := path.Base(t.PkgPath())
+		name = exported(pkg) + name
+	}
+	g.names[t] = name
+	// 先占位，允许结构体自引用
+	g.schemas[name] = &Schema{Type: "object"}
+	g.schemas[name] = g.structSchema(t)
+	return Ref(name)
+}
+
+// exported 将组件名的首字母转为大写
+func exported(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// structSchema 按 encoding/json 的规则生成结构体的属性
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(schema, t)
+	if len(schema.Properties) == 0 {
+		schema.Properties = nil
+	}
+	return schema
+}
+
+func (g *Generator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := g.Schema(field.Type)
+		required := applyBinding(prop, field.Tag.Get("binding"))
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+		if _, exists := schema.Properties[name]; !exists {
+			schema.PropertyOrder = append(schema.PropertyOrder, name)
+		}
+		schema.Properties[name] = prop
+	}
+}
+
+// QueryParameters 将结构体中带 form 标签的字段转换为查询参数，匿名嵌入的结构体展开
+func (g *Generator) QueryParameters(t reflect.Type) []*Parameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var params []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			params = append(params, g.QueryParameters(field.Type)...)
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		schema := g.Schema(field.Type)
+		param := &Parameter{
+			Name:     name,
+			In:       "query",
+			Required: applyBinding(schema, field.Tag.Get("binding")),
+			Schema:   schema,
+		}
+		if schema.Type == "array" {
+			explode := true
+			param.Style = "form"
+			param.Explode = &explode
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// applyBinding 将 gin binding 标签转换为 Schema 约束，返回字段是否必填
+func applyBinding(schema *Schema, binding string) bool {
+	if binding == "" {
+		return false
+	}
+	// 引用的组件是共享的，只记录是否必填
+	target := schema
+	if len(schema.AllOf) > 0 || schema.Ref != "" {
+		target = &Schema{}
+	}
+
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "min", "max", "gt":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			setBound(target, key, n)
+		case "oneof":
+			for _, v := range strings.Fields(value) {
+				target.Enum = append(target.Enum, enumValue(target.Type, v))
+			}
+		}
+	}
+	return required
+}
+
+// setBound 按类型设置 min/max/gt：字符串为长度，数组为元素个数，数字为取值范围
+func setBound(schema *Schema, key string, n float64) {
+	count := int(n)
+	switch schema.Type {
+	case "string":
+		if key == "max" {
+			schema.MaxLength = &count
+		} else {
+			schema.MinLength = &count
+		}
+	case "array":
+		if key == "max" {
+			schema.MaxItems = &count
+		} else {
+			schema.MinItems = &count
+		}
+	case "integer", "number":
+		switch key {
+		case "max":
+			schema.Maximum = &n
+		case "min":
+			schema.Minimum = &n
+		case "gt":
+			schema.Minimum = &n
+			schema.ExclusiveMinimum = true
+		}
+	}
+}
+
+// enumValue 将 oneof 中的取值转换为与类型一致的枚举值
+func enumValue(schemaType, value string) any {
+	switch schemaType {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
+
+// PathParameters 将 gin 路径中的 :name 转换为 OpenAPI 的 {name} 并返回路径参数
+// 名为 id 或以 _id 结尾的参数视为整数
+func PathParameters(ginPath string) (string, []*Parameter) {
+	segments := strings.Split(ginPath, "/")
+	var params []*Parameter
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := segment[1:]
+		schema := &Schema{Type: "string"}
+		if name == "id" || strings.HasSuffix(name, "_id") {
+			schema = &Schema{Type: "integer", Format: "int64"}
+		}
+		params = append(params, &Parameter{Name: name, In: "path", Required: true, Schema: schema})
+		segments[i] = fmt.Sprintf("{%s}", name)
+	}
+	return strings.Join(segments, "/"), params
+}
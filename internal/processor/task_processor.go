@@ -8,6 +8,8 @@ import (
 	"paguu/internal/embedding"
 	"paguu/internal/enrich"
 	"paguu/internal/storage/postgres"
+	"paguu/internal/usage"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -22,6 +24,8 @@ type Task struct {
 	CreatedAt time.Time              `json:"created_at"`
 	Source    string                 `json:"source"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	NoCache   bool                   `json:"no_cache,omitempty"`   // 不读取丰富化缓存，强制重新请求 LLM
+	CreatedBy string                 `json:"created_by,omitempty"` // 提交任务的用户，未启用鉴权时为空
 }
 
 // FillMetadata 自动填充元信息
@@ -58,10 +62,18 @@ func (t *Task) FromJSON(data datatypes.JSON) error {
 	return nil
 }
 
+// EnricherFactory 按模板路径和模型创建丰富化器，用于工作区覆盖了全局模板或模型的情况
+type EnricherFactory func(templatePath, model string) (*enrich.QuestionsEnricher, error)
+
 type TaskProcessor struct {
 	enricher      *enrich.QuestionsEnricher
+	newEnricher   EnricherFactory
+	enrichersMu   sync.Mutex
+	enrichers     map[string]*enrich.QuestionsEnricher // key 为 模板路径 + 模型
 	repo          *postgres.Repository
 	embedder      *embedding.Embedder
+	tracker       *usage.Tracker
+	dedupe        postgres.DedupeThresholds
 	activeWorkers atomic.Int32
 	maxWorkers    int32
 
@@ -75,6 +87,8 @@ func NewTaskProcessor(enricher *enrich.QuestionsEnricher, repo *postgres.Reposit
 		enricher:   enricher,
 		repo:       repo,
 		embedder:   embedder,
+		enrichers:  make(map[string]*enrich.QuestionsEnricher),
+		dedupe:     postgres.DefaultDedupeThresholds(),
 		maxWorkers: 3, // 默认最大并发数为3
 	}
 	tp.activeWorkers.Store(0)
@@ -83,6 +97,21 @@ func NewTaskProcessor(enricher *enrich.QuestionsEnricher, repo *postgres.Reposit
 	return tp
 }
 
+// SetDedupeThresholds 设置去重的自动合并与灰区审核阈值
+func (tp *TaskProcessor) SetDedupeThresholds(thresholds postgres.DedupeThresholds) {
+	tp.dedupe = thresholds
+}
+
+// SetEnricherFactory 设置工作区丰富化器的创建函数，未设置时所有工作区都使用默认丰富化器
+func (tp *TaskProcessor) SetEnricherFactory(factory EnricherFactory) {
+	tp.newEnricher = factory
+}
+
+// SetUsageTracker 设置用量追踪器，超过每日预算时 worker 会暂停取任务
+func (tp *TaskProcessor) SetUsageTracker(tracker *usage.Tracker) {
+	tp.tracker = tracker
+}
+
 func (tp *TaskProcessor) NewTask(ctx context.Context, taskType string, task Task) error {
 	payload, err := task.ToJSON()
 	if err != nil {
@@ -115,46 +144,8 @@ func (tp *TaskProcessor) ProcessNextTask(ctx context.Context) error {
 		slog.Info("no left task")
 		return nil
 	}
-	task := new(Task)
-	err = task.FromJSON(processingQueue.Payload)
-	if err != nil {
-		if err2 := tp.repo.UpdateTaskFailed(ctx, processingQueue, err); err2 != nil {
-			slog.Error("UpdateTaskFailed error after json unmarshal", "error", err2)
-		}
-		return err
-	}
-	questionSet, err := tp.enricher.EnrichQuestions(ctx, task.RawQuestions)
-	if err != nil {
-		if err2 := tp.repo.UpdateTaskFailed(ctx, processingQueue, err); err2 != nil {
-			slog.Error("UpdateTaskFailed error", "error", err2)
-		}
-		return err
-	}
-	vectors, err := tp.embedder.EmbedBatch(ctx, questionSet.GetEmbeddableTexts())
-	if err != nil {
-		if err2 := tp.repo.UpdateTaskFailed(ctx, processingQueue, err); err2 != nil {
-			slog.Error("UpdateTaskFailed error", "error", err2)
-		}
-		return err
-	}
-	statusSet := make([]postgres.QuestionInsertStatus, len(vectors))
-	for i := range vectors {
-		statusSet[i], err = tp.repo.ProcessEnrichedQuestion(ctx, questionSet.Questions[i], vectors[i], -0.95)
-
-		if err != nil {
-			slog.Error("ProcessEnrichedQuestion error", "error", err, "question_index", i)
-			if err2 := tp.repo.UpdateTaskFailed(ctx, processingQueue, err); err2 != nil {
-				slog.Error("UpdateTaskFailed error after ProcessEnrichedQuestion error", "original_error", err, "update_error", err2)
-			}
-			return err
-		}
-	}
-	if err2 := tp.repo.UpdateTaskCompleted(ctx, processingQueue); err2 != nil {
-		slog.Error("UpdateTaskCompleted error", "error", err2)
-		return err2
-	}
 
-	return nil
+	return tp.processTask(ctx, processingQueue)
 }
 
 func (tp *TaskProcessor) ProcessFailedTask(ctx context.Context, maxRetries int) error {
@@ -174,8 +165,25 @@ func (tp *TaskProcessor) ProcessFailedTask(ctx context.Context, maxRetries int)
 		return nil
 	}
 
+	return tp.processTask(ctx, processingQueue)
+}
+
+// TaskResult 是任务完成时写入 processing_queue.result 的处理结果
+type TaskResult struct {
+	Lines        []LineResult               `json:"lines"`
+	Hallucinated []enrich.InterviewQuestion `json:"hallucinated,omitempty"`
+}
+
+// LineResult 记录一行输入的匹配情况及其入库结果
+type LineResult struct {
+	enrich.LineMapping
+	InsertStatus string `json:"insert_status,omitempty"` // inserted/merged/flagged/already_ingested，missing 行为空
+}
+
+// processTask 执行 丰富化 -> 向量化 -> 去重入库 的完整流程，并更新任务状态
+func (tp *TaskProcessor) processTask(ctx context.Context, processingQueue *postgres.ProcessingQueue) error {
 	task := new(Task)
-	err = task.FromJSON(processingQueue.Payload)
+	err := task.FromJSON(processingQueue.Payload)
 	if err != nil {
 		if err2 := tp.repo.UpdateTaskFailed(ctx, processingQueue, err); err2 != nil {
 			slog.Error("UpdateTaskFailed error after json unmarshal", "error", err2)
@@ -183,7 +191,11 @@ func (tp *TaskProcessor) ProcessFailedTask(ctx context.Context, maxRetries int)
 		return err
 	}
 
-	questionSet, err := tp.enricher.EnrichQuestions(ctx, task.RawQuestions)
+	// 后续 LLM / Embedding 调用的用量都归属到该任务，入库限定在任务所属的工作区
+	ctx = usage.WithTask(ctx, task.TaskID, task.Source)
+	ctx = postgres.WithWorkspace(ctx, processingQueue.WorkspaceID)
+
+	enricher, err := tp.enricherFor(ctx, processingQueue.WorkspaceID)
 	if err != nil {
 		if err2 := tp.repo.UpdateTaskFailed(ctx, processingQueue, err); err2 != nil {
 			slog.Error("UpdateTaskFailed error", "error", err2)
@@ -191,17 +203,85 @@ func (tp *TaskProcessor) ProcessFailedTask(ctx context.Context, maxRetries int)
 		return err
 	}
 
-	vectors, err := tp.embedder.EmbedBatch(ctx, questionSet.GetEmbeddableTexts())
+	questionSet, report, err := enricher.EnrichQuestions(ctx, task.RawQuestions, enrich.EnrichOptions{BypassCache: task.NoCache})
 	if err != nil {
 		if err2 := tp.repo.UpdateTaskFailed(ctx, processingQueue, err); err2 != nil {
 			slog.Error("UpdateTaskFailed error", "error", err2)
 		}
 		return err
 	}
+	if missing := report.MissingLines(); len(missing) > 0 {
+		slog.Warn("enrichment did not cover all input lines", "task_id", task.TaskID, "missing", missing)
+	}
 
+	// 每个问题对应的输入行；重试时跳过之前的尝试中已经入库的行，不再重复向量化和入库
+	lineNos := make([]int, len(questionSet.Questions))
+	rawLines := make([]string, len(questionSet.Questions))
+	for _, line := range report.Lines {
+		if line.QuestionIndex >= 0 && line.QuestionIndex < len(rawLines) {
+			lineNos[line.QuestionIndex] = line.LineNo
+			rawLines[line.QuestionIndex] = line.Line
+		}
+	}
+	ingested, err := tp.repo.IngestedTaskLines(ctx, task.TaskID)
+	if err != nil {
+		if err2 := tp.repo.UpdateTaskFailed(ctx, processingQueue, err); err2 != nil {
+			slog.Error("UpdateTaskFailed error", "error", err2)
+		}
+		return err
+	}
+	if len(ingested) > 0 {
+		slog.Info("skipping lines ingested by a previous attempt", "task_id", task.TaskID, "lines", len(ingested))
+	}
+
+	texts := questionSet.GetEmbeddableTexts()
+	var pending []int
+	var pendingTexts []string
+	for i := range questionSet.Questions {
+		if _, ok := ingested[lineNos[i]]; !ok {
+			pending = append(pending, i)
+			pendingTexts = append(pendingTexts, texts[i])
+		}
+	}
+	vectors := make([][]float32, len(questionSet.Questions))
+	if len(pendingTexts) > 0 {
+		embedded, err := tp.embedder.EmbedBatch(ctx, pendingTexts)
+		if err != nil {
+			if err2 := tp.repo.UpdateTaskFailed(ctx, processingQueue, err); err2 != nil {
+				slog.Error("UpdateTaskFailed error", "error", err2)
+			}
+			return err
+		}
+		for j, i := range pending {
+			vectors[i] = embedded[j]
+		}
+	}
+
+	// 出现记录的来源信息：同一任务共享元信息，原始行按 QuestionIndex 对应
+	var metadata datatypes.JSON
+	if len(task.Metadata) > 0 {
+		if metadata, err = json.Marshal(task.Metadata); err != nil {
+			slog.Warn("marshal task metadata error", "error", err, "task_id", task.TaskID)
+			metadata = nil
+		}
+	}
+
+	// 每一行的文章和出现记录在同一事务中写入，出现记录的 (task_id, line_no) 唯一，失败的行重试时才会再次入库
 	statusSet := make([]postgres.QuestionInsertStatus, len(vectors))
 	for i := range vectors {
-		statusSet[i], err = tp.repo.ProcessEnrichedQuestion(ctx, questionSet.Questions[i], vectors[i], -0.95)
+		if _, ok := ingested[lineNos[i]]; ok {
+			statusSet[i] = postgres.QuestionInsertStatusIngested
+			continue
+		}
+		info := postgres.OccurrenceInfo{
+			TaskID:   task.TaskID,
+			Source:   task.Source,
+			Metadata: metadata,
+			RawLine:  rawLines[i],
+			LineNo:   lineNos[i],
+			SeenAt:   task.CreatedAt,
+		}
+		statusSet[i], err = tp.repo.ProcessEnrichedQuestion(ctx, questionSet.Questions[i], vectors[i], tp.dedupe, info)
 		if err != nil {
 			slog.Error("ProcessEnrichedQuestion error", "error", err, "question_index", i)
 			if err2 := tp.repo.UpdateTaskFailed(ctx, processingQueue, err); err2 != nil {
@@ -211,7 +291,23 @@ func (tp *TaskProcessor) ProcessFailedTask(ctx context.Context, maxRetries int)
 		}
 	}
 
-	if err2 := tp.repo.UpdateTaskCompleted(ctx, processingQueue); err2 != nil {
+	result := TaskResult{
+		Lines:        make([]LineResult, len(report.Lines)),
+		Hallucinated: report.Hallucinated,
+	}
+	for i, line := range report.Lines {
+		result.Lines[i] = LineResult{LineMapping: line}
+		if line.QuestionIndex >= 0 {
+			result.Lines[i].InsertStatus = statusSet[line.QuestionIndex].String()
+		}
+	}
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		slog.Error("marshal task result error", "error", err)
+		resultJSON = nil
+	}
+
+	if err2 := tp.repo.UpdateTaskCompleted(ctx, processingQueue, datatypes.JSON(resultJSON)); err2 != nil {
 		slog.Error("UpdateTaskCompleted error", "error", err2)
 		return err2
 	}
@@ -219,6 +315,42 @@ func (tp *TaskProcessor) ProcessFailedTask(ctx context.Context, maxRetries int)
 	return nil
 }
 
+// enricherFor 返回工作区使用的丰富化器：工作区未覆盖模板和模型时使用默认丰富化器，
+// 否则按 模板路径 + 模型 创建并缓存，工作区配置修改后新任务立即生效
+func (tp *TaskProcessor) enricherFor(ctx context.Context, workspaceID uint) (*enrich.QuestionsEnricher, error) {
+	if tp.newEnricher == nil {
+		return tp.enricher, nil
+	}
+	workspace, err := tp.repo.GetWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace.EnrichTemplatePath == nil && workspace.EnrichModel == nil {
+		return tp.enricher, nil
+	}
+
+	var templatePath, model string
+	if workspace.EnrichTemplatePath != nil {
+		templatePath = *workspace.EnrichTemplatePath
+	}
+	if workspace.EnrichModel != nil {
+		model = *workspace.EnrichModel
+	}
+	key := templatePath + "\x00" + model
+
+	tp.enrichersMu.Lock()
+	defer tp.enrichersMu.Unlock()
+	if enricher, ok := tp.enrichers[key]; ok {
+		return enricher, nil
+	}
+	enricher, err := tp.newEnricher(templatePath, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create enricher for workspace %s: %w", workspace.Slug, err)
+	}
+	tp.enrichers[key] = enricher
+	return enricher, nil
+}
+
 func (tp *TaskProcessor) tryAcquireWorker() bool {
 	for {
 		current := tp.activeWorkers.Load()
@@ -262,18 +394,37 @@ func (tp *TaskProcessor) shouldPoll() bool {
 	return true
 }
 
+// budgetExceeded 检查是否已超过每日用量预算
+func (tp *TaskProcessor) budgetExceeded(ctx context.Context) bool {
+	if tp.tracker == nil {
+		return false
+	}
+	return tp.tracker.BudgetExceeded(ctx)
+}
+
+// RunTaskWorkers 启动任务处理工作者，阻塞到 done 关闭或 ctx 取消后所有工作者处理完当前任务并退出
 func (tp *TaskProcessor) RunTaskWorkers(ctx context.Context, normalWorkers, retryWorkers, maxRetries int, pollInterval time.Duration, done <-chan struct{}) {
+	var wg sync.WaitGroup
 	for i := 0; i < normalWorkers; i++ {
 		workerID := i + 1
-		go tp.normalTaskWorker(ctx, workerID, pollInterval, done)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tp.normalTaskWorker(ctx, workerID, pollInterval, done)
+		}()
 	}
 
 	for i := 0; i < retryWorkers; i++ {
 		workerID := i + 1
-		go tp.retryTaskWorker(ctx, workerID, maxRetries, pollInterval, done)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tp.retryTaskWorker(ctx, workerID, maxRetries, pollInterval, done)
+		}()
 	}
 
 	slog.Info("任务处理工作者已启动", "normal_workers", normalWorkers, "retry_workers", retryWorkers, "max_concurrent", tp.maxWorkers)
+	wg.Wait()
 }
 
 func (tp *TaskProcessor) normalTaskWorker(ctx context.Context, workerID int, pollInterval time.Duration, done <-chan struct{}) {
@@ -297,6 +448,11 @@ func (tp *TaskProcessor) normalTaskWorker(ctx context.Context, workerID int, pol
 				continue
 			}
 
+			// 超过每日预算时暂停处理，任务保留在队列中
+			if tp.budgetExceeded(ctx) {
+				continue
+			}
+
 			err := tp.ProcessNextTask(ctx)
 			if err != nil {
 				slog.Error("正常任务处理失败", "worker_id", workerID, "error", err)
@@ -326,6 +482,11 @@ func (tp *TaskProcessor) retryTaskWorker(ctx context.Context, workerID int, maxR
 				continue
 			}
 
+			// 超过每日预算时暂停处理，任务保留在队列中
+			if tp.budgetExceeded(ctx) {
+				continue
+			}
+
 			err := tp.ProcessFailedTask(ctx, maxRetries)
 			if err != nil {
 				slog.Error("失败任务重试失败", "worker_id", workerID, "error", err)
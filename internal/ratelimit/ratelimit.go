@@ -0,0 +1,238 @@
+// 位于: internal/ratelimit/ratelimit.go
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// 令牌桶名称
+const (
+	BucketAPI  = "api"  // 所有 API 请求
+	BucketPaid = "paid" // 会调用付费 LLM / Embedding API 的请求
+)
+
+// Limit 是一个令牌桶：每分钟补充 PerMinute 个令牌，最多积累 Burst 个；PerMinute 为 0 表示不限制
+type Limit struct {
+	PerMinute float64 `mapstructure:"per_minute"`
+	Burst     int     `mapstructure:"burst"` // 0 表示与 PerMinute 相同
+}
+
+// Enabled 判断令牌桶是否生效
+func (l Limit) Enabled() bool {
+	return l.PerMinute > 0
+}
+
+// capacity 返回桶的容量
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return math.Max(1, math.Ceil(l.PerMinute))
+}
+
+// perSecond 返回每秒补充的令牌数
+func (l Limit) perSecond() float64 {
+	return l.PerMinute / 60
+}
+
+// Quota 是每个调用者每天可以提交的任务数和问题行数，0 表示不限制
+type Quota struct {
+	DailyTasks     int `mapstructure:"daily_tasks"`
+	DailyQuestions int `mapstructure:"daily_questions"`
+}
+
+// Enabled 判断配额是否生效
+func (q Quota) Enabled() bool {
+	return q.DailyTasks > 0 || q.DailyQuestions > 0
+}
+
+// QuotaUsage 是调用者当天已用的配额
+type QuotaUsage struct {
+	Tasks     int
+	Questions int
+}
+
+// Store 持久化令牌桶和每日配额，多个副本共享同一份状态
+type Store interface {
+	// TakeRateLimitToken 按经过的时间补充令牌后尝试取出一个，返回剩余令牌数
+	TakeRateLimitToken(ctx context.Context, key string, perSecond, capacity float64) (remaining float64, allowed bool, err error)
+	// PruneRateLimitBuckets 删除 before 之后没有使用过的令牌桶
+	PruneRateLimitBuckets(ctx context.Context, before time.Time) (int64, error)
+	// ConsumeDailyQuota 在不超过 quota 时累加当天的用量，返回累加后 (或被拒绝时当前) 的用量
+	ConsumeDailyQuota(ctx context.Context, subject, day string, tasks, questions int, quota Quota) (QuotaUsage, bool, error)
+	// ReleaseDailyQuota 退还已累加的用量
+	ReleaseDailyQuota(ctx context.Context, subject, day string, tasks, questions int) error
+	// GetDailyQuota 返回当天的用量
+	GetDailyQuota(ctx context.Context, subject, day string) (QuotaUsage, error)
+}
+
+// Decision 是一次令牌桶检查的结果，对应 RateLimit-* 响应头
+type Decision struct {
+	Allowed    bool
+	Limit      int           // 桶容量
+	Remaining  int           // 剩余令牌数 (向下取整)
+	Reset      time.Duration // 令牌补满所需时间
+	RetryAfter time.Duration // 被拒绝时，补充一个令牌所需时间
+	Window     time.Duration // 空桶补满所需时间
+}
+
+// QuotaDecision 是一次配额检查的结果
+type QuotaDecision struct {
+	Allowed  bool
+	Usage    QuotaUsage
+	Quota    Quota
+	Exceeded string    // 被拒绝时超出的配额：tasks/questions
+	ResetAt  time.Time // 配额重置的时间 (本地时间次日 0 点)
+}
+
+// pruneInterval 是清理闲置令牌桶的间隔，bucketIdleTTL 是令牌桶闲置多久后删除
+// 闲置超过补满时间的桶与新桶等价，删除不影响限流结果
+const (
+	pruneInterval = time.Hour
+	bucketIdleTTL = 24 * time.Hour
+)
+
+// Limiter 按调用者限流并检查每日配额，Store 出错时放行并记录日志，避免数据库抖动导致接口不可用
+type Limiter struct {
+	store  Store
+	limits map[string]Limit
+	quota  Quota
+
+	mu       sync.Mutex
+	prunedAt time.Time
+}
+
+func NewLimiter(store Store, limits map[string]Limit, quota Quota) *Limiter {
+	if limits == nil {
+		limits = make(map[string]Limit)
+	}
+	return &Limiter{
+		store:  store,
+		limits: limits,
+		quota:  quota,
+	}
+}
+
+// Allow 从 bucket 中为 identity 取出一个令牌；bucket 未配置时返回 nil
+func (l *Limiter) Allow(ctx context.Context, bucket, identity string) *Decision {
+	limit, ok := l.limits[bucket]
+	if !ok || !limit.Enabled() {
+		return nil
+	}
+	l.pruneIfDue(ctx)
+
+	capacity, perSecond := limit.capacity(), limit.perSecond()
+	decision := &Decision{
+		Allowed: true,
+		Limit:   int(capacity),
+		Window:  seconds(capacity / perSecond),
+	}
+
+	remaining, allowed, err := l.store.TakeRateLimitToken(ctx, bucket+":"+identity, perSecond, capacity)
+	if err != nil {
+		slog.Error("rate limit check error", "error", err, "bucket", bucket, "identity", identity)
+		decision.Remaining = decision.Limit
+		return decision
+	}
+
+	decision.Allowed = allowed
+	decision.Remaining = int(math.Floor(remaining))
+	decision.Reset = seconds((capacity - remaining) / perSecond)
+	if !allowed {
+		decision.RetryAfter = seconds((1 - remaining) / perSecond)
+	}
+	return decision
+}
+
+// pruneIfDue 每隔 pruneInterval 在后台清理一次闲置的令牌桶
+func (l *Limiter) pruneIfDue(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if time.Since(l.prunedAt) < pruneInterval {
+		return
+	}
+	l.prunedAt = time.Now()
+
+	go func() {
+		pruneCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		n, err := l.store.PruneRateLimitBuckets(pruneCtx, time.Now().Add(-bucketIdleTTL))
+		if err != nil {
+			slog.Warn("清理闲置令牌桶失败", "error", err)
+			return
+		}
+		if n > 0 {
+			slog.Info("已清理闲置令牌桶", "count", n)
+		}
+	}()
+}
+
+// QuotaEnabled 判断是否配置了每日配额
+func (l *Limiter) QuotaEnabled() bool {
+	return l.quota.Enabled()
+}
+
+// ConsumeQuota 为 subject 记一次任务提交和 questions 行问题，超出配额时不记录并返回 Allowed=false
+func (l *Limiter) ConsumeQuota(ctx context.Context, subject string, questions int) QuotaDecision {
+	day, resetAt := today()
+	decision := QuotaDecision{Allowed: true, Quota: l.quota, ResetAt: resetAt}
+	if !l.quota.Enabled() {
+		return decision
+	}
+
+	usage, allowed, err := l.store.ConsumeDailyQuota(ctx, subject, day, 1, questions, l.quota)
+	if err != nil {
+		slog.Error("quota check error", "error", err, "subject", subject)
+		return decision
+	}
+	decision.Usage = usage
+	decision.Allowed = allowed
+	if !allowed {
+		if l.quota.DailyTasks > 0 && usage.Tasks+1 > l.quota.DailyTasks {
+			decision.Exceeded = "tasks"
+		} else {
+			decision.Exceeded = "questions"
+		}
+	}
+	return decision
+}
+
+// ReleaseQuota 退还 ConsumeQuota 记录的用量，用于任务最终没有创建成功的情况
+func (l *Limiter) ReleaseQuota(ctx context.Context, subject string, questions int) {
+	if !l.quota.Enabled() {
+		return
+	}
+	day, _ := today()
+	if err := l.store.ReleaseDailyQuota(ctx, subject, day, 1, questions); err != nil {
+		slog.Error("release quota error", "error", err, "subject", subject)
+	}
+}
+
+// QuotaStatus 返回 subject 当天的配额用量
+func (l *Limiter) QuotaStatus(ctx context.Context, subject string) (QuotaDecision, error) {
+	day, resetAt := today()
+	usage, err := l.store.GetDailyQuota(ctx, subject, day)
+	if err != nil {
+		return QuotaDecision{}, err
+	}
+	return QuotaDecision{Allowed: true, Usage: usage, Quota: l.quota, ResetAt: resetAt}, nil
+}
+
+// today 返回本地日期及下一个 0 点，与每日用量预算的计算方式一致
+func today() (string, time.Time) {
+	now := time.Now()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	return now.Format("2006-01-02"), tomorrow
+}
+
+// seconds 将秒数转换为 Duration，负数视为 0
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
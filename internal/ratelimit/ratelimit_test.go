@@ -0,0 +1,169 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeStore 返回预设的结果，并记录最近一次调用的参数
+type fakeStore struct {
+	remaining float64
+	allowed   bool
+	usage     QuotaUsage
+	err       error
+
+	key       string
+	perSecond float64
+	capacity  float64
+	calls     int
+}
+
+func (s *fakeStore) TakeRateLimitToken(_ context.Context, key string, perSecond, capacity float64) (float64, bool, error) {
+	s.key, s.perSecond, s.capacity = key, perSecond, capacity
+	s.calls++
+	return s.remaining, s.allowed, s.err
+}
+
+func (s *fakeStore) PruneRateLimitBuckets(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (s *fakeStore) ConsumeDailyQuota(context.Context, string, string, int, int, Quota) (QuotaUsage, bool, error) {
+	s.calls++
+	return s.usage, s.allowed, s.err
+}
+
+func (s *fakeStore) ReleaseDailyQuota(context.Context, string, string, int, int) error {
+	return nil
+}
+
+func (s *fakeStore) GetDailyQuota(context.Context, string, string) (QuotaUsage, error) {
+	return s.usage, s.err
+}
+
+func TestLimitCapacity(t *testing.T) {
+	tests := []struct {
+		limit         Limit
+		wantCapacity  float64
+		wantPerSecond float64
+	}{
+		{Limit{PerMinute: 60}, 60, 1},
+		{Limit{PerMinute: 30, Burst: 10}, 10, 0.5},
+		{Limit{PerMinute: 2.5}, 3, 2.5 / 60},
+		{Limit{PerMinute: 0.5}, 1, 0.5 / 60},
+	}
+	for _, tt := range tests {
+		if got := tt.limit.capacity(); got != tt.wantCapacity {
+			t.Errorf("%+v capacity = %v, want %v", tt.limit, got, tt.wantCapacity)
+		}
+		if got := tt.limit.perSecond(); got != tt.wantPerSecond {
+			t.Errorf("%+v perSecond = %v, want %v", tt.limit, got, tt.wantPerSecond)
+		}
+	}
+}
+
+func TestAllow(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit Limit
+		store fakeStore
+		want  Decision
+	}{
+		{
+			name:  "allowed",
+			limit: Limit{PerMinute: 60},
+			store: fakeStore{remaining: 59.5, allowed: true},
+			want:  Decision{Allowed: true, Limit: 60, Remaining: 59, Reset: 500 * time.Millisecond, Window: time.Minute},
+		},
+		{
+			name:  "rejected waits for the next token",
+			limit: Limit{PerMinute: 60},
+			store: fakeStore{remaining: 0.25, allowed: false},
+			want:  Decision{Limit: 60, Remaining: 0, Reset: 59750 * time.Millisecond, RetryAfter: 750 * time.Millisecond, Window: time.Minute},
+		},
+		{
+			name:  "burst",
+			limit: Limit{PerMinute: 30, Burst: 10},
+			store: fakeStore{remaining: 9, allowed: true},
+			want:  Decision{Allowed: true, Limit: 10, Remaining: 9, Reset: 2 * time.Second, Window: 20 * time.Second},
+		},
+		{
+			name:  "slow bucket",
+			limit: Limit{PerMinute: 0.5},
+			store: fakeStore{remaining: 0, allowed: false},
+			want:  Decision{Limit: 1, Remaining: 0, Reset: 2 * time.Minute, RetryAfter: 2 * time.Minute, Window: 2 * time.Minute},
+		},
+		{
+			name:  "store error fails open",
+			limit: Limit{PerMinute: 60},
+			store: fakeStore{err: errors.New("db down")},
+			want:  Decision{Allowed: true, Limit: 60, Remaining: 60, Window: time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.store
+			l := NewLimiter(&store, map[string]Limit{BucketAPI: tt.limit}, Quota{})
+			got := l.Allow(context.Background(), BucketAPI, "alice")
+			if got == nil {
+				t.Fatal("Allow returned nil for a configured bucket")
+			}
+			if *got != tt.want {
+				t.Errorf("Allow = %+v, want %+v", *got, tt.want)
+			}
+			if store.key != "api:alice" || store.perSecond != tt.limit.perSecond() || store.capacity != tt.limit.capacity() {
+				t.Errorf("store called with key=%q perSecond=%v capacity=%v", store.key, store.perSecond, store.capacity)
+			}
+		})
+	}
+}
+
+func TestAllowUnlimited(t *testing.T) {
+	store := &fakeStore{}
+	l := NewLimiter(store, map[string]Limit{BucketAPI: {PerMinute: 0, Burst: 5}}, Quota{})
+	for _, bucket := range []string{BucketAPI, BucketPaid} {
+		if got := l.Allow(context.Background(), bucket, "alice"); got != nil {
+			t.Errorf("Allow(%s) = %+v, want nil", bucket, got)
+		}
+	}
+	if store.calls != 0 {
+		t.Errorf("store called %d times for unlimited buckets", store.calls)
+	}
+}
+
+func TestConsumeQuota(t *testing.T) {
+	quota := Quota{DailyTasks: 5, DailyQuestions: 100}
+	tests := []struct {
+		name         string
+		quota        Quota
+		store        fakeStore
+		wantAllowed  bool
+		wantExceeded string
+		wantCalls    int
+	}{
+		{"allowed", quota, fakeStore{usage: QuotaUsage{Tasks: 3, Questions: 40}, allowed: true}, true, "", 1},
+		{"tasks exceeded", quota, fakeStore{usage: QuotaUsage{Tasks: 5, Questions: 40}}, false, "tasks", 1},
+		{"questions exceeded", quota, fakeStore{usage: QuotaUsage{Tasks: 2, Questions: 95}}, false, "questions", 1},
+		{"questions only quota", Quota{DailyQuestions: 100}, fakeStore{usage: QuotaUsage{Tasks: 50, Questions: 95}}, false, "questions", 1},
+		{"disabled", Quota{}, fakeStore{}, true, "", 0},
+		{"store error fails open", quota, fakeStore{err: errors.New("db down")}, true, "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.store
+			l := NewLimiter(&store, nil, tt.quota)
+			got := l.ConsumeQuota(context.Background(), "alice", 10)
+			if got.Allowed != tt.wantAllowed || got.Exceeded != tt.wantExceeded {
+				t.Errorf("ConsumeQuota = allowed %v exceeded %q, want %v %q", got.Allowed, got.Exceeded, tt.wantAllowed, tt.wantExceeded)
+			}
+			if store.calls != tt.wantCalls {
+				t.Errorf("store called %d times, want %d", store.calls, tt.wantCalls)
+			}
+			if got.ResetAt.Before(time.Now()) {
+				t.Errorf("ResetAt = %v, want a time in the future", got.ResetAt)
+			}
+		})
+	}
+}
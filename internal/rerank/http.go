@@ -0,0 +1,85 @@
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPReranker 调用本地部署的 rerank 服务 (Text Embeddings Inference 的 /rerank 接口格式)
+//
+// 请求: {"query": "...", "texts": ["...", "..."]}
+// 响应: [{"index": 0, "score": 0.98}, ...]
+type HTTPReranker struct {
+	endpoint string
+	client   *http.Client
+}
+
+func NewHTTPReranker(endpoint string, timeout time.Duration) (*HTTPReranker, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("rerank endpoint is required")
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &HTTPReranker{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+type httpRerankRequest struct {
+	Query string   `json:"query"`
+	Texts []string `json:"texts"`
+}
+
+type httpRerankResult struct {
+	Index int     `json:"index"`
+	Score float64 `json:"score"`
+}
+
+func (hr *HTTPReranker) Rerank(ctx context.Context, query string, docs []Document) ([]float64, error) {
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Text
+	}
+
+	body, err := json.Marshal(httpRerankRequest{Query: query, Texts: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hr.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := hr.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rerank endpoint error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("rerank endpoint returned %d: %s", resp.StatusCode, msg)
+	}
+
+	var results []httpRerankResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to decode rerank response: %w", err)
+	}
+
+	scores := make([]float64, len(docs))
+	for _, r := range results {
+		if r.Index >= 0 && r.Index < len(docs) {
+			scores[r.Index] = r.Score
+		}
+	}
+	return scores, nil
+}
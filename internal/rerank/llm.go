@@ -0,0 +1,133 @@
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"paguu/internal/enrich"
+	"paguu/internal/usage"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime"
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model/responses"
+)
+
+// LLMReranker 通过丰富化所用的 Ark 模型判断候选与查询的相关性
+type LLMReranker struct {
+	client         *arkruntime.Client
+	modelName      string
+	promptTemplate *template.Template
+	recorder       usage.Recorder
+}
+
+func NewLLMReranker(client *arkruntime.Client, templatePath string, modelName string) (*LLMReranker, error) {
+	if client == nil {
+		return nil, fmt.Errorf("ark client is required")
+	}
+	if modelName == "" {
+		return nil, fmt.Errorf("rerank model name is required")
+	}
+
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(templatePath).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	return &LLMReranker{
+		client:         client,
+		modelName:      modelName,
+		promptTemplate: tmpl,
+	}, nil
+}
+
+// SetUsageRecorder 设置用量记录器，为 nil 时不记录
+func (lr *LLMReranker) SetUsageRecorder(recorder usage.Recorder) {
+	lr.recorder = recorder
+}
+
+// llmCandidate 是传入 prompt 的候选，ID 为从 1 开始的序号
+type llmCandidate struct {
+	ID   int
+	Text string
+}
+
+// llmScores 是 LLM 返回的打分结果
+type llmScores struct {
+	Scores []struct {
+		ID    int     `json:"id"`
+		Score float64 `json:"score"`
+	} `json:"scores"`
+}
+
+func (lr *LLMReranker) Rerank(ctx context.Context, query string, docs []Document) ([]float64, error) {
+	candidates := make([]llmCandidate, len(docs))
+	for i, doc := range docs {
+		candidates[i] = llmCandidate{ID: i + 1, Text: doc.Text}
+	}
+
+	var buf bytes.Buffer
+	err := lr.promptTemplate.Execute(&buf, map[string]interface{}{
+		"Query":      query,
+		"Candidates": candidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	resp, err := lr.client.CreateResponses(ctx, &responses.ResponsesRequest{
+		Model: lr.modelName,
+		Input: &responses.ResponsesInput{Union: &responses.ResponsesInput_StringValue{StringValue: buf.String()}},
+	})
+	lr.recordUsage(ctx, resp, time.Since(startedAt), err)
+	if err != nil {
+		return nil, fmt.Errorf("rerank request error: %w", err)
+	}
+
+	text := enrich.ExtractResponseText(resp)
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("empty rerank response text")
+	}
+
+	var parsed llmScores
+	if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rerank response JSON: %w", err)
+	}
+
+	// 未被打分的候选记为 0 分，排在已打分候选之后
+	scores := make([]float64, len(docs))
+	for _, s := range parsed.Scores {
+		if s.ID >= 1 && s.ID <= len(docs) {
+			scores[s.ID-1] = s.Score
+		}
+	}
+	return scores, nil
+}
+
+// recordUsage 记录一次重排序调用的用量
+func (lr *LLMReranker) recordUsage(ctx context.Context, resp *responses.ResponseObject, latency time.Duration, err error) {
+	if lr.recorder == nil {
+		return
+	}
+	event := usage.Event{
+		Provider:  usage.ProviderArk,
+		Operation: usage.OperationRerank,
+		Model:     lr.modelName,
+		Latency:   latency,
+		Err:       err,
+	}
+	if resp != nil && resp.Usage != nil {
+		event.PromptTokens = resp.Usage.InputTokens
+		event.CompletionTokens = resp.Usage.OutputTokens
+		event.TotalTokens = resp.Usage.TotalTokens
+	}
+	lr.recorder.Record(ctx, event)
+}
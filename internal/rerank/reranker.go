@@ -0,0 +1,103 @@
+// 位于: internal/rerank/reranker.go
+package rerank
+
+import (
+	"context"
+	"fmt"
+	"paguu/internal/usage"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime"
+)
+
+// Document 是一个待重排序的候选
+type Document struct {
+	ID   uint
+	Text string
+}
+
+// Reranker 对候选文档按与查询的相关性打分，返回的分数与 docs 一一对应 (越大越相关)
+type Reranker interface {
+	Rerank(ctx context.Context, query string, docs []Document) ([]float64, error)
+}
+
+// Result 是重排序后的一个候选
+type Result struct {
+	Index int     // 在输入 docs 中的下标
+	Score float64 // 重排序分数
+}
+
+// Order 对候选打分并按分数降序排列，同分时保持原始 (向量检索) 顺序
+func Order(ctx context.Context, r Reranker, query string, docs []Document) ([]Result, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	scores, err := r.Rerank(ctx, query, docs)
+	if err != nil {
+		return nil, err
+	}
+	if len(scores) != len(docs) {
+		return nil, fmt.Errorf("reranker returned %d scores for %d documents", len(scores), len(docs))
+	}
+
+	results := make([]Result, len(docs))
+	for i, score := range scores {
+		results[i] = Result{Index: i, Score: score}
+	}
+	sort.SliceStable(results, func(a, b int) bool {
+		return results[a].Score > results[b].Score
+	})
+	return results, nil
+}
+
+// DocumentText 拼接用于重排序的文章文本：优先使用专业化问题，附带关键词密集的答案
+func DocumentText(originalQuestion string, detailedQuestion, conciseAnswer *string) string {
+	var sb strings.Builder
+	if detailedQuestion != nil && *detailedQuestion != "" {
+		sb.WriteString(*detailedQuestion)
+	} else {
+		sb.WriteString(originalQuestion)
+	}
+	if conciseAnswer != nil && *conciseAnswer != "" {
+		sb.WriteString("\n")
+		sb.WriteString(*conciseAnswer)
+	}
+	return sb.String()
+}
+
+// 重排序器类型
+const (
+	ProviderLLM  = "llm"
+	ProviderHTTP = "http"
+)
+
+// Options 描述要创建的重排序器
+type Options struct {
+	Provider     string        // llm/http，空表示不启用
+	Model        string        // llm: Ark 模型名
+	TemplatePath string        // llm: prompt 模板路径
+	Endpoint     string        // http: rerank 服务地址
+	Timeout      time.Duration // http: 请求超时
+}
+
+// New 按配置创建重排序器，Provider 为空时返回 nil
+func New(opts Options, arkClient *arkruntime.Client, recorder usage.Recorder) (Reranker, error) {
+	switch opts.Provider {
+	case "":
+		return nil, nil
+	case ProviderLLM:
+		r, err := NewLLMReranker(arkClient, opts.TemplatePath, opts.Model)
+		if err != nil {
+			return nil, err
+		}
+		r.SetUsageRecorder(recorder)
+		return r, nil
+	case ProviderHTTP:
+		return NewHTTPReranker(opts.Endpoint, opts.Timeout)
+	default:
+		return nil, fmt.Errorf("unknown rerank provider %q", opts.Provider)
+	}
+}
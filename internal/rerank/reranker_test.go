@@ -0,0 +1,119 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// fixedReranker 返回预设的分数
+type fixedReranker struct {
+	scores []float64
+	err    error
+}
+
+func (r fixedReranker) Rerank(context.Context, string, []Document) ([]float64, error) {
+	return r.scores, r.err
+}
+
+func TestOrder(t *testing.T) {
+	docs := []Document{{ID: 10}, {ID: 11}, {ID: 12}, {ID: 13}}
+
+	tests := []struct {
+		name      string
+		docs      []Document
+		reranker  fixedReranker
+		wantOrder []int
+		wantErr   bool
+	}{
+		{"by score descending", docs, fixedReranker{scores: []float64{0.1, 0.9, 0.5, 0.3}}, []int{1, 2, 3, 0}, false},
+		{"ties keep vector order", docs, fixedReranker{scores: []float64{0.5, 0.8, 0.5, 0.8}}, []int{1, 3, 0, 2}, false},
+		{"no documents", nil, fixedReranker{err: errors.New("must not be called")}, nil, false},
+		{"score count mismatch", docs, fixedReranker{scores: []float64{1, 2}}, nil, true},
+		{"reranker error", docs, fixedReranker{err: errors.New("timeout")}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := Order(context.Background(), tt.reranker, "query", tt.docs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Order error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var order []int
+			for _, r := range results {
+				order = append(order, r.Index)
+				if r.Score != tt.reranker.scores[r.Index] {
+					t.Errorf("result %d score = %v, want %v", r.Index, r.Score, tt.reranker.scores[r.Index])
+				}
+			}
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("order = %v, want %v", order, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestHTTPReranker(t *testing.T) {
+	docs := []Document{{ID: 1, Text: "a"}, {ID: 2, Text: "b"}, {ID: 3, Text: "c"}}
+
+	tests := []struct {
+		name       string
+		status     int
+		response   string
+		wantScores []float64
+		wantErr    bool
+	}{
+		{"results in any order", http.StatusOK, `[{"index":2,"score":0.9},{"index":0,"score":0.2},{"index":1,"score":0.5}]`, []float64{0.2, 0.5, 0.9}, false},
+		{"missing and out-of-range indexes", http.StatusOK, `[{"index":1,"score":0.7},{"index":5,"score":1}]`, []float64{0, 0.7, 0}, false},
+		{"server error", http.StatusBadGateway, `upstream down`, nil, true},
+		{"invalid json", http.StatusOK, `{"scores":`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got httpRerankRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("decode request: %v", err)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			hr, err := NewHTTPReranker(server.URL, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scores, err := hr.Rerank(context.Background(), "query", docs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Rerank error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(scores, tt.wantScores) {
+				t.Errorf("scores = %v, want %v", scores, tt.wantScores)
+			}
+			if got.Query != "query" || !reflect.DeepEqual(got.Texts, []string{"a", "b", "c"}) {
+				t.Errorf("request = %+v", got)
+			}
+		})
+	}
+}
+
+func TestDocumentText(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		detailed, answer *string
+		want             string
+	}{
+		{str("什么是 GMP 调度模型？"), str("G、M、P 三者协作"), "什么是 GMP 调度模型？\nG、M、P 三者协作"},
+		{nil, str("G、M、P 三者协作"), "GMP\nG、M、P 三者协作"},
+		{str(""), nil, "GMP"},
+	}
+	for _, tt := range tests {
+		if got := DocumentText("GMP", tt.detailed, tt.answer); got != tt.want {
+			t.Errorf("DocumentText = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package srs
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Rating 是复习时对回忆程度的自评
+type Rating string
+
+const (
+	RatingAgain Rating = "again" // 完全没想起来
+	RatingHard  Rating = "hard"  // 想起来了，但很吃力
+	RatingGood  Rating = "good"  // 正常想起
+	RatingEasy  Rating = "easy"  // 毫不费力
+)
+
+// ParseRating 解析自评，只接受 again/hard/good/easy
+func ParseRating(s string) (Rating, error) {
+	switch r := Rating(s); r {
+	case RatingAgain, RatingHard, RatingGood, RatingEasy:
+		return r, nil
+	default:
+		return "", fmt.Errorf("invalid rating %q, want again/hard/good/easy", s)
+	}
+}
+
+// quality 将自评映射为 SM-2 的 0-5 分：again=1, hard=3, good=4, easy=5
+func (r Rating) quality() float64 {
+	switch r {
+	case RatingAgain:
+		return 1
+	case RatingHard:
+		return 3
+	case RatingEasy:
+		return 5
+	default:
+		return 4
+	}
+}
+
+// SM-2 参数
+const (
+	DefaultEase = 2.5 // 新卡片的难度系数
+	MinEase     = 1.3 // 难度系数下限
+)
+
+// State 是一张卡片的 SM-2 复习状态
+type State struct {
+	Ease        float64 // 难度系数，每次复习后按自评调整
+	Interval    int     // 当前复习间隔 (天)
+	Repetitions int     // 连续答对次数，答错时清零
+	Lapses      int     // 累计遗忘次数
+}
+
+// NewState 返回新卡片的初始状态
+func NewState() State {
+	return State{Ease: DefaultEase}
+}
+
+// Schedule 按 SM-2 计算一次复习后的状态和下次到期时间
+// 答错 (again) 时连续次数清零、明天重来；答对时间隔依次为 1 天、6 天，之后乘以难度系数
+func Schedule(s State, rating Rating, now time.Time) (State, time.Time) {
+	if s.Ease < MinEase {
+		s.Ease = DefaultEase
+	}
+
+	q := rating.quality()
+	s.Ease = math.Max(MinEase, s.Ease+0.1-(5-q)*(0.08+(5-q)*0.02))
+
+	if q < 3 {
+		s.Repetitions = 0
+		s.Lapses++
+		s.Interval = 1
+	} else {
+		switch s.Repetitions {
+		case 0:
+			s.Interval = 1
+		case 1:
+			s.Interval = 6
+		default:
+			s.Interval = int(math.Round(float64(s.Interval) * s.Ease))
+		}
+		s.Interval = max(s.Interval, 1)
+		s.Repetitions++
+	}
+
+	return s, now.AddDate(0, 0, s.Interval)
+}
@@ -0,0 +1,95 @@
+package srs
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseRating(t *testing.T) {
+	for _, s := range []string{"again", "hard", "good", "easy"} {
+		if r, err := ParseRating(s); err != nil || string(r) != s {
+			t.Errorf("ParseRating(%q) = %q, %v", s, r, err)
+		}
+	}
+	for _, s := range []string{"", "Good", "ok", "5"} {
+		if _, err := ParseRating(s); err == nil {
+			t.Errorf("ParseRating(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	now := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		state  State
+		rating Rating
+		want   State
+	}{
+		{
+			name:   "new card good",
+			state:  NewState(),
+			rating: RatingGood,
+			want:   State{Ease: 2.5, Interval: 1, Repetitions: 1},
+		},
+		{
+			name:   "second repetition is 6 days",
+			state:  State{Ease: 2.5, Interval: 1, Repetitions: 1},
+			rating: RatingGood,
+			want:   State{Ease: 2.5, Interval: 6, Repetitions: 2},
+		},
+		{
+			name:   "later repetitions multiply by ease",
+			state:  State{Ease: 2.5, Interval: 6, Repetitions: 2},
+			rating: RatingGood,
+			want:   State{Ease: 2.5, Interval: 15, Repetitions: 3},
+		},
+		{
+			name:   "easy raises ease before multiplying",
+			state:  State{Ease: 2.5, Interval: 6, Repetitions: 2},
+			rating: RatingEasy,
+			want:   State{Ease: 2.6, Interval: 16, Repetitions: 3},
+		},
+		{
+			name:   "hard lowers ease but still passes",
+			state:  State{Ease: 2.5, Interval: 6, Repetitions: 2},
+			rating: RatingHard,
+			want:   State{Ease: 2.36, Interval: 14, Repetitions: 3},
+		},
+		{
+			name:   "again resets repetitions and counts a lapse",
+			state:  State{Ease: 2.5, Interval: 15, Repetitions: 3, Lapses: 1},
+			rating: RatingAgain,
+			want:   State{Ease: 1.96, Interval: 1, Repetitions: 0, Lapses: 2},
+		},
+		{
+			name:   "ease never drops below the minimum",
+			state:  State{Ease: MinEase, Interval: 1, Repetitions: 0},
+			rating: RatingAgain,
+			want:   State{Ease: MinEase, Interval: 1, Repetitions: 0, Lapses: 1},
+		},
+		{
+			name:   "invalid ease resets to default",
+			state:  State{},
+			rating: RatingGood,
+			want:   State{Ease: DefaultEase, Interval: 1, Repetitions: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, due := Schedule(tt.state, tt.rating, now)
+			if math.Abs(got.Ease-tt.want.Ease) > 1e-9 {
+				t.Errorf("Ease = %v, want %v", got.Ease, tt.want.Ease)
+			}
+			got.Ease = tt.want.Ease
+			if got != tt.want {
+				t.Errorf("Schedule() = %+v, want %+v", got, tt.want)
+			}
+			if wantDue := now.AddDate(0, 0, tt.want.Interval); !due.Equal(wantDue) {
+				t.Errorf("due = %v, want %v", due, wantDue)
+			}
+		})
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log/slog"
 	"paguu/internal/enrich"
+	"strconv"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/pgvector/pgvector-go"
@@ -25,7 +27,7 @@ func (r *Repository) FilterByTag(ctx context.Context, tag string, limit, offset
 	var total int64
 
 	// 1. 构建查询
-	query := r.db.WithContext(ctx).Model(&Article{})
+	query := r.db.WithContext(ctx).Model(&Article{}).Scopes(inWorkspace("workspace_id"))
 
 	// 2. 添加 Tag 筛选条件
 	// "tags @> ?" 是 GIN 索引支持的 "数组包含" 查询
@@ -53,10 +55,10 @@ func (r *Repository) FilterByTag(ctx context.Context, tag string, limit, offset
 func (r *Repository) FindSimilarBySourceID(ctx context.Context, sourceID uint, k int) ([]Article, error) {
 	// 1. 获取源文章的向量
 	var sourceArticle Article
-	err := r.db.WithContext(ctx).Select("embedding").First(&sourceArticle, sourceID).Error
+	err := r.db.WithContext(ctx).Scopes(inWorkspace("workspace_id")).Select("embedding").First(&sourceArticle, sourceID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("未找到源文章 ID: %d", sourceID)
+			return nil, ErrArticleNotFound
 		}
 		return nil, fmt.Errorf("获取源向量失败: %w", err)
 	}
@@ -70,6 +72,7 @@ func (r *Repository) FindSimilarBySourceID(ctx context.Context, sourceID uint, k
 			Vars: []interface{}{anchorVector},
 		},
 	}).
+		Scopes(inWorkspace("workspace_id")).
 		Where("id != ?", sourceID).
 		Limit(k).
 		Find(&similarResults).Error
@@ -81,13 +84,20 @@ func (r *Repository) FindSimilarBySourceID(ctx context.Context, sourceID uint, k
 	return similarResults, nil
 }
 
-// InsertArticle 插入新文章
+// InsertArticle 插入新文章，未指定工作区时写入 context 中的工作区
 func (r *Repository) InsertArticle(ctx context.Context, article *Article) error {
+	if article.WorkspaceID == 0 {
+		workspaceID, err := requireWorkspace(ctx)
+		if err != nil {
+			return err
+		}
+		article.WorkspaceID = workspaceID
+	}
 	result := r.db.WithContext(ctx).Create(article)
 	return result.Error
 }
 
-// FindClosestArticle 查找最接近的向量及其距离 (用于检查重复)
+// FindClosestArticle 在 context 中的工作区内查找最接近的向量及其距离 (用于检查重复)
 // 返回: (最接近的文章, 距离, 错误)
 func (r *Repository) FindClosestArticle(ctx context.Context, queryVector pgvector.Vector) (*Article, float64, error) {
 	// 我们需要一个临时结构体来接收查询结果
@@ -97,6 +107,7 @@ func (r *Repository) FindClosestArticle(ctx context.Context, queryVector pgvecto
 	}
 
 	err := r.db.WithContext(ctx).Model(&Article{}).
+		Scopes(inWorkspace("workspace_id")).
 		Select("*, embedding <#> ? AS distance", queryVector).
 		Order("distance ASC").
 		Limit(1).
@@ -115,7 +126,12 @@ func (r *Repository) FindClosestArticle(ctx context.Context, queryVector pgvecto
 // MergeDuplicate 合并重复项
 // 将新的 InterviewQuestion 添加到 ext 字段的数组中
 func (r *Repository) MergeDuplicate(ctx context.Context, targetID uint, duplicate enrich.InterviewQuestion) error {
-	// 序列化 InterviewQuestion 为 JSON
+	return mergeDuplicate(r.db.WithContext(ctx), targetID, ExtEntry{InterviewQuestion: duplicate})
+}
+
+// mergeDuplicate 是 MergeDuplicate 的实现，db 可以是事务
+func mergeDuplicate(db *gorm.DB, targetID uint, duplicate ExtEntry) error {
+	// 序列化 Ext 项为 JSON
 	duplicateJSON, err := json.Marshal(duplicate)
 	if err != nil {
 		return fmt.Errorf("序列化 InterviewQuestion 失败: %w", err)
@@ -123,12 +139,12 @@ func (r *Repository) MergeDuplicate(ctx context.Context, targetID uint, duplicat
 
 	// 将新的 InterviewQuestion 追加到 ext 数组末尾
 	// COALESCE(ext, '[]'::jsonb) 确保如果 ext 为 NULL，则初始化为空数组
-	result := r.db.WithContext(ctx).Model(&Article{}).
+	result := db.Model(&Article{}).
 		Where("id = ?", targetID).
-		UpdateColumn("ext", gorm.Expr(
-			"COALESCE(ext, '[]'::jsonb) || ?::jsonb",
-			duplicateJSON,
-		))
+		UpdateColumns(map[string]interface{}{
+			"ext":        gorm.Expr("COALESCE(ext, '[]'::jsonb) || ?::jsonb", duplicateJSON),
+			"updated_at": gorm.Expr("NOW()"),
+		})
 
 	return result.Error
 }
@@ -140,24 +156,79 @@ const (
 	QuestionInsertStatusFailed QuestionInsertStatus = iota
 	QuestionInsertStatusMerged
 	QuestionInsertStatusSuccess
+	QuestionInsertStatusFlagged  // 已作为新文章插入，但落在灰区内，等待人工审核是否重复
+	QuestionInsertStatusIngested // 任务之前的尝试中已经入库，本次跳过
 )
 
+// String 返回状态的文本表示，用于记录任务结果
+func (s QuestionInsertStatus) String() string {
+	switch s {
+	case QuestionInsertStatusMerged:
+		return "merged"
+	case QuestionInsertStatusSuccess:
+		return "inserted"
+	case QuestionInsertStatusFlagged:
+		return "flagged"
+	case QuestionInsertStatusIngested:
+		return "already_ingested"
+	default:
+		return "failed"
+	}
+}
+
+// DedupeThresholds 是去重使用的相似度阈值
+// 向量已归一化，相似度即内积 (= -(embedding <#> v))，范围 -1 ~ 1
+type DedupeThresholds struct {
+	Merge  float64 // 相似度 > Merge 判定为重复并自动合并，例如 0.95
+	Review float64 // Review < 相似度 <= Merge 时插入新文章并标记为疑似重复，0 表示不启用灰区
+}
+
+// DefaultDedupeThresholds 返回默认阈值：0.95 以上自动合并，0.85 ~ 0.95 进入人工审核
+func DefaultDedupeThresholds() DedupeThresholds {
+	return DedupeThresholds{Merge: 0.95, Review: 0.85}
+}
+
+// decide 根据与最近邻的相似度决定新问题的去向：合并、插入并标记待审核或直接插入
+// found 为 false 表示库中没有任何文章
+func (t DedupeThresholds) decide(found bool, similarity float64) QuestionInsertStatus {
+	switch {
+	case found && similarity > t.Merge:
+		return QuestionInsertStatusMerged
+	case found && t.Review > 0 && similarity > t.Review:
+		return QuestionInsertStatusFlagged
+	default:
+		return QuestionInsertStatusSuccess
+	}
+}
+
 // ProcessEnrichedQuestion 实现了完整的新增记录逻辑 (去重与合并)
 //
 // 这是你的 worker 应该调用的主要方法。它接收：
 // 1. q: 一个从 LLM 返回的、已丰富的 InterviewQuestion 结构体。
-// 2. vector: q 对应的、已归一化的 1024 维向量。
-// 3. similarityThreshold: 你设定的重复项阈值 (例如 -0.95)。
+// 2. vector: q 对应的、已归一化的 1536 维向量。
+// 3. thresholds: 自动合并和灰区审核的相似度阈值。
+// 4. info: 本次提交的来源 (任务、来源、元信息、原始行)，写入出现记录。
 //
-// 它会自动处理"查找-决策-插入/合并"的完整流程。
+// 标签、去重查找和写入都限定在 context 中的工作区内。
+// 它会自动处理"规范化-查找-决策-插入/合并"的完整流程：
+// 相似度高于 Merge 时合并到最近的文章；落在灰区时插入新文章并创建一条待审核的疑似重复记录。
+// 无论插入还是合并，都会在同一事务中写入一条 article_occurrences 记录；新文章还会加入订阅其标签的用户的复习卡组。
 // 返回值: (QuestionInsertStatus, error)
 func (r *Repository) ProcessEnrichedQuestion(
 	ctx context.Context,
 	q enrich.InterviewQuestion,
 	vector []float32,
-	similarityThreshold float64, // e.g., -0.95
+	thresholds DedupeThresholds,
+	info OccurrenceInfo,
 ) (QuestionInsertStatus, error) {
 
+	// 0. 【规范化】标签统一映射为规范名，未登记的标签自动登记
+	tags, err := r.NormalizeTags(ctx, q.Tags)
+	if err != nil {
+		return QuestionInsertStatusFailed, fmt.Errorf("规范化标签失败: %w", err)
+	}
+	q.Tags = tags
+
 	pgNewVec := pgvector.NewVector(vector)
 
 	// 1. 【查找】使用向量查找最接近的现有文章
@@ -165,79 +236,278 @@ func (r *Repository) ProcessEnrichedQuestion(
 	if err != nil {
 		return QuestionInsertStatusFailed, fmt.Errorf("查找最近向量失败: %w", err)
 	}
+	similarity := -distance
+	decision := thresholds.decide(closestArticle != nil, similarity)
 
 	// 2. 【决策】
-	if closestArticle != nil && distance < similarityThreshold {
+	if decision == QuestionInsertStatusMerged {
 		// --- 【合并逻辑】---
 		// 判定为重复项
 		slog.Info("发现重复项，正在合并",
 			"target_id", closestArticle.ID,
 			"distance", distance)
 
-		// 将新的 InterviewQuestion 追加到 ext 数组中
-		err = r.MergeDuplicate(ctx, closestArticle.ID, q)
+		// 记录出现，并将新的 InterviewQuestion 连同出现记录 ID 追加到 ext 数组中，拆分时据此找回
+		err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			occurrenceID, err := insertOccurrence(tx, closestArticle.ID, OccurrenceMerged, q.OriginalQuestion, info, &similarity)
+			if err != nil {
+				return err
+			}
+			return mergeDuplicate(tx, closestArticle.ID, ExtEntry{InterviewQuestion: q, OccurrenceIDs: []uint{occurrenceID}})
+		})
 		if err != nil {
 			return QuestionInsertStatusFailed, fmt.Errorf("合并重复项到 ID %d 失败: %w", closestArticle.ID, err)
 		}
 
 		return QuestionInsertStatusMerged, nil
 
+	}
+
+	// --- 【新增逻辑】---
+	flagged := decision == QuestionInsertStatusFlagged
+	if closestArticle != nil {
+		slog.Info("判定为新文章，正在插入",
+			"nearest_id", closestArticle.ID,
+			"distance", distance,
+			"merge_threshold", thresholds.Merge,
+			"flagged", flagged)
 	} else {
-		// --- 【新增逻辑】---
-		if closestArticle != nil {
-			slog.Info("判定为新文章，正在插入",
-				"nearest_id", closestArticle.ID,
-				"distance", distance,
-				"threshold", similarityThreshold)
-		} else {
-			slog.Info("判定为新文章 (库为空)，正在插入")
-		}
+		slog.Info("判定为新文章 (库为空)，正在插入")
+	}
 
-		// 3. 【映射】将 InterviewQuestion 直接映射到 Article
-		// ext 初始化为空数组
-		emptyArray, _ := json.Marshal([]enrich.InterviewQuestion{})
-
-		newArticle := &Article{
-			OriginalQuestion: q.OriginalQuestion,
-			DetailedQuestion: &q.DetailedQuestion,
-			ConciseAnswer:    &q.ConciseAnswer,
-			Tags:             pq.StringArray(q.Tags),
-			Embedding:        pgNewVec,
-			Ext:              datatypes.JSON(emptyArray), // 初始化为空的 InterviewQuestion 数组
-		}
+	// 3. 【映射】将 InterviewQuestion 直接映射到 Article
+	// ext 初始化为空数组
+	emptyArray, _ := json.Marshal([]enrich.InterviewQuestion{})
+
+	newArticle := &Article{
+		WorkspaceID:      WorkspaceFrom(ctx),
+		OriginalQuestion: q.OriginalQuestion,
+		DetailedQuestion: &q.DetailedQuestion,
+		ConciseAnswer:    &q.ConciseAnswer,
+		Tags:             pq.StringArray(q.Tags),
+		Embedding:        pgNewVec,
+		Ext:              datatypes.JSON(emptyArray), // 初始化为空的 InterviewQuestion 数组
+	}
 
-		// 4. 【执行插入】
-		err = r.InsertArticle(ctx, newArticle)
-		if err != nil {
-			return QuestionInsertStatusFailed, fmt.Errorf("插入新文章失败: %w", err)
+	// 4. 【执行插入】文章、出现记录以及灰区内的疑似重复记录在同一事务中写入
+	var nearestSimilarity *float64
+	if closestArticle != nil {
+		nearestSimilarity = &similarity
+	}
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newArticle).Error; err != nil {
+			return err
 		}
+		if _, err := insertOccurrence(tx, newArticle.ID, OccurrenceInserted, q.OriginalQuestion, info, nearestSimilarity); err != nil {
+			return err
+		}
+		// 订阅了这些标签的用户自动把新文章加入复习卡组
+		if err := enrollArticle(tx, newArticle.ID, q.Tags); err != nil {
+			return err
+		}
+		if !flagged {
+			return nil
+		}
+		return tx.Create(&DuplicateReview{
+			WorkspaceID: newArticle.WorkspaceID,
+			ArticleID:   newArticle.ID,
+			CandidateID: closestArticle.ID,
+			Similarity:  similarity,
+			Status:      DuplicateReviewPending,
+		}).Error
+	})
+	if err != nil {
+		return QuestionInsertStatusFailed, fmt.Errorf("插入新文章失败: %w", err)
+	}
 
-		return QuestionInsertStatusSuccess, nil
+	if flagged {
+		return QuestionInsertStatusFlagged, nil
 	}
+	return QuestionInsertStatusSuccess, nil
 }
 
-// ListArticles 获取文章列表（按 ID 降序，支持 tag 筛选）
-func (r *Repository) ListArticles(ctx context.Context, tags []string, limit, offset int) ([]Article, int64, error) {
-	var articles []Article
+// 文章列表排序方式，排序键相同时都按 ID 排序
+const (
+	ArticleSortLatest      = "latest"      // 按 ID 排序 (默认，等同于 id)
+	ArticleSortID          = "id"          // 按 ID 排序
+	ArticleSortCreatedAt   = "created_at"  // 按创建时间排序
+	ArticleSortUpdatedAt   = "updated_at"  // 按最后更新时间排序
+	ArticleSortOccurrences = "occurrences" // 按出现次数排序
+)
+
+// 文章列表排序方向
+const (
+	SortDesc = "desc" // 降序 (默认)
+	SortAsc  = "asc"
+)
+
+// ErrInvalidCursor 表示分页游标无法解析，或与本次请求的排序方式不一致
+var ErrInvalidCursor = invalidError("invalid_cursor", "invalid cursor")
+
+// ListArticlesOptions 是文章列表的筛选、排序和分页参数
+type ListArticlesOptions struct {
+	Tags           []string         // 文章需包含全部 tags
+	Sort           string           // latest/id/created_at/updated_at/occurrences，空值为 latest
+	Order          string           // desc/asc，空值为 desc
+	Occurrence     OccurrenceFilter // 统计出现次数的范围 (时间窗口、来源、公司)
+	MinOccurrences int64            // 出现次数下限；设置了 Occurrence 筛选时至少为 1
+	After          *ArticleCursor   // keyset 分页：只返回排在该位置之后的文章，与 Offset 同时设置时先按游标筛选
+	EstimateTotal  bool             // 总数使用查询规划器的估计值，不执行 COUNT(*)
+	Limit          int
+	Offset         int
+}
+
+// ArticleCursor 是文章列表 keyset 分页的位置，即上一页最后一篇文章的排序键
+// 包含排序方式，换了排序方式的游标不能继续使用
+type ArticleCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v,omitempty"` // 排序键：时间为 RFC 3339，出现次数为整数，按 ID 排序时为空
+	ID    uint   `json:"id"`
+}
+
+// CursorAfter 返回 article 之后的位置，sort 和 order 与查询时的参数一致
+func CursorAfter(article ArticleWithOccurrences, sort, order string) ArticleCursor {
+	sort, order = normalizeArticleSort(sort, order)
+	cursor := ArticleCursor{Sort: sort, Order: order, ID: article.ID}
+	switch sort {
+	case ArticleSortCreatedAt:
+		cursor.Value = article.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case ArticleSortUpdatedAt:
+		cursor.Value = article.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case ArticleSortOccurrences:
+		cursor.Value = strconv.FormatInt(article.OccurrenceCount, 10)
+	}
+	return cursor
+}
+
+// normalizeArticleSort 填充默认的排序方式和方向，latest 视为 id
+func normalizeArticleSort(sort, order string) (string, string) {
+	if sort == "" || sort == ArticleSortLatest {
+		sort = ArticleSortID
+	}
+	if order != SortAsc {
+		order = SortDesc
+	}
+	return sort, order
+}
+
+// articleSortKey 返回排序方式对应的 SQL 表达式，occurrence_count 来自 ListArticles 中 join 的 oc
+func articleSortKey(sort string) string {
+	switch sort {
+	case ArticleSortCreatedAt:
+		return "articles.created_at"
+	case ArticleSortUpdatedAt:
+		return "articles.updated_at"
+	case ArticleSortOccurrences:
+		return "COALESCE(oc.occurrence_count, 0)"
+	}
+	return "articles.id"
+}
+
+// afterCursor 返回只保留 cursor 之后的文章的条件，排序键相同时按 ID 比较
+func afterCursor(cursor *ArticleCursor, sort, order string) (clause.Expr, error) {
+	if cursor.Sort != sort || cursor.Order != order {
+		return clause.Expr{}, fmt.Errorf("%w: cursor was issued for sort=%s order=%s", ErrInvalidCursor, cursor.Sort, cursor.Order)
+	}
+	op := "<"
+	if order == SortAsc {
+		op = ">"
+	}
+	if sort == ArticleSortID {
+		return gorm.Expr("articles.id "+op+" ?", cursor.ID), nil
+	}
+
+	var value any
+	var err error
+	switch sort {
+	case ArticleSortCreatedAt, ArticleSortUpdatedAt:
+		value, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	case ArticleSortOccurrences:
+		value, err = strconv.ParseInt(cursor.Value, 10, 64)
+	default:
+		return clause.Expr{}, fmt.Errorf("%w: unknown sort %q", ErrInvalidCursor, sort)
+	}
+	if err != nil {
+		return clause.Expr{}, fmt.Errorf("%w: malformed position", ErrInvalidCursor)
+	}
+	return gorm.Expr("("+articleSortKey(sort)+", articles.id) "+op+" (?, ?)", value, cursor.ID), nil
+}
+
+// ArticleWithOccurrences 是附带出现次数的文章
+type ArticleWithOccurrences struct {
+	Article
+	OccurrenceCount int64 `gorm:"column:occurrence_count"`
+}
+
+// ListArticles 获取文章列表（默认按 ID 降序，支持 tag 筛选、按出现次数筛选和多种排序）
+// 返回的总数不受 After 和分页影响；EstimateTotal 时为估计值
+func (r *Repository) ListArticles(ctx context.Context, opts ListArticlesOptions) ([]ArticleWithOccurrences, int64, error) {
+	var articles []ArticleWithOccurrences
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&Article{})
+	// 按筛选条件统计每篇文章的出现次数
+	counts := opts.Occurrence.apply(r.db.WithContext(ctx).Model(&ArticleOccurrence{})).
+		Scopes(inWorkspace("article_occurrences.workspace_id")).
+		Select("article_id, COUNT(*) AS occurrence_count").
+		Group("article_id")
+
+	query := r.db.WithContext(ctx).Model(&Article{}).
+		Scopes(inWorkspace("articles.workspace_id")).
+		Joins("LEFT JOIN (?) AS oc ON oc.article_id = articles.id", counts)
 
 	// 如果有 tag 筛选条件
-	if len(tags) > 0 {
+	if len(opts.Tags) > 0 {
 		// tags @> ? 表示 Article.Tags 数组包含查询的所有 tags
-		query = query.Where("tags @> ?", pq.Array(tags))
+		query = query.Where("articles.tags @> ?", pq.Array(opts.Tags))
 	}
 
-	// 获取总数
-	if err := query.Count(&total).Error; err != nil {
+	minOccurrences := opts.MinOccurrences
+	if !opts.Occurrence.IsZero() {
+		minOccurrences = max(minOccurrences, 1)
+	}
+	if minOccurrences > 0 {
+		query = query.Where("COALESCE(oc.occurrence_count, 0) >= ?", minOccurrences)
+	}
+
+	// 先校验游标，游标条件在统计总数之后再加入查询
+	sort, order := normalizeArticleSort(opts.Sort, opts.Order)
+	var after clause.Expr
+	if opts.After != nil {
+		cond, err := afterCursor(opts.After, sort, order)
+		if err != nil {
+			return nil, 0, err
+		}
+		after = cond
+	}
+
+	// 获取总数；COUNT(*) 需要扫描全部匹配的文章，文章较多时可以改用估计值
+	if opts.EstimateTotal {
+		estimated, err := estimateRows(r.db.WithContext(ctx), query.Session(&gorm.Session{}).Select("articles.id"))
+		if err != nil {
+			return nil, 0, err
+		}
+		total = estimated
+	} else if err := query.Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to count articles: %w", err)
 	}
 
+	if opts.After != nil {
+		query = query.Where(after)
+	}
+	direction := " DESC"
+	if order == SortAsc {
+		direction = " ASC"
+	}
+	if sort != ArticleSortID {
+		query = query.Order(articleSortKey(sort) + direction)
+	}
+	query = query.Order("articles.id" + direction)
+
 	// 获取分页数据
-	err := query.Order("id DESC").
-		Limit(limit).
-		Offset(offset).
+	err := query.Select("articles.*, COALESCE(oc.occurrence_count, 0) AS occurrence_count").
+		Limit(opts.Limit).
+		Offset(opts.Offset).
 		Find(&articles).Error
 
 	if err != nil {
@@ -247,58 +517,58 @@ func (r *Repository) ListArticles(ctx context.Context, tags []string, limit, off
 	return articles, total, nil
 }
 
-// VectorSearchArticles 向量相似度搜索
+// estimateRows 返回查询规划器对 query 结果行数的估计 (EXPLAIN)，不执行查询
+// 估计值依赖表的统计信息，可能与实际行数相差较大，适合只需要数量级的场景
+func estimateRows(db *gorm.DB, query *gorm.DB) (int64, error) {
+	var plan string
+	if err := db.Raw("EXPLAIN (FORMAT JSON) ?", query).Row().Scan(&plan); err != nil {
+		return 0, fmt.Errorf("failed to explain article query: %w", err)
+	}
+	var explained []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explained); err != nil {
+		return 0, fmt.Errorf("failed to parse query plan: %w", err)
+	}
+	if len(explained) == 0 {
+		return 0, errors.New("empty query plan")
+	}
+	return int64(explained[0].Plan.Rows), nil
+}
+
+// VectorSearchArticles 在 context 中的工作区内进行向量相似度搜索
 func (r *Repository) VectorSearchArticles(ctx context.Context, queryVector []float32, limit int) ([]Article, []float64, error) {
-	var articles []Article
+	var rows []struct {
+		Article
+		Similarity float64 `gorm:"column:similarity"`
+	}
 
-	// 使用原始 SQL 进行向量相似度搜索
 	// <#> 是内积距离运算符（值越小越相似，因为向量已归一化）
 	// 返回相似度 = 1 - distance（值越大越相似）
-	query := `
-		SELECT *, 1 - (embedding <#> ?) AS similarity
-		FROM articles
-		ORDER BY embedding <#> ?
-		LIMIT ?
-	`
-
 	pgVec := pgvector.NewVector(queryVector)
 
-	rows, err := r.db.WithContext(ctx).Raw(query, pgVec, pgVec, limit).Rows()
+	err := r.db.WithContext(ctx).Model(&Article{}).
+		Scopes(inWorkspace("workspace_id")).
+		Select("*, 1 - (embedding <#> ?) AS similarity", pgVec).
+		Clauses(clause.OrderBy{
+			Expression: clause.Expr{
+				SQL:  "embedding <#> ?",
+				Vars: []interface{}{pgVec},
+			},
+		}).
+		Limit(limit).
+		Find(&rows).Error
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to execute vector search: %w", err)
 	}
-	defer rows.Close()
-
-	similarities := make([]float64, 0, limit)
-
-	for rows.Next() {
-		var article Article
-		var similarity float64
-
-		err := r.db.ScanRows(rows, &article)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to scan article row: %w", err)
-		}
-
-		// 手动读取 similarity 列
-		if err := rows.Scan(
-			&article.ID,
-			&article.OriginalQuestion,
-			&article.DetailedQuestion,
-			&article.ConciseAnswer,
-			&article.Tags,
-			&article.Embedding,
-			&article.Ext,
-			&article.NotionPageID,
-			&article.LastSyncedAt,
-			&article.CreatedAt,
-			&similarity,
-		); err != nil {
-			return nil, nil, fmt.Errorf("failed to scan similarity: %w", err)
-		}
 
-		articles = append(articles, article)
-		similarities = append(similarities, similarity)
+	articles := make([]Article, len(rows))
+	similarities := make([]float64, len(rows))
+	for i, row := range rows {
+		articles[i] = row.Article
+		similarities[i] = row.Similarity
 	}
 
 	return articles, similarities, nil
@@ -307,17 +577,17 @@ func (r *Repository) VectorSearchArticles(ctx context.Context, queryVector []flo
 // GetArticleByID 根据 ID 获取文章
 func (r *Repository) GetArticleByID(ctx context.Context, id uint) (*Article, error) {
 	var article Article
-	err := r.db.WithContext(ctx).First(&article, id).Error
+	err := r.db.WithContext(ctx).Scopes(inWorkspace("workspace_id")).First(&article, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("article not found")
+			return nil, ErrArticleNotFound
 		}
 		return nil, fmt.Errorf("failed to get article: %w", err)
 	}
 	return &article, nil
 }
 
-// GetAllTags 获取所有不重复的 tag
+// GetAllTags 获取工作区内文章使用的所有不重复的 tag
 func (r *Repository) GetAllTags(ctx context.Context) ([]string, error) {
 	var tags []string
 
@@ -325,11 +595,11 @@ func (r *Repository) GetAllTags(ctx context.Context) ([]string, error) {
 	query := `
 		SELECT DISTINCT unnest(tags) AS tag
 		FROM articles
-		WHERE tags IS NOT NULL AND array_length(tags, 1) > 0
+		WHERE workspace_id = ? AND tags IS NOT NULL AND array_length(tags, 1) > 0
 		ORDER BY tag
 	`
 
-	err := r.db.WithContext(ctx).Raw(query).Scan(&tags).Error
+	err := r.db.WithContext(ctx).Raw(query, WorkspaceFrom(ctx)).Scan(&tags).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all tags: %w", err)
 	}
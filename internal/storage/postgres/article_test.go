@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDedupeDecide(t *testing.T) {
+	defaults := DefaultDedupeThresholds()
+	tests := []struct {
+		name       string
+		thresholds DedupeThresholds
+		found      bool
+		similarity float64
+		want       QuestionInsertStatus
+	}{
+		{"empty library", defaults, false, 0, QuestionInsertStatusSuccess},
+		{"above merge threshold", defaults, true, 0.97, QuestionInsertStatusMerged},
+		{"exactly merge threshold is gray zone", defaults, true, 0.95, QuestionInsertStatusFlagged},
+		{"gray zone", defaults, true, 0.9, QuestionInsertStatusFlagged},
+		{"exactly review threshold is new", defaults, true, 0.85, QuestionInsertStatusSuccess},
+		{"far away", defaults, true, 0.3, QuestionInsertStatusSuccess},
+		{"gray zone disabled", DedupeThresholds{Merge: 0.95}, true, 0.9, QuestionInsertStatusSuccess},
+		{"disabled still merges", DedupeThresholds{Merge: 0.95}, true, 0.99, QuestionInsertStatusMerged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.thresholds.decide(tt.found, tt.similarity); got != tt.want {
+				t.Errorf("decide(%v, %v) = %s, want %s", tt.found, tt.similarity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorAfter(t *testing.T) {
+	created := time.Date(2025, 10, 1, 9, 0, 0, 123456000, time.FixedZone("CST", 8*3600))
+	updated := created.Add(time.Hour)
+	article := ArticleWithOccurrences{
+		Article:         Article{ID: 42, CreatedAt: created, UpdatedAt: updated},
+		OccurrenceCount: 5,
+	}
+
+	tests := []struct {
+		sort, order string
+		want        ArticleCursor
+	}{
+		{"", "", ArticleCursor{Sort: "id", Order: "desc", ID: 42}},
+		{"latest", "asc", ArticleCursor{Sort: "id", Order: "asc", ID: 42}},
+		{"id", "bogus", ArticleCursor{Sort: "id", Order: "desc", ID: 42}},
+		{"created_at", "asc", ArticleCursor{Sort: "created_at", Order: "asc", Value: "2025-10-01T01:00:00.123456Z", ID: 42}},
+		{"updated_at", "desc", ArticleCursor{Sort: "updated_at", Order: "desc", Value: "2025-10-01T02:00:00.123456Z", ID: 42}},
+		{"occurrences", "desc", ArticleCursor{Sort: "occurrences", Order: "desc", Value: "5", ID: 42}},
+	}
+	for _, tt := range tests {
+		if got := CursorAfter(article, tt.sort, tt.order); got != tt.want {
+			t.Errorf("CursorAfter(sort=%q, order=%q) = %+v, want %+v", tt.sort, tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestAfterCursor(t *testing.T) {
+	created := time.Date(2025, 10, 1, 1, 0, 0, 123456000, time.UTC)
+
+	tests := []struct {
+		name        string
+		cursor      ArticleCursor
+		sort, order string
+		wantSQL     string
+		wantVars    []any
+	}{
+		{
+			name:     "id desc",
+			cursor:   ArticleCursor{Sort: "id", Order: "desc", ID: 42},
+			sort:     "id",
+			order:    "desc",
+			wantSQL:  "articles.id < ?",
+			wantVars: []any{uint(42)},
+		},
+		{
+			name:     "id asc",
+			cursor:   ArticleCursor{Sort: "id", Order: "asc", ID: 42},
+			sort:     "id",
+			order:    "asc",
+			wantSQL:  "articles.id > ?",
+			wantVars: []any{uint(42)},
+		},
+		{
+			name:     "created_at asc",
+			cursor:   CursorAfter(ArticleWithOccurrences{Article: Article{ID: 7, CreatedAt: created}}, "created_at", "asc"),
+			sort:     "created_at",
+			order:    "asc",
+			wantSQL:  "(articles.created_at, articles.id) > (?, ?)",
+			wantVars: []any{created, uint(7)},
+		},
+		{
+			name:     "occurrences desc",
+			cursor:   ArticleCursor{Sort: "occurrences", Order: "desc", Value: "5", ID: 7},
+			sort:     "occurrences",
+			order:    "desc",
+			wantSQL:  "(COALESCE(oc.occurrence_count, 0), articles.id) < (?, ?)",
+			wantVars: []any{int64(5), uint(7)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := afterCursor(&tt.cursor, tt.sort, tt.order)
+			if err != nil {
+				t.Fatalf("afterCursor: %v", err)
+			}
+			if expr.SQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", expr.SQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(expr.Vars, tt.wantVars) {
+				t.Errorf("Vars = %#v, want %#v", expr.Vars, tt.wantVars)
+			}
+		})
+	}
+}
+
+func TestAfterCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		cursor      ArticleCursor
+		sort, order string
+	}{
+		{"different sort", ArticleCursor{Sort: "id", Order: "desc", ID: 1}, "created_at", "desc"},
+		{"different order", ArticleCursor{Sort: "id", Order: "asc", ID: 1}, "id", "desc"},
+		{"malformed time", ArticleCursor{Sort: "created_at", Order: "desc", Value: "yesterday", ID: 1}, "created_at", "desc"},
+		{"malformed count", ArticleCursor{Sort: "occurrences", Order: "desc", Value: "many", ID: 1}, "occurrences", "desc"},
+		{"unknown sort", ArticleCursor{Sort: "title", Order: "desc", ID: 1}, "title", "desc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := afterCursor(&tt.cursor, tt.sort, tt.order); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("afterCursor error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrAttemptNotFound 表示作答记录不存在
+var ErrAttemptNotFound = notFoundError("answer_attempt_not_found", "answer attempt not found")
+
+// CreateAnswerAttempt 保存一次作答及评分，作答属于 context 中的工作区
+func (r *Repository) CreateAnswerAttempt(ctx context.Context, attempt *AnswerAttempt) error {
+	workspaceID, err := requireWorkspace(ctx)
+	if err != nil {
+		return err
+	}
+	attempt.WorkspaceID = workspaceID
+	if err := r.db.WithContext(ctx).Create(attempt).Error; err != nil {
+		return fmt.Errorf("failed to create answer attempt: %w", err)
+	}
+	return nil
+}
+
+// GetAnswerAttempt 根据 ID 获取作答记录
+func (r *Repository) GetAnswerAttempt(ctx context.Context, id uint) (*AnswerAttempt, error) {
+	var attempt AnswerAttempt
+	err := r.db.WithContext(ctx).Scopes(inWorkspace("workspace_id")).First(&attempt, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrAttemptNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get answer attempt: %w", err)
+	}
+	return &attempt, nil
+}
+
+// AttemptFilter 限定作答记录列表，零值表示全部
+type AttemptFilter struct {
+	ArticleID uint
+	UserName  string
+	MaxScore  *int // 只返回分数不高于该值的记录，用于复习薄弱题
+}
+
+// ListAnswerAttempts 按时间倒序返回作答记录
+func (r *Repository) ListAnswerAttempts(ctx context.Context, filter AttemptFilter, limit, offset int) ([]AnswerAttempt, int64, error) {
+	var attempts []AnswerAttempt
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&AnswerAttempt{}).Scopes(inWorkspace("workspace_id"))
+	if filter.ArticleID != 0 {
+		query = query.Where("article_id = ?", filter.ArticleID)
+	}
+	if filter.UserName != "" {
+		query = query.Where("user_name = ?", filter.UserName)
+	}
+	if filter.MaxScore != nil {
+		query = query.Where("score <= ?", *filter.MaxScore)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count answer attempts: %w", err)
+	}
+
+	err := query.Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&attempts).Error
+	if err != nil {
+		return nil, total, fmt.Errorf("failed to list answer attempts: %w", err)
+	}
+
+	return attempts, total, nil
+}
@@ -0,0 +1,268 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// ===================================================================
+// 主题聚类
+// ===================================================================
+
+// ErrClusterNotFound 表示簇不存在，或还没有完成过聚类
+var ErrClusterNotFound = notFoundError("cluster_not_found", "cluster not found")
+
+// ArticleEmbedding 是聚类所需的文章字段
+type ArticleEmbedding struct {
+	ID               uint
+	OriginalQuestion string
+	DetailedQuestion *string
+	Tags             pq.StringArray
+	Vector           []float32
+}
+
+// LoadArticleEmbeddings 读取工作区内所有文章的向量及用于命名的文本
+func (r *Repository) LoadArticleEmbeddings(ctx context.Context) ([]ArticleEmbedding, error) {
+	var articles []Article
+	err := r.db.WithContext(ctx).
+		Scopes(inWorkspace("workspace_id")).
+		Select("id", "original_question", "detailed_question", "tags", "embedding").
+		Order("id ASC").
+		Find(&articles).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load article embeddings: %w", err)
+	}
+
+	result := make([]ArticleEmbedding, 0, len(articles))
+	for _, a := range articles {
+		vector := a.Embedding.Slice()
+		if len(vector) == 0 {
+			continue
+		}
+		result = append(result, ArticleEmbedding{
+			ID:               a.ID,
+			OriginalQuestion: a.OriginalQuestion,
+			DetailedQuestion: a.DetailedQuestion,
+			Tags:             a.Tags,
+			Vector:           vector,
+		})
+	}
+	return result, nil
+}
+
+// ClusterAssignment 是一篇文章的聚类结果，Label 对应 Cluster.Label
+type ClusterAssignment struct {
+	ArticleID  uint
+	Label      int
+	Similarity float64
+}
+
+// SaveClusterRun 在一个事务中写入一次聚类运行的结果，运行属于 context 中的工作区
+// clusters 的 RunID 和 ID 会被填充
+func (r *Repository) SaveClusterRun(ctx context.Context, run *ClusterRun, clusters []Cluster, assignments []ClusterAssignment) error {
+	workspaceID, err := requireWorkspace(ctx)
+	if err != nil {
+		return err
+	}
+	run.WorkspaceID = workspaceID
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(run).Error; err != nil {
+			return fmt.Errorf("failed to create cluster run: %w", err)
+		}
+
+		for i := range clusters {
+			clusters[i].RunID = run.ID
+		}
+		if len(clusters) > 0 {
+			if err := tx.Create(&clusters).Error; err != nil {
+				return fmt.Errorf("failed to create clusters: %w", err)
+			}
+		}
+
+		clusterIDs := make(map[int]uint, len(clusters))
+		for _, c := range clusters {
+			clusterIDs[c.Label] = c.ID
+		}
+		rows := make([]ArticleCluster, 0, len(assignments))
+		for _, a := range assignments {
+			clusterID, ok := clusterIDs[a.Label]
+			if !ok {
+				return fmt.Errorf("assignment of article %d refers to unknown cluster label %d", a.ArticleID, a.Label)
+			}
+			rows = append(rows, ArticleCluster{
+				RunID:      run.ID,
+				ArticleID:  a.ArticleID,
+				ClusterID:  clusterID,
+				Similarity: a.Similarity,
+			})
+		}
+		if len(rows) > 0 {
+			if err := tx.CreateInBatches(rows, 1000).Error; err != nil {
+				return fmt.Errorf("failed to create article clusters: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// PruneClusterRuns 只保留工作区最近 keep 次聚类运行，删除更早的运行及其簇和分配
+func (r *Repository) PruneClusterRuns(ctx context.Context, keep int) (int64, error) {
+	if keep < 1 {
+		keep = 1
+	}
+	var pruned int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var stale []uint
+		err := tx.Model(&ClusterRun{}).
+			Scopes(inWorkspace("workspace_id")).
+			Order("id DESC").
+			Offset(keep).
+			Pluck("id", &stale).Error
+		if err != nil {
+			return fmt.Errorf("failed to find stale cluster runs: %w", err)
+		}
+		if len(stale) == 0 {
+			return nil
+		}
+		if err := tx.Where("run_id IN ?", stale).Delete(&ArticleCluster{}).Error; err != nil {
+			return fmt.Errorf("failed to delete article clusters: %w", err)
+		}
+		if err := tx.Where("run_id IN ?", stale).Delete(&Cluster{}).Error; err != nil {
+			return fmt.Errorf("failed to delete clusters: %w", err)
+		}
+		if err := tx.Delete(&ClusterRun{}, stale).Error; err != nil {
+			return fmt.Errorf("failed to delete cluster runs: %w", err)
+		}
+		pruned = int64(len(stale))
+		return nil
+	})
+	return pruned, err
+}
+
+// LatestClusterRun 返回工作区最近一次聚类运行，没有时返回 ErrClusterNotFound
+func (r *Repository) LatestClusterRun(ctx context.Context) (*ClusterRun, error) {
+	var run ClusterRun
+	err := r.db.WithContext(ctx).Scopes(inWorkspace("workspace_id")).Order("id DESC").First(&run).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: no cluster run yet", ErrClusterNotFound)
+		}
+		return nil, fmt.Errorf("failed to get latest cluster run: %w", err)
+	}
+	return &run, nil
+}
+
+// 簇列表排序方式
+const (
+	ClusterSortSize   = "size"   // 按当前文章数降序 (默认)
+	ClusterSortGrowth = "growth" // 按最近窗口的出现次数增量降序
+)
+
+// ClusterSummary 是簇及其当前规模和增长情况
+type ClusterSummary struct {
+	Cluster
+	ArticleCount int64 // 仍存在的成员文章数 (被合并删除的文章不计入)
+	Recent       int64 // 最近窗口内成员文章的出现次数
+	Previous     int64 // 上一个等长窗口内的出现次数
+}
+
+// Growth 返回最近窗口相对上一窗口的出现次数增量
+func (s ClusterSummary) Growth() int64 {
+	return s.Recent - s.Previous
+}
+
+// clusterSummaryQuery 构造簇摘要查询：当前成员数，以及最近两个等长窗口内成员文章的出现次数
+func (r *Repository) clusterSummaryQuery(ctx context.Context, window time.Duration) *gorm.DB {
+	now := time.Now()
+	recentStart := now.Add(-window)
+	previousStart := now.Add(-2 * window)
+
+	return r.db.WithContext(ctx).
+		Table("clusters c").
+		Select(`c.id, c.run_id, c.label, c.name, c.summary, c.top_tags, c.size, c.created_at,
+			m.article_count, g.recent, g.previous`).
+		Joins("JOIN cluster_runs cr ON cr.id = c.run_id").
+		Scopes(inWorkspace("cr.workspace_id")).
+		Joins(`LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS article_count
+			FROM article_clusters ac
+			JOIN articles a ON a.id = ac.article_id
+			WHERE ac.cluster_id = c.id
+		) m ON true`).
+		Joins(`LEFT JOIN LATERAL (
+			SELECT COUNT(*) FILTER (WHERE o.seen_at >= ?) AS recent,
+			       COUNT(*) FILTER (WHERE o.seen_at < ?) AS previous
+			FROM article_clusters ac
+			JOIN article_occurrences o ON o.article_id = ac.article_id
+			WHERE ac.cluster_id = c.id AND o.seen_at >= ?
+		) g ON true`, recentStart, recentStart, previousStart)
+}
+
+// ListClusters 返回某次运行的所有簇，window 为统计增长的时间窗口
+func (r *Repository) ListClusters(ctx context.Context, runID uint, window time.Duration, sortBy string) ([]ClusterSummary, error) {
+	query := r.clusterSummaryQuery(ctx, window).Where("c.run_id = ?", runID)
+
+	switch sortBy {
+	case ClusterSortGrowth:
+		query = query.Order("g.recent - g.previous DESC").Order("g.recent DESC")
+	default:
+		query = query.Order("m.article_count DESC")
+	}
+
+	summaries := make([]ClusterSummary, 0)
+	if err := query.Order("c.label ASC").Scan(&summaries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+	return summaries, nil
+}
+
+// GetClusterSummary 根据 ID 获取簇及其增长情况
+func (r *Repository) GetClusterSummary(ctx context.Context, id uint, window time.Duration) (*ClusterSummary, error) {
+	var summaries []ClusterSummary
+	err := r.clusterSummaryQuery(ctx, window).
+		Where("c.id = ?", id).
+		Scan(&summaries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	if len(summaries) == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrClusterNotFound, id)
+	}
+	return &summaries[0], nil
+}
+
+// ClusterMember 是簇中的文章及其与簇中心的相似度
+type ClusterMember struct {
+	Article
+	Similarity float64 `gorm:"column:similarity"`
+}
+
+// ListClusterArticles 按与簇中心的相似度降序列出簇中的文章
+func (r *Repository) ListClusterArticles(ctx context.Context, clusterID uint, limit, offset int) ([]ClusterMember, int64, error) {
+	var members []ClusterMember
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&Article{}).
+		Joins("JOIN article_clusters ac ON ac.article_id = articles.id").
+		Scopes(inWorkspace("articles.workspace_id")).
+		Where("ac.cluster_id = ?", clusterID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count cluster articles: %w", err)
+	}
+
+	err := query.Select("articles.id, articles.original_question, articles.detailed_question, articles.concise_answer, articles.tags, articles.created_at, ac.similarity").
+		Order("ac.similarity DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&members).Error
+	if err != nil {
+		return nil, total, fmt.Errorf("failed to list cluster articles: %w", err)
+	}
+	return members, total, nil
+}
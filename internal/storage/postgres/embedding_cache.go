@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"paguu/internal/embedding"
+
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm/clause"
+)
+
+// GetCachedEmbeddings 按 key 批量查询缓存的向量，实现 embedding.VectorStore
+func (r *Repository) GetCachedEmbeddings(ctx context.Context, keys []string) (map[string][]float32, error) {
+	result := make(map[string][]float32)
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	var entries []EmbeddingCache
+	err := r.db.WithContext(ctx).Where("key IN ?", keys).Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query embedding cache: %w", err)
+	}
+
+	for _, entry := range entries {
+		result[entry.Key] = entry.Embedding.Slice()
+	}
+	return result, nil
+}
+
+// PutCachedEmbeddings 写入缓存的向量，已存在的 key 保持不变，实现 embedding.VectorStore
+func (r *Repository) PutCachedEmbeddings(ctx context.Context, entries []embedding.CachedEmbedding) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	records := make([]EmbeddingCache, len(entries))
+	for i, entry := range entries {
+		records[i] = EmbeddingCache{
+			Key:       entry.Key,
+			Model:     entry.Model,
+			Dimension: entry.Dimension,
+			Embedding: pgvector.NewVector(entry.Vector),
+		}
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&records).Error
+	if err != nil {
+		return fmt.Errorf("failed to store embedding cache: %w", err)
+	}
+	return nil
+}
+
+// CountCachedEmbeddings 返回持久化缓存中的向量条数
+func (r *Repository) CountCachedEmbeddings(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&EmbeddingCache{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count embedding cache: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"paguu/internal/enrich"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetCachedEnrichments 批量查询丰富化缓存，并累加命中次数，实现 enrich.Cache
+func (r *Repository) GetCachedEnrichments(ctx context.Context, keys []string, maxAge time.Duration) (map[string]enrich.InterviewQuestion, error) {
+	result := make(map[string]enrich.InterviewQuestion)
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	query := r.db.WithContext(ctx).Where("key IN ?", keys)
+	if maxAge > 0 {
+		query = query.Where("created_at > ?", time.Now().Add(-maxAge))
+	}
+
+	var entries []EnrichmentCache
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to query enrichment cache: %w", err)
+	}
+	if len(entries) == 0 {
+		return result, nil
+	}
+
+	hitKeys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		var q enrich.InterviewQuestion
+		if err := json.Unmarshal(entry.Result, &q); err != nil {
+			slog.Warn("skip corrupted enrichment cache entry", "key", entry.Key, "error", err)
+			continue
+		}
+		result[entry.Key] = q
+		hitKeys = append(hitKeys, entry.Key)
+	}
+
+	if len(hitKeys) > 0 {
+		err := r.db.WithContext(ctx).Model(&EnrichmentCache{}).
+			Where("key IN ?", hitKeys).
+			Updates(map[string]interface{}{
+				"hits":        gorm.Expr("hits + 1"),
+				"last_hit_at": time.Now(),
+			}).Error
+		if err != nil {
+			// 命中统计失败不影响缓存结果
+			slog.Warn("failed to update enrichment cache hits", "error", err)
+		}
+	}
+
+	return result, nil
+}
+
+// PutCachedEnrichments 写入或覆盖丰富化缓存，实现 enrich.Cache
+func (r *Repository) PutCachedEnrichments(ctx context.Context, entries []enrich.CacheEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	records := make([]EnrichmentCache, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		// 同一批中重复的行只写一次，避免 ON CONFLICT 作用于同一行两次
+		if seen[entry.Key] {
+			continue
+		}
+		seen[entry.Key] = true
+
+		data, err := json.Marshal(entry.Question)
+		if err != nil {
+			return fmt.Errorf("failed to marshal cached question: %w", err)
+		}
+		records = append(records, EnrichmentCache{
+			Key:                entry.Key,
+			NormalizedQuestion: entry.NormalizedQuestion,
+			Model:              entry.Model,
+			TemplateVersion:    entry.TemplateVersion,
+			Result:             datatypes.JSON(data),
+		})
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"result":     gorm.Expr("EXCLUDED.result"),
+			"created_at": gorm.Expr("EXCLUDED.created_at"),
+		}),
+	}).Create(&records).Error
+	if err != nil {
+		return fmt.Errorf("failed to store enrichment cache: %w", err)
+	}
+	return nil
+}
+
+// EnrichmentCacheFilter 指定要失效的缓存范围，所有字段为空时清空全部缓存
+type EnrichmentCacheFilter struct {
+	Question        string // 原始问题文本，会先归一化
+	Model           string
+	TemplateVersion string
+}
+
+// InvalidateEnrichmentCache 删除匹配的缓存条目，返回删除的条数
+func (r *Repository) InvalidateEnrichmentCache(ctx context.Context, filter EnrichmentCacheFilter) (int64, error) {
+	query := r.db.WithContext(ctx).Session(&gorm.Session{AllowGlobalUpdate: true})
+	if filter.Question != "" {
+		query = query.Where("normalized_question = ?", enrich.NormalizeQuestion(filter.Question))
+	}
+	if filter.Model != "" {
+		query = query.Where("model = ?", filter.Model)
+	}
+	if filter.TemplateVersion != "" {
+		query = query.Where("template_version = ?", filter.TemplateVersion)
+	}
+
+	result := query.Delete(&EnrichmentCache{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to invalidate enrichment cache: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// EnrichmentCacheStats 是按 模型/模板版本 汇总的缓存统计
+type EnrichmentCacheStats struct {
+	Model           string `json:"model"`
+	TemplateVersion string `json:"template_version"`
+	Entries         int64  `json:"entries"`
+	Hits            int64  `json:"hits"`
+}
+
+// GetEnrichmentCacheStats 汇总缓存条目数和命中次数
+func (r *Repository) GetEnrichmentCacheStats(ctx context.Context) ([]EnrichmentCacheStats, error) {
+	var stats []EnrichmentCacheStats
+	err := r.db.WithContext(ctx).Model(&EnrichmentCache{}).
+		Select("model, template_version, COUNT(*) AS entries, COALESCE(SUM(hits), 0) AS hits").
+		Group("model, template_version").
+		Order("model, template_version").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enrichment cache stats: %w", err)
+	}
+	return stats, nil
+}
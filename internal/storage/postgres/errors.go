@@ -0,0 +1,39 @@
+package postgres
+
+import "errors"
+
+// 领域错误的类别，调用方用 errors.Is(err, ErrNotFound) 判断类别，不需要逐个列举具体的错误
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("conflict")
+	ErrInvalid  = errors.New("invalid")
+)
+
+// Error 是仓储层返回的领域错误，带有类别和稳定的错误码
+// 具体的错误 (如 ErrArticleNotFound) 都是 *Error，可以用 fmt.Errorf("%w: ...") 附加说明
+type Error struct {
+	Kind    error  // ErrNotFound/ErrConflict/ErrInvalid
+	Code    string // 机器可读的错误码，如 article_not_found
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is 使 errors.Is(err, ErrNotFound) 等类别判断成立
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func notFoundError(code, message string) error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+func conflictError(code, message string) error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message}
+}
+
+func invalidError(code, message string) error {
+	return &Error{Kind: ErrInvalid, Code: code, Message: message}
+}
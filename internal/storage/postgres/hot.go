@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// HotQuestionsOptions 是热门问题查询参数
+type HotQuestionsOptions struct {
+	Occurrence OccurrenceFilter // 统计窗口和来源，通常设置 Since
+	Tags       []string         // 只返回这些 tag，为空时返回出现次数最多的 TagLimit 个 tag
+	TagLimit   int              // Tags 为空时返回的 tag 数量
+	PerTag     int              // 每个 tag 返回的问题数量
+}
+
+// HotArticle 是某个 tag 下的一道热门问题
+type HotArticle struct {
+	Article
+	OccurrenceCount int64
+	LastSeen        time.Time
+}
+
+// HotTag 是一个 tag 及其热门问题，按出现次数降序
+type HotTag struct {
+	Tag             string
+	OccurrenceCount int64 // 该 tag 下所有问题在窗口内的出现次数之和
+	Articles        []HotArticle
+}
+
+// GetHotQuestions 按 tag 分组返回工作区内窗口期出现次数最多的问题
+// 同一篇文章有多个 tag 时会出现在每个 tag 下
+func (r *Repository) GetHotQuestions(ctx context.Context, opts HotQuestionsOptions) ([]HotTag, error) {
+	counts := opts.Occurrence.apply(r.db.WithContext(ctx).Model(&ArticleOccurrence{})).
+		Scopes(inWorkspace("article_occurrences.workspace_id")).
+		Select("article_id, COUNT(*) AS occurrence_count, MAX(seen_at) AS last_seen").
+		Group("article_id")
+
+	ranked := r.db.Table("(?) AS c", counts).
+		Select(`t.tag, a.id AS article_id, c.occurrence_count, c.last_seen,
+			SUM(c.occurrence_count) OVER (PARTITION BY t.tag) AS tag_total,
+			ROW_NUMBER() OVER (PARTITION BY t.tag ORDER BY c.occurrence_count DESC, c.last_seen DESC, a.id DESC) AS rn`).
+		Joins("JOIN articles a ON a.id = c.article_id").
+		Joins("CROSS JOIN LATERAL unnest(a.tags) AS t(tag)")
+	if len(opts.Tags) > 0 {
+		ranked = ranked.Where("t.tag = ANY(?)", pq.Array(opts.Tags))
+	}
+
+	var rows []hotRow
+	err := r.db.WithContext(ctx).Table("(?) AS ranked", ranked).
+		Select("tag, article_id, occurrence_count, last_seen, tag_total").
+		Where("rn <= ?", opts.PerTag).
+		Order("tag_total DESC, tag ASC, rn ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank hot questions: %w", err)
+	}
+
+	hotTags, articleIDs := groupHotTags(rows, opts)
+	if len(hotTags) == 0 {
+		return []HotTag{}, nil
+	}
+
+	// 补全文章内容
+	var articles []Article
+	err = r.db.WithContext(ctx).
+		Omit("embedding").
+		Where("id IN ?", articleIDs).
+		Find(&articles).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load hot articles: %w", err)
+	}
+	byID := make(map[uint]Article, len(articles))
+	for _, a := range articles {
+		byID[a.ID] = a
+	}
+	for i := range hotTags {
+		for j := range hotTags[i].Articles {
+			hotTags[i].Articles[j].Article = byID[hotTags[i].Articles[j].ID]
+		}
+	}
+	return hotTags, nil
+}
+
+// hotRow 是排名查询返回的一行：某个 tag 下的一篇文章
+type hotRow struct {
+	Tag             string
+	ArticleID       uint
+	OccurrenceCount int64
+	LastSeen        time.Time
+	TagTotal        int64
+}
+
+// groupHotTags 将已按 tag 总数和名次排好序的行按 tag 分组，未指定 tags 时最多保留 TagLimit 个 tag
+// 同时返回需要补全内容的文章 ID
+func groupHotTags(rows []hotRow, opts HotQuestionsOptions) ([]HotTag, []uint) {
+	var hotTags []HotTag
+	articleIDs := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		if len(hotTags) == 0 || hotTags[len(hotTags)-1].Tag != row.Tag {
+			if len(opts.Tags) == 0 && opts.TagLimit > 0 && len(hotTags) >= opts.TagLimit {
+				break
+			}
+			hotTags = append(hotTags, HotTag{Tag: row.Tag, OccurrenceCount: row.TagTotal})
+		}
+		last := &hotTags[len(hotTags)-1]
+		last.Articles = append(last.Articles, HotArticle{
+			Article:         Article{ID: row.ArticleID},
+			OccurrenceCount: row.OccurrenceCount,
+			LastSeen:        row.LastSeen,
+		})
+		articleIDs = append(articleIDs, row.ArticleID)
+	}
+	return hotTags, articleIDs
+}
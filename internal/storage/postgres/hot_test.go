@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestGroupHotTags(t *testing.T) {
+	rows := []hotRow{
+		{Tag: "go", ArticleID: 1, OccurrenceCount: 5, TagTotal: 8},
+		{Tag: "go", ArticleID: 2, OccurrenceCount: 3, TagTotal: 8},
+		{Tag: "redis", ArticleID: 1, OccurrenceCount: 5, TagTotal: 6},
+		{Tag: "redis", ArticleID: 3, OccurrenceCount: 1, TagTotal: 6},
+		{Tag: "mysql", ArticleID: 4, OccurrenceCount: 2, TagTotal: 2},
+	}
+
+	tests := []struct {
+		name      string
+		opts      HotQuestionsOptions
+		wantTags  []string
+		wantTotal []int64
+		wantIDs   []uint
+	}{
+		{"all tags", HotQuestionsOptions{}, []string{"go", "redis", "mysql"}, []int64{8, 6, 2}, []uint{1, 2, 1, 3, 4}},
+		{"tag limit", HotQuestionsOptions{TagLimit: 2}, []string{"go", "redis"}, []int64{8, 6}, []uint{1, 2, 1, 3}},
+		{"explicit tags ignore limit", HotQuestionsOptions{Tags: []string{"go", "redis", "mysql"}, TagLimit: 1}, []string{"go", "redis", "mysql"}, []int64{8, 6, 2}, []uint{1, 2, 1, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hotTags, ids := groupHotTags(rows, tt.opts)
+			var tags []string
+			var totals []int64
+			for _, h := range hotTags {
+				tags = append(tags, h.Tag)
+				totals = append(totals, h.OccurrenceCount)
+			}
+			if !reflect.DeepEqual(tags, tt.wantTags) || !reflect.DeepEqual(totals, tt.wantTotal) {
+				t.Errorf("tags = %v %v, want %v %v", tags, totals, tt.wantTags, tt.wantTotal)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("article ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+
+	if hotTags, _ := groupHotTags(rows, HotQuestionsOptions{}); hotTags[0].Articles[0].OccurrenceCount != 5 || hotTags[0].Articles[1].ID != 2 {
+		t.Errorf("articles in first tag = %+v", hotTags[0].Articles)
+	}
+	if hotTags, ids := groupHotTags(nil, HotQuestionsOptions{}); hotTags != nil || len(ids) != 0 {
+		t.Errorf("empty rows = %v, %v", hotTags, ids)
+	}
+}
+
+// dryRunDB 返回不连接数据库、只生成 SQL 的 gorm 实例
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestOccurrenceFilter(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name     string
+		filter   OccurrenceFilter
+		wantZero bool
+		wantSQL  []string
+	}{
+		{"zero", OccurrenceFilter{}, true, nil},
+		{"window", OccurrenceFilter{Since: &since, Until: &until}, false, []string{"article_occurrences.seen_at >= $1", "article_occurrences.seen_at < $2"}},
+		{"source", OccurrenceFilter{Source: "nowcoder"}, false, []string{"article_occurrences.source = $1"}},
+		{"company", OccurrenceFilter{Company: "acme"}, false, []string{"article_occurrences.metadata->>'company' = $1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.IsZero(); got != tt.wantZero {
+				t.Errorf("IsZero = %v, want %v", got, tt.wantZero)
+			}
+			var rows []ArticleOccurrence
+			stmt := tt.filter.apply(dryRunDB(t).Model(&ArticleOccurrence{})).Find(&rows).Statement
+			sql := stmt.SQL.String()
+			for _, want := range tt.wantSQL {
+				if !strings.Contains(sql, want) {
+					t.Errorf("SQL %q does not contain %q", sql, want)
+				}
+			}
+			if tt.wantZero && strings.Contains(sql, "WHERE") {
+				t.Errorf("zero filter added conditions: %q", sql)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInterviewNotFound 表示模拟面试不存在
+	ErrInterviewNotFound = notFoundError("interview_not_found", "interview session not found")
+	// ErrInterviewItemNotFound 表示模拟面试中没有该序号的题目
+	ErrInterviewItemNotFound = notFoundError("interview_item_not_found", "interview item not found")
+)
+
+// CreateInterviewSession 在一个事务中保存面试及其题目，题目的 SessionID 由此处填充
+// 面试属于 context 中的工作区
+func (r *Repository) CreateInterviewSession(ctx context.Context, session *InterviewSession, items []InterviewItem) error {
+	workspaceID, err := requireWorkspace(ctx)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		session.WorkspaceID = workspaceID
+		session.Length = len(items)
+		if err := tx.Create(session).Error; err != nil {
+			return fmt.Errorf("failed to create interview session: %w", err)
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		for i := range items {
+			items[i].SessionID = session.ID
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			return fmt.Errorf("failed to create interview items: %w", err)
+		}
+		return nil
+	})
+}
+
+// InterviewItemDetail 是附带文章内容的面试题目
+type InterviewItemDetail struct {
+	InterviewItem
+	Article *Article // 文章被删除时为 nil
+}
+
+// InterviewSessionDetail 是按序号排列题目的完整面试
+type InterviewSessionDetail struct {
+	Session InterviewSession
+	Items   []InterviewItemDetail
+}
+
+// GetInterviewSession 获取面试及其全部题目和文章
+func (r *Repository) GetInterviewSession(ctx context.Context, id uint) (*InterviewSessionDetail, error) {
+	var session InterviewSession
+	err := r.db.WithContext(ctx).Scopes(inWorkspace("workspace_id")).First(&session, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrInterviewNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get interview session: %w", err)
+	}
+
+	var items []InterviewItem
+	err = r.db.WithContext(ctx).Where("session_id = ?", id).Order("position").Find(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load interview items: %w", err)
+	}
+
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ArticleID
+	}
+	var articles []Article
+	if len(ids) > 0 {
+		if err := r.db.WithContext(ctx).Omit("embedding").Where("id IN ?", ids).Find(&articles).Error; err != nil {
+			return nil, fmt.Errorf("failed to load interview articles: %w", err)
+		}
+	}
+	byID := make(map[uint]*Article, len(articles))
+	for i := range articles {
+		byID[articles[i].ID] = &articles[i]
+	}
+
+	detail := &InterviewSessionDetail{
+		Session: session,
+		Items:   make([]InterviewItemDetail, len(items)),
+	}
+	for i, item := range items {
+		detail.Items[i] = InterviewItemDetail{InterviewItem: item, Article: byID[item.ArticleID]}
+	}
+	return detail, nil
+}
+
+// InterviewSessionSummary 是附带作答进度的面试
+type InterviewSessionSummary struct {
+	InterviewSession
+	AnsweredCount int64 `gorm:"column:answered_count"`
+}
+
+// ListInterviewSessions 按时间倒序列出面试，userName 为空时列出全部
+func (r *Repository) ListInterviewSessions(ctx context.Context, userName string, limit, offset int) ([]InterviewSessionSummary, int64, error) {
+	var sessions []InterviewSessionSummary
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&InterviewSession{}).Scopes(inWorkspace("interview_sessions.workspace_id"))
+	if userName != "" {
+		query = query.Where("interview_sessions.user_name = ?", userName)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count interview sessions: %w", err)
+	}
+
+	answered := r.db.Model(&InterviewItem{}).
+		Select("session_id, COUNT(*) AS answered_count").
+		Where("answered_at IS NOT NULL").
+		Group("session_id")
+
+	err := query.Joins("LEFT JOIN (?) AS ans ON ans.session_id = interview_sessions.id", answered).
+		Select("interview_sessions.*, COALESCE(ans.answered_count, 0) AS answered_count").
+		Order("interview_sessions.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&sessions).Error
+	if err != nil {
+		return nil, total, fmt.Errorf("failed to list interview sessions: %w", err)
+	}
+
+	return sessions, total, nil
+}
+
+// RecordInterviewAnswer 保存某道题的作答 (重复作答时覆盖)，所有题目都作答后面试标记为 completed
+// attemptID 为评分后生成的作答记录，未评分时为 nil
+func (r *Repository) RecordInterviewAnswer(ctx context.Context, sessionID uint, position int, answer string, attemptID *uint) (*InterviewSession, *InterviewItem, error) {
+	var session InterviewSession
+	var item InterviewItem
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁定面试，避免并发作答时重复判定完成
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(inWorkspace("workspace_id")).First(&session, sessionID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %d", ErrInterviewNotFound, sessionID)
+			}
+			return err
+		}
+
+		err = tx.Where("session_id = ? AND position = ?", sessionID, position).First(&item).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: position %d", ErrInterviewItemNotFound, position)
+			}
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&item).Updates(map[string]interface{}{
+			"answer":      answer,
+			"attempt_id":  attemptID,
+			"answered_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to save interview answer: %w", err)
+		}
+		item.Answer = &answer
+		item.AttemptID = attemptID
+		item.AnsweredAt = &now
+
+		if session.Status == InterviewStatusCompleted {
+			return nil
+		}
+		var unanswered int64
+		err = tx.Model(&InterviewItem{}).
+			Where("session_id = ? AND answered_at IS NULL", sessionID).
+			Count(&unanswered).Error
+		if err != nil {
+			return err
+		}
+		if unanswered == 0 {
+			if err := tx.Model(&session).Updates(map[string]interface{}{
+				"status":       InterviewStatusCompleted,
+				"completed_at": now,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to complete interview session: %w", err)
+			}
+			session.Status = InterviewStatusCompleted
+			session.CompletedAt = &now
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &session, &item, nil
+}
+
+// ReplayInterviewSession 以相同的题目和顺序创建一场新面试，作答清空
+// userName 为空时沿用原面试的作答人
+func (r *Repository) ReplayInterviewSession(ctx context.Context, id uint, userName string) (*InterviewSession, error) {
+	var replay InterviewSession
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var original InterviewSession
+		if err := tx.Scopes(inWorkspace("workspace_id")).First(&original, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %d", ErrInterviewNotFound, id)
+			}
+			return err
+		}
+
+		var items []InterviewItem
+		if err := tx.Where("session_id = ?", id).Order("position").Find(&items).Error; err != nil {
+			return fmt.Errorf("failed to load interview items: %w", err)
+		}
+
+		if userName == "" {
+			userName = original.UserName
+		}
+		replay = InterviewSession{
+			WorkspaceID: original.WorkspaceID,
+			UserName:    userName,
+			Tags:        original.Tags,
+			Level:       original.Level,
+			Length:      len(items),
+			Status:      InterviewStatusInProgress,
+			ReplayOf:    &original.ID,
+		}
+		if err := tx.Create(&replay).Error; err != nil {
+			return fmt.Errorf("failed to create interview session: %w", err)
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		copies := make([]InterviewItem, len(items))
+		for i, item := range items {
+			copies[i] = InterviewItem{
+				SessionID: replay.ID,
+				Position:  item.Position,
+				ArticleID: item.ArticleID,
+				Tag:       item.Tag,
+				Depth:     item.Depth,
+			}
+		}
+		if err := tx.Create(&copies).Error; err != nil {
+			return fmt.Errorf("failed to create interview items: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &replay, nil
+}
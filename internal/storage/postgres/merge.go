@@ -0,0 +1,521 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"paguu/internal/enrich"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrArticleNotFound 表示文章不存在
+	ErrArticleNotFound = notFoundError("article_not_found", "article not found")
+	// ErrInvalidMerge 表示合并/拆分参数不合法 (合并到自身、Ext 下标越界等)
+	ErrInvalidMerge = invalidError("invalid_merge", "invalid merge request")
+)
+
+// ExtEntry 是 Article.Ext 数组中的一项：被合并进来的重复问题
+// 自动合并时记录同时写入的出现记录 ID，拆分时据此找回；手动合并时记录来源文章 ID，拆分时据此恢复原 ID
+type ExtEntry struct {
+	enrich.InterviewQuestion
+	SourceArticleID *uint  `json:"source_article_id,omitempty"`
+	OccurrenceIDs   []uint `json:"occurrence_ids,omitempty"`
+}
+
+// ParseArticleExt 解析文章的 Ext 数组，NULL 视为空数组
+func ParseArticleExt(ext datatypes.JSON) ([]ExtEntry, error) {
+	if len(ext) == 0 {
+		return []ExtEntry{}, nil
+	}
+	var entries []ExtEntry
+	if err := json.Unmarshal(ext, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse article ext: %w", err)
+	}
+	if entries == nil {
+		entries = []ExtEntry{}
+	}
+	return entries, nil
+}
+
+// ResolveArticleID 跟随重定向记录，返回文章当前的 ID；没有重定向时原样返回
+func (r *Repository) ResolveArticleID(ctx context.Context, id uint) (uint, error) {
+	var redirect ArticleRedirect
+	err := r.db.WithContext(ctx).Where("from_id = ?", id).Limit(1).Find(&redirect).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve article redirect: %w", err)
+	}
+	if redirect.FromID == 0 {
+		return id, nil
+	}
+	return redirect.ToID, nil
+}
+
+// MergeArticles 将 sourceIDs 对应的文章手动合并进 targetID：
+// 来源文章 (及其 Ext 中已有的重复项) 追加到目标的 Ext，tags 取并集，来源文章被删除并留下重定向记录
+func (r *Repository) MergeArticles(ctx context.Context, targetID uint, sourceIDs []uint) (*Article, error) {
+	var target *Article
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		target, err = mergeArticles(tx, targetID, sourceIDs, "merge")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// mergeArticles 是 MergeArticles 的事务内实现，reason 记录到重定向中
+func mergeArticles(tx *gorm.DB, targetID uint, sourceIDs []uint, reason string) (*Article, error) {
+	if len(sourceIDs) == 0 {
+		return nil, fmt.Errorf("%w: no source articles", ErrInvalidMerge)
+	}
+	seen := make(map[uint]bool, len(sourceIDs))
+	for _, id := range sourceIDs {
+		if id == targetID {
+			return nil, fmt.Errorf("%w: cannot merge article %d into itself", ErrInvalidMerge, id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("%w: duplicate source article %d", ErrInvalidMerge, id)
+		}
+		seen[id] = true
+	}
+
+	// 锁定目标和来源文章，只能合并同一工作区内的文章
+	var target Article
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(inWorkspace("workspace_id")).First(&target, targetID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: target %d", ErrArticleNotFound, targetID)
+		}
+		return nil, err
+	}
+
+	var sources []Article
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Scopes(inWorkspace("workspace_id")).
+		Where("id IN ?", sourceIDs).
+		Order("id ASC").
+		Find(&sources).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) != len(sourceIDs) {
+		found := make(map[uint]bool, len(sources))
+		for _, s := range sources {
+			found[s.ID] = true
+		}
+		for _, id := range sourceIDs {
+			if !found[id] {
+				return nil, fmt.Errorf("%w: source %d", ErrArticleNotFound, id)
+			}
+		}
+	}
+
+	ext, err := ParseArticleExt(target.Ext)
+	if err != nil {
+		return nil, err
+	}
+	tags := append(pq.StringArray{}, target.Tags...)
+	tagSet := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		tagSet[t] = true
+	}
+
+	for _, source := range sources {
+		sourceID := source.ID
+		ext = append(ext, ExtEntry{
+			InterviewQuestion: articleToQuestion(source),
+			SourceArticleID:   &sourceID,
+		})
+
+		// 来源文章已合并的重复项一并转移
+		sourceExt, err := ParseArticleExt(source.Ext)
+		if err != nil {
+			return nil, err
+		}
+		ext = append(ext, sourceExt...)
+
+		for _, t := range source.Tags {
+			if !tagSet[t] {
+				tagSet[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+
+	extJSON, err := json.Marshal(ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal article ext: %w", err)
+	}
+	err = tx.Model(&target).Updates(map[string]interface{}{
+		"ext":  datatypes.JSON(extJSON),
+		"tags": tags,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update merge target: %w", err)
+	}
+	target.Ext = datatypes.JSON(extJSON)
+	target.Tags = tags
+
+	// 迁移之前记录每篇来源文章名下的行，拆分时原样恢复
+	moves := make([]MergeMove, len(sources))
+	cards := make([][]mergedReviewCard, len(sources))
+	for i, source := range sources {
+		if moves[i], cards[i], err = collectMergeMove(tx, source, targetID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Delete(&Article{}, sourceIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete merged articles: %w", err)
+	}
+
+	// 来源文章的出现记录、作答记录、复习卡片和面试题目归入目标
+	err = tx.Model(&ArticleOccurrence{}).Where("article_id IN ?", sourceIDs).Update("article_id", targetID).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to move article occurrences: %w", err)
+	}
+	err = tx.Model(&AnswerAttempt{}).Where("article_id IN ?", sourceIDs).Update("article_id", targetID).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to move answer attempts: %w", err)
+	}
+	copied, err := moveReviewCards(tx, sourceIDs, targetID)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Model(&InterviewItem{}).Where("article_id IN ?", sourceIDs).Update("article_id", targetID).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to move interview items: %w", err)
+	}
+
+	for i := range moves {
+		for j := range cards[i] {
+			if id, ok := copied[cards[i][j].ID]; ok {
+				cards[i][j].TargetCardID = &id
+			}
+		}
+		cardsJSON, err := json.Marshal(cards[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal merged review cards: %w", err)
+		}
+		moves[i].ReviewCards = datatypes.JSON(cardsJSON)
+	}
+	// 来源文章此前作为目标时记录的迁移，这些行现在随之归入新目标
+	err = tx.Model(&MergeMove{}).Where("target_id IN ?", sourceIDs).Update("target_id", targetID).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update merge moves: %w", err)
+	}
+	if err := tx.Create(&moves).Error; err != nil {
+		return nil, fmt.Errorf("failed to record merge moves: %w", err)
+	}
+
+	// 记录重定向，并将原本指向来源文章的重定向改指目标，保持单跳
+	err = tx.Model(&ArticleRedirect{}).Where("to_id IN ?", sourceIDs).Update("to_id", targetID).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update article redirects: %w", err)
+	}
+	redirects := make([]ArticleRedirect, len(sourceIDs))
+	for i, id := range sourceIDs {
+		redirects[i] = ArticleRedirect{FromID: id, ToID: targetID, Reason: reason}
+	}
+	err = tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "from_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"to_id", "reason", "created_at"}),
+	}).Create(&redirects).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create article redirects: %w", err)
+	}
+
+	// 涉及来源文章的待审核疑似重复记录：正是本次合并的记为 merged，其余驳回
+	now := time.Now()
+	err = tx.Model(&DuplicateReview{}).
+		Where("status = ? AND article_id IN ? AND candidate_id = ?", DuplicateReviewPending, sourceIDs, targetID).
+		Updates(map[string]interface{}{"status": DuplicateReviewMerged, "resolved_at": now}).Error
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Model(&DuplicateReview{}).
+		Where("status = ? AND (article_id IN ? OR candidate_id IN ?)", DuplicateReviewPending, sourceIDs, sourceIDs).
+		Updates(map[string]interface{}{"status": DuplicateReviewDismissed, "resolved_at": now}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &target, nil
+}
+
+// mergedReviewCard 是合并时来源文章的一张复习卡片，TargetCardID 是合并时由它复制到目标文章的卡片
+type mergedReviewCard struct {
+	ReviewCard
+	TargetCardID *uint `json:"target_card_id,omitempty"`
+}
+
+// collectMergeMove 记录来源文章即将迁移到目标的行，需要在迁移之前调用
+func collectMergeMove(tx *gorm.DB, source Article, targetID uint) (MergeMove, []mergedReviewCard, error) {
+	move := MergeMove{TargetID: targetID, SourceID: source.ID, Ext: source.Ext}
+	pluck := func(model any, what string) (pq.Int64Array, error) {
+		var ids []int64
+		if err := tx.Model(model).Where("article_id = ?", source.ID).Order("id").Pluck("id", &ids).Error; err != nil {
+			return nil, fmt.Errorf("failed to record merged %s: %w", what, err)
+		}
+		return pq.Int64Array(ids), nil
+	}
+
+	var err error
+	if move.OccurrenceIDs, err = pluck(&ArticleOccurrence{}, "occurrences"); err != nil {
+		return move, nil, err
+	}
+	if move.AttemptIDs, err = pluck(&AnswerAttempt{}, "answer attempts"); err != nil {
+		return move, nil, err
+	}
+	if move.InterviewItemIDs, err = pluck(&InterviewItem{}, "interview items"); err != nil {
+		return move, nil, err
+	}
+	if move.ReviewLogIDs, err = pluck(&ReviewLog{}, "review logs"); err != nil {
+		return move, nil, err
+	}
+
+	var cards []ReviewCard
+	if err := tx.Where("article_id = ?", source.ID).Order("id").Find(&cards).Error; err != nil {
+		return move, nil, fmt.Errorf("failed to record merged review cards: %w", err)
+	}
+	snapshots := make([]mergedReviewCard, len(cards))
+	for i, card := range cards {
+		snapshots[i] = mergedReviewCard{ReviewCard: card}
+	}
+	return move, snapshots, nil
+}
+
+// restoreMergeMove 把合并时从来源文章迁移到 fromID 的行还给 toID
+// 只迁移仍属于 fromID 的行：之后已被单独拆分或删除的行保持不变
+func restoreMergeMove(tx *gorm.DB, move *MergeMove, fromID, toID uint) error {
+	restore := func(model any, ids pq.Int64Array, what string) error {
+		if len(ids) == 0 {
+			return nil
+		}
+		err := tx.Model(model).Where("id IN ? AND article_id = ?", []int64(ids), fromID).Update("article_id", toID).Error
+		if err != nil {
+			return fmt.Errorf("failed to restore %s: %w", what, err)
+		}
+		return nil
+	}
+	if err := restore(&ArticleOccurrence{}, move.OccurrenceIDs, "article occurrences"); err != nil {
+		return err
+	}
+	if err := restore(&AnswerAttempt{}, move.AttemptIDs, "answer attempts"); err != nil {
+		return err
+	}
+	if err := restore(&InterviewItem{}, move.InterviewItemIDs, "interview items"); err != nil {
+		return err
+	}
+	if err := restore(&ReviewLog{}, move.ReviewLogIDs, "review logs"); err != nil {
+		return err
+	}
+
+	// 复习卡片：由来源卡片复制到目标的卡片连同之后的复习进度还给来源，其余按快照重建
+	var cards []mergedReviewCard
+	if len(move.ReviewCards) > 0 {
+		if err := json.Unmarshal(move.ReviewCards, &cards); err != nil {
+			return fmt.Errorf("failed to parse merged review cards: %w", err)
+		}
+	}
+	for _, card := range cards {
+		if card.TargetCardID != nil {
+			result := tx.Model(&ReviewCard{}).Where("id = ? AND article_id = ?", *card.TargetCardID, fromID).Update("article_id", toID)
+			if result.Error != nil {
+				return fmt.Errorf("failed to restore review cards: %w", result.Error)
+			}
+			if result.RowsAffected > 0 {
+				continue
+			}
+		}
+		restored := card.ReviewCard
+		restored.ArticleID = toID
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&restored).Error; err != nil {
+			return fmt.Errorf("failed to restore review cards: %w", err)
+		}
+	}
+	return nil
+}
+
+// unmergeOccurrences 把自动合并的 Ext 项对应的出现记录从目标移到拆分出的文章
+// 较早合并的 Ext 项没有记录出现记录 ID，只能按问题原文找回；
+// 目标中还有其他同文的 Ext 项时无法区分，出现记录留在目标并记录警告
+func unmergeOccurrences(tx *gorm.DB, target Article, entry ExtEntry, remaining []ExtEntry, newID uint) error {
+	query := tx.Model(&ArticleOccurrence{}).Where("article_id = ?", target.ID)
+	if len(entry.OccurrenceIDs) > 0 {
+		query = query.Where("id IN ?", entry.OccurrenceIDs)
+	} else {
+		for _, e := range remaining {
+			if e.OriginalQuestion == entry.OriginalQuestion && len(e.OccurrenceIDs) == 0 {
+				slog.Warn("拆分的重复项与其他重复项同文，出现记录保留在原文章",
+					"target_id", target.ID, "article_id", newID, "question", entry.OriginalQuestion)
+				return nil
+			}
+		}
+		query = query.Where("kind = ? AND question = ? AND question <> ?", OccurrenceMerged, entry.OriginalQuestion, target.OriginalQuestion)
+	}
+	if err := query.Update("article_id", newID).Error; err != nil {
+		return fmt.Errorf("failed to move article occurrences: %w", err)
+	}
+	return nil
+}
+
+// sameSource 判断两个 Ext 项是否来自同一篇手动合并的文章 (都不是手动合并也视为相同)
+func sameSource(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// UnmergeArticle 将目标文章 Ext 中下标为 index 的重复项拆分为独立文章
+// expectedQuestion 用于确认调用方读取 Ext 之后该下标没有被并发修改；vector 是该重复项的文档向量。
+// 如果该项来自手动合并，新文章恢复原来的 ID，合并时从它迁移过来的出现记录、作答、复习卡片和日志、
+// 面试题目以及它原有的 Ext 重复项按合并记录原样恢复；否则分配新 ID，并按 Ext 项记录的 ID 找回自动合并时写入的出现记录
+func (r *Repository) UnmergeArticle(ctx context.Context, targetID uint, index int, expectedQuestion string, vector []float32) (*Article, error) {
+	var newArticle *Article
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var target Article
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(inWorkspace("workspace_id")).First(&target, targetID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: target %d", ErrArticleNotFound, targetID)
+			}
+			return err
+		}
+
+		ext, err := ParseArticleExt(target.Ext)
+		if err != nil {
+			return err
+		}
+		if index < 0 || index >= len(ext) {
+			return fmt.Errorf("%w: ext index %d out of range (0-%d)", ErrInvalidMerge, index, len(ext)-1)
+		}
+		entry := ext[index]
+		if entry.OriginalQuestion != expectedQuestion {
+			return fmt.Errorf("%w: ext entry %d changed concurrently", ErrInvalidMerge, index)
+		}
+		ext = append(ext[:index], ext[index+1:]...)
+
+		// 手动合并的来源文章：找到合并记录，把它原有的重复项一并取回
+		var move *MergeMove
+		restoredExt := []ExtEntry{}
+		if entry.SourceArticleID != nil {
+			var moves []MergeMove
+			err := tx.Where("target_id = ? AND source_id = ?", targetID, *entry.SourceArticleID).Order("id DESC").Limit(1).Find(&moves).Error
+			if err != nil {
+				return fmt.Errorf("failed to load merge moves: %w", err)
+			}
+			if len(moves) > 0 {
+				move = &moves[0]
+				movedExt, err := ParseArticleExt(move.Ext)
+				if err != nil {
+					return err
+				}
+				for _, moved := range movedExt {
+					for j := range ext {
+						if ext[j].OriginalQuestion == moved.OriginalQuestion && sameSource(ext[j].SourceArticleID, moved.SourceArticleID) {
+							restoredExt = append(restoredExt, ext[j])
+							ext = append(ext[:j], ext[j+1:]...)
+							break
+						}
+					}
+				}
+			}
+		}
+
+		extJSON, err := json.Marshal(ext)
+		if err != nil {
+			return fmt.Errorf("failed to marshal article ext: %w", err)
+		}
+		if err := tx.Model(&target).Update("ext", datatypes.JSON(extJSON)).Error; err != nil {
+			return fmt.Errorf("failed to update unmerge target: %w", err)
+		}
+
+		restoredJSON, err := json.Marshal(restoredExt)
+		if err != nil {
+			return fmt.Errorf("failed to marshal article ext: %w", err)
+		}
+		q := entry.InterviewQuestion
+		// 较早合并进来的重复项可能带有未规范化的标签
+		if q.Tags, err = resolveTags(tx, q.Tags); err != nil {
+			return err
+		}
+		article := &Article{
+			WorkspaceID:      target.WorkspaceID,
+			OriginalQuestion: q.OriginalQuestion,
+			DetailedQuestion: &q.DetailedQuestion,
+			ConciseAnswer:    &q.ConciseAnswer,
+			Tags:             pq.StringArray(q.Tags),
+			Embedding:        pgvector.NewVector(vector),
+			Ext:              datatypes.JSON(restoredJSON),
+		}
+
+		if entry.SourceArticleID != nil {
+			// 原 ID 仅在仍指向本目标时才恢复，避免与其他记录冲突
+			result := tx.Where("from_id = ? AND to_id = ?", *entry.SourceArticleID, targetID).Delete(&ArticleRedirect{})
+			if result.Error != nil {
+				return fmt.Errorf("failed to delete article redirect: %w", result.Error)
+			}
+			if result.RowsAffected > 0 {
+				article.ID = *entry.SourceArticleID
+			}
+		}
+
+		if err := tx.Create(article).Error; err != nil {
+			return fmt.Errorf("failed to create unmerged article: %w", err)
+		}
+
+		if move == nil {
+			// 没有合并记录 (自动合并的重复项)：按 Ext 项记录的 ID 找回出现记录
+			if err := unmergeOccurrences(tx, target, entry, ext, article.ID); err != nil {
+				return err
+			}
+			newArticle = article
+			return nil
+		}
+
+		if err := restoreMergeMove(tx, move, targetID, article.ID); err != nil {
+			return err
+		}
+		if err := tx.Delete(move).Error; err != nil {
+			return fmt.Errorf("failed to delete merge move: %w", err)
+		}
+
+		// 取回的重复项中更早手动合并的文章，其合并记录和重定向改为指向恢复的文章
+		var nestedIDs []uint
+		for _, e := range restoredExt {
+			if e.SourceArticleID != nil {
+				nestedIDs = append(nestedIDs, *e.SourceArticleID)
+			}
+		}
+		if len(nestedIDs) > 0 {
+			err = tx.Model(&MergeMove{}).Where("target_id = ? AND source_id IN ?", targetID, nestedIDs).Update("target_id", article.ID).Error
+			if err != nil {
+				return fmt.Errorf("failed to update merge moves: %w", err)
+			}
+			err = tx.Model(&ArticleRedirect{}).Where("to_id = ? AND from_id IN ?", targetID, nestedIDs).Update("to_id", article.ID).Error
+			if err != nil {
+				return fmt.Errorf("failed to update article redirects: %w", err)
+			}
+		}
+		newArticle = article
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newArticle, nil
+}
@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"paguu/internal/enrich"
+	"reflect"
+	"testing"
+)
+
+// unitVector 返回第 i 维为 1 的单位向量，不同 i 的向量正交 (相似度为 0)
+func unitVector(i int) []float32 {
+	v := make([]float32, 1536)
+	v[i] = 1
+	return v
+}
+
+// ingest 以 vector 提交一个问题，返回入库状态
+func ingest(t *testing.T, ctx context.Context, repo *Repository, question string, vector []float32) QuestionInsertStatus {
+	t.Helper()
+	q := enrich.InterviewQuestion{OriginalQuestion: question, DetailedQuestion: "详细: " + question, Tags: []string{"Go"}}
+	status, err := repo.ProcessEnrichedQuestion(ctx, q, vector, DefaultDedupeThresholds(), OccurrenceInfo{Source: "test", RawLine: question})
+	if err != nil {
+		t.Fatalf("ProcessEnrichedQuestion(%q): %v", question, err)
+	}
+	return status
+}
+
+// articleIDOf 返回原问题为 question 的文章 ID
+func articleIDOf(t *testing.T, repo *Repository, question string) uint {
+	t.Helper()
+	var article Article
+	if err := repo.db.Where("original_question = ?", question).First(&article).Error; err != nil {
+		t.Fatalf("find article %q: %v", question, err)
+	}
+	return article.ID
+}
+
+// occurrenceQuestions 返回文章名下出现记录的问题原文，按 ID 排序
+func occurrenceQuestions(t *testing.T, repo *Repository, articleID uint) []string {
+	t.Helper()
+	var questions []string
+	if err := repo.db.Model(&ArticleOccurrence{}).Where("article_id = ?", articleID).Order("id").Pluck("question", &questions).Error; err != nil {
+		t.Fatal(err)
+	}
+	return questions
+}
+
+// extQuestions 返回文章 Ext 中各项的问题原文
+func extQuestions(t *testing.T, repo *Repository, articleID uint) []string {
+	t.Helper()
+	var article Article
+	if err := repo.db.First(&article, articleID).Error; err != nil {
+		t.Fatal(err)
+	}
+	ext, err := ParseArticleExt(article.Ext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	questions := []string{}
+	for _, e := range ext {
+		questions = append(questions, e.OriginalQuestion)
+	}
+	return questions
+}
+
+func TestMergeUnmergeRoundTrip(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := WithWorkspace(context.Background(), DefaultWorkspaceID)
+
+	ingest(t, ctx, repo, "Go 的 GC", unitVector(0))
+	ingest(t, ctx, repo, "GMP 调度", unitVector(1))
+	if status := ingest(t, ctx, repo, "GMP 调度模型", unitVector(1)); status != QuestionInsertStatusMerged {
+		t.Fatalf("duplicate status = %s, want merged", status)
+	}
+	targetID := articleIDOf(t, repo, "Go 的 GC")
+	sourceID := articleIDOf(t, repo, "GMP 调度")
+
+	if _, err := repo.MergeArticles(ctx, targetID, []uint{sourceID}); err != nil {
+		t.Fatalf("MergeArticles: %v", err)
+	}
+	if got, want := extQuestions(t, repo, targetID), []string{"GMP 调度", "GMP 调度模型"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("target ext after merge = %q, want %q", got, want)
+	}
+	if got := occurrenceQuestions(t, repo, targetID); len(got) != 3 {
+		t.Errorf("target occurrences after merge = %q, want 3", got)
+	}
+	if id, err := repo.ResolveArticleID(ctx, sourceID); err != nil || id != targetID {
+		t.Errorf("ResolveArticleID(%d) = %d, %v, want %d", sourceID, id, err, targetID)
+	}
+
+	restored, err := repo.UnmergeArticle(ctx, targetID, 0, "GMP 调度", unitVector(1))
+	if err != nil {
+		t.Fatalf("UnmergeArticle: %v", err)
+	}
+	if restored.ID != sourceID {
+		t.Errorf("unmerged article ID = %d, want original %d", restored.ID, sourceID)
+	}
+	if got := extQuestions(t, repo, targetID); len(got) != 0 {
+		t.Errorf("target ext after unmerge = %q, want empty", got)
+	}
+	if got, want := extQuestions(t, repo, sourceID), []string{"GMP 调度模型"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("restored ext = %q, want %q", got, want)
+	}
+	if got, want := occurrenceQuestions(t, repo, targetID), []string{"Go 的 GC"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("target occurrences after unmerge = %q, want %q", got, want)
+	}
+	if got, want := occurrenceQuestions(t, repo, sourceID), []string{"GMP 调度", "GMP 调度模型"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("restored occurrences = %q, want %q", got, want)
+	}
+	if id, err := repo.ResolveArticleID(ctx, sourceID); err != nil || id != sourceID {
+		t.Errorf("ResolveArticleID(%d) after unmerge = %d, %v, want itself", sourceID, id, err)
+	}
+}
+
+func TestUnmergeAutoMergedOccurrences(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := WithWorkspace(context.Background(), DefaultWorkspaceID)
+
+	// 同一个问题被两次提交并自动合并，拆分其中一项只迁回它自己的出现记录
+	ingest(t, ctx, repo, "Redis 持久化", unitVector(0))
+	for range 2 {
+		if status := ingest(t, ctx, repo, "Redis 的持久化方式", unitVector(0)); status != QuestionInsertStatusMerged {
+			t.Fatalf("duplicate status = %s, want merged", status)
+		}
+	}
+	targetID := articleIDOf(t, repo, "Redis 持久化")
+
+	article, err := repo.UnmergeArticle(ctx, targetID, 0, "Redis 的持久化方式", unitVector(2))
+	if err != nil {
+		t.Fatalf("UnmergeArticle: %v", err)
+	}
+	if got, want := occurrenceQuestions(t, repo, article.ID), []string{"Redis 的持久化方式"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unmerged occurrences = %q, want %q", got, want)
+	}
+	if got, want := occurrenceQuestions(t, repo, targetID), []string{"Redis 持久化", "Redis 的持久化方式"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("target occurrences = %q, want %q", got, want)
+	}
+}
+
+func TestUnmergeLegacyAmbiguousOccurrences(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := WithWorkspace(context.Background(), DefaultWorkspaceID)
+
+	// 较早合并的 Ext 项没有出现记录 ID：同文的项无法区分时出现记录留在目标
+	ingest(t, ctx, repo, "MySQL 索引", unitVector(0))
+	targetID := articleIDOf(t, repo, "MySQL 索引")
+	duplicate := enrich.InterviewQuestion{OriginalQuestion: "MySQL 索引原理", Tags: []string{"MySQL"}}
+	for range 2 {
+		if err := repo.MergeDuplicate(ctx, targetID, duplicate); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := insertOccurrence(repo.db.WithContext(ctx), targetID, OccurrenceMerged, duplicate.OriginalQuestion, OccurrenceInfo{}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	article, err := repo.UnmergeArticle(ctx, targetID, 0, duplicate.OriginalQuestion, unitVector(2))
+	if err != nil {
+		t.Fatalf("UnmergeArticle: %v", err)
+	}
+	if got := occurrenceQuestions(t, repo, article.ID); len(got) != 0 {
+		t.Errorf("unmerged occurrences = %q, want none", got)
+	}
+	if got := occurrenceQuestions(t, repo, targetID); len(got) != 3 {
+		t.Errorf("target occurrences = %q, want 3", got)
+	}
+
+	// 只剩一项同文时可以按问题原文迁回
+	article, err = repo.UnmergeArticle(ctx, targetID, 0, duplicate.OriginalQuestion, unitVector(3))
+	if err != nil {
+		t.Fatalf("second UnmergeArticle: %v", err)
+	}
+	if got := occurrenceQuestions(t, repo, article.ID); len(got) != 2 {
+		t.Errorf("unmerged occurrences = %q, want both legacy occurrences", got)
+	}
+}
@@ -0,0 +1,244 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// 出现记录类型
+const (
+	OccurrenceInserted = "inserted" // 首次提交，创建了新文章
+	OccurrenceMerged   = "merged"   // 判定为重复，合并到已有文章
+)
+
+// occurrenceBackfillSource 是回填记录的来源标记
+const occurrenceBackfillSource = "backfill"
+
+// OccurrenceInfo 描述一次问题提交的来源，由任务处理器传入
+type OccurrenceInfo struct {
+	TaskID   string
+	Source   string
+	Metadata datatypes.JSON // 任务的 metadata，原样保存
+	RawLine  string         // 任务输入中对应的原始行，未知时为空
+	LineNo   int            // 原始行的非空行序号 (从 1 开始)，0 表示未知
+	SeenAt   time.Time      // 零值表示当前时间
+}
+
+// insertOccurrence 在 db 所绑定 context 中的工作区写入一条出现记录并返回其 ID，db 可以是事务
+func insertOccurrence(db *gorm.DB, articleID uint, kind, question string, info OccurrenceInfo, similarity *float64) (uint, error) {
+	workspaceID, err := requireWorkspace(db.Statement.Context)
+	if err != nil {
+		return 0, err
+	}
+	occurrence := &ArticleOccurrence{
+		WorkspaceID: workspaceID,
+		ArticleID:   articleID,
+		Kind:        kind,
+		Question:    question,
+		Source:      info.Source,
+		Metadata:    info.Metadata,
+		Similarity:  similarity,
+		SeenAt:      info.SeenAt,
+	}
+	if info.TaskID != "" {
+		occurrence.TaskID = &info.TaskID
+	}
+	if info.RawLine != "" {
+		occurrence.RawLine = &info.RawLine
+	}
+	if info.TaskID != "" && info.LineNo > 0 {
+		occurrence.LineNo = &info.LineNo
+	}
+	if occurrence.SeenAt.IsZero() {
+		occurrence.SeenAt = time.Now()
+	}
+	if err := db.Create(occurrence).Error; err != nil {
+		return 0, fmt.Errorf("failed to insert article occurrence: %w", err)
+	}
+	return occurrence.ID, nil
+}
+
+// IngestedTaskLines 返回任务中已经入库的行：行序号 -> 出现记录类型 (inserted/merged)
+// 任务失败重试时，之前的尝试可能已经入库了部分行，这些行应当跳过
+func (r *Repository) IngestedTaskLines(ctx context.Context, taskID string) (map[int]string, error) {
+	var rows []struct {
+		LineNo int
+		Kind   string
+	}
+	err := r.db.WithContext(ctx).Model(&ArticleOccurrence{}).
+		Select("line_no, kind").
+		Where("task_id = ? AND line_no IS NOT NULL", taskID).
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingested task lines: %w", err)
+	}
+	ingested := make(map[int]string, len(rows))
+	for _, row := range rows {
+		ingested[row.LineNo] = row.Kind
+	}
+	return ingested, nil
+}
+
+// OccurrenceFilter 限定统计出现次数时计入哪些记录，零值表示全部
+type OccurrenceFilter struct {
+	Since   *time.Time // 只计入 seen_at >= Since 的记录
+	Until   *time.Time // 只计入 seen_at < Until 的记录
+	Source  string     // 任务来源
+	Company string     // 任务 metadata 中的 company
+}
+
+// IsZero 判断是否没有任何筛选条件
+func (f OccurrenceFilter) IsZero() bool {
+	return f.Since == nil && f.Until == nil && f.Source == "" && f.Company == ""
+}
+
+// apply 将筛选条件添加到 article_occurrences 查询上
+func (f OccurrenceFilter) apply(db *gorm.DB) *gorm.DB {
+	if f.Since != nil {
+		db = db.Where("article_occurrences.seen_at >= ?", *f.Since)
+	}
+	if f.Until != nil {
+		db = db.Where("article_occurrences.seen_at < ?", *f.Until)
+	}
+	if f.Source != "" {
+		db = db.Where("article_occurrences.source = ?", f.Source)
+	}
+	if f.Company != "" {
+		db = db.Where("article_occurrences.metadata->>'company' = ?", f.Company)
+	}
+	return db
+}
+
+// CountOccurrences 按筛选条件批量统计文章的出现次数，没有记录的文章不在结果中
+func (r *Repository) CountOccurrences(ctx context.Context, articleIDs []uint, filter OccurrenceFilter) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(articleIDs))
+	if len(articleIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		ArticleID       uint
+		OccurrenceCount int64
+	}
+	err := filter.apply(r.db.WithContext(ctx).Model(&ArticleOccurrence{})).
+		Scopes(inWorkspace("workspace_id")).
+		Select("article_id, COUNT(*) AS occurrence_count").
+		Where("article_id IN ?", articleIDs).
+		Group("article_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count article occurrences: %w", err)
+	}
+
+	for _, row := range rows {
+		counts[row.ArticleID] = row.OccurrenceCount
+	}
+	return counts, nil
+}
+
+// ListArticleOccurrences 按时间倒序返回文章的出现记录
+func (r *Repository) ListArticleOccurrences(ctx context.Context, articleID uint, limit, offset int) ([]ArticleOccurrence, int64, error) {
+	var occurrences []ArticleOccurrence
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&ArticleOccurrence{}).
+		Scopes(inWorkspace("workspace_id")).
+		Where("article_id = ?", articleID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count article occurrences: %w", err)
+	}
+
+	err := query.Order("seen_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&occurrences).Error
+	if err != nil {
+		return nil, total, fmt.Errorf("failed to list article occurrences: %w", err)
+	}
+
+	return occurrences, total, nil
+}
+
+// SourceCount 是某个来源的出现次数
+type SourceCount struct {
+	Source string `json:"source"`
+	Count  int64  `json:"count"`
+}
+
+// OccurrenceSummary 汇总一篇文章被提问的情况
+type OccurrenceSummary struct {
+	Count     int64         `json:"count"`
+	FirstSeen *time.Time    `json:"first_seen,omitempty"`
+	LastSeen  *time.Time    `json:"last_seen,omitempty"`
+	Sources   []SourceCount `json:"sources"`
+}
+
+// GetOccurrenceSummary 返回文章出现次数、首次/最近出现时间以及按来源的分布
+func (r *Repository) GetOccurrenceSummary(ctx context.Context, articleID uint) (*OccurrenceSummary, error) {
+	var row struct {
+		Count     int64
+		FirstSeen *time.Time
+		LastSeen  *time.Time
+	}
+	err := r.db.WithContext(ctx).Model(&ArticleOccurrence{}).
+		Select("COUNT(*) AS count, MIN(seen_at) AS first_seen, MAX(seen_at) AS last_seen").
+		Scopes(inWorkspace("workspace_id")).
+		Where("article_id = ?", articleID).
+		Scan(&row).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize article occurrences: %w", err)
+	}
+
+	sources := make([]SourceCount, 0)
+	err = r.db.WithContext(ctx).Model(&ArticleOccurrence{}).
+		Select("source, COUNT(*) AS count").
+		Scopes(inWorkspace("workspace_id")).
+		Where("article_id = ?", articleID).
+		Group("source").
+		Order("count DESC, source ASC").
+		Scan(&sources).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize article occurrence sources: %w", err)
+	}
+
+	return &OccurrenceSummary{
+		Count:     row.Count,
+		FirstSeen: row.FirstSeen,
+		LastSeen:  row.LastSeen,
+		Sources:   sources,
+	}, nil
+}
+
+// backfillOccurrences 在 article_occurrences 首次创建时，为已有文章及其 Ext 中的重复项生成出现记录
+// 历史数据没有任务信息，来源记为 backfill，时间取文章创建时间
+func backfillOccurrences(db *gorm.DB) error {
+	slog.Info("正在从已有文章回填 article_occurrences...")
+
+	statements := []string{
+		`INSERT INTO article_occurrences (article_id, kind, question, source, seen_at, created_at)
+		 SELECT id, 'inserted', original_question, ?, created_at, NOW()
+		 FROM articles`,
+
+		`INSERT INTO article_occurrences (article_id, kind, question, source, seen_at, created_at)
+		 SELECT a.id, 'merged', COALESCE(e->>'original_question', ''), ?, a.created_at, NOW()
+		 FROM articles a, jsonb_array_elements(COALESCE(a.ext, '[]'::jsonb)) AS e`,
+	}
+
+	var total int64
+	for _, sql := range statements {
+		result := db.Exec(sql, occurrenceBackfillSource)
+		if result.Error != nil {
+			return fmt.Errorf("failed to backfill article occurrences: %w", result.Error)
+		}
+		total += result.RowsAffected
+	}
+
+	slog.Info("article_occurrences 回填完成", "rows", total, "source", occurrenceBackfillSource)
+	return nil
+}
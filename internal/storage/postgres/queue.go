@@ -10,12 +10,18 @@ import (
 	"gorm.io/gorm/clause"
 )
 
-// EnqueueTask 向队列添加一个新任务（状态默认为 ready）
+// EnqueueTask 向队列添加一个新任务（状态默认为 ready），任务属于 context 中的工作区
+// 出队不区分工作区，worker 按任务的 WorkspaceID 处理
 func (r *Repository) EnqueueTask(ctx context.Context, taskType string, payload datatypes.JSON) error {
+	workspaceID, err := requireWorkspace(ctx)
+	if err != nil {
+		return err
+	}
 	task := ProcessingQueue{
-		TaskType: taskType,
-		Payload:  payload,
-		Status:   "ready",
+		WorkspaceID: workspaceID,
+		TaskType:    taskType,
+		Payload:     payload,
+		Status:      "ready",
 	}
 	result := r.db.WithContext(ctx).Create(&task)
 	return result.Error
@@ -52,9 +58,12 @@ func (r *Repository) DequeueTask(ctx context.Context) (*ProcessingQueue, error)
 	return &task, nil
 }
 
-// UpdateTaskCompleted 将任务标记为完成
-func (r *Repository) UpdateTaskCompleted(ctx context.Context, task *ProcessingQueue) error {
-	err := r.db.WithContext(ctx).Model(task).Update("status", "completed").Error
+// UpdateTaskCompleted 将任务标记为完成，并记录处理结果
+func (r *Repository) UpdateTaskCompleted(ctx context.Context, task *ProcessingQueue, result datatypes.JSON) error {
+	err := r.db.WithContext(ctx).Model(task).Updates(map[string]interface{}{
+		"status": "completed",
+		"result": result,
+	}).Error
 	return err
 }
 
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"paguu/internal/ratelimit"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TakeRateLimitToken 按上次使用后经过的时间补充令牌 (不超过 capacity)，再尝试取出一个，实现 ratelimit.Store
+// 补充和扣减在同一个事务中完成，upsert 会锁住该行，多个副本并发请求时也不会超发；时间以数据库的 NOW() 为准
+func (r *Repository) TakeRateLimitToken(ctx context.Context, key string, perSecond, capacity float64) (float64, bool, error) {
+	var remaining float64
+	var allowed bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tokens float64
+		err := tx.Raw(`
+			INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at)
+			VALUES (?, ?, NOW())
+			ON CONFLICT (key) DO UPDATE SET
+				tokens = LEAST(EXCLUDED.tokens, b.tokens + EXTRACT(EPOCH FROM (NOW() - b.updated_at)) * ?),
+				updated_at = NOW()
+			RETURNING tokens`, key, capacity, perSecond).Scan(&tokens).Error
+		if err != nil {
+			return fmt.Errorf("failed to refill rate limit bucket: %w", err)
+		}
+
+		remaining = tokens
+		if tokens < 1 {
+			return nil
+		}
+		if err := tx.Model(&RateLimitBucket{}).Where("key = ?", key).
+			Update("tokens", gorm.Expr("tokens - 1")).Error; err != nil {
+			return fmt.Errorf("failed to take rate limit token: %w", err)
+		}
+		remaining, allowed = tokens-1, true
+		return nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return remaining, allowed, nil
+}
+
+// PruneRateLimitBuckets 删除 before 之后没有使用过的令牌桶，实现 ratelimit.Store
+func (r *Repository) PruneRateLimitBuckets(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("updated_at < ?", before).Delete(&RateLimitBucket{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune rate limit buckets: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ConsumeDailyQuota 在不超过 quota 时累加 subject 当天的任务数和问题行数，实现 ratelimit.Store
+// 超出时不修改并返回当前用量
+func (r *Repository) ConsumeDailyQuota(ctx context.Context, subject, day string, tasks, questions int, quota ratelimit.Quota) (ratelimit.QuotaUsage, bool, error) {
+	var usage ratelimit.QuotaUsage
+	var allowed bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&DailyQuota{Subject: subject, Day: day}).Error
+		if err != nil {
+			return fmt.Errorf("failed to create daily quota: %w", err)
+		}
+
+		var row DailyQuota
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("tasks", "questions").
+			Where("subject = ? AND day = ?", subject, day).
+			First(&row).Error
+		if err != nil {
+			return fmt.Errorf("failed to lock daily quota: %w", err)
+		}
+
+		usage = ratelimit.QuotaUsage{Tasks: row.Tasks, Questions: row.Questions}
+		if (quota.DailyTasks > 0 && row.Tasks+tasks > quota.DailyTasks) ||
+			(quota.DailyQuestions > 0 && row.Questions+questions > quota.DailyQuestions) {
+			return nil
+		}
+
+		err = tx.Model(&DailyQuota{}).
+			Where("subject = ? AND day = ?", subject, day).
+			Updates(map[string]interface{}{
+				"tasks":     gorm.Expr("tasks + ?", tasks),
+				"questions": gorm.Expr("questions + ?", questions),
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update daily quota: %w", err)
+		}
+		usage.Tasks += tasks
+		usage.Questions += questions
+		allowed = true
+		return nil
+	})
+	if err != nil {
+		return ratelimit.QuotaUsage{}, false, err
+	}
+	return usage, allowed, nil
+}
+
+// ReleaseDailyQuota 退还已累加的用量，不会减到 0 以下，实现 ratelimit.Store
+func (r *Repository) ReleaseDailyQuota(ctx context.Context, subject, day string, tasks, questions int) error {
+	err := r.db.WithContext(ctx).Model(&DailyQuota{}).
+		Where("subject = ? AND day = ?", subject, day).
+		Updates(map[string]interface{}{
+			"tasks":     gorm.Expr("GREATEST(tasks - ?, 0)", tasks),
+			"questions": gorm.Expr("GREATEST(questions - ?, 0)", questions),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release daily quota: %w", err)
+	}
+	return nil
+}
+
+// GetDailyQuota 返回 subject 当天的用量，没有记录时为 0，实现 ratelimit.Store
+func (r *Repository) GetDailyQuota(ctx context.Context, subject, day string) (ratelimit.QuotaUsage, error) {
+	var usage ratelimit.QuotaUsage
+	err := r.db.WithContext(ctx).Model(&DailyQuota{}).
+		Select("tasks, questions").
+		Where("subject = ? AND day = ?", subject, day).
+		Scan(&usage).Error
+	if err != nil {
+		return ratelimit.QuotaUsage{}, fmt.Errorf("failed to get daily quota: %w", err)
+	}
+	return usage, nil
+}
@@ -19,7 +19,8 @@ import (
 
 // Article 对应 'articles' 表
 type Article struct {
-	ID uint `gorm:"primaryKey"`
+	ID          uint `gorm:"primaryKey"`
+	WorkspaceID uint `gorm:"not null;default:1;index"` // 所属工作区，去重和检索都只在同一工作区内进行
 
 	// --- 与 InterviewQuestion 同步的字段 ---
 	OriginalQuestion string         `gorm:"type:text;not null"`
@@ -33,6 +34,7 @@ type Article struct {
 	NotionPageID *string         `gorm:"type:text"`
 	LastSyncedAt *time.Time      `gorm:"type:timestamptz"`
 	CreatedAt    time.Time       `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time       `gorm:"autoUpdateTime;not null;default:CURRENT_TIMESTAMP"` // 最后一次追加重复项、合并/拆分或改写标签的时间
 }
 
 // TableName 指定表名
@@ -43,14 +45,16 @@ func (Article) TableName() string {
 // ProcessingQueue 对应 'processing_queue' 表
 // 状态流转: ready -> processing -> completed/failed
 type ProcessingQueue struct {
-	ID        uint           `gorm:"primaryKey"`
-	TaskType  string         `gorm:"type:text;not null"`
-	Payload   datatypes.JSON `gorm:"type:jsonb;not null"`
-	Status    string         `gorm:"type:text;default:'ready'"` // ready/processing/completed/failed
-	Retries   int            `gorm:"default:0"`
-	LastError *string        `gorm:"type:text"`
-	CreatedAt time.Time      `gorm:"autoCreateTime"`
-	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
+	ID          uint           `gorm:"primaryKey"`
+	WorkspaceID uint           `gorm:"not null;default:1;index"` // 任务结果写入的工作区
+	TaskType    string         `gorm:"type:text;not null"`
+	Payload     datatypes.JSON `gorm:"type:jsonb;not null"`
+	Status      string         `gorm:"type:text;default:'ready'"` // ready/processing/completed/failed
+	Retries     int            `gorm:"default:0"`
+	LastError   *string        `gorm:"type:text"`
+	Result      datatypes.JSON `gorm:"type:jsonb"` // 处理结果 (逐行映射等)，完成时写入
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
 }
 
 // TableName 指定表名
@@ -58,6 +62,438 @@ func (ProcessingQueue) TableName() string {
 	return "processing_queue"
 }
 
+// ArticleRedirect 对应 'article_redirects' 表
+// 文章被合并删除后，旧 ID 通过重定向记录解析到合并目标
+type ArticleRedirect struct {
+	FromID    uint      `gorm:"primaryKey;autoIncrement:false"`
+	ToID      uint      `gorm:"not null;index"`
+	Reason    string    `gorm:"type:text;not null"` // merge/review
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (ArticleRedirect) TableName() string {
+	return "article_redirects"
+}
+
+// MergeMove 对应 'merge_moves' 表
+// 手动合并时为每篇来源文章记录一行：迁移到目标的出现记录、作答、面试题目、复习日志和卡片，
+// 以及来源文章原有的 Ext 重复项，拆分时据此原样恢复；拆分后删除
+type MergeMove struct {
+	ID               uint           `gorm:"primaryKey"`
+	TargetID         uint           `gorm:"not null;index"` // 当前持有这些行的文章，目标再被合并时随之更新
+	SourceID         uint           `gorm:"not null;index"`
+	Ext              datatypes.JSON `gorm:"type:jsonb"` // 来源文章合并前 Ext 中的重复项 ([]ExtEntry)
+	OccurrenceIDs    pq.Int64Array  `gorm:"type:bigint[]"`
+	AttemptIDs       pq.Int64Array  `gorm:"type:bigint[]"`
+	InterviewItemIDs pq.Int64Array  `gorm:"type:bigint[]"`
+	ReviewLogIDs     pq.Int64Array  `gorm:"type:bigint[]"`
+	ReviewCards      datatypes.JSON `gorm:"type:jsonb"` // 来源文章的复习卡片快照 ([]mergedReviewCard)
+	CreatedAt        time.Time      `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (MergeMove) TableName() string {
+	return "merge_moves"
+}
+
+// ArticleOccurrence 对应 'article_occurrences' 表
+// 每当一个问题被提交 (无论是新插入还是合并为重复项) 就记录一行，保留任务、来源和元信息
+type ArticleOccurrence struct {
+	ID          uint           `gorm:"primaryKey"`
+	WorkspaceID uint           `gorm:"not null;default:1;index"` // 与文章所属工作区相同
+	ArticleID   uint           `gorm:"not null;index"`           // 当前所属文章，手动合并/拆分时随之迁移
+	Kind        string         `gorm:"type:text;not null"`       // inserted/merged
+	Question    string         `gorm:"type:text;not null"`       // LLM 返回的 original_question，拆分时用于找回归属
+	RawLine     *string        `gorm:"type:text"`                // 任务输入中的原始行
+	TaskID      *string        `gorm:"type:text;index"`
+	LineNo      *int           `gorm:"type:integer"` // 任务输入中的非空行序号 (从 1 开始)，与 TaskID 一起唯一，任务重试时据此跳过已入库的行
+	Source      string         `gorm:"type:text;not null;default:''"`
+	Metadata    datatypes.JSON `gorm:"type:jsonb"`            // 任务的 metadata (公司、日期等)
+	Similarity  *float64       `gorm:"type:double precision"` // 与合并目标/最近邻的相似度，库为空时为 NULL
+	SeenAt      time.Time      `gorm:"not null"`              // 任务创建时间
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (ArticleOccurrence) TableName() string {
+	return "article_occurrences"
+}
+
+// Tag 对应 'tags' 表，保存规范化后的标签及其层级关系
+type Tag struct {
+	ID          uint      `gorm:"primaryKey"`
+	WorkspaceID uint      `gorm:"not null;default:1;uniqueIndex:idx_tags_workspace_name"`
+	Name        string    `gorm:"type:text;not null;uniqueIndex:idx_tags_workspace_name"` // 规范名，在工作区内唯一，Article.Tags 中只出现规范名
+	ParentID    *uint     `gorm:"index"`                                                  // 上级标签，例如 "GC" -> "Go"
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// TagAlias 对应 'tag_aliases' 表，将标签的各种写法映射到规范标签
+// Key 为 NormalizeTagKey 的结果，规范名自身也有一条记录
+type TagAlias struct {
+	WorkspaceID uint      `gorm:"primaryKey;autoIncrement:false;default:1"`
+	Key         string    `gorm:"primaryKey;type:text"`
+	Alias       string    `gorm:"type:text;not null"` // 登记时的原始写法
+	TagID       uint      `gorm:"not null;index"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (TagAlias) TableName() string {
+	return "tag_aliases"
+}
+
+// ClusterRun 对应 'cluster_runs' 表，每次聚类任务完成后写入一行
+// 查询接口使用最新的一次运行
+type ClusterRun struct {
+	ID           uint      `gorm:"primaryKey"`
+	WorkspaceID  uint      `gorm:"not null;default:1;index"` // 每个工作区独立聚类
+	K            int       `gorm:"not null"`
+	ArticleCount int       `gorm:"not null"`
+	Iterations   int       `gorm:"not null"`
+	Inertia      float64   `gorm:"type:double precision;not null"`
+	NamingModel  string    `gorm:"type:text;not null;default:''"` // 为簇命名的 LLM 模型，未命名时为空
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (ClusterRun) TableName() string {
+	return "cluster_runs"
+}
+
+// Cluster 对应 'clusters' 表，一次聚类运行中的一个主题簇
+type Cluster struct {
+	ID        uint            `gorm:"primaryKey"`
+	RunID     uint            `gorm:"not null;index"`
+	Label     int             `gorm:"not null"` // 在本次运行中的簇下标
+	Name      string          `gorm:"type:text;not null"`
+	Summary   *string         `gorm:"type:text"`
+	TopTags   pq.StringArray  `gorm:"type:text[]"`
+	Size      int             `gorm:"not null"` // 聚类时的文章数
+	Centroid  pgvector.Vector `gorm:"type:vector(1536)"`
+	CreatedAt time.Time       `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (Cluster) TableName() string {
+	return "clusters"
+}
+
+// ArticleCluster 对应 'article_clusters' 表，记录文章在某次运行中所属的簇
+type ArticleCluster struct {
+	RunID      uint    `gorm:"primaryKey;autoIncrement:false"`
+	ArticleID  uint    `gorm:"primaryKey;autoIncrement:false;index"`
+	ClusterID  uint    `gorm:"not null;index"`
+	Similarity float64 `gorm:"type:double precision;not null"` // 与簇中心的余弦相似度
+}
+
+// TableName 指定表名
+func (ArticleCluster) TableName() string {
+	return "article_clusters"
+}
+
+// AnswerAttempt 对应 'answer_attempts' 表，记录一次练习作答及 LLM 的评分
+type AnswerAttempt struct {
+	ID              uint           `gorm:"primaryKey"`
+	WorkspaceID     uint           `gorm:"not null;default:1;index"`
+	ArticleID       uint           `gorm:"not null;index"`                      // 手动合并时随文章迁移
+	UserName        string         `gorm:"type:text;not null;default:'';index"` // 作答人，未指定时为空
+	Answer          string         `gorm:"type:text;not null"`
+	Score           int            `gorm:"not null"` // 0-100
+	CoveredConcepts pq.StringArray `gorm:"type:text[]"`
+	MissedConcepts  pq.StringArray `gorm:"type:text[]"` // 参考答案中未答到的要点
+	FollowUps       pq.StringArray `gorm:"type:text[]"` // 面试官可能的追问
+	Feedback        *string        `gorm:"type:text"`
+	Model           string         `gorm:"type:text;not null"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (AnswerAttempt) TableName() string {
+	return "answer_attempts"
+}
+
+// ReviewCard 对应 'review_cards' 表，记录某个用户对一篇文章的 SM-2 复习状态
+type ReviewCard struct {
+	ID             uint       `gorm:"primaryKey"`
+	UserName       string     `gorm:"type:text;not null;uniqueIndex:idx_review_cards_user_article"`
+	ArticleID      uint       `gorm:"not null;uniqueIndex:idx_review_cards_user_article;index"` // 手动合并时随文章迁移
+	Ease           float64    `gorm:"type:double precision;not null;default:2.5"`
+	IntervalDays   int        `gorm:"not null;default:0"`
+	Repetitions    int        `gorm:"not null;default:0"` // 连续答对次数
+	Lapses         int        `gorm:"not null;default:0"` // 累计遗忘次数
+	DueAt          time.Time  `gorm:"not null"`
+	LastReviewedAt *time.Time `gorm:"type:timestamptz"` // 从未复习过的新卡片为 NULL
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (ReviewCard) TableName() string {
+	return "review_cards"
+}
+
+// ReviewLog 对应 'review_logs' 表，每次复习记录一行
+type ReviewLog struct {
+	ID           uint      `gorm:"primaryKey"`
+	UserName     string    `gorm:"type:text;not null;index"`
+	ArticleID    uint      `gorm:"not null;index"`
+	Rating       string    `gorm:"type:text;not null"` // again/hard/good/easy
+	Ease         float64   `gorm:"type:double precision;not null"`
+	IntervalDays int       `gorm:"not null"`
+	DueAt        time.Time `gorm:"not null"` // 复习后的下次到期时间
+	ReviewedAt   time.Time `gorm:"not null"`
+}
+
+// TableName 指定表名
+func (ReviewLog) TableName() string {
+	return "review_logs"
+}
+
+// TagSubscription 对应 'tag_subscriptions' 表，订阅标签的新文章会自动加入用户的复习卡组
+type TagSubscription struct {
+	WorkspaceID uint      `gorm:"primaryKey;autoIncrement:false;default:1"`
+	UserName    string    `gorm:"type:text;primaryKey"`
+	Tag         string    `gorm:"type:text;primaryKey;index"` // 规范名
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (TagSubscription) TableName() string {
+	return "tag_subscriptions"
+}
+
+// 模拟面试状态
+const (
+	InterviewStatusInProgress = "in_progress"
+	InterviewStatusCompleted  = "completed"
+)
+
+// InterviewSession 对应 'interview_sessions' 表，记录一次模拟面试的组卷参数和状态
+// 状态流转: in_progress -> completed (所有题目都已作答)
+type InterviewSession struct {
+	ID          uint           `gorm:"primaryKey"`
+	WorkspaceID uint           `gorm:"not null;default:1;index"`
+	UserName    string         `gorm:"type:text;not null;default:'';index"`
+	Tags        pq.StringArray `gorm:"type:text[]"`
+	Level       string         `gorm:"type:text;not null"` // junior/mid/senior
+	Length      int            `gorm:"not null"`           // 实际题目数
+	Status      string         `gorm:"type:text;not null;default:'in_progress'"`
+	ReplayOf    *uint          `gorm:"index"` // 重放时指向原始面试
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+	CompletedAt *time.Time     `gorm:"type:timestamptz"`
+}
+
+// TableName 指定表名
+func (InterviewSession) TableName() string {
+	return "interview_sessions"
+}
+
+// InterviewItem 对应 'interview_items' 表，是面试中按顺序排列的一道题及其作答
+type InterviewItem struct {
+	ID         uint       `gorm:"primaryKey"`
+	SessionID  uint       `gorm:"not null;uniqueIndex:idx_interview_items_session_position"`
+	Position   int        `gorm:"not null;uniqueIndex:idx_interview_items_session_position"` // 从 1 开始
+	ArticleID  uint       `gorm:"not null;index"`                                            // 手动合并时随文章迁移
+	Tag        string     `gorm:"type:text;not null"`                                        // 该题覆盖的目标标签
+	Depth      int        `gorm:"not null"`                                                  // 1 基础 - 5 深挖
+	Answer     *string    `gorm:"type:text"`
+	AttemptID  *uint      // 作答被评分时关联的 answer_attempts 记录
+	AnsweredAt *time.Time `gorm:"type:timestamptz"`
+}
+
+// TableName 指定表名
+func (InterviewItem) TableName() string {
+	return "interview_items"
+}
+
+// User 对应 'users' 表，是 API 的调用者
+// Name 与其他表中的 user_name 列对应
+type User struct {
+	ID         uint       `gorm:"primaryKey"`
+	Name       string     `gorm:"type:text;not null;uniqueIndex"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+	DisabledAt *time.Time `gorm:"type:timestamptz"` // 停用后该用户的所有 API key 失效
+}
+
+// TableName 指定表名
+func (User) TableName() string {
+	return "users"
+}
+
+// APIKey 对应 'api_keys' 表，只保存 key 的 SHA-256 哈希，明文只在创建时返回一次
+type APIKey struct {
+	ID         uint           `gorm:"primaryKey"`
+	UserID     uint           `gorm:"not null;index"`
+	Name       string         `gorm:"type:text;not null;default:''"` // 用途备注
+	Prefix     string         `gorm:"type:text;not null"`            // key 的前几位，用于辨认
+	KeyHash    string         `gorm:"type:text;not null;uniqueIndex"`
+	Scopes     pq.StringArray `gorm:"type:text[]"` // read/write/admin
+	ExpiresAt  *time.Time     `gorm:"type:timestamptz"`
+	LastUsedAt *time.Time     `gorm:"type:timestamptz"`
+	RevokedAt  *time.Time     `gorm:"type:timestamptz"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// AuditLog 对应 'audit_logs' 表，每个成功的写请求记录一行，用于追溯任务和修改的发起人
+type AuditLog struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserName  string    `gorm:"type:text;not null;default:'';index"` // 未启用鉴权时为空
+	KeyID     *uint     // 使用 API key 或由其签发的 JWT 调用时记录
+	Method    string    `gorm:"type:text;not null"`
+	Route     string    `gorm:"type:text;not null;index"` // 路由模板，例如 /api/v1/articles/:id/merge
+	Path      string    `gorm:"type:text;not null"`       // 实际请求路径
+	Status    int       `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index"`
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// Workspace 对应 'workspaces' 表，不同团队 (后端、前端、SRE 等) 的题库互相隔离
+// 文章、标签、出现记录、任务、聚类、复习订阅和面试都属于某个工作区；ID 为 1 的 default 工作区承载升级前的数据
+type Workspace struct {
+	ID                 uint      `gorm:"primaryKey"`
+	Slug               string    `gorm:"type:text;not null;uniqueIndex"` // 请求中用于选择工作区的标识
+	Name               string    `gorm:"type:text;not null"`
+	EnrichModel        *string   `gorm:"type:text"` // 覆盖全局的丰富化模型，NULL 表示使用全局配置
+	EnrichTemplatePath *string   `gorm:"type:text"` // 覆盖全局的丰富化模板，NULL 表示使用全局配置
+	CreatedAt          time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (Workspace) TableName() string {
+	return "workspaces"
+}
+
+// WorkspaceMember 对应 'workspace_members' 表，记录用户在工作区中的角色
+type WorkspaceMember struct {
+	WorkspaceID uint      `gorm:"primaryKey;autoIncrement:false"`
+	UserID      uint      `gorm:"primaryKey;autoIncrement:false;index"`
+	Role        string    `gorm:"type:text;not null"` // viewer/editor/owner
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (WorkspaceMember) TableName() string {
+	return "workspace_members"
+}
+
+// RateLimitBucket 对应 'rate_limit_buckets' 表，每个 (令牌桶, 调用者) 一行，多个副本共享限流状态
+type RateLimitBucket struct {
+	Key       string    `gorm:"type:text;primaryKey"` // 桶名:调用者，例如 paid:key:5、api:ip:10.0.0.3
+	Tokens    float64   `gorm:"type:double precision;not null"`
+	UpdatedAt time.Time `gorm:"type:timestamptz;not null;index"` // 上次补充令牌的时间
+}
+
+// TableName 指定表名
+func (RateLimitBucket) TableName() string {
+	return "rate_limit_buckets"
+}
+
+// DailyQuota 对应 'daily_quotas' 表，记录每个调用者每天提交的任务数和问题行数
+type DailyQuota struct {
+	Subject   string    `gorm:"type:text;primaryKey"` // user:3，未启用鉴权时为 ip:10.0.0.3
+	Day       string    `gorm:"type:date;primaryKey"`
+	Tasks     int       `gorm:"not null;default:0"`
+	Questions int       `gorm:"not null;default:0"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (DailyQuota) TableName() string {
+	return "daily_quotas"
+}
+
+// DuplicateReview 对应 'duplicate_reviews' 表
+// 记录相似度落在灰区内的新文章与其最近邻，等待人工确认合并或驳回
+// 状态流转: pending -> merged/dismissed
+type DuplicateReview struct {
+	ID          uint       `gorm:"primaryKey"`
+	WorkspaceID uint       `gorm:"not null;default:1;index"`
+	ArticleID   uint       `gorm:"not null;index"` // 新插入的疑似重复文章
+	CandidateID uint       `gorm:"not null;index"` // 最近邻 (合并目标)
+	Similarity  float64    `gorm:"type:double precision;not null"`
+	Status      string     `gorm:"type:text;not null;default:'pending'"` // pending/merged/dismissed
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+	ResolvedAt  *time.Time `gorm:"type:timestamptz"`
+}
+
+// TableName 指定表名
+func (DuplicateReview) TableName() string {
+	return "duplicate_reviews"
+}
+
+// UsageEvent 对应 'usage_events' 表，记录每次 LLM / Embedding 调用的用量
+type UsageEvent struct {
+	ID               uint      `gorm:"primaryKey"`
+	TaskID           *string   `gorm:"type:text"`
+	Source           string    `gorm:"type:text;not null;default:''"`
+	Provider         string    `gorm:"type:text;not null"` // ark/gemini
+	Operation        string    `gorm:"type:text;not null"` // enrich/embed
+	Model            string    `gorm:"type:text;not null"`
+	PromptTokens     int64     `gorm:"not null;default:0"`
+	CompletionTokens int64     `gorm:"not null;default:0"`
+	TotalTokens      int64     `gorm:"not null;default:0"`
+	Estimated        bool      `gorm:"not null;default:false"` // token 数为估算值
+	LatencyMs        int64     `gorm:"not null;default:0"`
+	Cost             float64   `gorm:"type:double precision;not null;default:0"`
+	Error            *string   `gorm:"type:text"`
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (UsageEvent) TableName() string {
+	return "usage_events"
+}
+
+// EnrichmentCache 对应 'enrichment_cache' 表，缓存单个问题的丰富化结果
+// Key 由 归一化问题 + 模型 + 模板版本 计算得到
+type EnrichmentCache struct {
+	Key                string         `gorm:"primaryKey;type:text"`
+	NormalizedQuestion string         `gorm:"type:text;not null;index"`
+	Model              string         `gorm:"type:text;not null"`
+	TemplateVersion    string         `gorm:"type:text;not null"`
+	Result             datatypes.JSON `gorm:"type:jsonb;not null"` // enrich.InterviewQuestion
+	Hits               int64          `gorm:"not null;default:0"`
+	LastHitAt          *time.Time     `gorm:"type:timestamptz"`
+	CreatedAt          time.Time      `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (EnrichmentCache) TableName() string {
+	return "enrichment_cache"
+}
+
+// EmbeddingCache 对应 'embedding_cache' 表，按内容哈希缓存归一化后的向量
+// Key 由 模型 + 维度 + 文本内容 计算得到
+type EmbeddingCache struct {
+	Key       string          `gorm:"primaryKey;type:text"`
+	Model     string          `gorm:"type:text;not null"`
+	Dimension int             `gorm:"not null"`
+	Embedding pgvector.Vector `gorm:"type:vector;not null"`
+	CreatedAt time.Time       `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (EmbeddingCache) TableName() string {
+	return "embedding_cache"
+}
+
 // ===================================================================
 // Repository 结构体和初始化
 // ===================================================================
@@ -110,12 +546,50 @@ func NewRepository(dsn string) (*Repository, error) {
 	slog.Info("Vector 扩展已启用")
 
 	// 2. 自动迁移
-	slog.Info("正在自动迁移 GORM schema (articles, processing_queue)...")
-	if err := db.AutoMigrate(&Article{}, &ProcessingQueue{}); err != nil {
+	// article_occurrences 首次创建时需要从已有文章回填
+	needOccurrenceBackfill := !db.Migrator().HasTable(&ArticleOccurrence{})
+	// tags 首次创建时需要登记已有标签并改写 Article.Tags
+	needTagBackfill := !db.Migrator().HasTable(&Tag{})
+
+	// workspaces 首次创建时需要建立 default 工作区，并把标签等表的唯一约束改为按工作区
+	needWorkspaceBackfill := !db.Migrator().HasTable(&Workspace{})
+
+	// articles.updated_at 首次创建时用 created_at 回填，而不是迁移的时间
+	needUpdatedAtBackfill := db.Migrator().HasTable(&Article{}) && !db.Migrator().HasColumn(&Article{}, "UpdatedAt")
+
+	slog.Info("正在自动迁移 GORM schema (articles, processing_queue, article_redirects, article_occurrences, tags, tag_aliases, cluster_runs, clusters, article_clusters, answer_attempts, review_cards, review_logs, tag_subscriptions, interview_sessions, interview_items, users, api_keys, audit_logs, workspaces, workspace_members, rate_limit_buckets, daily_quotas, duplicate_reviews, usage_events, enrichment_cache, embedding_cache)...")
+	if err := db.AutoMigrate(&Article{}, &ProcessingQueue{}, &ArticleRedirect{}, &MergeMove{}, &ArticleOccurrence{}, &Tag{}, &TagAlias{}, &ClusterRun{}, &Cluster{}, &ArticleCluster{}, &AnswerAttempt{}, &ReviewCard{}, &ReviewLog{}, &TagSubscription{}, &InterviewSession{}, &InterviewItem{}, &User{}, &APIKey{}, &AuditLog{}, &Workspace{}, &WorkspaceMember{}, &RateLimitBucket{}, &DailyQuota{}, &DuplicateReview{}, &UsageEvent{}, &EnrichmentCache{}, &EmbeddingCache{}); err != nil {
 		return nil, fmt.Errorf("failed to auto-migrate schema: %w", err)
 	}
 	slog.Info("GORM schema 迁移完成")
 
+	if needWorkspaceBackfill {
+		if err := migrateWorkspaces(db); err != nil {
+			return nil, err
+		}
+	}
+
+	if needUpdatedAtBackfill {
+		if err := db.Exec("UPDATE articles SET updated_at = created_at").Error; err != nil {
+			return nil, fmt.Errorf("failed to backfill articles.updated_at: %w", err)
+		}
+		slog.Info("已用 created_at 回填 articles.updated_at")
+	}
+
+	if needOccurrenceBackfill {
+		if err := backfillOccurrences(db); err != nil {
+			return nil, err
+		}
+	}
+	if needTagBackfill {
+		slog.Info("正在登记已有标签并规范化 Article.Tags...")
+		updated, err := normalizeArticleTags(db)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("标签规范化完成", "updated_articles", updated)
+	}
+
 	// 3. 创建自定义索引
 	slog.Info("正在确保自定义索引存在...")
 
@@ -129,6 +603,21 @@ func NewRepository(dsn string) (*Repository, error) {
 		return nil, err
 	}
 
+	// 用量索引
+	if err := createUsageIndexes(db); err != nil {
+		return nil, err
+	}
+
+	// 出现记录索引
+	if err := createOccurrenceIndexes(db); err != nil {
+		return nil, err
+	}
+
+	// 复习卡片索引
+	if err := createReviewIndexes(db); err != nil {
+		return nil, err
+	}
+
 	slog.Info("所有自定义索引已确保存在")
 	return &Repository{db: db}, nil
 }
@@ -172,6 +661,12 @@ func createArticleIndexes(db *gorm.DB) error {
 		// 使用 vector_ip_ops 因为向量是归一化的，使用内积 <#> 查询
 		`CREATE INDEX IF NOT EXISTS idx_articles_embedding_hnsw_ip
 		 ON articles USING hnsw (embedding vector_ip_ops)`,
+
+		// 文章列表按创建/更新时间的 keyset 分页 (升序和降序都可以使用)
+		`CREATE INDEX IF NOT EXISTS idx_articles_workspace_created
+		 ON articles (workspace_id, created_at, id)`,
+		`CREATE INDEX IF NOT EXISTS idx_articles_workspace_updated
+		 ON articles (workspace_id, updated_at, id)`,
 	}
 
 	for _, sql := range indexes {
@@ -182,3 +677,68 @@ func createArticleIndexes(db *gorm.DB) error {
 
 	return nil
 }
+
+// createUsageIndexes 创建用量统计相关索引
+func createUsageIndexes(db *gorm.DB) error {
+	indexes := []string{
+		// 按时间范围汇总和每日预算检查
+		`CREATE INDEX IF NOT EXISTS idx_usage_events_created_at
+		 ON usage_events (created_at)`,
+
+		// 按任务查看用量
+		`CREATE INDEX IF NOT EXISTS idx_usage_events_task_id
+		 ON usage_events (task_id)
+		 WHERE task_id IS NOT NULL`,
+	}
+
+	for _, sql := range indexes {
+		if err := db.Exec(sql).Error; err != nil {
+			return fmt.Errorf("failed to create usage index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createOccurrenceIndexes 创建出现记录相关索引
+func createOccurrenceIndexes(db *gorm.DB) error {
+	indexes := []string{
+		// 按文章查看出现历史 (按时间倒序)
+		`CREATE INDEX IF NOT EXISTS idx_article_occurrences_article_seen
+		 ON article_occurrences (article_id, seen_at DESC)`,
+
+		// 按时间窗口统计
+		`CREATE INDEX IF NOT EXISTS idx_article_occurrences_seen_at
+		 ON article_occurrences (seen_at)`,
+
+		// 同一任务的同一行只入库一次，任务重试时不会重复插入文章和出现记录
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_article_occurrences_task_line
+		 ON article_occurrences (task_id, line_no)
+		 WHERE task_id IS NOT NULL AND line_no IS NOT NULL`,
+	}
+
+	for _, sql := range indexes {
+		if err := db.Exec(sql).Error; err != nil {
+			return fmt.Errorf("failed to create occurrence index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createReviewIndexes 创建复习卡片相关索引
+func createReviewIndexes(db *gorm.DB) error {
+	indexes := []string{
+		// 按用户查询到期卡片
+		`CREATE INDEX IF NOT EXISTS idx_review_cards_user_due
+		 ON review_cards (user_name, due_at)`,
+	}
+
+	for _, sql := range indexes {
+		if err := db.Exec(sql).Error; err != nil {
+			return fmt.Errorf("failed to create review index: %w", err)
+		}
+	}
+
+	return nil
+}
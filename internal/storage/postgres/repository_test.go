@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestRepository 在 PAGUU_TEST_DSN 指向的数据库中创建一个临时 schema 并在其中初始化 Repository，
+// 测试结束后删除该 schema；未设置 PAGUU_TEST_DSN 时跳过测试
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+	dsn := os.Getenv("PAGUU_TEST_DSN")
+	if dsn == "" {
+		t.Skip("PAGUU_TEST_DSN not set")
+	}
+
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	schema := fmt.Sprintf("paguu_test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	repo, err := NewRepository(withSearchPath(dsn, schema))
+	if err != nil {
+		t.Fatalf("NewRepository: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := repo.db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return repo
+}
+
+// withSearchPath 将连接的 search_path 设为 schema (vector 扩展仍在 public 中)
+func withSearchPath(dsn, schema string) string {
+	searchPath := schema + ",public"
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err == nil {
+			q := u.Query()
+			q.Set("search_path", searchPath)
+			u.RawQuery = q.Encode()
+			return u.String()
+		}
+	}
+	return dsn + " search_path=" + searchPath
+}
@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"paguu/internal/enrich"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 疑似重复审核状态
+const (
+	DuplicateReviewPending   = "pending"
+	DuplicateReviewMerged    = "merged"
+	DuplicateReviewDismissed = "dismissed"
+)
+
+var (
+	// ErrReviewNotFound 表示审核记录不存在
+	ErrReviewNotFound = notFoundError("review_not_found", "duplicate review not found")
+	// ErrReviewResolved 表示审核记录已被处理过
+	ErrReviewResolved = conflictError("review_resolved", "duplicate review already resolved")
+)
+
+// DuplicateReviewItem 是带有双方文章内容的审核记录
+type DuplicateReviewItem struct {
+	DuplicateReview
+	Article   *Article
+	Candidate *Article
+}
+
+// ListDuplicateReviews 按状态分页列出审核记录 (按 ID 降序)，并附带双方文章
+func (r *Repository) ListDuplicateReviews(ctx context.Context, status string, limit, offset int) ([]DuplicateReviewItem, int64, error) {
+	var reviews []DuplicateReview
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&DuplicateReview{}).Scopes(inWorkspace("workspace_id"))
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count duplicate reviews: %w", err)
+	}
+
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&reviews).Error
+	if err != nil {
+		return nil, total, fmt.Errorf("failed to list duplicate reviews: %w", err)
+	}
+
+	// 批量加载双方文章 (已合并的文章可能已被删除)
+	ids := make([]uint, 0, len(reviews)*2)
+	for _, review := range reviews {
+		ids = append(ids, review.ArticleID, review.CandidateID)
+	}
+	var articles []Article
+	if len(ids) > 0 {
+		if err := r.db.WithContext(ctx).Omit("embedding").Where("id IN ?", ids).Find(&articles).Error; err != nil {
+			return nil, total, fmt.Errorf("failed to load review articles: %w", err)
+		}
+	}
+	byID := make(map[uint]*Article, len(articles))
+	for i := range articles {
+		byID[articles[i].ID] = &articles[i]
+	}
+
+	items := make([]DuplicateReviewItem, len(reviews))
+	for i, review := range reviews {
+		items[i] = DuplicateReviewItem{
+			DuplicateReview: review,
+			Article:         byID[review.ArticleID],
+			Candidate:       byID[review.CandidateID],
+		}
+	}
+	return items, total, nil
+}
+
+// lockPendingReview 在事务中锁定当前工作区内一条 pending 状态的审核记录
+func lockPendingReview(tx *gorm.DB, reviewID uint) (*DuplicateReview, error) {
+	var review DuplicateReview
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(inWorkspace("workspace_id")).First(&review, reviewID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	if review.Status != DuplicateReviewPending {
+		return nil, ErrReviewResolved
+	}
+	return &review, nil
+}
+
+// ConfirmDuplicateReview 确认疑似重复：将新文章合并进最近邻 (进入 Ext，tags 取并集)，删除新文章并留下重定向
+// 与被删除文章相关的其他待审核记录一并驳回
+func (r *Repository) ConfirmDuplicateReview(ctx context.Context, reviewID uint) (*DuplicateReview, error) {
+	var result *DuplicateReview
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		review, err := lockPendingReview(tx, reviewID)
+		if err != nil {
+			return err
+		}
+
+		// mergeArticles 会将本条审核记录标记为 merged，并驳回其他相关记录
+		if _, err := mergeArticles(tx, review.CandidateID, []uint{review.ArticleID}, "review"); err != nil {
+			return fmt.Errorf("failed to merge article %d into %d: %w", review.ArticleID, review.CandidateID, err)
+		}
+
+		if err := tx.First(review, review.ID).Error; err != nil {
+			return err
+		}
+		result = review
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DismissDuplicateReview 驳回疑似重复标记，两篇文章都保留
+func (r *Repository) DismissDuplicateReview(ctx context.Context, reviewID uint) (*DuplicateReview, error) {
+	var result *DuplicateReview
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		review, err := lockPendingReview(tx, reviewID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(review).Updates(map[string]interface{}{
+			"status":      DuplicateReviewDismissed,
+			"resolved_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		review.Status = DuplicateReviewDismissed
+		review.ResolvedAt = &now
+		result = review
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// articleToQuestion 将文章还原为 InterviewQuestion，用于合并进其他文章的 Ext
+func articleToQuestion(article Article) enrich.InterviewQuestion {
+	q := enrich.InterviewQuestion{
+		OriginalQuestion: article.OriginalQuestion,
+		Tags:             article.Tags,
+	}
+	if article.DetailedQuestion != nil {
+		q.DetailedQuestion = *article.DetailedQuestion
+	}
+	if article.ConciseAnswer != nil {
+		q.ConciseAnswer = *article.ConciseAnswer
+	}
+	return q
+}
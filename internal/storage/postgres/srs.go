@@ -0,0 +1,309 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"paguu/internal/srs"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ===================================================================
+// 间隔重复复习
+// 标签订阅按工作区区分；卡片跟随文章，列表和统计只包含当前工作区的文章
+// ===================================================================
+
+// ListTagSubscriptions 返回用户订阅的标签，按标签名排序
+func (r *Repository) ListTagSubscriptions(ctx context.Context, userName string) ([]string, error) {
+	tags := make([]string, 0)
+	err := r.db.WithContext(ctx).Model(&TagSubscription{}).
+		Scopes(inWorkspace("workspace_id")).
+		Where("user_name = ?", userName).
+		Order("tag ASC").
+		Pluck("tag", &tags).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tag subscriptions: %w", err)
+	}
+	return tags, nil
+}
+
+// SubscribeTags 订阅标签 (应为规范名)，enroll 为 true 时把已有的带这些标签的文章加入卡组
+// 返回新加入卡组的卡片数
+func (r *Repository) SubscribeTags(ctx context.Context, userName string, tags []string, enroll bool) (int64, error) {
+	if len(tags) == 0 {
+		return 0, nil
+	}
+
+	workspaceID, err := requireWorkspace(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var enrolled int64
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		subscriptions := make([]TagSubscription, len(tags))
+		for i, tag := range tags {
+			subscriptions[i] = TagSubscription{WorkspaceID: workspaceID, UserName: userName, Tag: tag}
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&subscriptions).Error; err != nil {
+			return fmt.Errorf("failed to create tag subscriptions: %w", err)
+		}
+		if !enroll {
+			return nil
+		}
+
+		result := tx.Exec(`
+			INSERT INTO review_cards (user_name, article_id, ease, interval_days, repetitions, lapses, due_at, created_at, updated_at)
+			SELECT ?, a.id, ?, 0, 0, 0, NOW(), NOW(), NOW()
+			FROM articles a
+			WHERE a.workspace_id = ? AND a.tags && ?
+			ON CONFLICT (user_name, article_id) DO NOTHING
+		`, userName, srs.DefaultEase, WorkspaceFrom(ctx), pq.Array(tags))
+		if result.Error != nil {
+			return fmt.Errorf("failed to enroll subscribed articles: %w", result.Error)
+		}
+		enrolled = result.RowsAffected
+		return nil
+	})
+	return enrolled, err
+}
+
+// UnsubscribeTags 取消订阅标签，已加入卡组的卡片保留
+func (r *Repository) UnsubscribeTags(ctx context.Context, userName string, tags []string) (int64, error) {
+	if len(tags) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).
+		Scopes(inWorkspace("workspace_id")).
+		Where("user_name = ? AND tag IN ?", userName, tags).
+		Delete(&TagSubscription{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete tag subscriptions: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// enrollArticle 把新文章加入在当前工作区订阅了其任一标签的用户的卡组，db 可以是事务
+func enrollArticle(db *gorm.DB, articleID uint, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	err := db.Exec(`
+		INSERT INTO review_cards (user_name, article_id, ease, interval_days, repetitions, lapses, due_at, created_at, updated_at)
+		SELECT DISTINCT user_name, ?, ?, 0, 0, 0, NOW(), NOW(), NOW()
+		FROM tag_subscriptions
+		WHERE workspace_id = ? AND tag = ANY(?)
+		ON CONFLICT (user_name, article_id) DO NOTHING
+	`, articleID, srs.DefaultEase, workspaceOf(db), pq.Array(tags)).Error
+	if err != nil {
+		return fmt.Errorf("failed to enroll article %d: %w", articleID, err)
+	}
+	return nil
+}
+
+// moveReviewCards 把来源文章的复习卡片归入目标文章，db 应为事务
+// 同一用户在目标 (或多个来源) 上已有卡片时保留目标上的卡片，否则复制最早到期的一张
+// 返回 来源卡片 ID -> 由它复制到目标文章的卡片 ID，拆分时据此把卡片还给来源文章
+func moveReviewCards(db *gorm.DB, sourceIDs []uint, targetID uint) (map[uint]uint, error) {
+	var cards []ReviewCard
+	if err := db.Where("article_id IN ?", sourceIDs).Order("user_name, due_at ASC, id ASC").Find(&cards).Error; err != nil {
+		return nil, fmt.Errorf("failed to load merged review cards: %w", err)
+	}
+
+	copied := make(map[uint]uint)
+	for i, card := range cards {
+		if i > 0 && cards[i-1].UserName == card.UserName {
+			continue
+		}
+		moved := ReviewCard{
+			UserName:       card.UserName,
+			ArticleID:      targetID,
+			Ease:           card.Ease,
+			IntervalDays:   card.IntervalDays,
+			Repetitions:    card.Repetitions,
+			Lapses:         card.Lapses,
+			DueAt:          card.DueAt,
+			LastReviewedAt: card.LastReviewedAt,
+			CreatedAt:      card.CreatedAt,
+		}
+		result := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_name"}, {Name: "article_id"}},
+			DoNothing: true,
+		}).Create(&moved)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to move review cards: %w", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			copied[card.ID] = moved.ID
+		}
+	}
+
+	if err := db.Where("article_id IN ?", sourceIDs).Delete(&ReviewCard{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete merged review cards: %w", err)
+	}
+	if err := db.Model(&ReviewLog{}).Where("article_id IN ?", sourceIDs).Update("article_id", targetID).Error; err != nil {
+		return nil, fmt.Errorf("failed to move review logs: %w", err)
+	}
+	return copied, nil
+}
+
+// DueCardsOptions 是到期卡片查询参数
+type DueCardsOptions struct {
+	UserName string
+	Tags     []string  // 只返回带有这些标签之一的文章，为空时不限制
+	Now      time.Time // 零值表示当前时间
+	Limit    int
+}
+
+// DueCard 是一张到期卡片及其文章
+type DueCard struct {
+	Card    ReviewCard
+	Article Article
+}
+
+// ListDueCards 按到期时间返回用户已到期的卡片，以及符合条件的到期卡片总数
+func (r *Repository) ListDueCards(ctx context.Context, opts DueCardsOptions) ([]DueCard, int64, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	query := r.db.WithContext(ctx).Model(&ReviewCard{}).
+		Joins("JOIN articles a ON a.id = review_cards.article_id").
+		Scopes(inWorkspace("a.workspace_id")).
+		Where("review_cards.user_name = ? AND review_cards.due_at <= ?", opts.UserName, now)
+	if len(opts.Tags) > 0 {
+		query = query.Where("a.tags && ?", pq.Array(opts.Tags))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count due cards: %w", err)
+	}
+
+	var cards []ReviewCard
+	err := query.Select("review_cards.*").
+		Order("review_cards.due_at ASC, review_cards.id ASC").
+		Limit(opts.Limit).
+		Find(&cards).Error
+	if err != nil {
+		return nil, total, fmt.Errorf("failed to list due cards: %w", err)
+	}
+	if len(cards) == 0 {
+		return []DueCard{}, total, nil
+	}
+
+	articleIDs := make([]uint, len(cards))
+	for i, c := range cards {
+		articleIDs[i] = c.ArticleID
+	}
+	var articles []Article
+	err = r.db.WithContext(ctx).
+		Omit("embedding").
+		Where("id IN ?", articleIDs).
+		Find(&articles).Error
+	if err != nil {
+		return nil, total, fmt.Errorf("failed to load due articles: %w", err)
+	}
+	byID := make(map[uint]Article, len(articles))
+	for _, a := range articles {
+		byID[a.ID] = a
+	}
+
+	due := make([]DueCard, len(cards))
+	for i, c := range cards {
+		due[i] = DueCard{Card: c, Article: byID[c.ArticleID]}
+	}
+	return due, total, nil
+}
+
+// RecordReview 记录一次复习并按 SM-2 更新卡片，文章不在用户卡组中时先加入
+func (r *Repository) RecordReview(ctx context.Context, userName string, articleID uint, rating srs.Rating, now time.Time) (*ReviewCard, error) {
+	var card ReviewCard
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var exists int64
+		if err := tx.Model(&Article{}).Scopes(inWorkspace("workspace_id")).Where("id = ?", articleID).Count(&exists).Error; err != nil {
+			return fmt.Errorf("failed to check article: %w", err)
+		}
+		if exists == 0 {
+			return fmt.Errorf("%w: %d", ErrArticleNotFound, articleID)
+		}
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_name = ? AND article_id = ?", userName, articleID).
+			First(&card).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to get review card: %w", err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			card = ReviewCard{UserName: userName, ArticleID: articleID, Ease: srs.DefaultEase, DueAt: now}
+		}
+
+		state, dueAt := srs.Schedule(srs.State{
+			Ease:        card.Ease,
+			Interval:    card.IntervalDays,
+			Repetitions: card.Repetitions,
+			Lapses:      card.Lapses,
+		}, rating, now)
+		card.Ease = state.Ease
+		card.IntervalDays = state.Interval
+		card.Repetitions = state.Repetitions
+		card.Lapses = state.Lapses
+		card.DueAt = dueAt
+		card.LastReviewedAt = &now
+
+		if err := tx.Save(&card).Error; err != nil {
+			return fmt.Errorf("failed to save review card: %w", err)
+		}
+		return tx.Create(&ReviewLog{
+			UserName:     userName,
+			ArticleID:    articleID,
+			Rating:       string(rating),
+			Ease:         card.Ease,
+			IntervalDays: card.IntervalDays,
+			DueAt:        card.DueAt,
+			ReviewedAt:   now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// ReviewStats 汇总用户的卡组情况
+type ReviewStats struct {
+	Total    int64 `json:"total"`    // 卡组中的卡片数
+	Due      int64 `json:"due"`      // 当前已到期
+	New      int64 `json:"new"`      // 从未复习过
+	Reviewed int64 `json:"reviewed"` // 今天 (最近 24 小时) 复习次数
+}
+
+// GetReviewStats 返回用户在当前工作区的卡片数、到期数、新卡片数和最近 24 小时的复习次数
+func (r *Repository) GetReviewStats(ctx context.Context, userName string) (*ReviewStats, error) {
+	var stats ReviewStats
+	now := time.Now()
+	err := r.db.WithContext(ctx).Model(&ReviewCard{}).
+		Select(`COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE due_at <= ?) AS due,
+			COUNT(*) FILTER (WHERE last_reviewed_at IS NULL) AS new`, now).
+		Joins("JOIN articles a ON a.id = review_cards.article_id").
+		Scopes(inWorkspace("a.workspace_id")).
+		Where("review_cards.user_name = ?", userName).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review stats: %w", err)
+	}
+	err = r.db.WithContext(ctx).Model(&ReviewLog{}).
+		Joins("JOIN articles a ON a.id = review_logs.article_id").
+		Scopes(inWorkspace("a.workspace_id")).
+		Where("review_logs.user_name = ? AND review_logs.reviewed_at >= ?", userName, now.Add(-24*time.Hour)).
+		Count(&stats.Reviewed).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reviews: %w", err)
+	}
+	return &stats, nil
+}
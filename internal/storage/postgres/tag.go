@@ -0,0 +1,526 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ===================================================================
+// 标签体系 (规范名、别名、层级)
+// 每个工作区有独立的标签体系，以下操作都限定在 context 中的工作区内
+// ===================================================================
+
+var (
+	// ErrTagNotFound 表示标签 (或其任一别名) 不存在
+	ErrTagNotFound = notFoundError("tag_not_found", "tag not found")
+	// ErrTagConflict 表示名称或别名已属于另一个标签
+	ErrTagConflict = conflictError("tag_conflict", "tag conflict")
+	// ErrInvalidTag 表示标签操作不合法 (空名称、合并到自身、层级成环等)
+	ErrInvalidTag = invalidError("invalid_tag", "invalid tag operation")
+)
+
+// NormalizeTagKey 生成标签的匹配 key：转为小写，并去掉空白、'-' 和 '_'
+// 例如 "MySQL"、"mysql"、"My SQL" 都得到 "mysql"；"message-queue" 与 "Message Queue" 相同
+func NormalizeTagKey(tag string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(tag) {
+		if unicode.IsSpace(r) || r == '-' || r == '_' {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// NormalizeTags 将标签映射为规范名，按首次出现的顺序去重
+// 未登记的标签会以当前写法登记为新的规范标签
+func (r *Repository) NormalizeTags(ctx context.Context, tags []string) ([]string, error) {
+	return resolveTags(r.db.WithContext(ctx), tags)
+}
+
+// resolveTags 是 NormalizeTags 的实现，db 可以是事务
+func resolveTags(db *gorm.DB, tags []string) ([]string, error) {
+	keys := make([]string, 0, len(tags))
+	spelling := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := NormalizeTagKey(tag)
+		if key == "" {
+			continue
+		}
+		if _, ok := spelling[key]; !ok {
+			spelling[key] = tag
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return []string{}, nil
+	}
+
+	canonical, err := lookupTagKeys(db, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if _, ok := canonical[key]; ok {
+			continue
+		}
+		name, err := registerTag(db, spelling[key])
+		if err != nil {
+			return nil, err
+		}
+		canonical[key] = name
+	}
+
+	// 不同写法可能指向同一个规范名，再去重一次
+	result := make([]string, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		name := canonical[key]
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+// CanonicalizeTags 将查询参数中的标签映射为规范名，不登记新标签；未登记的标签原样保留
+func (r *Repository) CanonicalizeTags(ctx context.Context, tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return tags, nil
+	}
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = NormalizeTagKey(tag)
+	}
+	canonical, err := lookupTagKeys(r.db.WithContext(ctx), keys)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, len(tags))
+	for i, tag := range tags {
+		if name, ok := canonical[keys[i]]; ok {
+			result[i] = name
+		} else {
+			result[i] = tag
+		}
+	}
+	return result, nil
+}
+
+// lookupTagKeys 批量查询 key 对应的规范名，未登记的 key 不在结果中
+func lookupTagKeys(db *gorm.DB, keys []string) (map[string]string, error) {
+	var rows []struct {
+		Key  string
+		Name string
+	}
+	err := db.Table("tag_aliases").
+		Select("tag_aliases.key, tags.name").
+		Joins("JOIN tags ON tags.id = tag_aliases.tag_id").
+		Scopes(inWorkspace("tag_aliases.workspace_id")).
+		Where("tag_aliases.key IN ?", keys).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up tag aliases: %w", err)
+	}
+
+	canonical := make(map[string]string, len(rows))
+	for _, row := range rows {
+		canonical[row.Key] = row.Name
+	}
+	return canonical, nil
+}
+
+// registerTag 以 name 登记一个新的规范标签并返回最终的规范名
+// 并发登记同一 key 时以先写入别名者为准，落败方创建的标签会被删除
+func registerTag(db *gorm.DB, name string) (string, error) {
+	key := NormalizeTagKey(name)
+
+	workspaceID, err := requireWorkspace(db.Statement.Context)
+	if err != nil {
+		return "", err
+	}
+	tag := Tag{WorkspaceID: workspaceID, Name: name}
+	err = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to create tag: %w", err)
+	}
+	created := tag.ID != 0
+	if !created {
+		// 同名标签已存在 (例如只是别名被删除)，直接复用
+		if err := db.Scopes(inWorkspace("workspace_id")).Where("name = ?", name).First(&tag).Error; err != nil {
+			return "", fmt.Errorf("failed to load tag: %w", err)
+		}
+	}
+
+	alias := TagAlias{WorkspaceID: tag.WorkspaceID, Key: key, Alias: name, TagID: tag.ID}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&alias).Error; err != nil {
+		return "", fmt.Errorf("failed to create tag alias: %w", err)
+	}
+
+	canonical, err := lookupTagKeys(db, []string{key})
+	if err != nil {
+		return "", err
+	}
+	winner, ok := canonical[key]
+	if !ok {
+		return "", fmt.Errorf("tag alias %q disappeared after insert", key)
+	}
+	if created && winner != name {
+		if err := db.Delete(&Tag{}, tag.ID).Error; err != nil {
+			return "", fmt.Errorf("failed to delete duplicate tag: %w", err)
+		}
+	}
+	return winner, nil
+}
+
+// findTag 按名称或任一别名查找标签
+func findTag(db *gorm.DB, name string) (*Tag, error) {
+	key := NormalizeTagKey(name)
+	if key == "" {
+		return nil, fmt.Errorf("%w: empty tag name", ErrInvalidTag)
+	}
+	var tag Tag
+	err := db.Joins("JOIN tag_aliases ON tag_aliases.tag_id = tags.id").
+		Scopes(inWorkspace("tags.workspace_id")).
+		Where("tag_aliases.key = ?", key).
+		First(&tag).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrTagNotFound, name)
+		}
+		return nil, fmt.Errorf("failed to find tag: %w", err)
+	}
+	return &tag, nil
+}
+
+// replaceArticleTag 将所有文章中的 from 替换为 to；已包含 to 的文章直接移除 from，避免重复
+// 标签订阅随之改指 to
+func replaceArticleTag(db *gorm.DB, from, to string) (int64, error) {
+	result := db.Model(&Article{}).
+		Scopes(inWorkspace("workspace_id")).
+		Where("tags @> ?", pq.Array([]string{from})).
+		UpdateColumns(map[string]interface{}{
+			"tags": gorm.Expr(
+				"CASE WHEN tags @> ? THEN array_remove(tags, ?) ELSE array_replace(tags, ?, ?) END",
+				pq.Array([]string{to}), from, from, to,
+			),
+			"updated_at": gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to rewrite article tags: %w", result.Error)
+	}
+
+	err := db.Exec(`
+		INSERT INTO tag_subscriptions (workspace_id, user_name, tag, created_at)
+		SELECT workspace_id, user_name, ?, created_at FROM tag_subscriptions WHERE workspace_id = ? AND tag = ?
+		ON CONFLICT (workspace_id, user_name, tag) DO NOTHING
+	`, to, workspaceOf(db), from).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to rewrite tag subscriptions: %w", err)
+	}
+	if err := db.Scopes(inWorkspace("workspace_id")).Where("tag = ?", from).Delete(&TagSubscription{}).Error; err != nil {
+		return 0, fmt.Errorf("failed to delete old tag subscriptions: %w", err)
+	}
+	return result.RowsAffected, nil
+}
+
+// NormalizeArticleTags 登记所有文章中尚未登记的标签，并将 Article.Tags 改写为规范名
+// 返回被改写的文章数
+func (r *Repository) NormalizeArticleTags(ctx context.Context) (int64, error) {
+	return normalizeArticleTags(r.db.WithContext(ctx))
+}
+
+func normalizeArticleTags(db *gorm.DB) (int64, error) {
+	// 1. 按使用次数从多到少登记，同一 key 的多种写法以最常用者为规范名
+	var used []string
+	err := db.Raw(`
+		SELECT tag
+		FROM articles, unnest(tags) AS tag
+		WHERE articles.workspace_id = ?
+		GROUP BY tag
+		ORDER BY COUNT(*) DESC, tag ASC
+	`, workspaceOf(db)).Scan(&used).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to collect article tags: %w", err)
+	}
+	if _, err := resolveTags(db, used); err != nil {
+		return 0, err
+	}
+
+	canonical := make(map[string]string)
+	var aliases []struct {
+		Key  string
+		Name string
+	}
+	err = db.Table("tag_aliases").
+		Select("tag_aliases.key, tags.name").
+		Joins("JOIN tags ON tags.id = tag_aliases.tag_id").
+		Scopes(inWorkspace("tag_aliases.workspace_id")).
+		Scan(&aliases).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load tag aliases: %w", err)
+	}
+	for _, a := range aliases {
+		canonical[a.Key] = a.Name
+	}
+
+	// 2. 分批改写需要变化的文章
+	var updated int64
+	var batch []Article
+	err = db.Model(&Article{}).Scopes(inWorkspace("workspace_id")).Select("id", "tags").FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+		for _, article := range batch {
+			tags := make(pq.StringArray, 0, len(article.Tags))
+			seen := make(map[string]bool, len(article.Tags))
+			for _, tag := range article.Tags {
+				name, ok := canonical[NormalizeTagKey(tag)]
+				if !ok {
+					continue
+				}
+				if !seen[name] {
+					seen[name] = true
+					tags = append(tags, name)
+				}
+			}
+			if slices.Equal(tags, article.Tags) {
+				continue
+			}
+			if err := db.Model(&Article{}).Where("id = ?", article.ID).UpdateColumn("tags", tags).Error; err != nil {
+				return fmt.Errorf("failed to rewrite tags of article %d: %w", article.ID, err)
+			}
+			updated++
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return updated, err
+	}
+	return updated, nil
+}
+
+// TagInfo 是标签体系中的一个标签
+type TagInfo struct {
+	Name    string   `json:"name"`
+	Parent  *string  `json:"parent,omitempty"`
+	Aliases []string `json:"aliases"` // 除规范名外登记过的写法
+}
+
+// ListTagTaxonomy 返回所有规范标签及其别名和上级标签，按名称排序
+func (r *Repository) ListTagTaxonomy(ctx context.Context) ([]TagInfo, error) {
+	var tags []Tag
+	if err := r.db.WithContext(ctx).Scopes(inWorkspace("workspace_id")).Order("name ASC").Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	var aliases []TagAlias
+	if err := r.db.WithContext(ctx).Scopes(inWorkspace("workspace_id")).Order("alias ASC").Find(&aliases).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tag aliases: %w", err)
+	}
+
+	names := make(map[uint]string, len(tags))
+	for _, t := range tags {
+		names[t.ID] = t.Name
+	}
+	aliasesByTag := make(map[uint][]string)
+	for _, a := range aliases {
+		if name, ok := names[a.TagID]; ok && a.Key != NormalizeTagKey(name) {
+			aliasesByTag[a.TagID] = append(aliasesByTag[a.TagID], a.Alias)
+		}
+	}
+
+	infos := make([]TagInfo, len(tags))
+	for i, t := range tags {
+		infos[i] = TagInfo{Name: t.Name, Aliases: aliasesByTag[t.ID]}
+		if infos[i].Aliases == nil {
+			infos[i].Aliases = []string{}
+		}
+		if t.ParentID != nil {
+			if parent, ok := names[*t.ParentID]; ok {
+				infos[i].Parent = &parent
+			}
+		}
+	}
+	return infos, nil
+}
+
+// CanonicalTags 返回最常用的 limit 个规范标签，用于引导 LLM 复用已有标签
+func (r *Repository) CanonicalTags(ctx context.Context, limit int) ([]string, error) {
+	var names []string
+	workspaceID := WorkspaceFrom(ctx)
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT t.name
+		FROM tags t
+		LEFT JOIN (
+			SELECT tag, COUNT(*) AS cnt
+			FROM articles, unnest(tags) AS tag
+			WHERE articles.workspace_id = ?
+			GROUP BY tag
+		) u ON u.tag = t.name
+		WHERE t.workspace_id = ?
+		ORDER BY COALESCE(u.cnt, 0) DESC, t.name ASC
+		LIMIT ?
+	`, workspaceID, workspaceID, limit).Scan(&names).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list canonical tags: %w", err)
+	}
+	return names, nil
+}
+
+// MergeTags 将 source 标签合并到 target：source 的所有别名归入 target，
+// 下级标签改挂到 target，文章中的 source 替换为 target，最后删除 source
+// 返回被改写的文章数
+func (r *Repository) MergeTags(ctx context.Context, source, target string) (int64, error) {
+	var updated int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		src, err := findTag(tx.Clauses(clause.Locking{Strength: "UPDATE"}), source)
+		if err != nil {
+			return err
+		}
+		dst, err := findTag(tx.Clauses(clause.Locking{Strength: "UPDATE"}), target)
+		if err != nil {
+			return err
+		}
+		if src.ID == dst.ID {
+			return fmt.Errorf("%w: %q and %q are the same tag", ErrInvalidTag, source, target)
+		}
+
+		if err := tx.Model(&TagAlias{}).Where("tag_id = ?", src.ID).Update("tag_id", dst.ID).Error; err != nil {
+			return fmt.Errorf("failed to move tag aliases: %w", err)
+		}
+		// target 原本挂在 source 下时，改挂到 source 的上级，避免自环
+		if dst.ParentID != nil && *dst.ParentID == src.ID {
+			if err := tx.Model(dst).Update("parent_id", src.ParentID).Error; err != nil {
+				return fmt.Errorf("failed to update tag parent: %w", err)
+			}
+		}
+		if err := tx.Model(&Tag{}).Where("parent_id = ? AND id <> ?", src.ID, dst.ID).Update("parent_id", dst.ID).Error; err != nil {
+			return fmt.Errorf("failed to move child tags: %w", err)
+		}
+		if err := tx.Delete(&Tag{}, src.ID).Error; err != nil {
+			return fmt.Errorf("failed to delete merged tag: %w", err)
+		}
+
+		updated, err = replaceArticleTag(tx, src.Name, dst.Name)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+// RenameTag 修改标签的规范名，旧名称保留为别名；文章中的旧名称同步替换
+// 新名称已属于另一个标签时返回 ErrTagConflict (应改用 MergeTags)
+func (r *Repository) RenameTag(ctx context.Context, from, to string) (int64, error) {
+	to = strings.TrimSpace(to)
+	key := NormalizeTagKey(to)
+	if key == "" {
+		return 0, fmt.Errorf("%w: empty tag name", ErrInvalidTag)
+	}
+
+	var updated int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tag, err := findTag(tx.Clauses(clause.Locking{Strength: "UPDATE"}), from)
+		if err != nil {
+			return err
+		}
+		if tag.Name == to {
+			return nil
+		}
+
+		existing, err := findTag(tx, to)
+		if err != nil && !errors.Is(err, ErrTagNotFound) {
+			return err
+		}
+		if existing != nil && existing.ID != tag.ID {
+			return fmt.Errorf("%w: %q already belongs to tag %q", ErrTagConflict, to, existing.Name)
+		}
+
+		oldName := tag.Name
+		if err := tx.Model(tag).Update("name", to).Error; err != nil {
+			return fmt.Errorf("failed to rename tag: %w", err)
+		}
+		alias := TagAlias{WorkspaceID: tag.WorkspaceID, Key: key, Alias: to, TagID: tag.ID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&alias).Error; err != nil {
+			return fmt.Errorf("failed to create tag alias: %w", err)
+		}
+
+		updated, err = replaceArticleTag(tx, oldName, to)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+// AddTagAlias 为标签登记一个别名，别名已属于该标签时不做任何事
+func (r *Repository) AddTagAlias(ctx context.Context, name, alias string) error {
+	alias = strings.TrimSpace(alias)
+	key := NormalizeTagKey(alias)
+	if key == "" {
+		return fmt.Errorf("%w: empty alias", ErrInvalidTag)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tag, err := findTag(tx, name)
+		if err != nil {
+			return err
+		}
+		existing, err := findTag(tx, alias)
+		if err != nil && !errors.Is(err, ErrTagNotFound) {
+			return err
+		}
+		if existing != nil {
+			if existing.ID == tag.ID {
+				return nil
+			}
+			return fmt.Errorf("%w: %q already belongs to tag %q", ErrTagConflict, alias, existing.Name)
+		}
+		if err := tx.Create(&TagAlias{WorkspaceID: tag.WorkspaceID, Key: key, Alias: alias, TagID: tag.ID}).Error; err != nil {
+			return fmt.Errorf("failed to create tag alias: %w", err)
+		}
+		return nil
+	})
+}
+
+// SetTagParent 设置标签的上级标签，parent 为 nil 时移除上级
+func (r *Repository) SetTagParent(ctx context.Context, name string, parent *string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tag, err := findTag(tx.Clauses(clause.Locking{Strength: "UPDATE"}), name)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return tx.Model(tag).Update("parent_id", nil).Error
+		}
+
+		parentTag, err := findTag(tx, *parent)
+		if err != nil {
+			return err
+		}
+
+		// 沿上级链向上检查，避免成环
+		for id := &parentTag.ID; id != nil; {
+			if *id == tag.ID {
+				return fmt.Errorf("%w: %q is an ancestor of %q", ErrInvalidTag, tag.Name, parentTag.Name)
+			}
+			var ancestor Tag
+			if err := tx.Select("id", "parent_id").First(&ancestor, *id).Error; err != nil {
+				return fmt.Errorf("failed to load tag ancestor: %w", err)
+			}
+			id = ancestor.ParentID
+		}
+
+		return tx.Model(tag).Update("parent_id", parentTag.ID).Error
+	})
+}
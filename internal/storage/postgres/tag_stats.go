@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ===================================================================
+// 标签统计
+// ===================================================================
+
+// TagStat 是单个标签的统计信息
+type TagStat struct {
+	Name            string    `json:"name"`
+	ArticleCount    int64     `json:"article_count"`    // 带有该标签的文章数
+	OccurrenceCount int64     `json:"occurrence_count"` // 这些文章被提问的总次数
+	NewestArticleAt time.Time `json:"newest_article_at"`
+}
+
+// GetTagStats 返回工作区内每个标签的文章数、出现次数和最新文章时间，按文章数降序
+func (r *Repository) GetTagStats(ctx context.Context) ([]TagStat, error) {
+	stats := make([]TagStat, 0)
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT t.tag AS name,
+		       COUNT(*) AS article_count,
+		       COALESCE(SUM(oc.cnt), 0) AS occurrence_count,
+		       MAX(a.created_at) AS newest_article_at
+		FROM articles a
+		CROSS JOIN LATERAL unnest(a.tags) AS t(tag)
+		LEFT JOIN (
+			SELECT article_id, COUNT(*) AS cnt
+			FROM article_occurrences
+			GROUP BY article_id
+		) oc ON oc.article_id = a.id
+		WHERE a.workspace_id = ?
+		GROUP BY t.tag
+		ORDER BY article_count DESC, name ASC
+	`, WorkspaceFrom(ctx)).Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute tag stats: %w", err)
+	}
+	return stats, nil
+}
+
+// TagPair 是两个标签同时出现的文章数，A < B
+type TagPair struct {
+	A     string `json:"a"`
+	B     string `json:"b"`
+	Count int64  `json:"count"`
+}
+
+// GetTagCooccurrence 统计标签两两同时出现的文章数，只返回 Count >= minCount 的组合
+// tags 非空时只统计这些标签之间的组合，并通过 GIN 索引 (&&) 只扫描相关文章
+func (r *Repository) GetTagCooccurrence(ctx context.Context, tags []string, minCount int64) ([]TagPair, error) {
+	query := r.db.WithContext(ctx).
+		Table("articles art").
+		Select("t1.tag AS a, t2.tag AS b, COUNT(*) AS count").
+		Joins("CROSS JOIN LATERAL unnest(art.tags) AS t1(tag)").
+		Joins("CROSS JOIN LATERAL unnest(art.tags) AS t2(tag)").
+		Scopes(inWorkspace("art.workspace_id")).
+		Where("t1.tag < t2.tag")
+	if len(tags) > 0 {
+		query = query.
+			Where("art.tags && ?", pq.Array(tags)).
+			Where("t1.tag = ANY(?) AND t2.tag = ANY(?)", pq.Array(tags), pq.Array(tags))
+	}
+
+	pairs := make([]TagPair, 0)
+	err := query.
+		Group("t1.tag, t2.tag").
+		Having("COUNT(*) >= ?", max(minCount, 1)).
+		Order("count DESC, a ASC, b ASC").
+		Scan(&pairs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute tag co-occurrence: %w", err)
+	}
+	return pairs, nil
+}
+
+// TagGraphNode 是标签图中的节点
+type TagGraphNode struct {
+	TagStat
+	Parent *string `json:"parent,omitempty"`
+}
+
+// 标签图的边类型
+const (
+	TagEdgeCooccurrence = "cooccurrence" // 两个标签出现在同一篇文章中，Weight 为文章数
+	TagEdgeParent       = "parent"       // Source 是 Target 的上级标签
+)
+
+// TagGraphEdge 是标签图中的边
+type TagGraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+	Weight int64  `json:"weight"`
+}
+
+// TagGraph 是用于可视化的标签图
+type TagGraph struct {
+	Nodes []TagGraphNode `json:"nodes"`
+	Edges []TagGraphEdge `json:"edges"`
+}
+
+// GetTagGraph 返回文章数最多的 nodeLimit 个标签及它们之间的共现边和层级边
+// 共现边只保留 Weight >= minWeight 的组合
+func (r *Repository) GetTagGraph(ctx context.Context, nodeLimit int, minWeight int64) (*TagGraph, error) {
+	stats, err := r.GetTagStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if nodeLimit > 0 && len(stats) > nodeLimit {
+		stats = stats[:nodeLimit]
+	}
+
+	graph := &TagGraph{
+		Nodes: make([]TagGraphNode, len(stats)),
+		Edges: make([]TagGraphEdge, 0),
+	}
+	if len(stats) == 0 {
+		return graph, nil
+	}
+
+	names := make([]string, len(stats))
+	for i, s := range stats {
+		names[i] = s.Name
+	}
+
+	// 上级关系来自标签体系
+	var parents []struct {
+		Name   string
+		Parent string
+	}
+	err = r.db.WithContext(ctx).
+		Table("tags t").
+		Select("t.name, p.name AS parent").
+		Joins("JOIN tags p ON p.id = t.parent_id").
+		Scopes(inWorkspace("t.workspace_id")).
+		Where("t.name IN ?", names).
+		Scan(&parents).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tag parents: %w", err)
+	}
+	parentOf := make(map[string]string, len(parents))
+	for _, p := range parents {
+		parentOf[p.Name] = p.Parent
+	}
+
+	inGraph := make(map[string]bool, len(stats))
+	for i, s := range stats {
+		inGraph[s.Name] = true
+		graph.Nodes[i] = TagGraphNode{TagStat: s}
+		if parent, ok := parentOf[s.Name]; ok {
+			graph.Nodes[i].Parent = &parent
+		}
+	}
+	for _, s := range stats {
+		if parent, ok := parentOf[s.Name]; ok && inGraph[parent] {
+			graph.Edges = append(graph.Edges, TagGraphEdge{Source: parent, Target: s.Name, Type: TagEdgeParent, Weight: 1})
+		}
+	}
+
+	pairs, err := r.GetTagCooccurrence(ctx, names, minWeight)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pairs {
+		graph.Edges = append(graph.Edges, TagGraphEdge{Source: p.A, Target: p.B, Type: TagEdgeCooccurrence, Weight: p.Count})
+	}
+
+	return graph, nil
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestNormalizeTagKey(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"MySQL", "mysql"},
+		{"My SQL", "mysql"},
+		{" mysql\t", "mysql"},
+		{"message-queue", "messagequeue"},
+		{"Message Queue", "messagequeue"},
+		{"message_queue", "messagequeue"},
+		{"消息 队列", "消息队列"},
+		{"C++", "c++"},
+		{" - _ ", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeTagKey(tt.tag); got != tt.want {
+			t.Errorf("NormalizeTagKey(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterTagConcurrent(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := WithWorkspace(context.Background(), DefaultWorkspaceID)
+
+	// 不同写法同时登记，最终只能有一个规范标签，所有调用方得到相同的规范名
+	spellings := []string{"Message Queue", "message-queue", "MessageQueue", "message_queue", "MESSAGE QUEUE", "message queue", "Message-Queue", "messagequeue"}
+	results := make([]string, len(spellings))
+	errs := make([]error, len(spellings))
+	var wg sync.WaitGroup
+	for i, spelling := range spellings {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tags, err := repo.NormalizeTags(ctx, []string{spelling})
+			if err == nil && len(tags) == 1 {
+				results[i] = tags[0]
+			}
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("NormalizeTags(%q): %v", spellings[i], err)
+		}
+		if results[i] != results[0] {
+			t.Errorf("NormalizeTags(%q) = %q, want %q", spellings[i], results[i], results[0])
+		}
+	}
+
+	var tags int64
+	if err := repo.db.Model(&Tag{}).Count(&tags).Error; err != nil {
+		t.Fatal(err)
+	}
+	var aliases []TagAlias
+	if err := repo.db.Find(&aliases).Error; err != nil {
+		t.Fatal(err)
+	}
+	if tags != 1 || len(aliases) != 1 || aliases[0].Key != "messagequeue" {
+		t.Errorf("got %d tags and aliases %+v, want one tag with key messagequeue", tags, aliases)
+	}
+}
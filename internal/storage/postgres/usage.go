@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"paguu/internal/usage"
+	"time"
+)
+
+// InsertUsageEvents 批量写入用量事件，实现 usage.Store
+func (r *Repository) InsertUsageEvents(ctx context.Context, events []usage.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	records := make([]UsageEvent, len(events))
+	for i, event := range events {
+		records[i] = UsageEvent{
+			Source:           event.Source,
+			Provider:         event.Provider,
+			Operation:        event.Operation,
+			Model:            event.Model,
+			PromptTokens:     event.PromptTokens,
+			CompletionTokens: event.CompletionTokens,
+			TotalTokens:      event.TotalTokens,
+			Estimated:        event.Estimated,
+			LatencyMs:        event.Latency.Milliseconds(),
+			Cost:             event.Cost,
+			CreatedAt:        event.At,
+		}
+		if event.TaskID != "" {
+			records[i].TaskID = &event.TaskID
+		}
+		if event.Err != nil {
+			errText := event.Err.Error()
+			records[i].Error = &errText
+		}
+	}
+
+	if err := r.db.WithContext(ctx).Create(&records).Error; err != nil {
+		return fmt.Errorf("failed to insert usage events: %w", err)
+	}
+	return nil
+}
+
+// SumUsageSince 汇总指定时间之后的总费用和总 token 数，实现 usage.Store
+func (r *Repository) SumUsageSince(ctx context.Context, since time.Time) (float64, int64, error) {
+	var sum struct {
+		Cost   float64
+		Tokens int64
+	}
+	err := r.db.WithContext(ctx).Model(&UsageEvent{}).
+		Select("COALESCE(SUM(cost), 0) AS cost, COALESCE(SUM(total_tokens), 0) AS tokens").
+		Where("created_at >= ?", since).
+		Scan(&sum).Error
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to sum usage: %w", err)
+	}
+	return sum.Cost, sum.Tokens, nil
+}
+
+// UsageSummary 是按 天/模型/来源 聚合后的一行用量
+type UsageSummary struct {
+	Day              string  `json:"day"`
+	Model            string  `json:"model"`
+	Source           string  `json:"source"`
+	Calls            int64   `json:"calls"`
+	Errors           int64   `json:"errors"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+	AvgLatencyMs     float64 `json:"avg_latency_ms"`
+}
+
+// AggregateUsage 按 天/模型/来源 汇总 [from, to) 时间范围内的用量
+func (r *Repository) AggregateUsage(ctx context.Context, from, to time.Time) ([]UsageSummary, error) {
+	var summaries []UsageSummary
+
+	query := `
+		SELECT to_char(created_at, 'YYYY-MM-DD') AS day,
+		       model,
+		       source,
+		       COUNT(*) AS calls,
+		       COUNT(error) AS errors,
+		       SUM(prompt_tokens) AS prompt_tokens,
+		       SUM(completion_tokens) AS completion_tokens,
+		       SUM(total_tokens) AS total_tokens,
+		       SUM(cost) AS cost,
+		       AVG(latency_ms) AS avg_latency_ms
+		FROM usage_events
+		WHERE created_at >= ? AND created_at < ?
+		GROUP BY day, model, source
+		ORDER BY day DESC, model, source
+	`
+
+	err := r.db.WithContext(ctx).Raw(query, from, to).Scan(&summaries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate usage: %w", err)
+	}
+
+	return summaries, nil
+}
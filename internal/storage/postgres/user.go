@@ -0,0 +1,231 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUserNotFound 表示用户不存在
+	ErrUserNotFound = notFoundError("user_not_found", "user not found")
+	// ErrUserExists 表示用户名已被占用
+	ErrUserExists = conflictError("user_exists", "user already exists")
+	// ErrAPIKeyNotFound 表示 API key 不存在或已失效
+	ErrAPIKeyNotFound = notFoundError("api_key_not_found", "api key not found")
+)
+
+// CreateUser 创建用户并以 editor 角色加入 default 工作区，用户名已存在时返回 ErrUserExists
+func (r *Repository) CreateUser(ctx context.Context, name string) (*User, error) {
+	var existing int64
+	if err := r.db.WithContext(ctx).Model(&User{}).Where("name = ?", name).Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check user: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUserExists, name)
+	}
+
+	user := User{Name: name}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		member := WorkspaceMember{WorkspaceID: DefaultWorkspaceID, UserID: user.ID, Role: WorkspaceRoleEditor}
+		if err := tx.Create(&member).Error; err != nil {
+			return fmt.Errorf("failed to add user to default workspace: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUser 根据 ID 获取用户
+func (r *Repository) GetUser(ctx context.Context, id uint) (*User, error) {
+	var user User
+	err := r.db.WithContext(ctx).First(&user, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrUserNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// GetUserByName 根据用户名获取用户
+func (r *Repository) GetUserByName(ctx context.Context, name string) (*User, error) {
+	var user User
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, name)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// ListUsers 按 ID 升序列出所有用户
+func (r *Repository) ListUsers(ctx context.Context) ([]User, error) {
+	var users []User
+	if err := r.db.WithContext(ctx).Order("id").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, nil
+}
+
+// SetUserDisabled 停用或重新启用用户
+func (r *Repository) SetUserDisabled(ctx context.Context, id uint, disabled bool) (*User, error) {
+	var disabledAt *time.Time
+	if disabled {
+		now := time.Now()
+		disabledAt = &now
+	}
+	result := r.db.WithContext(ctx).Model(&User{}).Where("id = ?", id).Update("disabled_at", disabledAt)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrUserNotFound, id)
+	}
+	return r.GetUser(ctx, id)
+}
+
+// CreateAPIKey 保存 API key (只含哈希)
+func (r *Repository) CreateAPIKey(ctx context.Context, key *APIKey) error {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// ListAPIKeys 按 ID 升序列出用户的 API key，包括已吊销和已过期的
+func (r *Repository) ListAPIKeys(ctx context.Context, userID uint) ([]APIKey, error) {
+	var keys []APIKey
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey 吊销 API key，重复吊销不报错
+func (r *Repository) RevokeAPIKey(ctx context.Context, id uint) (*APIKey, error) {
+	var key APIKey
+	if err := r.db.WithContext(ctx).First(&key, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrAPIKeyNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	if key.RevokedAt != nil {
+		return &key, nil
+	}
+
+	now := time.Now()
+	if err := r.db.WithContext(ctx).Model(&key).Update("revoked_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	key.RevokedAt = &now
+	return &key, nil
+}
+
+// ActiveAPIKey 是一个有效的 API key 及其所属用户
+type ActiveAPIKey struct {
+	Key  APIKey
+	User User
+}
+
+// FindActiveAPIKey 根据哈希查找未吊销、未过期且用户未停用的 API key
+func (r *Repository) FindActiveAPIKey(ctx context.Context, keyHash string) (*ActiveAPIKey, error) {
+	return r.findActiveAPIKey(ctx, "key_hash = ?", keyHash)
+}
+
+// GetActiveAPIKey 根据 ID 查找未吊销、未过期且用户未停用的 API key，用于校验由其签发的 JWT
+func (r *Repository) GetActiveAPIKey(ctx context.Context, id uint) (*ActiveAPIKey, error) {
+	return r.findActiveAPIKey(ctx, "id = ?", id)
+}
+
+func (r *Repository) findActiveAPIKey(ctx context.Context, query string, arg any) (*ActiveAPIKey, error) {
+	var key APIKey
+	err := r.db.WithContext(ctx).
+		Where(query, arg).
+		Where("revoked_at IS NULL").
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to find api key: %w", err)
+	}
+
+	var user User
+	err = r.db.WithContext(ctx).Where("id = ? AND disabled_at IS NULL", key.UserID).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key user: %w", err)
+	}
+	return &ActiveAPIKey{Key: key, User: user}, nil
+}
+
+// TouchAPIKey 更新 API key 的最近使用时间，一分钟内的重复调用不写库
+func (r *Repository) TouchAPIKey(ctx context.Context, id uint) error {
+	now := time.Now()
+	err := r.db.WithContext(ctx).Model(&APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-time.Minute)).
+		Update("last_used_at", now).Error
+	if err != nil {
+		return fmt.Errorf("failed to touch api key: %w", err)
+	}
+	return nil
+}
+
+// CreateAuditLog 记录一次写请求
+func (r *Repository) CreateAuditLog(ctx context.Context, log *AuditLog) error {
+	if err := r.db.WithContext(ctx).Create(log).Error; err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
+
+// AuditLogFilter 限定审计日志列表，零值表示全部
+type AuditLogFilter struct {
+	UserName string
+	Route    string
+	Since    *time.Time
+}
+
+// ListAuditLogs 按时间倒序返回审计日志
+func (r *Repository) ListAuditLogs(ctx context.Context, filter AuditLogFilter, limit, offset int) ([]AuditLog, int64, error) {
+	var logs []AuditLog
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&AuditLog{})
+	if filter.UserName != "" {
+		query = query.Where("user_name = ?", filter.UserName)
+	}
+	if filter.Route != "" {
+		query = query.Where("route = ?", filter.Route)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&logs).Error
+	if err != nil {
+		return nil, total, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	return logs, total, nil
+}
@@ -0,0 +1,301 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ===================================================================
+// 工作区 (多团队隔离)
+// ===================================================================
+
+// DefaultWorkspaceID 是 default 工作区的 ID，升级前的数据和新用户归入该工作区
+const DefaultWorkspaceID uint = 1
+
+// DefaultWorkspaceSlug 是 default 工作区的标识
+const DefaultWorkspaceSlug = "default"
+
+// 工作区角色：owner 包含 editor，editor 包含 viewer
+const (
+	WorkspaceRoleViewer = "viewer" // 只读
+	WorkspaceRoleEditor = "editor" // 提交任务、修改文章和标签
+	WorkspaceRoleOwner  = "owner"  // 另外可以管理成员
+)
+
+// ValidWorkspaceRole 判断角色是否合法
+func ValidWorkspaceRole(role string) bool {
+	switch role {
+	case WorkspaceRoleViewer, WorkspaceRoleEditor, WorkspaceRoleOwner:
+		return true
+	}
+	return false
+}
+
+var (
+	// ErrWorkspaceNotFound 表示工作区不存在
+	ErrWorkspaceNotFound = notFoundError("workspace_not_found", "workspace not found")
+	// ErrWorkspaceExists 表示工作区标识已被占用
+	ErrWorkspaceExists = conflictError("workspace_exists", "workspace already exists")
+	// ErrMemberNotFound 表示用户不是工作区成员
+	ErrMemberNotFound = notFoundError("member_not_found", "workspace member not found")
+	// ErrNoWorkspace 表示 context 中没有指定工作区，属于调用方的编程错误
+	ErrNoWorkspace = errors.New("no workspace in context")
+)
+
+type workspaceKey struct{}
+
+// WithWorkspace 返回携带工作区的 context，Repository 的查询和写入都限定在该工作区内
+func WithWorkspace(ctx context.Context, workspaceID uint) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspaceID)
+}
+
+// WorkspaceFrom 返回 context 中的工作区，未指定时为 0 (不匹配任何工作区，不会退回 default)
+func WorkspaceFrom(ctx context.Context) uint {
+	if ctx != nil {
+		if id, ok := ctx.Value(workspaceKey{}).(uint); ok {
+			return id
+		}
+	}
+	return 0
+}
+
+// requireWorkspace 返回 context 中的工作区，未指定时返回 ErrNoWorkspace；写入前调用，避免数据落入错误的工作区
+func requireWorkspace(ctx context.Context) (uint, error) {
+	if id := WorkspaceFrom(ctx); id != 0 {
+		return id, nil
+	}
+	return 0, ErrNoWorkspace
+}
+
+// workspaceOf 返回 db 所绑定 context 中的工作区，db 可以是事务
+func workspaceOf(db *gorm.DB) uint {
+	return WorkspaceFrom(db.Statement.Context)
+}
+
+// inWorkspace 将查询限定在 db 所绑定 context 中的工作区，column 为 workspace_id 列 (有连接时需带表名或别名)
+// context 中没有工作区时查询以 ErrNoWorkspace 失败
+func inWorkspace(column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		workspaceID := workspaceOf(db)
+		if workspaceID == 0 {
+			db.AddError(ErrNoWorkspace)
+		}
+		return db.Where(column+" = ?", workspaceID)
+	}
+}
+
+// CreateWorkspace 创建工作区，ownerID 非 nil 时将该用户设为 owner
+func (r *Repository) CreateWorkspace(ctx context.Context, workspace *Workspace, ownerID *uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&Workspace{}).Where("slug = ?", workspace.Slug).Count(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check workspace: %w", err)
+		}
+		if existing > 0 {
+			return fmt.Errorf("%w: %s", ErrWorkspaceExists, workspace.Slug)
+		}
+		if err := tx.Create(workspace).Error; err != nil {
+			return fmt.Errorf("failed to create workspace: %w", err)
+		}
+		if ownerID == nil {
+			return nil
+		}
+		member := WorkspaceMember{WorkspaceID: workspace.ID, UserID: *ownerID, Role: WorkspaceRoleOwner}
+		if err := tx.Create(&member).Error; err != nil {
+			return fmt.Errorf("failed to add workspace owner: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetWorkspace 根据 ID 获取工作区
+func (r *Repository) GetWorkspace(ctx context.Context, id uint) (*Workspace, error) {
+	var workspace Workspace
+	err := r.db.WithContext(ctx).First(&workspace, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrWorkspaceNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	return &workspace, nil
+}
+
+// GetWorkspaceBySlug 根据标识获取工作区
+func (r *Repository) GetWorkspaceBySlug(ctx context.Context, slug string) (*Workspace, error) {
+	var workspace Workspace
+	err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&workspace).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrWorkspaceNotFound, slug)
+		}
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	return &workspace, nil
+}
+
+// WorkspaceWithRole 是工作区及调用者在其中的角色
+type WorkspaceWithRole struct {
+	Workspace
+	Role string `gorm:"column:role"` // 列出全部工作区时，非成员为空
+}
+
+// ListWorkspaces 按 ID 升序列出工作区；userID 非 nil 时只列出该用户所属的工作区
+func (r *Repository) ListWorkspaces(ctx context.Context, userID *uint) ([]WorkspaceWithRole, error) {
+	workspaces := make([]WorkspaceWithRole, 0)
+	query := r.db.WithContext(ctx).Model(&Workspace{})
+	if userID != nil {
+		query = query.Select("workspaces.*, m.role").
+			Joins("JOIN workspace_members m ON m.workspace_id = workspaces.id AND m.user_id = ?", *userID)
+	} else {
+		query = query.Select("workspaces.*, '' AS role")
+	}
+	if err := query.Order("workspaces.id").Find(&workspaces).Error; err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	return workspaces, nil
+}
+
+// WorkspaceUpdate 是可修改的工作区属性，nil 表示不修改；模型和模板设为空字符串表示恢复全局配置
+type WorkspaceUpdate struct {
+	Name               *string
+	EnrichModel        *string
+	EnrichTemplatePath *string
+}
+
+// UpdateWorkspace 修改工作区名称和丰富化配置
+func (r *Repository) UpdateWorkspace(ctx context.Context, id uint, update WorkspaceUpdate) (*Workspace, error) {
+	updates := make(map[string]interface{})
+	if update.Name != nil {
+		updates["name"] = *update.Name
+	}
+	if update.EnrichModel != nil {
+		updates["enrich_model"] = nullIfEmpty(*update.EnrichModel)
+	}
+	if update.EnrichTemplatePath != nil {
+		updates["enrich_template_path"] = nullIfEmpty(*update.EnrichTemplatePath)
+	}
+	if len(updates) > 0 {
+		result := r.db.WithContext(ctx).Model(&Workspace{}).Where("id = ?", id).Updates(updates)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to update workspace: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil, fmt.Errorf("%w: %d", ErrWorkspaceNotFound, id)
+		}
+	}
+	return r.GetWorkspace(ctx, id)
+}
+
+// nullIfEmpty 将空字符串转换为 NULL
+func nullIfEmpty(s string) *string {
+	if s = strings.TrimSpace(s); s == "" {
+		return nil
+	}
+	return &s
+}
+
+// GetWorkspaceRole 返回用户在工作区中的角色，不是成员时返回 ErrMemberNotFound
+func (r *Repository) GetWorkspaceRole(ctx context.Context, workspaceID, userID uint) (string, error) {
+	var member WorkspaceMember
+	err := r.db.WithContext(ctx).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrMemberNotFound
+		}
+		return "", fmt.Errorf("failed to get workspace member: %w", err)
+	}
+	return member.Role, nil
+}
+
+// WorkspaceMemberDetail 是附带用户名的工作区成员
+type WorkspaceMemberDetail struct {
+	WorkspaceMember
+	UserName string `gorm:"column:user_name"`
+}
+
+// ListWorkspaceMembers 按用户名列出工作区成员
+func (r *Repository) ListWorkspaceMembers(ctx context.Context, workspaceID uint) ([]WorkspaceMemberDetail, error) {
+	members := make([]WorkspaceMemberDetail, 0)
+	err := r.db.WithContext(ctx).Model(&WorkspaceMember{}).
+		Select("workspace_members.*, u.name AS user_name").
+		Joins("JOIN users u ON u.id = workspace_members.user_id").
+		Where("workspace_members.workspace_id = ?", workspaceID).
+		Order("u.name").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace members: %w", err)
+	}
+	return members, nil
+}
+
+// SetWorkspaceMember 添加成员或修改成员的角色
+func (r *Repository) SetWorkspaceMember(ctx context.Context, workspaceID, userID uint, role string) (*WorkspaceMember, error) {
+	member := WorkspaceMember{WorkspaceID: workspaceID, UserID: userID, Role: role}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workspace_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&member).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to set workspace member: %w", err)
+	}
+	return &member, nil
+}
+
+// RemoveWorkspaceMember 将用户移出工作区
+func (r *Repository) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID uint) error {
+	result := r.db.WithContext(ctx).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Delete(&WorkspaceMember{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove workspace member: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// migrateWorkspaces 在 workspaces 首次创建时执行：
+// 建立 ID 为 1 的 default 工作区 (已有数据的 workspace_id 列默认为 1)，把已有用户加为 editor，
+// 并把标签名、标签别名和标签订阅的唯一约束改为按工作区
+func migrateWorkspaces(db *gorm.DB) error {
+	slog.Info("正在建立 default 工作区并迁移唯一约束...")
+
+	statements := []string{
+		`INSERT INTO workspaces (id, slug, name, created_at)
+		 VALUES (1, 'default', 'Default', NOW())
+		 ON CONFLICT DO NOTHING`,
+
+		// 显式指定了 ID，需要同步序列
+		`SELECT setval(pg_get_serial_sequence('workspaces', 'id'), GREATEST((SELECT MAX(id) FROM workspaces), 1))`,
+
+		`INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+		 SELECT 1, id, 'editor', NOW() FROM users
+		 ON CONFLICT DO NOTHING`,
+
+		`DROP INDEX IF EXISTS idx_tags_name`,
+
+		`ALTER TABLE tag_aliases DROP CONSTRAINT IF EXISTS tag_aliases_pkey`,
+		`ALTER TABLE tag_aliases ADD PRIMARY KEY (workspace_id, key)`,
+
+		`ALTER TABLE tag_subscriptions DROP CONSTRAINT IF EXISTS tag_subscriptions_pkey`,
+		`ALTER TABLE tag_subscriptions ADD PRIMARY KEY (workspace_id, user_name, tag)`,
+	}
+	for _, sql := range statements {
+		if err := db.Exec(sql).Error; err != nil {
+			return fmt.Errorf("failed to migrate workspaces: %w", err)
+		}
+	}
+
+	slog.Info("工作区迁移完成", "workspace", DefaultWorkspaceSlug)
+	return nil
+}
@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+)
+
+// capturedQuery 是 DryRun 模式下生成的一条查询
+type capturedQuery struct {
+	sql  string
+	vars []any
+}
+
+// dryRunRepository 返回不连接数据库的 Repository，并记录每条查询的 SQL 和参数
+func dryRunRepository(t *testing.T) (*Repository, *[]capturedQuery) {
+	t.Helper()
+	db := dryRunDB(t)
+	var queries []capturedQuery
+	capture := func(db *gorm.DB) {
+		queries = append(queries, capturedQuery{sql: db.Statement.SQL.String(), vars: db.Statement.Vars})
+	}
+	if err := db.Callback().Query().After("gorm:query").Register("test:capture", capture); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("test:capture", capture); err != nil {
+		t.Fatal(err)
+	}
+	return &Repository{db: db}, &queries
+}
+
+func TestWorkspaceScopedQueries(t *testing.T) {
+	const workspaceID uint = 7
+	vector := make([]float32, 1536)
+
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, repo *Repository) error
+	}{
+		{"vector search", func(ctx context.Context, repo *Repository) error {
+			_, _, err := repo.VectorSearchArticles(ctx, vector, 10)
+			return err
+		}},
+		{"dedupe lookup", func(ctx context.Context, repo *Repository) error {
+			_, _, err := repo.FindClosestArticle(ctx, pgvector.NewVector(vector))
+			return err
+		}},
+		{"article occurrences", func(ctx context.Context, repo *Repository) error {
+			_, _, err := repo.ListArticleOccurrences(ctx, 1, 10, 0)
+			return err
+		}},
+		{"similar articles", func(ctx context.Context, repo *Repository) error {
+			_, err := repo.FindSimilarBySourceID(ctx, 1, 10)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, queries := dryRunRepository(t)
+			ctx := WithWorkspace(context.Background(), workspaceID)
+			if err := tt.run(ctx, repo); err != nil && !errors.Is(err, ErrArticleNotFound) {
+				t.Fatalf("run: %v", err)
+			}
+			if len(*queries) == 0 {
+				t.Fatal("no queries captured")
+			}
+			for _, q := range *queries {
+				if !strings.Contains(q.sql, "workspace_id = $") || !slices.Contains(q.vars, any(workspaceID)) {
+					t.Errorf("query not limited to workspace %d: %s %v", workspaceID, q.sql, q.vars)
+				}
+			}
+
+			// 没有工作区时失败，而不是查询某个默认工作区
+			repo, queries = dryRunRepository(t)
+			if err := tt.run(context.Background(), repo); !errors.Is(err, ErrNoWorkspace) {
+				t.Errorf("without workspace: error = %v, want ErrNoWorkspace", err)
+			}
+		})
+	}
+}
+
+func TestWriteRequiresWorkspace(t *testing.T) {
+	repo, _ := dryRunRepository(t)
+	ctx := context.Background()
+
+	if err := repo.InsertArticle(ctx, &Article{OriginalQuestion: "q"}); !errors.Is(err, ErrNoWorkspace) {
+		t.Errorf("InsertArticle error = %v, want ErrNoWorkspace", err)
+	}
+	if err := repo.EnqueueTask(ctx, "enrich_questions", nil); !errors.Is(err, ErrNoWorkspace) {
+		t.Errorf("EnqueueTask error = %v, want ErrNoWorkspace", err)
+	}
+	if err := repo.CreateAnswerAttempt(ctx, &AnswerAttempt{}); !errors.Is(err, ErrNoWorkspace) {
+		t.Errorf("CreateAnswerAttempt error = %v, want ErrNoWorkspace", err)
+	}
+	if _, err := repo.SubscribeTags(ctx, "alice", []string{"Go"}, false); !errors.Is(err, ErrNoWorkspace) {
+		t.Errorf("SubscribeTags error = %v, want ErrNoWorkspace", err)
+	}
+}
+
+func TestWorkspaceIsolation(t *testing.T) {
+	repo := newTestRepository(t)
+	teamB := &Workspace{Slug: "team-b", Name: "Team B"}
+	if err := repo.CreateWorkspace(context.Background(), teamB, nil); err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	ctxA := WithWorkspace(context.Background(), DefaultWorkspaceID)
+	ctxB := WithWorkspace(context.Background(), teamB.ID)
+
+	// 同一个问题在两个工作区各自入库，不会跨工作区去重合并
+	if status := ingest(t, ctxA, repo, "Go 的 GC", unitVector(0)); status != QuestionInsertStatusSuccess {
+		t.Fatalf("workspace A status = %s, want inserted", status)
+	}
+	if status := ingest(t, ctxB, repo, "Go 的 GC", unitVector(0)); status != QuestionInsertStatusSuccess {
+		t.Fatalf("workspace B status = %s, want inserted (not merged across workspaces)", status)
+	}
+
+	var idA, idB uint
+	for _, c := range []struct {
+		ctx context.Context
+		id  *uint
+	}{{ctxA, &idA}, {ctxB, &idB}} {
+		articles, _, err := repo.VectorSearchArticles(c.ctx, unitVector(0), 10)
+		if err != nil {
+			t.Fatalf("VectorSearchArticles: %v", err)
+		}
+		if len(articles) != 1 {
+			t.Fatalf("search returned %d articles, want only the workspace's own", len(articles))
+		}
+		*c.id = articles[0].ID
+	}
+	if idA == idB {
+		t.Fatalf("both workspaces see article %d", idA)
+	}
+
+	// 另一个工作区的文章及其出现记录不可见
+	if _, err := repo.GetArticleByID(ctxA, idB); !errors.Is(err, ErrArticleNotFound) {
+		t.Errorf("GetArticleByID across workspaces: error = %v, want ErrArticleNotFound", err)
+	}
+	occurrences, total, err := repo.ListArticleOccurrences(ctxA, idB, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 || len(occurrences) != 0 {
+		t.Errorf("workspace A sees %d occurrences of workspace B's article", total)
+	}
+	if _, total, err = repo.ListArticleOccurrences(ctxB, idB, 10, 0); err != nil || total != 1 {
+		t.Errorf("workspace B occurrences = %d, %v, want 1", total, err)
+	}
+}
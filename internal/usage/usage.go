@@ -0,0 +1,275 @@
+// 位于: internal/usage/usage.go
+package usage
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 调用提供方
+const (
+	ProviderArk    = "ark"
+	ProviderGemini = "gemini"
+)
+
+// 调用类型
+const (
+	OperationEnrich      = "enrich"
+	OperationEmbed       = "embed"
+	OperationRerank      = "rerank"
+	OperationClusterName = "cluster_name"
+	OperationAsk         = "ask"
+	OperationGrade       = "grade"
+	OperationInterview   = "interview"
+)
+
+// Event 描述一次 LLM / Embedding API 调用的用量
+type Event struct {
+	TaskID           string
+	Source           string
+	Provider         string
+	Operation        string
+	Model            string
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalTokens      int64
+	Estimated        bool // token 数由 API 未返回、按文本长度估算
+	Latency          time.Duration
+	Cost             float64
+	Err              error
+	At               time.Time // 调用完成时间，Record 时为零值则取当前时间
+}
+
+// Recorder 记录调用用量，实现者不应阻塞或让调用方失败
+type Recorder interface {
+	Record(ctx context.Context, event Event)
+}
+
+// ===================================================================
+// 通过 context 传递任务信息
+// ===================================================================
+
+type taskInfoKey struct{}
+
+type taskInfo struct {
+	taskID string
+	source string
+}
+
+// WithTask 将任务 ID 和来源附加到 context，供后续 API 调用记录用量时使用
+func WithTask(ctx context.Context, taskID, source string) context.Context {
+	return context.WithValue(ctx, taskInfoKey{}, taskInfo{taskID: taskID, source: source})
+}
+
+// TaskFromContext 取出 WithTask 附加的任务信息
+func TaskFromContext(ctx context.Context) (taskID, source string) {
+	info, _ := ctx.Value(taskInfoKey{}).(taskInfo)
+	return info.taskID, info.source
+}
+
+// EstimateTokens 在 API 未返回 token 数时按文本长度粗略估算 (约 4 字节 / token)
+func EstimateTokens(texts ...string) int64 {
+	var n int
+	for _, t := range texts {
+		n += len(t)
+	}
+	return int64((n + 3) / 4)
+}
+
+// ===================================================================
+// 价格与预算
+// ===================================================================
+
+// Price 是单个模型的价格，单位为每百万 token
+type Price struct {
+	InputPerMillion  float64 `mapstructure:"input_per_million"`
+	OutputPerMillion float64 `mapstructure:"output_per_million"`
+}
+
+// Cost 按价格计算一次调用的费用
+func (p Price) Cost(promptTokens, completionTokens int64) float64 {
+	return (float64(promptTokens)*p.InputPerMillion + float64(completionTokens)*p.OutputPerMillion) / 1e6
+}
+
+// Budget 是每日用量上限，0 表示不限制
+type Budget struct {
+	DailyCost   float64
+	DailyTokens int64
+}
+
+// Store 持久化用量事件并提供汇总查询
+type Store interface {
+	InsertUsageEvents(ctx context.Context, events []Event) error
+	SumUsageSince(ctx context.Context, since time.Time) (cost float64, tokens int64, err error)
+}
+
+// budgetCheckInterval 是预算检查结果的缓存时间，避免每次轮询都查询数据库
+const budgetCheckInterval = time.Minute
+
+// 用量事件的缓冲写入参数
+const (
+	bufferSize    = 1024            // 等待写入的事件上限，超出时丢弃并记录日志
+	flushBatch    = 100             // 单次批量写入的事件数
+	flushInterval = time.Second     // 缓冲中的事件最长等待时间
+	flushTimeout  = 5 * time.Second // 单次批量写入的超时时间
+)
+
+// Tracker 计算费用并将用量写入 Store，同时负责每日预算检查
+// 事件先进入缓冲，由后台 goroutine 批量写入，Record 不会阻塞调用方；退出前调用 Close 写入剩余事件
+type Tracker struct {
+	store  Store
+	prices map[string]Price // key 为小写的模型名
+	budget Budget
+
+	events  chan Event
+	stopped chan struct{}
+	closeMu sync.RWMutex // 保护 closed，Record 持读锁发送，Close 持写锁关闭 events
+	closed  bool
+
+	mu            sync.Mutex
+	checkedAt     time.Time
+	overBudget    bool
+	loggedOverrun bool
+}
+
+// NewTracker 创建 Tracker 并启动后台写入
+// prices 的模型名不区分大小写 (viper 读取配置时会把 map 的 key 转为小写)
+func NewTracker(store Store, prices map[string]Price, budget Budget) *Tracker {
+	normalized := make(map[string]Price, len(prices))
+	for model, price := range prices {
+		normalized[strings.ToLower(model)] = price
+	}
+	t := &Tracker{
+		store:   store,
+		prices:  normalized,
+		budget:  budget,
+		events:  make(chan Event, bufferSize),
+		stopped: make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Record 计算费用并将用量事件放入写入缓冲，缓冲已满时丢弃并记录日志
+func (t *Tracker) Record(ctx context.Context, event Event) {
+	if event.TaskID == "" && event.Source == "" {
+		event.TaskID, event.Source = TaskFromContext(ctx)
+	}
+	if event.TotalTokens == 0 {
+		event.TotalTokens = event.PromptTokens + event.CompletionTokens
+	}
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+	if price, ok := t.prices[strings.ToLower(event.Model)]; ok {
+		event.Cost = price.Cost(event.PromptTokens, event.CompletionTokens)
+	}
+
+	t.closeMu.RLock()
+	defer t.closeMu.RUnlock()
+	if t.closed {
+		slog.Warn("usage tracker closed, dropping event", "model", event.Model, "task_id", event.TaskID)
+		return
+	}
+	select {
+	case t.events <- event:
+	default:
+		slog.Error("usage buffer full, dropping event", "model", event.Model, "task_id", event.TaskID)
+	}
+}
+
+// Close 停止接收事件并写入缓冲中剩余的事件，可以重复调用
+// 之后的 Record 只记录日志并丢弃事件
+func (t *Tracker) Close() {
+	t.closeMu.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.events)
+	}
+	t.closeMu.Unlock()
+	<-t.stopped
+}
+
+// run 在后台批量写入事件：攒满 flushBatch 条或距离第一条事件超过 flushInterval 时写入
+func (t *Tracker) run() {
+	defer close(t.stopped)
+
+	batch := make([]Event, 0, flushBatch)
+	timer := time.NewTimer(flushInterval)
+	timer.Stop()
+	for {
+		select {
+		case event, ok := <-t.events:
+			if !ok {
+				t.flush(batch)
+				return
+			}
+			if len(batch) == 0 {
+				timer.Reset(flushInterval)
+			}
+			batch = append(batch, event)
+			if len(batch) >= flushBatch {
+				timer.Stop()
+				t.flush(batch)
+				batch = batch[:0]
+			}
+		case <-timer.C:
+			t.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush 写入一批事件，失败只记录日志
+func (t *Tracker) flush(batch []Event) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	if err := t.store.InsertUsageEvents(ctx, batch); err != nil {
+		slog.Error("record usage events error", "error", err, "events", len(batch))
+	}
+}
+
+// BudgetExceeded 检查当天用量是否超过预算，结果会缓存 budgetCheckInterval
+func (t *Tracker) BudgetExceeded(ctx context.Context) bool {
+	if t.budget.DailyCost <= 0 && t.budget.DailyTokens <= 0 {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if time.Since(t.checkedAt) < budgetCheckInterval {
+		return t.overBudget
+	}
+
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	cost, tokens, err := t.store.SumUsageSince(ctx, dayStart)
+	if err != nil {
+		// 查询失败时保持上一次的判断结果
+		slog.Error("check usage budget error", "error", err)
+		return t.overBudget
+	}
+	t.checkedAt = now
+
+	exceeded := (t.budget.DailyCost > 0 && cost >= t.budget.DailyCost) ||
+		(t.budget.DailyTokens > 0 && tokens >= t.budget.DailyTokens)
+
+	if exceeded && !t.loggedOverrun {
+		slog.Warn("已超过每日用量预算，暂停任务处理",
+			"cost", cost, "daily_cost", t.budget.DailyCost,
+			"tokens", tokens, "daily_tokens", t.budget.DailyTokens)
+	}
+	if !exceeded && t.overBudget {
+		slog.Info("用量预算已恢复，继续任务处理")
+	}
+	t.loggedOverrun = exceeded
+	t.overBudget = exceeded
+	return exceeded
+}
@@ -0,0 +1,147 @@
+package usage
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryStore 在内存中保存写入的事件
+type memoryStore struct {
+	mu      sync.Mutex
+	batches [][]Event
+}
+
+func (s *memoryStore) InsertUsageEvents(_ context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, append([]Event(nil), events...))
+	return nil
+}
+
+func (s *memoryStore) SumUsageSince(context.Context, time.Time) (float64, int64, error) {
+	return 0, 0, nil
+}
+
+func (s *memoryStore) events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var all []Event
+	for _, batch := range s.batches {
+		all = append(all, batch...)
+	}
+	return all
+}
+
+func TestTrackerCost(t *testing.T) {
+	// viper 会把配置中 map 的 key 转为小写
+	prices := map[string]Price{
+		"deepseek-v3-1-terminus": {InputPerMillion: 4, OutputPerMillion: 12},
+		"Gemini-Embedding-001":   {InputPerMillion: 0.15},
+	}
+	tests := []struct {
+		model    string
+		prompt   int64
+		output   int64
+		wantCost float64
+	}{
+		{"deepseek-v3-1-terminus", 1000, 500, (1000*4 + 500*12) / 1e6},
+		{"DeepSeek-V3-1-Terminus", 1000, 500, (1000*4 + 500*12) / 1e6},
+		{"gemini-embedding-001", 2000, 0, 2000 * 0.15 / 1e6},
+		{"unknown-model", 1000, 1000, 0},
+	}
+
+	store := &memoryStore{}
+	tracker := NewTracker(store, prices, Budget{})
+	for _, tt := range tests {
+		tracker.Record(context.Background(), Event{Model: tt.model, PromptTokens: tt.prompt, CompletionTokens: tt.output})
+	}
+	tracker.Close()
+
+	events := store.events()
+	if len(events) != len(tests) {
+		t.Fatalf("stored %d events, want %d", len(events), len(tests))
+	}
+	for i, tt := range tests {
+		if math.Abs(events[i].Cost-tt.wantCost) > 1e-12 {
+			t.Errorf("%s cost = %v, want %v", tt.model, events[i].Cost, tt.wantCost)
+		}
+		if events[i].TotalTokens != tt.prompt+tt.output {
+			t.Errorf("%s total tokens = %d, want %d", tt.model, events[i].TotalTokens, tt.prompt+tt.output)
+		}
+		if events[i].At.IsZero() {
+			t.Errorf("%s event has no timestamp", tt.model)
+		}
+	}
+}
+
+func TestTrackerFlush(t *testing.T) {
+	tests := []struct {
+		name        string
+		events      int
+		close       bool
+		wantBatches int
+	}{
+		{"close flushes the remainder", 3, true, 1},
+		{"full batches flush immediately", flushBatch*2 + 1, true, 3},
+		{"interval flushes without close", 2, false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryStore{}
+			tracker := NewTracker(store, nil, Budget{})
+			ctx := WithTask(context.Background(), "task-1", "api")
+			for range tt.events {
+				tracker.Record(ctx, Event{Model: "m", PromptTokens: 1})
+			}
+			if tt.close {
+				tracker.Close()
+			} else {
+				deadline := time.Now().Add(5 * flushInterval)
+				for len(store.events()) < tt.events && time.Now().Before(deadline) {
+					time.Sleep(10 * time.Millisecond)
+				}
+				defer tracker.Close()
+			}
+
+			events := store.events()
+			if len(events) != tt.events {
+				t.Fatalf("stored %d events, want %d", len(events), tt.events)
+			}
+			if len(store.batches) != tt.wantBatches {
+				t.Errorf("stored %d batches, want %d", len(store.batches), tt.wantBatches)
+			}
+			if events[0].TaskID != "task-1" || events[0].Source != "api" {
+				t.Errorf("event task = %q/%q, want task-1/api", events[0].TaskID, events[0].Source)
+			}
+		})
+	}
+}
+
+func TestTrackerRecordAfterClose(t *testing.T) {
+	store := &memoryStore{}
+	tracker := NewTracker(store, nil, Budget{})
+	tracker.Record(context.Background(), Event{Model: "m", PromptTokens: 1})
+
+	// Close 与 Record 并发时不能 panic，Close 之后的事件被丢弃
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				tracker.Record(context.Background(), Event{Model: "m", PromptTokens: 1})
+			}
+		}()
+	}
+	tracker.Close()
+	wg.Wait()
+	tracker.Record(context.Background(), Event{Model: "m", PromptTokens: 1})
+	tracker.Close()
+
+	if n := len(store.events()); n < 1 || n > 801 {
+		t.Errorf("stored %d events, want between 1 and 801", n)
+	}
+}